@@ -0,0 +1,125 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+)
+
+type Config struct {
+	// Timeout bounds the call to user-service.
+	Timeout time.Duration
+	// CacheTTL is how long a verification result is reused. It is also the
+	// longest a revoked key, or one whose creator was deactivated, keeps
+	// working.
+	CacheTTL time.Duration
+}
+
+type entry struct {
+	apiKey  *middleware.APIKey
+	expires time.Time
+}
+
+// Verifier checks API keys against user-service and caches the valid ones,
+// so a busy client does not cost a lookup per request. Invalid keys are not
+// cached, random keys would fill the cache.
+type Verifier struct {
+	proxy  *proxy.Proxy
+	config Config
+
+	mu      sync.Mutex
+	entries map[string]entry
+	// nextSweep is when expired entries are dropped next.
+	nextSweep time.Time
+}
+
+func NewVerifier(p *proxy.Proxy, config Config) *Verifier {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+
+	return &Verifier{
+		proxy:   p,
+		config:  config,
+		entries: make(map[string]entry),
+	}
+}
+
+// VerifyAPIKey implements middleware.APIKeyVerifier.
+func (v *Verifier) VerifyAPIKey(ctx context.Context, key string) (*middleware.APIKey, error) {
+	sum := sha256.Sum256([]byte(key))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	now := time.Now()
+	if apiKey, ok := v.cached(cacheKey, now); ok {
+		if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+			return nil, middleware.ErrInvalidAPIKey
+		}
+		return apiKey, nil
+	}
+
+	header := make(http.Header)
+	header.Set(middleware.APIKeyHeader, key)
+
+	status, body, err := v.proxy.Fetch(ctx, "user-service", "/internal/api-keys/verify", header, v.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, middleware.ErrInvalidAPIKey
+	default:
+		return nil, fmt.Errorf("user-service answered %d", status)
+	}
+
+	var envelope struct {
+		Data middleware.APIKey `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	v.store(cacheKey, &envelope.Data, now)
+	return &envelope.Data, nil
+}
+
+func (v *Verifier) cached(cacheKey string, now time.Time) (*middleware.APIKey, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	e, ok := v.entries[cacheKey]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+	return e.apiKey, true
+}
+
+func (v *Verifier) store(cacheKey string, apiKey *middleware.APIKey, now time.Time) {
+	if v.config.CacheTTL <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Sweeping once per TTL keeps the cache to the keys used within about
+	// two TTLs without walking it on every store.
+	if now.After(v.nextSweep) {
+		for k, e := range v.entries {
+			if now.After(e.expires) {
+				delete(v.entries, k)
+			}
+		}
+		v.nextSweep = now.Add(v.config.CacheTTL)
+	}
+	v.entries[cacheKey] = entry{apiKey: apiKey, expires: now.Add(v.config.CacheTTL)}
+}
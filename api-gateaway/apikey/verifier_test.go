@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+)
+
+// newTestVerifier verifies keys against a user-service that knows the key
+// "valid" only, and counts the lookups.
+func newTestVerifier(t *testing.T) (*Verifier, *atomic.Int32) {
+	t.Helper()
+
+	lookups := &atomic.Int32{}
+	userService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		if r.Header.Get(middleware.APIKeyHeader) != "valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":{"id":1,"name":"ci","user_id":3}}`))
+	}))
+	t.Cleanup(userService.Close)
+
+	upstreams, err := proxy.NewUpstreams(map[string]proxy.UpstreamConfig{
+		"user-service": {Instances: []string{userService.URL}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewVerifier(proxy.NewProxy(proxy.Config{}, upstreams), Config{CacheTTL: time.Minute}), lookups
+}
+
+func TestVerifierCachesValidKeys(t *testing.T) {
+	verifier, lookups := newTestVerifier(t)
+
+	for i := 0; i < 3; i++ {
+		apiKey, err := verifier.VerifyAPIKey(context.Background(), "valid")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if apiKey.UserID != 3 {
+			t.Fatalf("key of user %d, want 3", apiKey.UserID)
+		}
+	}
+	if got := lookups.Load(); got != 1 {
+		t.Errorf("%d lookups for one key, want 1", got)
+	}
+}
+
+func TestVerifierDoesNotCacheInvalidKeys(t *testing.T) {
+	verifier, lookups := newTestVerifier(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.VerifyAPIKey(context.Background(), "unknown"); !errors.Is(err, middleware.ErrInvalidAPIKey) {
+			t.Fatalf("error %v, want ErrInvalidAPIKey", err)
+		}
+	}
+	if got := lookups.Load(); got != 3 {
+		t.Errorf("%d lookups, want every invalid key looked up", got)
+	}
+	if len(verifier.entries) != 0 {
+		t.Errorf("%d cached entries, want none", len(verifier.entries))
+	}
+}
+
+// A cached key stops working when it expires, even while the entry itself
+// is still fresh.
+func TestVerifierChecksExpiryOfCachedKeys(t *testing.T) {
+	verifier, lookups := newTestVerifier(t)
+
+	if _, err := verifier.VerifyAPIKey(context.Background(), "valid"); err != nil {
+		t.Fatal(err)
+	}
+	if len(verifier.entries) != 1 {
+		t.Fatalf("%d cached entries, want 1", len(verifier.entries))
+	}
+	expiresAt := time.Now().Add(-time.Second)
+	for _, e := range verifier.entries {
+		e.apiKey.ExpiresAt = &expiresAt
+	}
+
+	if _, err := verifier.VerifyAPIKey(context.Background(), "valid"); !errors.Is(err, middleware.ErrInvalidAPIKey) {
+		t.Errorf("expired cached key: error %v, want ErrInvalidAPIKey", err)
+	}
+	if got := lookups.Load(); got != 1 {
+		t.Errorf("%d lookups, want the expired key answered from the cache", got)
+	}
+}
+
+func TestVerifierSweepsOncePerTTL(t *testing.T) {
+	verifier := NewVerifier(nil, Config{CacheTTL: time.Minute})
+	start := time.Now()
+
+	verifier.store("first", &middleware.APIKey{ID: 1}, start)
+	verifier.store("second", &middleware.APIKey{ID: 2}, start.Add(30*time.Second))
+	// A minute on, the store sweeps the first entry out.
+	verifier.store("third", &middleware.APIKey{ID: 3}, start.Add(61*time.Second))
+	if _, ok := verifier.entries["first"]; ok {
+		t.Error("expired entry kept after a sweep")
+	}
+
+	// The second entry expires at 90s but stays until the sweep after 121s.
+	verifier.store("fourth", &middleware.APIKey{ID: 4}, start.Add(100*time.Second))
+	if _, ok := verifier.entries["second"]; !ok {
+		t.Error("entries swept before a TTL passed since the last sweep")
+	}
+	verifier.store("fifth", &middleware.APIKey{ID: 5}, start.Add(122*time.Second))
+	if _, ok := verifier.entries["second"]; ok {
+		t.Error("expired entry kept after the next sweep")
+	}
+	if len(verifier.entries) != 2 {
+		t.Errorf("%d entries, want the fourth and fifth", len(verifier.entries))
+	}
+}
@@ -0,0 +1,76 @@
+package bff
+
+import (
+	"encoding/json"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Config struct {
+	// Timeout bounds every upstream call made for a composite response.
+	Timeout time.Duration
+	// CacheTTL is how long a complete composite response is reused. Partial
+	// responses are never cached.
+	CacheTTL time.Duration
+}
+
+// BFF serves composite endpoints that build one screen of the frontend from
+// several upstream services in a single round trip.
+type BFF struct {
+	proxy  *proxy.Proxy
+	config Config
+	cache  *cache
+}
+
+func New(p *proxy.Proxy, config Config) *BFF {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+
+	return &BFF{
+		proxy:  p,
+		config: config,
+		cache:  newCache(config.CacheTTL),
+	}
+}
+
+// Register adds the composite endpoints. They expect the JWT middleware to
+// have run already.
+func (b *BFF) Register(router fiber.Router) {
+	router.Get("/keeper/home", middleware.PermissionAuthMiddleware("dashboard:merchant"), b.KeeperHome)
+}
+
+func (b *BFF) respond(c *fiber.Ctx, key string, status int, message string, data interface{}, partial bool) error {
+	body, err := json.Marshal(fiber.Map{
+		"message": message,
+		"data":    data,
+		"partial": partial,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to build response",
+		})
+	}
+
+	if status == fiber.StatusOK && !partial {
+		b.cache.set(key, body)
+	}
+
+	c.Set("X-Cache", "MISS")
+	c.Type("json")
+	return c.Status(status).Send(body)
+}
+
+func (b *BFF) cached(c *fiber.Ctx, key string) (bool, error) {
+	body, ok := b.cache.get(key)
+	if !ok {
+		return false, nil
+	}
+
+	c.Set("X-Cache", "HIT")
+	c.Type("json")
+	return true, c.Status(fiber.StatusOK).Send(body)
+}
@@ -0,0 +1,116 @@
+package bff
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"warehouse-go/api-gateaway/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// KeeperHomeResponse is everything the POS home screen of a keeper shows.
+type KeeperHomeResponse struct {
+	Merchant           Section `json:"merchant"`
+	MerchantProducts   Section `json:"merchant_products"`
+	Dashboard          Section `json:"dashboard"`
+	RecentTransactions Section `json:"recent_transactions"`
+}
+
+func (r KeeperHomeResponse) complete() bool {
+	return r.Merchant.OK() && r.MerchantProducts.OK() && r.Dashboard.OK() && r.RecentTransactions.OK()
+}
+
+// KeeperHome loads one of the merchants the calling keeper is assigned to,
+// the one named by ?merchant_id= or else the first, and then, in parallel,
+// its products, its dashboard and its latest transactions.
+func (b *BFF) KeeperHome(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok || userID == 0 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Unauthorized",
+		})
+	}
+
+	requested := uint(c.QueryInt("merchant_id"))
+	key := fmt.Sprintf("keeper-home:%d:%d", userID, requested)
+	if hit, err := b.cached(c, key); hit {
+		return err
+	}
+
+	ctx := c.UserContext()
+	header := middleware.IdentityHeader(c)
+
+	var home KeeperHomeResponse
+	assigned := b.fetchSection(ctx, "merchant-service", fmt.Sprintf("/api/v1/merchants/keeper/%d", userID), header)
+	if assigned.OK() {
+		var keeper struct {
+			MerchantIDs []uint `json:"merchant_ids"`
+		}
+		if err := json.Unmarshal(assigned.Data, &keeper); err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"message": "Invalid merchant response",
+			})
+		}
+		if len(keeper.MerchantIDs) == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "No merchant is assigned to this keeper",
+			})
+		}
+
+		merchantID := keeper.MerchantIDs[0]
+		if requested != 0 {
+			if !slices.Contains(keeper.MerchantIDs, requested) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"message": "You are not assigned to this merchant",
+				})
+			}
+			merchantID = requested
+		}
+
+		home.Merchant = b.fetchSection(ctx, "merchant-service", fmt.Sprintf("/api/v1/merchants/%d", merchantID), header)
+	} else {
+		home.Merchant = assigned
+	}
+
+	if !home.Merchant.OK() {
+		// Everything else is looked up by merchant, so nothing can be loaded.
+		dependent := failed("merchant-service", http.StatusFailedDependency, "Merchant could not be loaded")
+		home.MerchantProducts = dependent
+		home.Dashboard = dependent
+		home.RecentTransactions = dependent
+		return b.respond(c, key, fiber.StatusBadGateway, "Keeper home could not be loaded", home, true)
+	}
+
+	var merchant struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(home.Merchant.Data, &merchant); err != nil || merchant.ID == 0 {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"message": "Invalid merchant response",
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		home.MerchantProducts = b.fetchSection(ctx, "merchant-service", fmt.Sprintf("/api/v1/merchant-products?merchant_id=%d&limit=100", merchant.ID), header)
+	}()
+	go func() {
+		defer wg.Done()
+		home.Dashboard = b.fetchSection(ctx, "transaction-service", fmt.Sprintf("/api/v1/dashboard/keeper/merchant/%d", merchant.ID), header)
+	}()
+	go func() {
+		defer wg.Done()
+		home.RecentTransactions = b.fetchSection(ctx, "transaction-service", fmt.Sprintf("/api/v1/transactions?merchant_id=%d&limit=5&sort_by=created_at&sort_order=desc", merchant.ID), header)
+	}()
+	wg.Wait()
+
+	if !home.complete() {
+		return b.respond(c, key, fiber.StatusOK, "Keeper home partially loaded", home, true)
+	}
+	return b.respond(c, key, fiber.StatusOK, "Keeper home fetched successfully", home, false)
+}
@@ -0,0 +1,58 @@
+package bff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"warehouse-go/api-gateaway/proxy"
+)
+
+// Section is one part of a composite response. Exactly one of Data and Error
+// is set, so a client can render the parts that loaded and mark the rest.
+type Section struct {
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *SectionError   `json:"error,omitempty"`
+}
+
+type SectionError struct {
+	Service string `json:"service"`
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message"`
+}
+
+func (s Section) OK() bool {
+	return s.Error == nil
+}
+
+func failed(service string, status int, message string) Section {
+	return Section{Error: &SectionError{Service: service, Status: status, Message: message}}
+}
+
+// fetchSection loads the "data" field of a standard upstream response.
+func (b *BFF) fetchSection(ctx context.Context, service, path string, header http.Header) Section {
+	status, body, err := b.proxy.Fetch(ctx, service, path, header, b.config.Timeout)
+	if err != nil {
+		switch {
+		case errors.Is(err, proxy.ErrCircuitOpen):
+			return failed(service, http.StatusServiceUnavailable, "Service is temporarily unavailable")
+		case errors.Is(err, context.DeadlineExceeded):
+			return failed(service, http.StatusGatewayTimeout, "Service did not respond in time")
+		default:
+			return failed(service, http.StatusBadGateway, "Service Unavailable")
+		}
+	}
+
+	var envelope struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return failed(service, http.StatusBadGateway, "Invalid response from service")
+	}
+
+	if status != http.StatusOK {
+		return failed(service, status, envelope.Message)
+	}
+	return Section{Data: envelope.Data}
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-go/api-gateaway/routing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "gwcache:"
+
+type Config struct {
+	// URL is the RabbitMQ connection string the invalidation events are
+	// consumed from.
+	URL string
+	// Timeout bounds every Redis call, so a slow Redis only costs a cache miss.
+	Timeout time.Duration
+	// MaxBodySize is the largest response body that is stored, in bytes.
+	MaxBodySize int
+	// ReconnectDelay is the wait between attempts to reach RabbitMQ.
+	ReconnectDelay time.Duration
+}
+
+// entry is a stored upstream response.
+type entry struct {
+	Status       int       `json:"status"`
+	ContentType  string    `json:"content_type"`
+	CacheControl string    `json:"cache_control,omitempty"`
+	ETag         string    `json:"etag"`
+	Body         []byte    `json:"body"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// Cache keeps upstream GET responses of the routes that opt in with
+// cache_ttl in Redis, shared by every gateway instance.
+//
+// Entries are never deleted on invalidation. Each route has a generation
+// counter that is part of every key; bumping it makes all older entries
+// unreachable and they expire on their own.
+type Cache struct {
+	redis  *redis.Client
+	config Config
+	routes []routing.Route
+}
+
+func New(client *redis.Client, routes []routing.Route, config Config) *Cache {
+	if config.Timeout <= 0 {
+		config.Timeout = 500 * time.Millisecond
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = 5 * time.Second
+	}
+
+	return &Cache{
+		redis:  client,
+		config: config,
+		routes: routes,
+	}
+}
+
+// Middleware serves GET requests on route from the cache and stores the
+// upstream responses. Successful writes on the route drop its entries. It
+// must run after auth and role checks, since a hit never reaches the
+// upstream.
+func (ca *Cache) Middleware(route routing.Route) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet:
+			return ca.serve(c, route)
+		case fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status >= 200 && status < 300 {
+			ca.invalidate(c.UserContext(), route.Prefix)
+		}
+		return nil
+	}
+}
+
+func (ca *Cache) serve(c *fiber.Ctx, route routing.Route) error {
+	request := parseCacheControl(c.Get(fiber.HeaderCacheControl))
+	if ca.redis == nil || request.has("no-store") {
+		return ca.forward(c, route, "", "BYPASS")
+	}
+
+	key, err := ca.key(c.UserContext(), route.Prefix, c.OriginalURL())
+	if err != nil {
+		log.Printf("Response cache error for %s: %v", route.Prefix, err)
+		return ca.forward(c, route, "", "BYPASS")
+	}
+
+	// no-cache and max-age=0 ask for a fresh response; it is still stored
+	// for the callers that come after.
+	if request.has("no-cache") || request.value("max-age") == "0" || c.Get(fiber.HeaderPragma) == "no-cache" {
+		return ca.forward(c, route, key, "REFRESH")
+	}
+
+	cached, err := ca.get(c.UserContext(), key)
+	if err != nil {
+		log.Printf("Response cache error for %s: %v", route.Prefix, err)
+		return ca.forward(c, route, "", "BYPASS")
+	}
+	if cached == nil {
+		return ca.forward(c, route, key, "MISS")
+	}
+
+	c.Set("X-Cache", "HIT")
+	c.Set(fiber.HeaderAge, strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+	c.Set(fiber.HeaderETag, cached.ETag)
+	setCacheControl(c, cached.CacheControl)
+	if notModified(c, cached.ETag) {
+		return nil
+	}
+
+	c.Set(fiber.HeaderContentType, cached.ContentType)
+	return c.Status(cached.Status).Send(cached.Body)
+}
+
+// forward passes the request on to the upstream, tags the response with an
+// ETag and stores it under key when key is set and the upstream allows it.
+func (ca *Cache) forward(c *fiber.Ctx, route routing.Route, key, state string) error {
+	if err := c.Next(); err != nil {
+		return err
+	}
+
+	c.Set("X-Cache", state)
+
+	resp := c.Response()
+	if resp.StatusCode() != fiber.StatusOK {
+		return nil
+	}
+
+	etag := string(resp.Header.Peek(fiber.HeaderETag))
+	if etag == "" {
+		etag = makeETag(resp.Body())
+		c.Set(fiber.HeaderETag, etag)
+	}
+
+	upstreamCacheControl := string(resp.Header.Peek(fiber.HeaderCacheControl))
+	if key != "" {
+		if ttl := storeTTL(time.Duration(route.CacheTTL), parseCacheControl(upstreamCacheControl)); ttl > 0 && len(resp.Body()) <= ca.config.MaxBodySize {
+			ca.set(c.UserContext(), key, entry{
+				Status:       resp.StatusCode(),
+				ContentType:  string(resp.Header.ContentType()),
+				CacheControl: upstreamCacheControl,
+				ETag:         etag,
+				Body:         resp.Body(),
+				StoredAt:     time.Now(),
+			}, ttl)
+		}
+	}
+
+	setCacheControl(c, upstreamCacheControl)
+	notModified(c, etag)
+	return nil
+}
+
+func (ca *Cache) key(ctx context.Context, prefix, url string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ca.config.Timeout)
+	defer cancel()
+
+	generation, err := ca.redis.Get(ctx, generationKey(prefix)).Result()
+	if errors.Is(err, redis.Nil) {
+		generation = "0"
+	} else if err != nil {
+		return "", err
+	}
+
+	return keyPrefix + prefix + ":" + generation + ":" + url, nil
+}
+
+func (ca *Cache) get(ctx context.Context, key string) (*entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, ca.config.Timeout)
+	defer cancel()
+
+	data, err := ca.redis.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cached entry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		// Treat an unreadable entry as a miss, the next store overwrites it.
+		return nil, nil
+	}
+	return &cached, nil
+}
+
+func (ca *Cache) set(ctx context.Context, key string, cached entry, ttl time.Duration) {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		log.Printf("Response cache error for %s: %v", key, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ca.config.Timeout)
+	defer cancel()
+
+	if err := ca.redis.Set(ctx, key, data, ttl).Err(); err != nil {
+		log.Printf("Response cache error for %s: %v", key, err)
+	}
+}
+
+// invalidate drops every cached response of the route with the given prefix.
+func (ca *Cache) invalidate(ctx context.Context, prefix string) {
+	if ca.redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ca.config.Timeout)
+	defer cancel()
+
+	if err := ca.redis.Incr(ctx, generationKey(prefix)).Err(); err != nil {
+		log.Printf("Response cache invalidation failed for %s: %v", prefix, err)
+	}
+}
+
+func generationKey(prefix string) string {
+	return keyPrefix + "gen:" + prefix
+}
+
+// makeETag returns a strong validator for body.
+func makeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified answers 304 when the request's If-None-Match matches etag.
+func notModified(c *fiber.Ctx, etag string) bool {
+	ifNoneMatch := c.Get(fiber.HeaderIfNoneMatch)
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			c.Status(fiber.StatusNotModified)
+			c.Response().ResetBody()
+			c.Response().Header.Del(fiber.HeaderContentType)
+			return true
+		}
+	}
+	return false
+}
+
+// setCacheControl keeps the upstream policy. Without one, clients are asked
+// to revalidate every time, which the ETag makes cheap.
+func setCacheControl(c *fiber.Ctx, upstream string) {
+	if upstream == "" {
+		upstream = "private, no-cache"
+	}
+	c.Set(fiber.HeaderCacheControl, upstream)
+}
+
+// storeTTL is how long a response may be kept: the route TTL, shortened by
+// the upstream's max-age, or zero when the upstream forbids shared caching.
+func storeTTL(routeTTL time.Duration, upstream cacheControl) time.Duration {
+	if upstream.has("no-store") || upstream.has("no-cache") || upstream.has("private") {
+		return 0
+	}
+
+	maxAge := upstream.value("s-maxage")
+	if maxAge == "" {
+		maxAge = upstream.value("max-age")
+	}
+	if maxAge != "" {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds <= 0 {
+			return 0
+		}
+		if upstreamTTL := time.Duration(seconds) * time.Second; upstreamTTL < routeTTL {
+			return upstreamTTL
+		}
+	}
+	return routeTTL
+}
+
+// cacheControl holds the directives of a Cache-Control header, lower cased,
+// with the value of the directives that have one.
+type cacheControl map[string]string
+
+func parseCacheControl(header string) cacheControl {
+	directives := cacheControl{}
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) value(name string) string {
+	return cc[name]
+}
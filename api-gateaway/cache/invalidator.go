@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Exchange is the topic exchange product-service publishes catalog changes
+// to, with the event type (e.g. product.updated) as routing key.
+const Exchange = "catalog_events"
+
+// Start consumes the catalog events in the background until ctx is done and
+// drops the cached responses of every route whose cache_invalidate matches.
+// Without Redis or without such routes there is nothing to do.
+func (ca *Cache) Start(ctx context.Context) {
+	if ca.redis == nil || !ca.hasInvalidation() {
+		return
+	}
+
+	go func() {
+		for {
+			if err := ca.consume(ctx); err != nil {
+				log.Printf("Cache invalidation consumer stopped: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(ca.config.ReconnectDelay):
+			}
+		}
+	}()
+}
+
+func (ca *Cache) hasInvalidation() bool {
+	for _, route := range ca.routes {
+		if route.CacheTTL > 0 && len(route.CacheInvalidate) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (ca *Cache) consume(ctx context.Context) error {
+	conn, err := amqp.Dial(ca.config.URL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(Exchange, "topic", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	// The cache is shared through Redis, but every instance consumes its own
+	// copy: bumping a generation twice is harmless, missing one is not.
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := ch.QueueBind(q.Name, "#", Exchange, false, nil); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(q.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Cache invalidation consumer listening on %s", Exchange)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return amqp.ErrClosed
+			}
+			ca.handle(ctx, msg.RoutingKey)
+		}
+	}
+}
+
+func (ca *Cache) handle(ctx context.Context, routingKey string) {
+	for _, route := range ca.routes {
+		if route.CacheTTL == 0 {
+			continue
+		}
+		for _, pattern := range route.CacheInvalidate {
+			if topicMatch(pattern, routingKey) {
+				ca.invalidate(ctx, route.Prefix)
+				break
+			}
+		}
+	}
+}
+
+// topicMatch matches a routing key against a binding pattern the way a
+// RabbitMQ topic exchange does: * is exactly one word, # zero or more.
+func topicMatch(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchWords(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchWords(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && pattern[0] == key[0] && matchWords(pattern[1:], key[1:])
+	}
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"time"
+	"warehouse-go/api-gateaway/apikey"
+)
+
+func LoadAPIKeyConfig() apikey.Config {
+	return apikey.Config{
+		Timeout:  parseDuration(getEnv("API_KEY_TIMEOUT", "3s"), 3*time.Second),
+		CacheTTL: parseDuration(getEnv("API_KEY_CACHE_TTL", "30s"), 30*time.Second),
+	}
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"time"
+	"warehouse-go/api-gateaway/bff"
+)
+
+func LoadBFFConfig() bff.Config {
+	return bff.Config{
+		Timeout:  parseDuration(getEnv("BFF_TIMEOUT", "3s"), 3*time.Second),
+		CacheTTL: parseDuration(getEnv("BFF_CACHE_TTL", "15s"), 15*time.Second),
+	}
+}
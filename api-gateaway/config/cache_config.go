@@ -0,0 +1,21 @@
+package config
+
+import (
+	"strconv"
+	"time"
+	"warehouse-go/api-gateaway/cache"
+)
+
+func LoadCacheConfig() cache.Config {
+	maxBodySize, err := strconv.Atoi(getEnv("CACHE_MAX_BODY_SIZE", "1048576"))
+	if err != nil {
+		maxBodySize = 1 << 20
+	}
+
+	return cache.Config{
+		URL:            rabbitMQURL(),
+		Timeout:        parseDuration(getEnv("CACHE_TIMEOUT", "500ms"), 500*time.Millisecond),
+		MaxBodySize:    maxBodySize,
+		ReconnectDelay: parseDuration(getEnv("CACHE_RECONNECT_DELAY", "5s"), 5*time.Second),
+	}
+}
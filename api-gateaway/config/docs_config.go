@@ -0,0 +1,14 @@
+package config
+
+import (
+	"time"
+	"warehouse-go/api-gateaway/docs"
+)
+
+func LoadDocsConfig() docs.Config {
+	return docs.Config{
+		SpecPath: getEnv("DOCS_SPEC_PATH", "/openapi.json"),
+		Timeout:  parseDuration(getEnv("DOCS_FETCH_TIMEOUT", "5s"), 5*time.Second),
+		CacheTTL: parseDuration(getEnv("DOCS_CACHE_TTL", "1m"), time.Minute),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"strconv"
+	"time"
+	"warehouse-go/api-gateaway/gql"
+)
+
+func LoadGraphQLConfig() gql.Config {
+	concurrency, err := strconv.Atoi(getEnv("GRAPHQL_CONCURRENCY", "8"))
+	if err != nil {
+		concurrency = 8
+	}
+
+	maxDepth, err := strconv.Atoi(getEnv("GRAPHQL_MAX_DEPTH", "8"))
+	if err != nil {
+		maxDepth = 8
+	}
+
+	return gql.Config{
+		Timeout:     parseDuration(getEnv("GRAPHQL_TIMEOUT", "5s"), 5*time.Second),
+		BatchWait:   parseDuration(getEnv("GRAPHQL_BATCH_WAIT", "2ms"), 2*time.Millisecond),
+		Concurrency: concurrency,
+		MaxDepth:    maxDepth,
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-go/api-gateaway/oidc"
+)
+
+// LoadOIDCConfig reads the single sign-on settings. SSO stays off until
+// OIDC_ISSUER and OIDC_CLIENT_ID are set. OIDC_GROUP_ROLES is a comma
+// separated list of group=role pairs, e.g. "warehouse-managers=Manager".
+// OIDC_MFA_AMR and OIDC_MFA_ACR list, space separated, the amr and acr values
+// that count as a second factor; OIDC_TRUST_IDP_MFA counts every login at
+// the provider.
+func LoadOIDCConfig() oidc.Config {
+	autoProvision, err := strconv.ParseBool(getEnv("OIDC_AUTO_PROVISION", "true"))
+	if err != nil {
+		autoProvision = true
+	}
+
+	trustMFA, err := strconv.ParseBool(getEnv("OIDC_TRUST_IDP_MFA", "false"))
+	if err != nil {
+		trustMFA = false
+	}
+
+	groupRoles := make(map[string]string)
+	for _, pair := range strings.Split(getEnv("OIDC_GROUP_ROLES", ""), ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(group) == "" || strings.TrimSpace(role) == "" {
+			continue
+		}
+		groupRoles[strings.TrimSpace(group)] = strings.TrimSpace(role)
+	}
+
+	return oidc.Config{
+		Issuer:        getEnv("OIDC_ISSUER", ""),
+		ClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+		Scopes:        strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+		GroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		GroupRoles:    groupRoles,
+		DefaultRole:   getEnv("OIDC_DEFAULT_ROLE", ""),
+		AutoProvision: autoProvision,
+		MFAMethods:    strings.Fields(getEnv("OIDC_MFA_AMR", "mfa")),
+		MFALevels:     strings.Fields(getEnv("OIDC_MFA_ACR", "")),
+		TrustMFA:      trustMFA,
+		StateTTL:      parseDuration(getEnv("OIDC_STATE_TTL", "10m"), 10*time.Minute),
+		Timeout:       parseDuration(getEnv("OIDC_TIMEOUT", "5s"), 5*time.Second),
+		KeysTTL:       parseDuration(getEnv("OIDC_KEYS_TTL", "1h"), time.Hour),
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strconv"
+	"time"
+	"warehouse-go/api-gateaway/proxy"
+)
+
+func LoadProxyConfig() proxy.Config {
+	maxAttempts, err := strconv.Atoi(getEnv("PROXY_RETRY_MAX_ATTEMPTS", "3"))
+	if err != nil {
+		maxAttempts = 3
+	}
+
+	failureThreshold, err := strconv.Atoi(getEnv("BREAKER_FAILURE_THRESHOLD", "5"))
+	if err != nil {
+		failureThreshold = 5
+	}
+
+	halfOpenMaxRequests, err := strconv.Atoi(getEnv("BREAKER_HALF_OPEN_MAX_REQUESTS", "1"))
+	if err != nil {
+		halfOpenMaxRequests = 1
+	}
+
+	return proxy.Config{
+		Timeout: parseDuration(getEnv("PROXY_TIMEOUT", "10s"), 10*time.Second),
+		Retry: proxy.RetryConfig{
+			MaxAttempts: maxAttempts,
+			BaseDelay:   parseDuration(getEnv("PROXY_RETRY_BASE_DELAY", "100ms"), 100*time.Millisecond),
+			MaxDelay:    parseDuration(getEnv("PROXY_RETRY_MAX_DELAY", "2s"), 2*time.Second),
+		},
+		Breaker: proxy.BreakerConfig{
+			FailureThreshold:    failureThreshold,
+			OpenTimeout:         parseDuration(getEnv("BREAKER_OPEN_TIMEOUT", "30s"), 30*time.Second),
+			HalfOpenMaxRequests: halfOpenMaxRequests,
+		},
+	}
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
+
+func LoadHealthCheckConfig() proxy.HealthCheckConfig {
+	return proxy.HealthCheckConfig{
+		Interval: parseDuration(getEnv("HEALTH_CHECK_INTERVAL", "10s"), 10*time.Second),
+		Timeout:  parseDuration(getEnv("HEALTH_CHECK_TIMEOUT", "2s"), 2*time.Second),
+		Path:     getEnv("HEALTH_CHECK_PATH", "/health"),
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+)
+
+// LoadRateLimitConfig reads the limiter settings. RATE_LIMIT_ROLE_QUOTAS is a
+// comma separated list of role=max pairs, e.g. "Manager=600,Keeper=300".
+func LoadRateLimitConfig() middleware.RedisRateLimiterConfig {
+	config := middleware.DefaultRateLimiterConfig()
+
+	if max, err := strconv.Atoi(getEnv("RATE_LIMIT_MAX", "")); err == nil && max > 0 {
+		config.Max = max
+	}
+	if globalMax, err := strconv.Atoi(getEnv("RATE_LIMIT_GLOBAL_MAX", "1000")); err == nil && globalMax > 0 {
+		config.GlobalMax = globalMax
+	}
+	config.Expiration = parseDuration(getEnv("RATE_LIMIT_WINDOW", "1m"), time.Minute)
+	config.FailOpen, _ = strconv.ParseBool(getEnv("RATE_LIMIT_FAIL_OPEN", "false"))
+	if apiKeyMax, err := strconv.Atoi(getEnv("RATE_LIMIT_API_KEY_MAX", "300")); err == nil && apiKeyMax > 0 {
+		config.APIKeyMax = apiKeyMax
+	}
+
+	config.RoleQuotas = make(map[string]int)
+	for _, pair := range strings.Split(getEnv("RATE_LIMIT_ROLE_QUOTAS", ""), ",") {
+		role, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if quota, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && quota > 0 {
+			config.RoleQuotas[strings.TrimSpace(role)] = quota
+		}
+	}
+
+	return config
+}
+
+func LoadLoginGuardConfig() middleware.LoginGuardConfig {
+	freeAttempts, err := strconv.Atoi(getEnv("LOGIN_FREE_ATTEMPTS", "3"))
+	if err != nil {
+		freeAttempts = 3
+	}
+
+	return middleware.LoginGuardConfig{
+		FreeAttempts: freeAttempts,
+		BaseDelay:    parseDuration(getEnv("LOGIN_BASE_DELAY", "1s"), time.Second),
+		MaxDelay:     parseDuration(getEnv("LOGIN_MAX_DELAY", "5m"), 5*time.Minute),
+		Window:       parseDuration(getEnv("LOGIN_FAILURE_WINDOW", "15m"), 15*time.Minute),
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"warehouse-go/api-gateaway/realtime"
+)
+
+func LoadRealtimeConfig() realtime.Config {
+	bufferSize, err := strconv.Atoi(getEnv("REALTIME_BUFFER_SIZE", "32"))
+	if err != nil {
+		bufferSize = 32
+	}
+
+	return realtime.Config{
+		URL:            rabbitMQURL(),
+		Heartbeat:      parseDuration(getEnv("REALTIME_HEARTBEAT", "25s"), 25*time.Second),
+		Timeout:        parseDuration(getEnv("REALTIME_TIMEOUT", "3s"), 3*time.Second),
+		BufferSize:     bufferSize,
+		ReconnectDelay: parseDuration(getEnv("REALTIME_RECONNECT_DELAY", "5s"), 5*time.Second),
+		Recheck:        parseDuration(getEnv("REALTIME_RECHECK", "1m"), time.Minute),
+	}
+}
+
+// rabbitMQURL reads RABBITMQ_URL, or builds it from the separate variables
+// the services use.
+func rabbitMQURL() string {
+	return getEnv("RABBITMQ_URL", fmt.Sprintf("amqp://%s:%s@%s:%s/",
+		getEnv("RABBITMQ_USER", "guest"),
+		getEnv("RABBITMQ_PASSWORD", "guest"),
+		getEnv("RABBITMQ_HOST", "localhost"),
+		getEnv("RABBITMQ_PORT", "5672"),
+	))
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"time"
+	"warehouse-go/api-gateaway/session"
+)
+
+func LoadSessionConfig() session.Config {
+	return session.Config{
+		Timeout:  parseDuration(getEnv("SESSION_TIMEOUT", "3s"), 3*time.Second),
+		CacheTTL: parseDuration(getEnv("SESSION_CACHE_TTL", "30s"), 30*time.Second),
+	}
+}
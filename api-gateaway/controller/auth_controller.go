@@ -1,18 +1,29 @@
 package controller
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// userService is the upstream that checks logins and keeps sessions.
+const userService = "user-service"
+
 type AuthController struct {
-	userServiceURL string
+	proxy          *proxy.Proxy
 	jwtConfig      middleware.JWTConfig
+	loginGuard     *middleware.LoginGuard
 }
 
 type LoginRequest struct {
@@ -20,33 +31,94 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code string `json:"code"`
+}
+
 type LoginResponse struct {
 	UserID uint `json:"user_id"`
 	Email string `json:"email"`
 	Roles string `json:"roles"`
+	Permissions string `json:"permissions"`
+	MustChangePassword bool `json:"must_change_password"`
+	TwoFactorRequired bool `json:"two_factor_required"`
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
 }
 
 type UserServiceResponse struct {
 	Data struct {
 		UserID uint `json:"user_id"`
 		Email string `json:"email"`
-		Role []string `json:"roles"`
+		Role []string `json:"role_names"`
+		Permissions []string `json:"permissions"`
+		MustChangePassword bool `json:"must_change_password"`
+		TwoFactorRequired bool `json:"two_factor_required"`
+		TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
 	} `json:"data"`
 }
 
+type AuthUser struct {
+	ID uint `json:"id"`
+	Email string `json:"email"`
+	Roles string `json:"roles"`
+	Permissions string `json:"permissions"`
+	MustChangePassword bool `json:"must_change_password"`
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
+}
+
 type AuthResponse struct {
 	Token string `json:"token"`
-	User struct {
-		ID uint `json:"id"`
-		Email string `json:"email"`
-		Roles string `json:"roles"`
-	} `json:"user"`
+	User AuthUser `json:"user"`
+}
+
+// TwoFactorChallenge is the answer to a correct password when the user has
+// 2FA enabled. The token is exchanged at /api/v1/auth/login/2fa together with
+// a code for a regular AuthResponse.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// upstreamError is a non-200 answer from user-service that is passed back to
+// the client unchanged.
+type upstreamError struct {
+	statusCode int
+	retryAfter string
+	body       []byte
+}
+
+func (e *upstreamError) Error() string {
+	return fmt.Sprintf("user service responded with status %d", e.statusCode)
+}
+
+// userServiceUnavailable answers a login user-service could not take, with
+// the same statuses the proxy uses for forwarded requests.
+func userServiceUnavailable(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, proxy.ErrCircuitOpen), errors.Is(err, proxy.ErrNoHealthyInstance):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error" : "Service Unavailable",
+			"message" : "User service is temporarily unavailable. Please try again later.",
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		return c.Status(fiber.StatusGatewayTimeout).JSON(fiber.Map{
+			"error" : "Gateway Timeout",
+			"message" : "User service did not respond in time",
+		})
+	default:
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"message" : "User service unavailable",
+		})
+	}
 }
 
-func NewAuthController(userServiceURL string, jwtConfig middleware.JWTConfig) *AuthController {
+func NewAuthController(p *proxy.Proxy, jwtConfig middleware.JWTConfig, loginGuard *middleware.LoginGuard) *AuthController {
 	return &AuthController{
-		userServiceURL: userServiceURL,
+		proxy: p,
 		jwtConfig: jwtConfig,
+		loginGuard: loginGuard,
 	}
 }
 
@@ -65,14 +137,174 @@ func (a *AuthController) Login(c *fiber.Ctx) error {
  		})
 	}
 
-	loginResp, err := a.forwardLoginRequest(loginRequest)
+	wait, err := a.loginGuard.Reserve(c.UserContext(), loginRequest.Email)
+	if err != nil {
+		log.Printf("Error checking login attempts: %v", err)
+	}
+	reserved := err == nil
+	if wait > 0 {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error" : "Too Many Requests",
+			"message" : "Too many failed login attempts. Please wait before trying again.",
+			"retry_after" : retryAfter,
+		})
+	}
+
+	loginResp, err := a.forwardLoginRequest(c.UserContext(), "/api/v1/auth/login", loginRequest)
+	if err != nil {
+		// The attempt stays counted only for a wrong password.
+		var upstreamErr *upstreamError
+		isUpstreamErr := errors.As(err, &upstreamErr)
+		if reserved && (!isUpstreamErr || upstreamErr.statusCode != fiber.StatusUnauthorized) {
+			a.releaseAttempt(c, loginRequest.Email)
+		}
+		if isUpstreamErr {
+			if upstreamErr.retryAfter != "" {
+				c.Set(fiber.HeaderRetryAfter, upstreamErr.retryAfter)
+			}
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(upstreamErr.statusCode).Send(upstreamErr.body)
+		}
+
+		log.Printf("Error forwarding login request: %v", err)
+		return userServiceUnavailable(c, err)
+	}
+
+	// The password alone does not finish a 2FA login, so earlier failures
+	// stay counted until the code is right as well.
+	if loginResp.TwoFactorRequired {
+		if reserved {
+			a.releaseAttempt(c, loginRequest.Email)
+		}
+		return a.sendTwoFactorChallenge(c, loginResp)
+	}
+
+	if err := a.loginGuard.Reset(c.UserContext(), loginRequest.Email); err != nil {
+		log.Printf("Error resetting login attempts: %v", err)
+	}
+
+	return a.sendToken(c, loginResp)
+}
+
+// LoginTwoFactor is the second login step: a challenge token from Login and
+// a TOTP or recovery code, checked by user-service.
+func (a *AuthController) LoginTwoFactor(c *fiber.Ctx) error {
+	var twoFactorRequest TwoFactorLoginRequest
+	if err := c.BodyParser(&twoFactorRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	if twoFactorRequest.ChallengeToken == "" || twoFactorRequest.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error" : "Bad Request",
+			"message" : "Challenge token and code are required",
+		})
+	}
+
+	claims, err := middleware.ValidateTwoFactorChallenge(twoFactorRequest.ChallengeToken, a.jwtConfig)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error" : "Unauthorized",
+			"message" : "Invalid or expired challenge token. Please log in again",
+		})
+	}
+
+	wait, err := a.loginGuard.Reserve(c.UserContext(), claims.Email)
+	if err != nil {
+		log.Printf("Error checking login attempts: %v", err)
+	}
+	reserved := err == nil
+	if wait > 0 {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error" : "Too Many Requests",
+			"message" : "Too many failed login attempts. Please wait before trying again.",
+			"retry_after" : retryAfter,
+		})
+	}
+
+	loginResp, err := a.forwardLoginRequest(c.UserContext(), "/internal/2fa/verify", fiber.Map{
+		"user_id" : claims.UserID,
+		"code" : twoFactorRequest.Code,
+	})
+	if err != nil {
+		// The attempt stays counted only for a wrong code.
+		var upstreamErr *upstreamError
+		isUpstreamErr := errors.As(err, &upstreamErr)
+		if reserved && (!isUpstreamErr || upstreamErr.statusCode != fiber.StatusUnauthorized) {
+			a.releaseAttempt(c, claims.Email)
+		}
+		if isUpstreamErr {
+			if upstreamErr.retryAfter != "" {
+				c.Set(fiber.HeaderRetryAfter, upstreamErr.retryAfter)
+			}
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(upstreamErr.statusCode).Send(upstreamErr.body)
+		}
+
+		log.Printf("Error forwarding two-factor request: %v", err)
+		return userServiceUnavailable(c, err)
+	}
+
+	if err := a.loginGuard.Reset(c.UserContext(), claims.Email); err != nil {
+		log.Printf("Error resetting login attempts: %v", err)
+	}
+
+	return a.sendToken(c, loginResp)
+}
+
+func (a *AuthController) releaseAttempt(c *fiber.Ctx, email string) {
+	if err := a.loginGuard.Release(c.UserContext(), email); err != nil {
+		log.Printf("Error releasing login attempt: %v", err)
+	}
+}
+
+func (a *AuthController) sendTwoFactorChallenge(c *fiber.Ctx, loginResp *LoginResponse) error {
+	challengeConfig := a.jwtConfig
+	challengeConfig.Duration = middleware.TwoFactorChallengeDuration
+
+	token, err := middleware.GenerateJWT(middleware.JWTClaims{
+		UserID: loginResp.UserID,
+		Email: loginResp.Email,
+		Purpose: middleware.PurposeTwoFactor,
+	}, challengeConfig)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message" : "Internal Server Error",
+			"message" : err.Error(),
 		})
 	}
 
-	token, err := middleware.GenerateJWT(loginResp.UserID, loginResp.Email, loginResp.Roles, a.jwtConfig)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Two-factor code required",
+		"data" : TwoFactorChallenge{
+			TwoFactorRequired: true,
+			ChallengeToken: token,
+			ExpiresAt: time.Now().Add(challengeConfig.Duration),
+		},
+	})
+}
+
+func (a *AuthController) sendToken(c *fiber.Ctx, loginResp *LoginResponse) error {
+	sessionID, err := a.createSession(c, loginResp.UserID, time.Now().Add(a.jwtConfig.Duration))
+	if err != nil {
+		log.Printf("Error creating session: %v", err)
+		return userServiceUnavailable(c, err)
+	}
+
+	token, err := middleware.GenerateJWT(middleware.JWTClaims{
+		UserID: loginResp.UserID,
+		Email: loginResp.Email,
+		Roles: loginResp.Roles,
+		Permissions: loginResp.Permissions,
+		MustChangePassword: loginResp.MustChangePassword,
+		TwoFactorSetupRequired: loginResp.TwoFactorSetupRequired,
+		SessionID: sessionID,
+	}, a.jwtConfig)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : err.Error(),
@@ -81,14 +313,13 @@ func (a *AuthController) Login(c *fiber.Ctx) error {
 
 	response := AuthResponse{
 		Token: token,
-		User: struct{
-			ID uint `json:"id"`
-			Email string `json:"email"`
-			Roles string `json:"roles"`
-		}{
+		User: AuthUser{
 			ID: loginResp.UserID,
 			Email: loginResp.Email,
 			Roles: loginResp.Roles,
+			Permissions: loginResp.Permissions,
+			MustChangePassword: loginResp.MustChangePassword,
+			TwoFactorSetupRequired: loginResp.TwoFactorSetupRequired,
 		},
 	}
 
@@ -98,55 +329,82 @@ func (a *AuthController) Login(c *fiber.Ctx) error {
 	})
 }
 
-func (ac *AuthController) forwardLoginRequest(loginReq LoginRequest) (*LoginResponse, error) {
-	reqBody, err := json.Marshal(loginReq)
+// createSession records the login in user-service, where the user can list
+// and sign out their sessions.
+func (ac *AuthController) createSession(c *fiber.Ctx, userID uint, expiresAt time.Time) (uint, error) {
+	reqBody, err := json.Marshal(fiber.Map{
+		"user_id" : userID,
+		"ip" : c.IP(),
+		"user_agent" : c.Get(fiber.HeaderUserAgent),
+		"expires_at" : expiresAt,
+	})
 	if err != nil {
-		return nil, err 
+		return 0, err
 	}
 
-	req, err :=http.NewRequest("POST", ac.userServiceURL+"api/v1/auth/login", bytes.NewBuffer(reqBody))
+	header := make(http.Header)
+	header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := ac.proxy.Send(c.UserContext(), userService, http.MethodPost, "/internal/sessions", header, reqBody, 0)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
-	req.Header.Set("X-Internal-Request", "true")
+	if resp.StatusCode != fiber.StatusCreated {
+		return 0, fmt.Errorf("user service responded with status %d", resp.StatusCode)
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	var sessionResp struct {
+		Data struct {
+			ID uint `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &sessionResp); err != nil {
+		return 0, err
+	}
+
+	return sessionResp.Data.ID, nil
+}
+
+// forwardLoginRequest posts body to a user-service endpoint that answers
+// with the logged in user. Logins are not retried, a lost answer may still
+// have counted as a failed attempt.
+func (ac *AuthController) forwardLoginRequest(ctx context.Context, path string, body interface{}) (*LoginResponse, error) {
+	reqBody, err := json.Marshal(body)
 	if err != nil {
-		return nil, err
+		return nil, err 
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	header := make(http.Header)
+	header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := ac.proxy.Send(ctx, userService, http.MethodPost, path, header, reqBody, 0)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, err
+		return nil, &upstreamError{
+			statusCode: resp.StatusCode,
+			retryAfter: resp.Header.Get("Retry-After"),
+			body:       resp.Body,
+		}
 	}
 
 	var userServiceResp UserServiceResponse
-	if err := json.Unmarshal(respBody, &userServiceResp); err != nil {
+	if err := json.Unmarshal(resp.Body, &userServiceResp); err != nil {
 		return nil, err
 	}
 
-	roleStr := ""
-	if len(userServiceResp.Data.Role) > 0 {
-		roleStr = userServiceResp.Data.Role[0]
-		for i := 1; i < len(userServiceResp.Data.Role); i++ {
-			roleStr += "," + userServiceResp.Data.Role[i]
-		}
-	}
-
 	loginResp := LoginResponse{
 		UserID: userServiceResp.Data.UserID,
 		Email: userServiceResp.Data.Email,
-		Roles: roleStr,
+		Roles: strings.Join(userServiceResp.Data.Role, ","),
+		Permissions: strings.Join(userServiceResp.Data.Permissions, ","),
+		MustChangePassword: userServiceResp.Data.MustChangePassword,
+		TwoFactorRequired: userServiceResp.Data.TwoFactorRequired,
+		TwoFactorSetupRequired: userServiceResp.Data.TwoFactorSetupRequired,
 	}
 
 	return &loginResp, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"warehouse-go/api-gateaway/oidc"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OIDCController signs users in through the identity provider. The session
+// and token are issued like for a password login, which keeps working next
+// to it.
+type OIDCController struct {
+	auth   *AuthController
+	client *oidc.Client
+	states oidc.StateStore
+}
+
+type OIDCCallbackRequest struct {
+	Code             string `json:"code" form:"code" query:"code"`
+	State            string `json:"state" form:"state" query:"state"`
+	Error            string `json:"error" form:"error" query:"error"`
+	ErrorDescription string `json:"error_description" form:"error_description" query:"error_description"`
+}
+
+func NewOIDCController(auth *AuthController, client *oidc.Client, states oidc.StateStore) *OIDCController {
+	return &OIDCController{
+		auth:   auth,
+		client: client,
+		states: states,
+	}
+}
+
+// Login sends the browser to the identity provider.
+func (o *OIDCController) Login(c *fiber.Ctx) error {
+	state, err := oidc.RandomString()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	authorizationURL, err := o.client.AuthorizationURL(c.UserContext(), state, nonce, oidc.Challenge(verifier))
+	if err != nil {
+		log.Printf("Error loading identity provider configuration: %v", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"message" : "Identity provider unavailable",
+		})
+	}
+
+	pending := oidc.PendingLogin{Verifier: verifier, Nonce: nonce}
+	if err := o.states.Save(c.UserContext(), state, pending, o.client.Config().StateTTL); err != nil {
+		log.Printf("Error saving oidc state: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : "Failed to start single sign-on",
+		})
+	}
+
+	return c.Redirect(authorizationURL, fiber.StatusFound)
+}
+
+// Callback finishes the login with the code the provider sent back. It is
+// the redirect URL itself, or is posted code and state by the frontend page
+// that is.
+func (o *OIDCController) Callback(c *fiber.Ctx) error {
+	var callbackRequest OIDCCallbackRequest
+	if err := c.QueryParser(&callbackRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+	if c.Method() == fiber.MethodPost {
+		if err := c.BodyParser(&callbackRequest); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
+	}
+
+	// The user cancelled, or the provider refused the login.
+	if callbackRequest.Error != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error" : "Unauthorized",
+			"message" : "Single sign-on failed: " + callbackRequest.Error,
+			"detail" : callbackRequest.ErrorDescription,
+		})
+	}
+	if callbackRequest.Code == "" || callbackRequest.State == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error" : "Bad Request",
+			"message" : "Code and state are required",
+		})
+	}
+
+	pending, err := o.states.Take(c.UserContext(), callbackRequest.State)
+	if err != nil {
+		if !errors.Is(err, oidc.ErrUnknownState) {
+			log.Printf("Error reading oidc state: %v", err)
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error" : "Bad Request",
+			"message" : "Unknown or expired login. Please start single sign-on again",
+		})
+	}
+
+	rawIDToken, err := o.client.Exchange(c.UserContext(), callbackRequest.Code, pending.Verifier)
+	if err != nil {
+		return o.providerError(c, err)
+	}
+
+	idToken, err := o.client.VerifyIDToken(c.UserContext(), rawIDToken, pending.Nonce)
+	if err != nil {
+		return o.providerError(c, err)
+	}
+
+	config := o.client.Config()
+	loginResp, err := o.auth.forwardLoginRequest(c.UserContext(), "/internal/oidc/login", fiber.Map{
+		"issuer" : idToken.Issuer,
+		"subject" : idToken.Subject,
+		"email" : idToken.Email,
+		"email_verified" : idToken.EmailVerified,
+		"name" : idToken.Name,
+		"role_names" : o.client.RoleNames(idToken.Groups),
+		"auto_provision" : config.AutoProvision,
+		"mfa" : o.client.MultiFactor(idToken),
+	})
+	if err != nil {
+		var upstreamErr *upstreamError
+		if errors.As(err, &upstreamErr) {
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(upstreamErr.statusCode).Send(upstreamErr.body)
+		}
+
+		log.Printf("Error forwarding oidc login: %v", err)
+		return userServiceUnavailable(c, err)
+	}
+
+	// Without a second factor at the provider a user with 2FA finishes the
+	// login at /api/v1/auth/login/2fa, like after a password.
+	if loginResp.TwoFactorRequired {
+		return o.auth.sendTwoFactorChallenge(c, loginResp)
+	}
+
+	return o.auth.sendToken(c, loginResp)
+}
+
+func (o *OIDCController) providerError(c *fiber.Ctx, err error) error {
+	log.Printf("Error completing single sign-on: %v", err)
+	if errors.Is(err, oidc.ErrInvalidToken) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error" : "Unauthorized",
+			"message" : "The identity provider did not confirm the login",
+		})
+	}
+	return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+		"message" : "Identity provider unavailable",
+	})
+}
@@ -0,0 +1,325 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"warehouse-go/api-gateaway/proxy"
+	"warehouse-go/api-gateaway/routing"
+)
+
+type Config struct {
+	// SpecPath is where every upstream serves its own OpenAPI document.
+	SpecPath string
+	// Timeout bounds the request for one upstream document.
+	Timeout time.Duration
+	// CacheTTL is how long the merged document is reused.
+	CacheTTL time.Duration
+}
+
+// Aggregator merges the OpenAPI documents of all upstreams into one document
+// describing the API as it is exposed by the gateway.
+type Aggregator struct {
+	config    Config
+	client    *http.Client
+	upstreams *proxy.Upstreams
+	routes    []routing.Route
+
+	mu       sync.Mutex
+	cached   map[string]interface{}
+	cachedAt time.Time
+}
+
+func NewAggregator(config Config, upstreams *proxy.Upstreams, routes []routing.Route) *Aggregator {
+	if config.SpecPath == "" {
+		config.SpecPath = "/openapi.json"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = time.Minute
+	}
+
+	return &Aggregator{
+		config:    config,
+		client:    &http.Client{Timeout: config.Timeout},
+		upstreams: upstreams,
+		routes:    routing.Sorted(routes),
+	}
+}
+
+// Spec returns the merged document, rebuilding it when the cached copy is
+// older than CacheTTL.
+func (a *Aggregator) Spec(ctx context.Context) map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cached != nil && time.Since(a.cachedAt) < a.config.CacheTTL {
+		return a.cached
+	}
+
+	a.cached = a.build(ctx)
+	a.cachedAt = time.Now()
+	return a.cached
+}
+
+func (a *Aggregator) build(ctx context.Context) map[string]interface{} {
+	paths := make(map[string]interface{})
+	schemas := make(map[string]interface{})
+	var unavailable []string
+
+	for name, path := range gatewayPaths() {
+		paths[name] = path
+	}
+	for name, schema := range gatewaySchemas() {
+		schemas[name] = schema
+	}
+
+	names := a.upstreams.Names()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !a.routed(name) {
+			continue
+		}
+
+		document, err := a.fetch(ctx, name)
+		if err != nil {
+			log.Printf("Warning: OpenAPI document of %s unavailable: %v", name, err)
+			unavailable = append(unavailable, name)
+			continue
+		}
+
+		a.merge(name, document, paths, schemas)
+	}
+
+	description := "Combined API of all warehouse services as exposed by the API gateaway."
+	if len(unavailable) > 0 {
+		description += " Currently missing: " + strings.Join(unavailable, ", ") + "."
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Warehouse API",
+			"version":     "1.0.0",
+			"description": description,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+				"apiKeyAuth": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "X-API-Key",
+					"description": "API key created by a manager, limited to its scopes",
+				},
+			},
+		},
+	}
+}
+
+func (a *Aggregator) routed(upstream string) bool {
+	for _, route := range a.routes {
+		if route.Upstream == upstream {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Aggregator) fetch(ctx context.Context, name string) (map[string]interface{}, error) {
+	pool, ok := a.upstreams.Pool(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown upstream %s", name)
+	}
+
+	instance, err := pool.Pick()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Release(instance)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, instance.URL+a.config.SpecPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var document map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+	return document, nil
+}
+
+// merge adds the operations of one upstream document that are reachable
+// through the gateway. Schemas are namespaced by upstream so equally named
+// types of different services do not collide.
+func (a *Aggregator) merge(upstream string, document map[string]interface{}, paths, schemas map[string]interface{}) {
+	refPrefix := "#/components/schemas/"
+
+	if components, ok := document["components"].(map[string]interface{}); ok {
+		if upstreamSchemas, ok := components["schemas"].(map[string]interface{}); ok {
+			for name, schema := range upstreamSchemas {
+				schemas[upstream+"."+name] = renameRefs(schema, refPrefix, refPrefix+upstream+".")
+			}
+		}
+	}
+
+	upstreamPaths, _ := document["paths"].(map[string]interface{})
+	for upstreamPath, item := range upstreamPaths {
+		operations, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for method, operation := range operations {
+			gatewayPath, route, ok := a.gatewayPath(upstream, upstreamPath, method)
+			if !ok {
+				continue
+			}
+
+			op, ok := renameRefs(operation, refPrefix, refPrefix+upstream+".").(map[string]interface{})
+			if !ok {
+				continue
+			}
+			decorate(op, route, method, gatewayPath)
+
+			pathItem, _ := paths[gatewayPath].(map[string]interface{})
+			if pathItem == nil {
+				pathItem = make(map[string]interface{})
+				paths[gatewayPath] = pathItem
+			}
+			pathItem[method] = op
+		}
+	}
+}
+
+// gatewayPath maps an upstream path back onto the gateway path serving it.
+// Paths that no route exposes, or that a more specific route sends to another
+// upstream, are left out.
+func (a *Aggregator) gatewayPath(upstream, upstreamPath, method string) (string, routing.Route, bool) {
+	for _, route := range a.routes {
+		if route.Upstream != upstream || !route.AllowsMethod(method) {
+			continue
+		}
+
+		base := route.Prefix
+		if route.Rewrite != "" {
+			base = route.Rewrite
+		}
+		if upstreamPath != base && !strings.HasPrefix(upstreamPath, base+"/") {
+			continue
+		}
+
+		gatewayPath := route.Prefix + strings.TrimPrefix(upstreamPath, base)
+		if match, ok := routing.Match(a.routes, gatewayPath); !ok || match.Prefix != route.Prefix {
+			continue
+		}
+		return gatewayPath, route, true
+	}
+	return "", routing.Route{}, false
+}
+
+func decorate(op map[string]interface{}, route routing.Route, method, gatewayPath string) {
+	op["operationId"] = operationID(method, gatewayPath)
+
+	responses, _ := op["responses"].(map[string]interface{})
+	if responses == nil {
+		responses = make(map[string]interface{})
+		op["responses"] = responses
+	}
+
+	if route.RequiresAuth() {
+		op["security"] = []interface{}{
+			map[string]interface{}{"bearerAuth": []interface{}{}},
+			map[string]interface{}{"apiKeyAuth": []interface{}{}},
+		}
+		responses["401"] = map[string]interface{}{"description": "Missing or invalid token"}
+	} else {
+		op["security"] = []interface{}{}
+	}
+
+	var notes []string
+	if len(route.Roles) > 0 {
+		notes = append(notes, "Requires role: "+strings.Join(route.Roles, " or ")+".")
+	}
+	for _, permissions := range route.RequiredPermissions(method) {
+		notes = append(notes, "Requires permission: "+strings.Join(permissions, " or ")+".")
+	}
+	if len(notes) > 0 {
+		note := strings.Join(notes, " ")
+		if description, ok := op["description"].(string); ok && description != "" {
+			note = description + "\n\n" + note
+		}
+		op["description"] = note
+		responses["403"] = map[string]interface{}{"description": "Insufficient permissions"}
+	}
+
+	if route.Tier() != routing.TierNone {
+		responses["429"] = map[string]interface{}{"description": "Rate limit exceeded"}
+	}
+	responses["502"] = map[string]interface{}{"description": "Upstream unavailable"}
+	responses["503"] = map[string]interface{}{"description": "Circuit open or no healthy instance"}
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment == "" || segment == "api" || segment == "v1" {
+			continue
+		}
+		parts = append(parts, segment)
+	}
+	return strings.Join(parts, "_")
+}
+
+// renameRefs returns a copy of value with every $ref starting with from
+// rewritten to start with to.
+func renameRefs(value interface{}, from, to string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if ref, ok := item.(string); ok && key == "$ref" && strings.HasPrefix(ref, from) {
+				out[key] = to + strings.TrimPrefix(ref, from)
+				continue
+			}
+			out[key] = renameRefs(item, from, to)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = renameRefs(item, from, to)
+		}
+		return out
+	default:
+		return v
+	}
+}
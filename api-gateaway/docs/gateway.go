@@ -0,0 +1,238 @@
+package docs
+
+// gatewayPaths documents the endpoints the gateway answers itself instead of
+// proxying them.
+func gatewayPaths() map[string]interface{} {
+	return map[string]interface{}{
+		"/api/v1/auth/login": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "post_auth_login",
+				"summary":     "Log in and receive a JWT",
+				"tags":        []string{"auth"},
+				"security":    []interface{}{},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/components/schemas/gateway.LoginRequest"},
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Successful response",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"message": map[string]interface{}{"type": "string"},
+										"data": map[string]interface{}{
+											"oneOf": []interface{}{
+												map[string]interface{}{"$ref": "#/components/schemas/gateway.AuthResponse"},
+												map[string]interface{}{"$ref": "#/components/schemas/gateway.TwoFactorChallenge"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"401": map[string]interface{}{"description": "Invalid email or password"},
+					"403": map[string]interface{}{"description": "Account not activated yet"},
+					"423": map[string]interface{}{"description": "Account locked after too many failed attempts"},
+					"429": map[string]interface{}{"description": "Too many attempts, retry after the Retry-After delay"},
+				},
+			},
+		},
+		"/api/v1/auth/login/2fa": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "post_auth_login_2fa",
+				"summary":     "Finish a two-factor login and receive a JWT",
+				"description": "Takes the challenge_token returned by /api/v1/auth/login and a code from the authenticator app or a recovery code.",
+				"tags":        []string{"auth"},
+				"security":    []interface{}{},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/components/schemas/gateway.TwoFactorLoginRequest"},
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Successful response",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"message": map[string]interface{}{"type": "string"},
+										"data":    map[string]interface{}{"$ref": "#/components/schemas/gateway.AuthResponse"},
+									},
+								},
+							},
+						},
+					},
+					"401": map[string]interface{}{"description": "Invalid code or invalid or expired challenge token"},
+					"423": map[string]interface{}{"description": "Account locked after too many failed attempts"},
+					"429": map[string]interface{}{"description": "Too many attempts, retry after the Retry-After delay"},
+				},
+			},
+		},
+		"/api/v1/graphql": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "post_graphql",
+				"summary":     "GraphQL query over products, merchants, warehouses and transactions",
+				"description": "Every upstream lookup a query makes is authorized like the matching REST route.",
+				"tags":        []string{"graphql"},
+				"security":    []interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{
+								"type":     "object",
+								"required": []string{"query"},
+								"properties": map[string]interface{}{
+									"query":         map[string]interface{}{"type": "string"},
+									"operationName": map[string]interface{}{"type": "string"},
+									"variables":     map[string]interface{}{"type": "object"},
+								},
+							},
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": "GraphQL response with data and errors"},
+					"401": map[string]interface{}{"description": "Missing or invalid token"},
+				},
+			},
+		},
+		"/api/v1/bff/keeper/home": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "get_bff_keeper_home",
+				"summary":     "Home screen of the calling keeper",
+				"description": "Merchant, merchant products, dashboard and recent transactions in one call. " +
+					"Shows the merchant given by merchant_id, or else the first merchant assigned to the keeper. " +
+					"Sections that fail carry an error instead of data and the response is marked partial.\n\nRequires permission: dashboard:merchant.",
+				"tags":     []string{"bff"},
+				"security": []interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}},
+				"parameters": []interface{}{
+					map[string]interface{}{
+						"name":        "merchant_id",
+						"in":          "query",
+						"required":    false,
+						"description": "One of the merchants assigned to the keeper",
+						"schema":      map[string]interface{}{"type": "integer", "minimum": 1},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": "Complete or partial home screen"},
+					"401": map[string]interface{}{"description": "Missing or invalid token"},
+					"403": map[string]interface{}{"description": "Insufficient permissions or merchant not assigned to the keeper"},
+					"404": map[string]interface{}{"description": "No merchant is assigned to the keeper"},
+					"502": map[string]interface{}{"description": "Merchant could not be loaded"},
+				},
+			},
+		},
+		"/api/v1/events": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "get_events",
+				"summary":     "Stream of payment and stock events",
+				"description": "Server-sent events named payment.status, stock.changed and stock.low, preceded by a ready event. " +
+					"Holders of dashboard:all receive every event, holders of dashboard:merchant only the events of the merchants they are assigned to. " +
+					"A close event ends the stream when the token expires, the session is signed out, the API key is revoked or the merchant assignments change; reconnect with valid credentials.\n\nRequires permission: dashboard:all or dashboard:merchant.",
+				"tags":     []string{"realtime"},
+				"security": []interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Event stream",
+						"content": map[string]interface{}{
+							"text/event-stream": map[string]interface{}{
+								"schema": map[string]interface{}{"$ref": "#/components/schemas/gateway.RealtimeEvent"},
+							},
+						},
+					},
+					"401": map[string]interface{}{"description": "Missing or invalid token"},
+					"403": map[string]interface{}{"description": "Insufficient permissions"},
+					"404": map[string]interface{}{"description": "No merchant is assigned to the keeper"},
+					"502": map[string]interface{}{"description": "Merchant could not be loaded"},
+				},
+			},
+		},
+	}
+}
+
+func gatewaySchemas() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	id := map[string]interface{}{"type": "integer", "minimum": 0}
+
+	return map[string]interface{}{
+		"gateway.LoginRequest": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"email", "password"},
+			"properties": map[string]interface{}{
+				"email":    str,
+				"password": str,
+			},
+		},
+		"gateway.AuthResponse": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"token": str,
+				"user": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id":    id,
+						"email": str,
+						"roles": map[string]interface{}{"type": "string", "description": "Comma separated role names"},
+						"permissions": map[string]interface{}{
+							"type":        "string",
+							"description": "Comma separated permissions granted by the roles, e.g. warehouse:write",
+						},
+						"must_change_password": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Until the password is changed the token only works for /api/v1/auth/change-password",
+						},
+						"two_factor_setup_required": map[string]interface{}{
+							"type":        "boolean",
+							"description": "A role requires 2FA; until it is set up the token only works for /api/v1/auth/2fa/enroll and /api/v1/auth/2fa/confirm",
+						},
+					},
+				},
+			},
+		},
+		"gateway.TwoFactorChallenge": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"two_factor_required": map[string]interface{}{"type": "boolean"},
+				"challenge_token":     map[string]interface{}{"type": "string", "description": "Valid for 5 minutes, only for /api/v1/auth/login/2fa"},
+				"expires_at":          map[string]interface{}{"type": "string", "format": "date-time"},
+			},
+		},
+		"gateway.TwoFactorLoginRequest": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"challenge_token", "code"},
+			"properties": map[string]interface{}{
+				"challenge_token": str,
+				"code":            map[string]interface{}{"type": "string", "description": "6 digit TOTP code or a recovery code"},
+			},
+		},
+		"gateway.RealtimeEvent": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"type", "timestamp"},
+			"properties": map[string]interface{}{
+				"type":           map[string]interface{}{"type": "string", "enum": []string{"payment.status", "stock.changed", "stock.low"}},
+				"merchant_id":    id,
+				"warehouse_id":   id,
+				"product_id":     id,
+				"stock":          map[string]interface{}{"type": "integer"},
+				"order_id":       str,
+				"payment_status": str,
+				"timestamp":      map[string]interface{}{"type": "string", "format": "date-time"},
+			},
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package docs
+
+import "github.com/gofiber/fiber/v2"
+
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Warehouse API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/docs/openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>`
+
+// Register serves the merged document at /docs/openapi.json and the docs UI
+// at /docs.
+func Register(app *fiber.App, aggregator *Aggregator) {
+	app.Get("/docs/openapi.json", func(c *fiber.Ctx) error {
+		return c.JSON(aggregator.Spec(c.UserContext()))
+	})
+
+	app.Get("/docs", func(c *fiber.Ctx) error {
+		c.Type("html")
+		return c.SendString(swaggerUI)
+	})
+}
@@ -2,7 +2,13 @@ module warehouse-go/api-gateaway
 
 go 1.25.3
 
-require github.com/redis/go-redis/v9 v9.17.2
+require (
+	github.com/alicebob/miniredis/v2 v2.39.0
+	github.com/graph-gophers/graphql-go v1.10.3
+	github.com/redis/go-redis/v9 v9.17.2
+	github.com/streadway/amqp v1.1.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
@@ -15,6 +21,7 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 )
 
@@ -0,0 +1,139 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+	"warehouse-go/api-gateaway/routing"
+)
+
+// Error is a GraphQL error with a machine readable code in its extensions.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+var errNotFound = errors.New("not found")
+
+// viewer is the authenticated caller of a GraphQL request.
+type viewer struct {
+	roles       []string
+	permissions []string
+	header      http.Header
+	// scopes is set for API key callers only, JWT callers are not scoped.
+	scopes []string
+}
+
+func (v *viewer) hasAnyRole(roles []string) bool {
+	for _, have := range v.roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *viewer) hasAnyPermission(permissions []string) bool {
+	for _, have := range v.permissions {
+		for _, want := range permissions {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type viewerKey struct{}
+
+func viewerFrom(ctx context.Context) *viewer {
+	v, _ := ctx.Value(viewerKey{}).(*viewer)
+	return v
+}
+
+// client performs the upstream calls of the resolvers. Every call is made
+// against a gateway path and checked against the route table first, so a
+// GraphQL query can read exactly what the caller could read through REST.
+type client struct {
+	proxy   *proxy.Proxy
+	routes  []routing.Route
+	timeout time.Duration
+}
+
+// get loads the "data" field of GET path into out. It returns errNotFound for
+// a 404 from the upstream.
+func (c *client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	route, ok := routing.Match(c.routes, path)
+	if !ok || !route.AllowsMethod(http.MethodGet) {
+		return &Error{Code: "NOT_EXPOSED", Message: "Resource is not exposed by the gateway"}
+	}
+
+	v := viewerFrom(ctx)
+	if route.RequiresAuth() && v == nil {
+		return &Error{Code: "UNAUTHENTICATED", Message: "Unauthorized"}
+	}
+	if len(route.Roles) > 0 && (v == nil || !v.hasAnyRole(route.Roles)) {
+		return &Error{Code: "FORBIDDEN", Message: "Insufficient permissions"}
+	}
+	for _, permissions := range route.RequiredPermissions(http.MethodGet) {
+		if v == nil || !v.hasAnyPermission(permissions) {
+			return &Error{Code: "FORBIDDEN", Message: "Insufficient permissions"}
+		}
+	}
+	if v != nil && v.scopes != nil && !middleware.ScopeAllows(v.scopes, path, http.MethodGet) {
+		return &Error{Code: "FORBIDDEN", Message: "API key scopes do not cover " + middleware.ScopeGroup(path)}
+	}
+
+	upstreamPath := route.UpstreamPath(path)
+	if len(query) > 0 {
+		upstreamPath += "?" + query.Encode()
+	}
+
+	var header http.Header
+	if v != nil {
+		header = v.header
+	}
+
+	status, body, err := c.proxy.Fetch(ctx, route.Upstream, upstreamPath, header, c.timeout)
+	if err != nil {
+		return &Error{Code: "UPSTREAM_UNAVAILABLE", Message: route.Upstream + " is unavailable"}
+	}
+
+	var envelope struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return &Error{Code: "UPSTREAM_ERROR", Message: "Invalid response from " + route.Upstream}
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		return errNotFound
+	case status != http.StatusOK:
+		return &Error{Code: "UPSTREAM_ERROR", Message: envelope.Message}
+	}
+
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return &Error{Code: "UPSTREAM_ERROR", Message: "Invalid response from " + route.Upstream}
+	}
+	return nil
+}
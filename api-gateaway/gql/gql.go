@@ -0,0 +1,86 @@
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+	"warehouse-go/api-gateaway/routing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+type Config struct {
+	// Timeout bounds every upstream call made by a resolver.
+	Timeout time.Duration
+	// BatchWait is how long a dataloader collects keys before loading them.
+	BatchWait time.Duration
+	// Concurrency limits the parallel upstream calls of one batch, for
+	// upstreams that cannot load a batch with a single call.
+	Concurrency int
+	// MaxDepth limits how deeply queries may nest.
+	MaxDepth int
+}
+
+// Server is the GraphQL facade over the upstream services.
+type Server struct {
+	schema *graphql.Schema
+	client *client
+	config Config
+}
+
+func NewServer(p *proxy.Proxy, routes []routing.Route, config Config) *Server {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.BatchWait <= 0 {
+		config.BatchWait = 2 * time.Millisecond
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = 8
+	}
+
+	c := &client{proxy: p, routes: routing.Sorted(routes), timeout: config.Timeout}
+
+	return &Server{
+		schema: graphql.MustParseSchema(schemaSource, &queryResolver{client: c}, graphql.MaxDepth(config.MaxDepth)),
+		client: c,
+		config: config,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler executes a GraphQL request. It expects the JWT middleware to have
+// run already; each upstream call is then authorized against the route table.
+func (s *Server) Handler(c *fiber.Ctx) error {
+	var req request
+	if err := c.BodyParser(&req); err != nil || req.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"errors": []fiber.Map{{"message": "Request body must be JSON with a query"}},
+		})
+	}
+
+	roles, _ := middleware.UserRoles(c)
+	v := &viewer{roles: roles, permissions: middleware.UserPermissions(c), header: middleware.IdentityHeader(c)}
+	if scopes, ok := middleware.APIKeyScopes(c); ok {
+		v.scopes = append([]string{}, scopes...)
+	}
+
+	ctx := context.WithValue(c.UserContext(), viewerKey{}, v)
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(s.client, s.config.BatchWait, s.config.Concurrency))
+
+	return c.JSON(s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
@@ -0,0 +1,189 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads many keys at once. Keys missing from the result resolve to
+// the zero value without an error, i.e. "not found".
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) map[K]Result[V]
+
+type Result[V any] struct {
+	Value V
+	Err   error
+}
+
+// Loader is a per-request dataloader. Loads issued within the batch window
+// are collected into one call of the batch function, and every key is loaded
+// at most once per request, so resolving a field on each item of a list does
+// not turn into one upstream call per item.
+type Loader[K comparable, V any] struct {
+	batch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*entry[V]
+	pending []K
+}
+
+type entry[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+func NewLoader[K comparable, V any](batch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	if maxBatch <= 0 {
+		maxBatch = 100
+	}
+	return &Loader[K, V]{
+		batch:    batch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]*entry[V]),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	e, ok := l.cache[key]
+	if !ok {
+		e = &entry[V]{done: make(chan struct{})}
+		l.cache[key] = e
+		l.pending = append(l.pending, key)
+
+		switch {
+		case len(l.pending) >= l.maxBatch:
+			keys := l.pending
+			l.pending = nil
+			go l.run(ctx, keys)
+		case len(l.pending) == 1:
+			go l.dispatchAfter(ctx)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.value, e.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Prime stores a value that arrived as part of another response, e.g. the
+// category embedded in a product, so it is not fetched again.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	e := &entry[V]{value: value, done: make(chan struct{})}
+	close(e.done)
+	l.cache[key] = e
+}
+
+func (l *Loader[K, V]) dispatchAfter(ctx context.Context) {
+	if l.wait > 0 {
+		time.Sleep(l.wait)
+	}
+
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	if len(keys) > 0 {
+		l.run(ctx, keys)
+	}
+}
+
+func (l *Loader[K, V]) run(ctx context.Context, keys []K) {
+	results := l.batch(ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		e := l.cache[key]
+		if result, ok := results[key]; ok {
+			e.value, e.err = result.Value, result.Err
+		}
+		close(e.done)
+	}
+}
+
+// eachKey is a BatchFunc for upstreams that only offer single item lookups:
+// it loads the distinct keys of a batch concurrently, at most concurrency at
+// a time.
+func eachKey[K comparable, V any](concurrency int, load func(ctx context.Context, key K) (V, error)) BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) map[K]Result[V] {
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results = make(map[K]Result[V], len(keys))
+			sem     = make(chan struct{}, concurrency)
+		)
+
+		for _, key := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(key K) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				value, err := load(ctx, key)
+				mu.Lock()
+				results[key] = Result[V]{Value: value, Err: err}
+				mu.Unlock()
+			}(key)
+		}
+		wg.Wait()
+
+		return results
+	}
+}
+
+// maxBatchIDs is how many ids the upstream list endpoints take in one ids
+// filter.
+const maxBatchIDs = 100
+
+// byIDs is a BatchFunc for upstreams whose list endpoint takes an ids
+// filter: it loads a whole batch with a single call. list fetches the items
+// for the query, id tells which key an item belongs to. An error fails every
+// key of the batch.
+func byIDs[V any](list func(ctx context.Context, query url.Values) ([]V, error), id func(*V) uint) BatchFunc[uint, *V] {
+	return func(ctx context.Context, keys []uint) map[uint]Result[*V] {
+		ids := make([]string, len(keys))
+		for i, key := range keys {
+			ids[i] = strconv.FormatUint(uint64(key), 10)
+		}
+		query := url.Values{
+			"ids":   {strings.Join(ids, ",")},
+			"limit": {strconv.Itoa(len(keys))},
+		}
+
+		results := make(map[uint]Result[*V], len(keys))
+		items, err := list(ctx, query)
+		if err != nil {
+			for _, key := range keys {
+				results[key] = Result[*V]{Err: err}
+			}
+			return results
+		}
+
+		for i := range items {
+			results[id(&items[i])] = Result[*V]{Value: &items[i]}
+		}
+		return results
+	}
+}
@@ -0,0 +1,80 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// loaders holds the dataloaders of one GraphQL request.
+type loaders struct {
+	products   *Loader[uint, *product]
+	categories *Loader[uint, *category]
+	warehouses *Loader[uint, *warehouse]
+	merchants  *Loader[uint, *merchant]
+}
+
+type loadersKey struct{}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func newLoaders(c *client, wait time.Duration, concurrency int) *loaders {
+	l := &loaders{}
+
+	l.products = NewLoader(byIDs(func(ctx context.Context, query url.Values) ([]product, error) {
+		var resp struct {
+			Products []product `json:"products"`
+		}
+		if err := c.list(ctx, "/api/v1/products", query, &resp); err != nil {
+			return nil, err
+		}
+		for i := range resp.Products {
+			if category := &resp.Products[i].Category; category.ID != 0 {
+				l.categories.Prime(category.ID, category)
+			}
+		}
+		return resp.Products, nil
+	}, func(p *product) uint { return p.ID }), wait, maxBatchIDs)
+
+	l.categories = NewLoader(eachKey(concurrency, func(ctx context.Context, id uint) (*category, error) {
+		var cat category
+		if err := getByID(ctx, c, "/api/v1/categories/%d", id, &cat); err != nil || cat.ID == 0 {
+			return nil, err
+		}
+		return &cat, nil
+	}), wait, 0)
+
+	l.warehouses = NewLoader(byIDs(func(ctx context.Context, query url.Values) ([]warehouse, error) {
+		var resp struct {
+			Warehouses []warehouse `json:"warehouse"`
+		}
+		if err := c.list(ctx, "/api/v1/warehouses", query, &resp); err != nil {
+			return nil, err
+		}
+		return resp.Warehouses, nil
+	}, func(w *warehouse) uint { return w.ID }), wait, maxBatchIDs)
+
+	l.merchants = NewLoader(eachKey(concurrency, func(ctx context.Context, id uint) (*merchant, error) {
+		var m merchant
+		if err := getByID(ctx, c, "/api/v1/merchants/%d", id, &m); err != nil || m.ID == 0 {
+			return nil, err
+		}
+		return &m, nil
+	}), wait, 0)
+
+	return l
+}
+
+// getByID loads a single item. A missing item is not an error, the field
+// just resolves to null.
+func getByID(ctx context.Context, c *client, pathFormat string, id uint, out interface{}) error {
+	err := c.get(ctx, fmt.Sprintf(pathFormat, id), nil, out)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	return err
+}
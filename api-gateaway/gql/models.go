@@ -0,0 +1,86 @@
+package gql
+
+// The types below mirror the JSON the upstream services return, including
+// their field names.
+
+type pagination struct {
+	CurrentPage  int  `json:"current_page"`
+	TotalPages   int  `json:"total_pages"`
+	TotalRecords int  `json:"total_records"`
+	Limit        int  `json:"limit"`
+	HasNext      bool `json:"has_next"`
+	HasPrev      bool `json:"has_prev"`
+}
+
+type category struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Tagline      string `json:"tagline"`
+	Photo        string `json:"photo"`
+	CountProduct int    `json:"count_product"`
+}
+
+type product struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Barcode    string   `json:"barcode"`
+	Price      int      `json:"price"`
+	About      string   `json:"about"`
+	CategoryID uint     `json:"category_id"`
+	Thumbnail  string   `json:"thumbail"`
+	Category   category `json:"category"`
+}
+
+type warehouse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	Photo        string `json:"photo"`
+	Phone        string `json:"phone"`
+	CountProduct int    `json:"count_product"`
+}
+
+type merchant struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	Photo        string `json:"photo"`
+	Phone        string `json:"phone"`
+	KeeperID     uint   `json:"keeper_id"`
+	KeepersName  string `json:"keepers_name"`
+	ProductCount int    `json:"product_count"`
+}
+
+type merchantProduct struct {
+	ID          uint `json:"id"`
+	MerchantID  uint `json:"merchant_id"`
+	ProductID   uint `json:"product_id"`
+	Stock       int  `json:"stock"`
+	WarehouseID uint `json:"warehouse_id"`
+}
+
+type transaction struct {
+	ID              uint                 `json:"id"`
+	Name            string               `json:"name"`
+	Phone           string               `json:"phone"`
+	Email           string               `json:"email"`
+	Address         string               `json:"address"`
+	SubTotal        int64                `json:"sub_total"`
+	TaxTotal        int64                `json:"tax_total"`
+	GrandTotal      int64                `json:"grand_total"`
+	MerchantID      uint                 `json:"merchant_id"`
+	PaymentStatus   string               `json:"payment_status"`
+	PaymentMethod   string               `json:"payment_method"`
+	TransactionCode string               `json:"transaction_code"`
+	OrderID         string               `json:"order_id"`
+	Notes           string               `json:"note"`
+	Products        []transactionProduct `json:"transaction_products"`
+}
+
+type transactionProduct struct {
+	ID        uint  `json:"id"`
+	ProductID uint  `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+	Price     int64 `json:"price"`
+	SubTotal  int64 `json:"sub_total"`
+}
@@ -0,0 +1,424 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type queryResolver struct {
+	client *client
+}
+
+type pageArgs struct {
+	Page   *int32
+	Limit  *int32
+	Search *string
+}
+
+func (a pageArgs) values() url.Values {
+	values := url.Values{}
+	if a.Page != nil {
+		values.Set("page", strconv.Itoa(int(*a.Page)))
+	}
+	if a.Limit != nil {
+		values.Set("limit", strconv.Itoa(int(*a.Limit)))
+	}
+	if a.Search != nil && *a.Search != "" {
+		values.Set("search", *a.Search)
+	}
+	return values
+}
+
+type idArgs struct {
+	ID graphql.ID
+}
+
+func parseID(id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, &Error{Code: "BAD_USER_INPUT", Message: "Invalid id " + string(id)}
+	}
+	return uint(value), nil
+}
+
+func toID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+// list loads a paginated upstream list. Some services answer an empty page
+// with 404, which is an empty list here.
+func (c *client) list(ctx context.Context, path string, query url.Values, out interface{}) error {
+	err := c.get(ctx, path, query, out)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ────────────────────────────────────────────────────────────────
+// Query
+// ────────────────────────────────────────────────────────────────
+
+func (q *queryResolver) Products(ctx context.Context, args pageArgs) (*pageResolver[*productResolver], error) {
+	var resp struct {
+		Products   []product  `json:"products"`
+		Pagination pagination `json:"pagination"`
+	}
+	if err := q.client.list(ctx, "/api/v1/products", args.values(), &resp); err != nil {
+		return nil, err
+	}
+
+	l := loadersFrom(ctx)
+	items := make([]*productResolver, 0, len(resp.Products))
+	for i := range resp.Products {
+		p := &resp.Products[i]
+		l.products.Prime(p.ID, p)
+		if p.Category.ID != 0 {
+			l.categories.Prime(p.Category.ID, &p.Category)
+		}
+		items = append(items, &productResolver{p})
+	}
+	return &pageResolver[*productResolver]{items, resp.Pagination}, nil
+}
+
+func (q *queryResolver) Product(ctx context.Context, args idArgs) (*productResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadersFrom(ctx).products.Load(ctx, id)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &productResolver{p}, nil
+}
+
+func (q *queryResolver) Categories(ctx context.Context, args pageArgs) (*pageResolver[*categoryResolver], error) {
+	var resp struct {
+		Categories []category `json:"categories"`
+		Pagination pagination `json:"pagination"`
+	}
+	if err := q.client.list(ctx, "/api/v1/categories", args.values(), &resp); err != nil {
+		return nil, err
+	}
+
+	items := make([]*categoryResolver, 0, len(resp.Categories))
+	for i := range resp.Categories {
+		items = append(items, &categoryResolver{&resp.Categories[i]})
+	}
+	return &pageResolver[*categoryResolver]{items, resp.Pagination}, nil
+}
+
+func (q *queryResolver) Category(ctx context.Context, args idArgs) (*categoryResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := loadersFrom(ctx).categories.Load(ctx, id)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return &categoryResolver{c}, nil
+}
+
+func (q *queryResolver) Merchants(ctx context.Context, args pageArgs) (*pageResolver[*merchantResolver], error) {
+	var resp struct {
+		Data       []merchant `json:"data"`
+		Pagination pagination `json:"pagination"`
+	}
+	if err := q.client.list(ctx, "/api/v1/merchants", args.values(), &resp); err != nil {
+		return nil, err
+	}
+
+	l := loadersFrom(ctx)
+	items := make([]*merchantResolver, 0, len(resp.Data))
+	for i := range resp.Data {
+		m := &resp.Data[i]
+		l.merchants.Prime(m.ID, m)
+		items = append(items, &merchantResolver{q.client, m})
+	}
+	return &pageResolver[*merchantResolver]{items, resp.Pagination}, nil
+}
+
+func (q *queryResolver) Merchant(ctx context.Context, args idArgs) (*merchantResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	m, err := loadersFrom(ctx).merchants.Load(ctx, id)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	return &merchantResolver{q.client, m}, nil
+}
+
+func (q *queryResolver) Warehouses(ctx context.Context, args pageArgs) (*pageResolver[*warehouseResolver], error) {
+	var resp struct {
+		Warehouses []warehouse `json:"warehouse"`
+		Pagination pagination  `json:"pagination"`
+	}
+	if err := q.client.list(ctx, "/api/v1/warehouses", args.values(), &resp); err != nil {
+		return nil, err
+	}
+
+	l := loadersFrom(ctx)
+	items := make([]*warehouseResolver, 0, len(resp.Warehouses))
+	for i := range resp.Warehouses {
+		w := &resp.Warehouses[i]
+		l.warehouses.Prime(w.ID, w)
+		items = append(items, &warehouseResolver{w})
+	}
+	return &pageResolver[*warehouseResolver]{items, resp.Pagination}, nil
+}
+
+func (q *queryResolver) Warehouse(ctx context.Context, args idArgs) (*warehouseResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	w, err := loadersFrom(ctx).warehouses.Load(ctx, id)
+	if err != nil || w == nil {
+		return nil, err
+	}
+	return &warehouseResolver{w}, nil
+}
+
+func (q *queryResolver) Transactions(ctx context.Context, args struct {
+	MerchantID *graphql.ID
+	Page       *int32
+	Limit      *int32
+	Search     *string
+}) (*pageResolver[*transactionResolver], error) {
+	query := pageArgs{Page: args.Page, Limit: args.Limit, Search: args.Search}.values()
+	if args.MerchantID != nil {
+		id, err := parseID(*args.MerchantID)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("merchant_id", strconv.FormatUint(uint64(id), 10))
+	}
+
+	var resp struct {
+		Transactions []transaction `json:"transaction"`
+		Pagination   pagination    `json:"pagination"`
+	}
+	if err := q.client.list(ctx, "/api/v1/transactions", query, &resp); err != nil {
+		return nil, err
+	}
+
+	items := make([]*transactionResolver, 0, len(resp.Transactions))
+	for i := range resp.Transactions {
+		items = append(items, &transactionResolver{q.client, &resp.Transactions[i]})
+	}
+	return &pageResolver[*transactionResolver]{items, resp.Pagination}, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Pages
+// ────────────────────────────────────────────────────────────────
+
+type pageResolver[T any] struct {
+	items      []T
+	pagination pagination
+}
+
+func (p *pageResolver[T]) Items() []T {
+	return p.items
+}
+
+func (p *pageResolver[T]) Pagination() *paginationResolver {
+	return &paginationResolver{p.pagination}
+}
+
+type paginationResolver struct {
+	p pagination
+}
+
+func (r *paginationResolver) CurrentPage() int32  { return int32(r.p.CurrentPage) }
+func (r *paginationResolver) TotalPages() int32   { return int32(r.p.TotalPages) }
+func (r *paginationResolver) TotalRecords() int32 { return int32(r.p.TotalRecords) }
+func (r *paginationResolver) Limit() int32        { return int32(r.p.Limit) }
+func (r *paginationResolver) HasNext() bool       { return r.p.HasNext }
+func (r *paginationResolver) HasPrev() bool       { return r.p.HasPrev }
+
+// ────────────────────────────────────────────────────────────────
+// Category
+// ────────────────────────────────────────────────────────────────
+
+type categoryResolver struct {
+	c *category
+}
+
+func (r *categoryResolver) ID() graphql.ID      { return toID(r.c.ID) }
+func (r *categoryResolver) Name() string        { return r.c.Name }
+func (r *categoryResolver) Tagline() string     { return r.c.Tagline }
+func (r *categoryResolver) Photo() string       { return r.c.Photo }
+func (r *categoryResolver) ProductCount() int32 { return int32(r.c.CountProduct) }
+
+// ────────────────────────────────────────────────────────────────
+// Product
+// ────────────────────────────────────────────────────────────────
+
+type productResolver struct {
+	p *product
+}
+
+func (r *productResolver) ID() graphql.ID    { return toID(r.p.ID) }
+func (r *productResolver) Name() string      { return r.p.Name }
+func (r *productResolver) Barcode() string   { return r.p.Barcode }
+func (r *productResolver) Price() int32      { return int32(r.p.Price) }
+func (r *productResolver) About() string     { return r.p.About }
+func (r *productResolver) Thumbnail() string { return r.p.Thumbnail }
+
+func (r *productResolver) Category(ctx context.Context) (*categoryResolver, error) {
+	if r.p.Category.ID != 0 {
+		return &categoryResolver{&r.p.Category}, nil
+	}
+	if r.p.CategoryID == 0 {
+		return nil, nil
+	}
+
+	c, err := loadersFrom(ctx).categories.Load(ctx, r.p.CategoryID)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return &categoryResolver{c}, nil
+}
+
+func resolveProduct(ctx context.Context, id uint) (*productResolver, error) {
+	p, err := loadersFrom(ctx).products.Load(ctx, id)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &productResolver{p}, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Warehouse
+// ────────────────────────────────────────────────────────────────
+
+type warehouseResolver struct {
+	w *warehouse
+}
+
+func (r *warehouseResolver) ID() graphql.ID      { return toID(r.w.ID) }
+func (r *warehouseResolver) Name() string        { return r.w.Name }
+func (r *warehouseResolver) Address() string     { return r.w.Address }
+func (r *warehouseResolver) Photo() string       { return r.w.Photo }
+func (r *warehouseResolver) Phone() string       { return r.w.Phone }
+func (r *warehouseResolver) ProductCount() int32 { return int32(r.w.CountProduct) }
+
+// ────────────────────────────────────────────────────────────────
+// Merchant
+// ────────────────────────────────────────────────────────────────
+
+type merchantResolver struct {
+	client *client
+	m      *merchant
+}
+
+func (r *merchantResolver) ID() graphql.ID       { return toID(r.m.ID) }
+func (r *merchantResolver) Name() string         { return r.m.Name }
+func (r *merchantResolver) Address() string      { return r.m.Address }
+func (r *merchantResolver) Photo() string        { return r.m.Photo }
+func (r *merchantResolver) Phone() string        { return r.m.Phone }
+func (r *merchantResolver) KeeperID() graphql.ID { return toID(r.m.KeeperID) }
+func (r *merchantResolver) KeeperName() string   { return r.m.KeepersName }
+func (r *merchantResolver) ProductCount() int32  { return int32(r.m.ProductCount) }
+
+func (r *merchantResolver) Products(ctx context.Context, args struct {
+	Page  *int32
+	Limit *int32
+}) (*pageResolver[*merchantProductResolver], error) {
+	query := pageArgs{Page: args.Page, Limit: args.Limit}.values()
+	query.Set("merchant_id", strconv.FormatUint(uint64(r.m.ID), 10))
+
+	var resp struct {
+		MerchantProducts []merchantProduct `json:"merchant_products"`
+		Pagination       pagination        `json:"pagination"`
+	}
+	if err := r.client.list(ctx, "/api/v1/merchant-products", query, &resp); err != nil {
+		return nil, err
+	}
+
+	items := make([]*merchantProductResolver, 0, len(resp.MerchantProducts))
+	for i := range resp.MerchantProducts {
+		items = append(items, &merchantProductResolver{&resp.MerchantProducts[i]})
+	}
+	return &pageResolver[*merchantProductResolver]{items, resp.Pagination}, nil
+}
+
+type merchantProductResolver struct {
+	mp *merchantProduct
+}
+
+func (r *merchantProductResolver) ID() graphql.ID { return toID(r.mp.ID) }
+func (r *merchantProductResolver) Stock() int32   { return int32(r.mp.Stock) }
+
+func (r *merchantProductResolver) Product(ctx context.Context) (*productResolver, error) {
+	return resolveProduct(ctx, r.mp.ProductID)
+}
+
+func (r *merchantProductResolver) Warehouse(ctx context.Context) (*warehouseResolver, error) {
+	w, err := loadersFrom(ctx).warehouses.Load(ctx, r.mp.WarehouseID)
+	if err != nil || w == nil {
+		return nil, err
+	}
+	return &warehouseResolver{w}, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Transaction
+// ────────────────────────────────────────────────────────────────
+
+type transactionResolver struct {
+	client *client
+	t      *transaction
+}
+
+func (r *transactionResolver) ID() graphql.ID          { return toID(r.t.ID) }
+func (r *transactionResolver) Name() string            { return r.t.Name }
+func (r *transactionResolver) Phone() string           { return r.t.Phone }
+func (r *transactionResolver) Email() string           { return r.t.Email }
+func (r *transactionResolver) Address() string         { return r.t.Address }
+func (r *transactionResolver) SubTotal() float64       { return float64(r.t.SubTotal) }
+func (r *transactionResolver) TaxTotal() float64       { return float64(r.t.TaxTotal) }
+func (r *transactionResolver) GrandTotal() float64     { return float64(r.t.GrandTotal) }
+func (r *transactionResolver) PaymentStatus() string   { return r.t.PaymentStatus }
+func (r *transactionResolver) PaymentMethod() string   { return r.t.PaymentMethod }
+func (r *transactionResolver) TransactionCode() string { return r.t.TransactionCode }
+func (r *transactionResolver) OrderID() string         { return r.t.OrderID }
+func (r *transactionResolver) Notes() string           { return r.t.Notes }
+
+func (r *transactionResolver) Merchant(ctx context.Context) (*merchantResolver, error) {
+	m, err := loadersFrom(ctx).merchants.Load(ctx, r.t.MerchantID)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	return &merchantResolver{r.client, m}, nil
+}
+
+func (r *transactionResolver) Products() []*transactionProductResolver {
+	items := make([]*transactionProductResolver, 0, len(r.t.Products))
+	for i := range r.t.Products {
+		items = append(items, &transactionProductResolver{&r.t.Products[i]})
+	}
+	return items
+}
+
+type transactionProductResolver struct {
+	tp *transactionProduct
+}
+
+func (r *transactionProductResolver) ID() graphql.ID    { return toID(r.tp.ID) }
+func (r *transactionProductResolver) Quantity() int32   { return int32(r.tp.Quantity) }
+func (r *transactionProductResolver) Price() float64    { return float64(r.tp.Price) }
+func (r *transactionProductResolver) SubTotal() float64 { return float64(r.tp.SubTotal) }
+
+func (r *transactionProductResolver) Product(ctx context.Context) (*productResolver, error) {
+	return resolveProduct(ctx, r.tp.ProductID)
+}
@@ -1,35 +1,57 @@
 package main
 
 import (
-	"bytes"
-	"fmt"
-	"io"
+	"context"
+	_ "embed"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 
 	jwtConf "warehouse-go/api-gateaway/config"
+	"warehouse-go/api-gateaway/apikey"
+	"warehouse-go/api-gateaway/bff"
+	"warehouse-go/api-gateaway/cache"
 	"warehouse-go/api-gateaway/controller"
+	"warehouse-go/api-gateaway/docs"
+	"warehouse-go/api-gateaway/gql"
 	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/oidc"
+	"warehouse-go/api-gateaway/proxy"
+	"warehouse-go/api-gateaway/realtime"
+	"warehouse-go/api-gateaway/routing"
+	"warehouse-go/api-gateaway/session"
 )
 
+var gatewayProxy *proxy.Proxy
+
+// defaultRoutes is the route table used when ROUTES_FILE does not exist.
+//
+//go:embed routes.yaml
+var defaultRoutes []byte
+
 
 type ServiceConfig struct {
 	Name string
 	URL string
+	Instances []string
 }
 
 type Config struct {
 	Port     string
 	Services map[string]ServiceConfig
+	LBStrategy string
+	UpstreamsFile string
+	RoutesFile string
 }
 
 func main() {
@@ -44,23 +66,50 @@ func main() {
 	jwtConfig := jwtConf.LoadJWTConfig()
 	redisConfig := jwtConf.LoadRedisConfig()
 
+	upstreams, err := loadUpstreams(config)
+	if err != nil {
+		log.Fatalf("Invalid upstream configuration: %v", err)
+	}
+
+	routes, err := loadRoutes(config, upstreams.Names())
+	if err != nil {
+		log.Fatalf("Invalid route configuration: %v", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "routes" {
+		if err := routing.PrintTable(os.Stdout, routes); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	gatewayProxy = proxy.NewProxy(jwtConf.LoadProxyConfig(), upstreams)
+	for _, name := range upstreams.Names() {
+		gatewayProxy.Register(name)
+	}
+	jwtConfig.APIKeys = apikey.NewVerifier(gatewayProxy, jwtConf.LoadAPIKeyConfig())
+	jwtConfig.Sessions = session.NewVerifier(gatewayProxy, jwtConf.LoadSessionConfig())
+
+	proxy.NewHealthChecker(upstreams, jwtConf.LoadHealthCheckConfig()).Start(context.Background())
+	if config.UpstreamsFile != "" {
+		proxy.WatchUpstreamsFile(context.Background(), config.UpstreamsFile, 0, upstreams)
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName: "Warehouse Project API Gateaway",
 		ServerHeader: "Warehouse-API-Gateaway",
 	})
 
-	rateLimiter := middleware.DefaultRateLimiterConfig()
-
 	var redisClient *redis.Client
 	if redisConfig.Host != "" {
 		redisClient = jwtConf.NewRedisClient(redisConfig)
 	}
-	redisRateConfig := middleware.RedisRateLimiterConfig{
-		Max: rateLimiter.Max,
-		Expiration: rateLimiter.Expiration,
-		RedisClient: redisClient,
-	}
+	redisRateConfig := jwtConf.LoadRateLimitConfig()
+	redisRateConfig.RedisClient = redisClient
 
+	// The id is echoed in X-Request-ID and forwarded upstream, where audit
+	// events record it.
+	app.Use(requestid.New())
 	app.Use(middleware.RedisGlobalRateLimiter(redisRateConfig))
 	app.Use(recover.New())
 	app.Use(logger.New())
@@ -88,11 +137,48 @@ func main() {
 		})
 	})
 
-	authController := controller.NewAuthController(config.Services["auth"].URL, jwtConfig)
-	setUpAuthRoutes(app, authController, rateLimiter)
-	setUpMidtransCallbackRoutes(app, config.Services["midtrans"])
+	app.Get("/health/breakers", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status" : "OK",
+			"breakers" : gatewayProxy.Breakers(),
+		})
+	})
 
-	setupProtectedRoutes(app, config, jwtConfig, rateLimiter)
+	app.Get("/health/upstreams", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status" : "OK",
+			"upstreams" : gatewayProxy.Upstreams(),
+		})
+	})
+
+	loginGuardConfig := jwtConf.LoadLoginGuardConfig()
+	loginGuardConfig.RedisClient = redisClient
+	authController := controller.NewAuthController(gatewayProxy, jwtConfig, middleware.NewLoginGuard(loginGuardConfig))
+	setUpAuthRoutes(app, authController, redisRateConfig)
+
+	if oidcConfig := jwtConf.LoadOIDCConfig(); oidcConfig.Enabled() {
+		oidcController := controller.NewOIDCController(authController, oidc.NewClient(oidcConfig), oidc.NewStateStore(redisClient))
+		setUpOIDCRoutes(app, oidcController)
+	}
+
+	responseCache := cache.New(redisClient, routes, jwtConf.LoadCacheConfig())
+	responseCache.Start(context.Background())
+	setupRoutes(app, routes, jwtConfig, redisRateConfig, responseCache)
+
+	bffGroup := app.Group("/api/v1/bff", middleware.JWTAuthMiddleware(jwtConfig), middleware.RedisAPIRateLimiter(redisRateConfig))
+	bff.New(gatewayProxy, jwtConf.LoadBFFConfig()).Register(bffGroup)
+
+	graphQLServer := gql.NewServer(gatewayProxy, routes, jwtConf.LoadGraphQLConfig())
+	app.Post("/api/v1/graphql", middleware.JWTAuthMiddleware(jwtConfig), middleware.RedisAPIRateLimiter(redisRateConfig), graphQLServer.Handler)
+
+	realtimeConfig := jwtConf.LoadRealtimeConfig()
+	realtimeConfig.Sessions = jwtConfig.Sessions
+	realtimeConfig.APIKeys = jwtConfig.APIKeys
+	realtimeHub := realtime.NewHub(gatewayProxy, realtimeConfig)
+	realtimeHub.Start(context.Background())
+	app.Get("/api/v1/events", middleware.JWTAuthMiddleware(jwtConfig), middleware.RedisAPIRateLimiter(redisRateConfig), realtimeHub.Stream)
+
+	docs.Register(app, docs.NewAggregator(jwtConf.LoadDocsConfig(), upstreams, routes))
 
 	app.Use(func(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{
@@ -109,58 +195,82 @@ func main() {
 func loadConfig() Config {
 	config := Config{
 		Port: getEnv("PORT", "8080"),
+		LBStrategy: getEnv("LB_STRATEGY", string(proxy.RoundRobin)),
+		UpstreamsFile: getEnv("UPSTREAMS_FILE", ""),
+		RoutesFile: getEnv("ROUTES_FILE", "routes.yaml"),
 		Services: map[string]ServiceConfig{
-			"user" : {
-				Name: "user-service",
-				URL: getEnv("USER_SERVICE_URL", "http://localhost:8081"),
-			},
-			"role" : {
-				Name: "role-service",
-				URL: getEnv("USER_SERVICE_URL", "http://localhost:8081"),
-			},
-			"assign-role" : {
-				Name: "assign-role-service", 
-				URL: getEnv("USER_SERVICE_URL", "http://localhost:8081"),
-			},
-			"auth" : {
-				Name: "auth-service", 
-				URL: getEnv("USER_SERVICE_URL", "http://localhost:8081"),
-			},
-			"product" : {
-				Name: "product-service", 
-				URL: getEnv("PRODUCT_SERVICE_URL", "http://localhost:8082"),
-			},
-			"merchant" : {
-				Name: "merchant-service", 
-				URL: getEnv("MERCHANT_SERVICE_URL", "http://localhost:8084"),
-			},
-			"notification" : {
-				Name: "notification-service", 
-				URL: getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:8086"),
-			},
-			"transaction" : {
-				Name: "transaction-service", 
-				URL: getEnv("TRANSACTION_SERVICE_URL", "http://localhost:8085"),
-			},
-			"midtrans" : {
-				Name: "midtrans-service", 
-				URL: getEnv("TRANSACTION_SERVICE_URL", "http://localhost:8085"),
-			},
-			"warehouse" : {
-				Name: "warehouse-service", 
-				URL: getEnv("WAREHOUSE_SERVICE_URL", "http://localhost:8083"),
-			},
+			"user" : newServiceConfig("user-service", "USER_SERVICE_URL", "http://localhost:8081"),
+			"role" : newServiceConfig("user-service", "USER_SERVICE_URL", "http://localhost:8081"),
+			"assign-role" : newServiceConfig("user-service", "USER_SERVICE_URL", "http://localhost:8081"),
+			"auth" : newServiceConfig("user-service", "USER_SERVICE_URL", "http://localhost:8081"),
+			"product" : newServiceConfig("product-service", "PRODUCT_SERVICE_URL", "http://localhost:8082"),
+			"merchant" : newServiceConfig("merchant-service", "MERCHANT_SERVICE_URL", "http://localhost:8084"),
+			"notification" : newServiceConfig("notification-service", "NOTIFICATION_SERVICE_URL", "http://localhost:8086"),
+			"transaction" : newServiceConfig("transaction-service", "TRANSACTION_SERVICE_URL", "http://localhost:8085"),
+			"midtrans" : newServiceConfig("transaction-service", "TRANSACTION_SERVICE_URL", "http://localhost:8085"),
+			"warehouse" : newServiceConfig("warehouse-service", "WAREHOUSE_SERVICE_URL", "http://localhost:8083"),
 		},
 	}
 
 	log.Println("Service Configuration")
 	for name, service := range config.Services {
-		log.Printf(" %s: %s", name, service.URL)
+		log.Printf(" %s: %s", name, strings.Join(service.Instances, ", "))
 	}
 
 	return config
 }
 
+// newServiceConfig reads the instances of a service from envKey, which holds
+// one URL or a comma separated list of URLs when the service has replicas.
+// An envKey without any URL falls back to fallback.
+func newServiceConfig(name, envKey, fallback string) ServiceConfig {
+	var instances []string
+	for _, instance := range strings.Split(getEnv(envKey, fallback), ",") {
+		if instance = strings.TrimSpace(instance); instance != "" {
+			instances = append(instances, strings.TrimRight(instance, "/"))
+		}
+	}
+	if len(instances) == 0 {
+		log.Printf("%s has no instances, using %s", envKey, fallback)
+		instances = []string{fallback}
+	}
+
+	return ServiceConfig{
+		Name: name,
+		URL: instances[0],
+		Instances: instances,
+	}
+}
+
+// loadUpstreams builds the instance pools from the env configuration and then
+// overrides them with the upstreams file, if one is configured.
+func loadUpstreams(config Config) (*proxy.Upstreams, error) {
+	configs := make(map[string]proxy.UpstreamConfig)
+	for _, service := range config.Services {
+		configs[service.Name] = proxy.UpstreamConfig{
+			Strategy: proxy.Strategy(config.LBStrategy),
+			Instances: service.Instances,
+		}
+	}
+
+	upstreams, err := proxy.NewUpstreams(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.UpstreamsFile != "" {
+		fileConfigs, err := proxy.LoadUpstreamsFile(config.UpstreamsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := upstreams.Update(fileConfigs); err != nil {
+			return nil, err
+		}
+	}
+
+	return upstreams, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -173,385 +283,131 @@ func setUpAuthRoutes(app *fiber.App, authController *controller.AuthController,
 
 	authGroup.Use(middleware.RedisAuthRateLimiter(rateLimiterConfig))
 	authGroup.Post("/login", authController.Login)
+	authGroup.Post("/login/2fa", authController.LoginTwoFactor)
 }
 
-func setUpMidtransCallbackRoutes(app *fiber.App, service ServiceConfig) {
-	app.Post("/api/v1/midtrans/callback", func(c *fiber.Ctx) error {
-		client := &http.Client{}
+// setUpOIDCRoutes registers single sign-on below the auth group, whose rate
+// limiter applies. The callback accepts the provider's redirect as well as
+// code and state posted by a frontend page.
+func setUpOIDCRoutes(app *fiber.App, oidcController *controller.OIDCController) {
+	oidcGroup := app.Group("/api/v1/auth/oidc")
 
-		fullUrl := service.URL + "/api/v1/midtrans/callback"
+	oidcGroup.Get("/login", oidcController.Login)
+	oidcGroup.Get("/callback", oidcController.Callback)
+	oidcGroup.Post("/callback", oidcController.Callback)
+}
 
-		body := c.Body()
+// setupRoutes registers every route of the route table. Each route gets its
+// own middleware chain so auth, roles and rate limiting follow the route
+// configuration.
+func setupRoutes(app *fiber.App, routes []routing.Route, jwtConfig middleware.JWTConfig, rateLimiterConfig middleware.RedisRateLimiterConfig, responseCache *cache.Cache) {
+	for _, route := range routing.Sorted(routes) {
+		route := route
 
-		req, err := http.NewRequest(c.Method(), fullUrl, bytes.NewReader(body))
-		if err != nil {
-			log.Printf("Error createing request: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to create request",
-			})
+		var handlers []fiber.Handler
+		if route.RequiresAuth() {
+			handlers = append(handlers, middleware.JWTAuthMiddleware(jwtConfig))
 		}
 
-		for key, values := range c.GetReqHeaders() {
-			for _, value := range values {
-				req.Header.Add(key, value)
-			}
+		switch route.Tier() {
+		case routing.TierAPI:
+			handlers = append(handlers, middleware.RedisAPIRateLimiter(rateLimiterConfig))
+		case routing.TierAuth:
+			handlers = append(handlers, middleware.RedisAuthRateLimiter(rateLimiterConfig))
 		}
-
-		req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
-		req.Header.Set("X-Internal-Request", "true")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Error making request to: %s: %v", fullUrl, err)
-			return c.Status(502).JSON(fiber.Map{
-				"error" : "Bad Request",
-				"message" : "Service unavailable",
-				"service" : service.URL,
-			})
+		if route.RateLimit > 0 {
+			handlers = append(handlers, middleware.RedisRouteRateLimiter(rateLimiterConfig, route.Prefix, route.RateLimit))
 		}
 
-		defer resp.Body.Close()
-
-		respBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("Error reading response body: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to read response",
-			})
+		if len(route.Roles) > 0 {
+			handlers = append(handlers, middleware.RoleAuthMiddleware(route.Roles...))
 		}
-		
-		for key, values := range resp.Header {
-			for _, value := range values {
-				c.Set(key, value)
-			}
+		if len(route.Permissions) > 0 {
+			handlers = append(handlers, middleware.PermissionAuthMiddleware(route.Permissions...))
 		}
-
-		return c.Status(resp.StatusCode).Send(respBody)
-
-	})
-}
-
-func proxyRequestWithPath(c *fiber.Ctx, targetURL string, basePath string) error {
-	fullPath := c.Path()
-	
-	fullURL := targetURL + fullPath
-
-	queryParams := c.Context().QueryArgs().String()
-	if queryParams != "" {
-		fullURL += "?" + queryParams
-	}
-
-	client := &http.Client{}
-
-	body := c.Body()
-
-	req, err := http.NewRequest(c.Method(), fullURL, bytes.NewReader(body))
-	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return c.Status(500).JSON(fiber.Map{
-			"error" : "Internal Server Error",
-			"message" : "Failed to create request",
-		})
-	}
-
-	for key, values := range c.GetReqHeaders() {
-		for _, value := range values {
-			req.Header.Add(key, value)
+		if len(route.WritePermissions) > 0 {
+			handlers = append(handlers, writePermissionMiddleware(route.WritePermissions))
 		}
-	}
-
-	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
-	req.Header.Set("X-Internal-Request", "true")
-	
-	addUserHeaders(req, c) 
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Error making request to: %s: %v", fullURL, err )
-		return c.Status(502).JSON(fiber.Map{
-			"error" : "Bad Gateaway",
-			"message" : "Service Unavailable",
-			"service" : targetURL, 
-		})
-	}
 
-	defer resp.Body.Close()
+		if route.CacheTTL > 0 {
+			handlers = append(handlers, responseCache.Middleware(route))
+		}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading response body: %v", err)
-		return c.Status(500).JSON(fiber.Map{
-			"error" : "Internal Server Error",
-			"message" : "Failed to read response",
+		handlers = append(handlers, func(c *fiber.Ctx) error {
+			return proxyRoute(c, route)
 		})
-	}
 
-	for key, values := range resp.Header {
-		for _, value := range values {
-			c.Set(key, value)
+		for _, path := range []string{route.Prefix, route.Prefix + "/*"} {
+			if len(route.Methods) == 0 {
+				app.All(path, handlers...)
+				continue
+			}
+			for _, method := range route.Methods {
+				app.Add(strings.ToUpper(method), path, handlers...)
+			}
 		}
 	}
-
-	return c.Status(resp.StatusCode).Send(respBody)
 }
 
-func proxyRequest(c *fiber.Ctx, targetURL string) error {
-	path := c.Params("*")
-	if path == "" {
-		path = c.Path()
+// writePermissionMiddleware applies the permission check to requests that
+// change something and lets reads through.
+func writePermissionMiddleware(permissions []string) fiber.Handler {
+	check := middleware.PermissionAuthMiddleware(permissions...)
+	return func(c *fiber.Ctx) error {
+		if routing.IsReadMethod(c.Method()) {
+			return c.Next()
+		}
+		return check(c)
 	}
+}
 
-	path = strings.TrimPrefix(path, "api/v1/")
-
-	fullURL := targetURL
-	if !strings.HasSuffix(targetURL, "/") && !strings.HasPrefix(path, "/") {
-		fullURL += "/"
-	}
-	fullURL += path
+func proxyRoute(c *fiber.Ctx, route routing.Route) error {
+	path := route.UpstreamPath(c.Path())
 
 	queryParams := c.Context().QueryArgs().String()
 	if queryParams != "" {
-		fullURL += "?" + queryParams
+		path += "?" + queryParams
 	}
 
-	client := &http.Client{}
-
-	body := c.Body()
-
-	req, err := http.NewRequest(c.Method(), fullURL, bytes.NewReader(body))
-	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return c.Status(500).JSON(fiber.Map{
-			"error" : "Internal Server Error",
-			"message" : "Failed to create request",
-		})
-	}
-
-	for key, values := range c.GetReqHeaders() {
-		for _, value := range values {
-			req.Header.Add(key, value)
-		}
-	}
-
-	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
-	req.Header.Set("X-Internal-Request", "true")
-	
-	addUserHeaders(req, c) 
+	return gatewayProxy.ForwardWithTimeout(c, route.Upstream, path, time.Duration(route.Timeout), func(req *http.Request) {
+		addUserHeaders(req, c)
+	})
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Error making request to: %s: %v", fullURL, err )
-		return c.Status(502).JSON(fiber.Map{
-			"error" : "Bad Gateaway",
-			"message" : "Service Unavailable",
-			"service" : targetURL, 
-		})
+// loadRoutes reads the route table from ROUTES_FILE, falling back to the
+// routes.yaml built into the binary when the file does not exist, and
+// validates it.
+func loadRoutes(config Config, upstreams []string) ([]routing.Route, error) {
+	var routes []routing.Route
+	var err error
+	if _, statErr := os.Stat(config.RoutesFile); statErr == nil {
+		routes, err = routing.Load(config.RoutesFile)
+	} else {
+		log.Printf("Warning: route file %s not found, using built-in routes", config.RoutesFile)
+		routes, err = routing.Parse("routes.yaml", defaultRoutes)
 	}
-
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Error reading response body: %v", err)
-		return c.Status(500).JSON(fiber.Map{
-			"error" : "Internal Server Error",
-			"message" : "Failed to read response",
-		})
+		return nil, err
 	}
 
-	for key, values := range resp.Header {
-		for _, value := range values {
-			c.Set(key, value)
-		}
+	if err := routing.Validate(routes, upstreams); err != nil {
+		return nil, err
 	}
 
-	return c.Status(resp.StatusCode).Send(respBody)
-
+	return routes, nil
 }
 
-func addUserHeaders( req *http.Request, c *fiber.Ctx) {
-	if userID := c.Locals("user_id"); userID != nil {
-		req.Header.Set("X-User-ID", fmt.Sprintf("%v", userID))
+func addUserHeaders(req *http.Request, c *fiber.Ctx) {
+	// Never trust identity headers sent by the client itself.
+	req.Header.Del("X-User-ID")
+	req.Header.Del("X-User-email")
+	req.Header.Del("X-User-Roles")
+	req.Header.Del("X-User-Permissions")
+	req.Header.Del("X-API-Key-ID")
+	req.Header.Del(middleware.SessionIDHeader)
+	req.Header.Del(middleware.APIKeyHeader)
+
+	for key, values := range middleware.IdentityHeader(c) {
+		req.Header[key] = values
 	}
-	if userEmail := c.Locals("user_email"); userEmail != nil {
-		req.Header.Set("X-User-email", fmt.Sprintf("%v", userEmail))
-	}
-	if userRoles := c.Locals("user_roles"); userRoles != nil {
-		req.Header.Set("X-User-Roles", fmt.Sprintf("%v", userRoles))
-	}
-}
-
-func setupProtectedRoutes(app *fiber.App, config Config, jwtConfig middleware.JWTConfig, rateLimiterConfig middleware.RedisRateLimiterConfig) {
-	protected := app.Group("/api/v1", middleware.JWTAuthMiddleware(jwtConfig))
-
-	protected.Use(middleware.RedisAPIRateLimiter(rateLimiterConfig))
-
-	setupUserRoutes(protected, config.Services["user"])
-	setupRolesRoutes(protected, config.Services["role"])
-	setupAssignRoleRoutes(protected, config.Services["assign-role"])
-	setupProductRoutes(protected, config.Services["product"])
-	setupMerchantRoutes(protected, config.Services["merchant"])
-	setupTransactionRoutes(protected, config.Services["transaction"])
-	setupWarehouseRoutes(protected, config.Services["warehouse"])
-
-
-}
-
-func setupUserRoutes(router fiber.Router, service ServiceConfig) {
-	userGroup := router.Group("/users")
-
-	userGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/users")
-	})
-
-	userGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/users")
-	})
-}
-
-func setupRolesRoutes(router fiber.Router, service ServiceConfig) {
-	roleGroup := router.Group("/roles")
-
-	roleGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/roles")
-	})
-
-	roleGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/roles")
-	})
-}
-
-func setupAssignRoleRoutes(router fiber.Router, service ServiceConfig) {
-	assignRoleGroup := router.Group("/assign-role")
-
-	assignRoleGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/assign-role")
-	})
-
-	assignRoleGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/assign-role")
-	})
-}
-
-func setupProductRoutes(router fiber.Router, service ServiceConfig) {
-	productGroup := router.Group("/product")
-
-	productGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/products")
-	})
-
-	productGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/products")
-	})
-
-	categoryGroup := router.Group("/categories")
-
-	categoryGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/categories")
-	})
-
-	categoryGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/categories")
-	})
-
-	uploadProductGroup := router.Group("/upload")
-
-	uploadProductGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/upload")
-	})
-
-	uploadProductGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/produploaducts")
-	})
-}
-
-func setupMerchantRoutes(router fiber.Router, service ServiceConfig) {
-	merchantGroup := router.Group("/merchants")
-
-	merchantGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/merchants")
-	})
-
-	merchantGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/merchants")
-	})
-
-	merchantProductGroup := router.Group("/merchant-products")
-
-	merchantProductGroup.All("/*", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/merchant-products")
-	})
-
-	merchantProductGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/merchant-products")
-	})
-
-	uploadGroup := router.Group("/upload-merchant")
-	uploadGroup.All("/*", func(c *fiber.Ctx) error {
-		return proxyRequest(c, service.URL)
-	})
-
-	uploadGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequest(c, service.URL)
-	})
-} 
-
-func setupTransactionRoutes(router fiber.Router, service ServiceConfig) {
-	transactionGroup := router.Group("/transactions")
-
-	transactionGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/transactions")
-	})
-
-	transactionGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/transactions")
-	})
-
-	dashboardGroup := router.Group("/dashboard")
-
-	dashboardGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/dashboard")
-	})
-
-	dashboardGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/dashboard")
-	})
-}
-
-func setupWarehouseRoutes(router fiber.Router, service ServiceConfig) {
-	warehouseGroup := router.Group("/warehouses")
-
-	warehouseGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/warehouses")
-	})
-
-	warehouseGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/warehouses")
-	})
-
-	warehouseProductGroup := router.Group("/warehouse-products")
-
-	warehouseProductGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequestWithPath(c, service.URL, "/api/v1/warehouse-products")
-	})
-
-	warehouseProductGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequestWithPath(c, service.URL, "/api/v1/warehouse-products")
-	})
-
-	uploadWarehouseGroup := router.Group("/upload-warehouse")
-
-	uploadWarehouseGroup.All("/*", func(c *fiber.Ctx) error {
-		 return proxyRequest(c, service.URL)
-	})
-
-	uploadWarehouseGroup.All("/", func(c *fiber.Ctx) error {
-		return proxyRequest(c, service.URL)
-	})
 }
-
-
-
-
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,13 +15,42 @@ type JWTClaims struct{
 	UserID uint `json:"user_id"`
 	Email string `json:"email"`
 	Roles string `json:"roles"`
+	// Permissions is the comma separated union of the permissions of Roles,
+	// as resolved by user-service at login.
+	Permissions string `json:"permissions"`
+	// MustChangePassword limits the token to changing the password, see
+	// allowsPendingPasswordChange.
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// TwoFactorSetupRequired limits the token to enrolling in 2FA, for users
+	// whose role requires it.
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
+	// Purpose marks tokens that are not for calling the API, like the 2FA
+	// login challenge. JWTAuthMiddleware refuses them.
+	Purpose string `json:"purpose,omitempty"`
+	// SessionID is the user-service session of the login. Signing it out
+	// ends the token early.
+	SessionID uint `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// PurposeTwoFactor is the purpose of the token handed out after a correct
+// password when the user still has to send a 2FA code.
+const PurposeTwoFactor = "2fa"
+
+// TwoFactorChallengeDuration is how long the user has to enter the 2FA code.
+const TwoFactorChallengeDuration = 5 * time.Minute
+
 type JWTConfig struct {
 	SecretKey string
 	Issuer string
 	Duration time.Duration
+
+	// APIKeys, if set, lets machine clients authenticate with an X-API-Key
+	// header instead of a Bearer token.
+	APIKeys APIKeyVerifier
+
+	// Sessions, if set, refuses tokens whose session was signed out.
+	Sessions SessionVerifier
 }
 
 func JWTAuthMiddleware(config JWTConfig) fiber.Handler {
@@ -27,6 +59,10 @@ func JWTAuthMiddleware(config JWTConfig) fiber.Handler {
 			return c.Next()
 		}
 
+		if key := c.Get(APIKeyHeader); key != "" && config.APIKeys != nil {
+			return apiKeyAuth(c, config.APIKeys, key)
+		}
+
 		authHeader := c.Get("Authorization")
 			if authHeader == "" {
 				return c.Status(401).JSON(fiber.Map{
@@ -42,9 +78,12 @@ func JWTAuthMiddleware(config JWTConfig) fiber.Handler {
 			})
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer")
+		tokenString := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer"))
 		
 		claims, err := validateJWT(tokenString, config.SecretKey)
+		if err == nil && claims.Purpose != "" {
+			err = jwt.ErrTokenInvalidClaims
+		}
 		if err != nil {
 			return c.Status(401).JSON(fiber.Map{
 				"error" : "Unauthorized",
@@ -52,9 +91,47 @@ func JWTAuthMiddleware(config JWTConfig) fiber.Handler {
 			})
 		}
 
+		if claims.SessionID != 0 && config.Sessions != nil {
+			if err := config.Sessions.VerifySession(c.UserContext(), claims.SessionID, claims.UserID, c.IP()); err != nil {
+				if errors.Is(err, ErrInvalidSession) {
+					return c.Status(401).JSON(fiber.Map{
+						"error" : "Unauthorized",
+						"message" : "Session expired or signed out",
+					})
+				}
+				return c.Status(503).JSON(fiber.Map{
+					"error" : "Service Unavailable",
+					"message" : "Session could not be verified",
+				})
+			}
+		}
+
+		if claims.MustChangePassword && !allowsPendingAction(c.Path()) {
+			return c.Status(403).JSON(fiber.Map{
+				"error" : "Forbidden",
+				"code" : "password_change_required",
+				"message" : "Change your password before using the API",
+			})
+		}
+
+		if claims.TwoFactorSetupRequired && !allowsPendingAction(c.Path()) {
+			return c.Status(403).JSON(fiber.Map{
+				"error" : "Forbidden",
+				"code" : "two_factor_setup_required",
+				"message" : "Set up two-factor authentication before using the API",
+			})
+		}
+
 		c.Locals("user_id", claims.UserID)
 		c.Locals("user_email", claims.Email)
 		c.Locals("user_roles", claims.Roles)
+		c.Locals("user_permissions", claims.Permissions)
+		if claims.SessionID != 0 {
+			c.Locals("session_id", claims.SessionID)
+		}
+		if claims.ExpiresAt != nil {
+			c.Locals("expires_at", claims.ExpiresAt.Time)
+		}
 
 		return c.Next()
 	}
@@ -71,7 +148,7 @@ func RoleAuthMiddleware(requiredRoles ...string) fiber.Handler {
 			})
 		}
 
-		roles, ok := userRoles.([]string)
+		roles, ok := UserRoles(c)
 		if !ok {
 			return c.Status(500).JSON(fiber.Map{
 				"error" : "Internal Server Error",
@@ -95,6 +172,28 @@ func RoleAuthMiddleware(requiredRoles ...string) fiber.Handler {
 	}
 }
 
+// PermissionAuthMiddleware lets the request through when the caller holds at
+// least one of the required permissions.
+func PermissionAuthMiddleware(requiredPermissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("user_id") == nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error" : "Unauthorized",
+				"message" : "User context not found",
+			})
+		}
+
+		if !HasAnyPermission(c, requiredPermissions...) {
+			return c.Status(403).JSON(fiber.Map{
+				"error" : "Forbidden",
+				"message" : "Insufficent permissions",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
 func validateJWT(tokenString, secretKey string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secretKey), nil
@@ -111,22 +210,30 @@ func validateJWT(tokenString, secretKey string) (*JWTClaims, error) {
 	return nil, jwt.ErrSignatureInvalid
 }
 
-func GenerateJWT(userID uint, email string, roles string, config JWTConfig) (string, error) {
-	claims := &JWTClaims{
-		UserID: userID,
-		Email: email,
-		Roles: roles,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.Duration)),
-			IssuedAt: jwt.NewNumericDate(time.Now()),
-			Issuer: config.Issuer,
-		},
+// GenerateJWT signs claims, filling in the registered claims from config.
+func GenerateJWT(claims JWTClaims, config JWTConfig) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.Duration)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+		Issuer: config.Issuer,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
 	return token.SignedString([]byte(config.SecretKey))
 }
 
+// ValidateTwoFactorChallenge returns the claims of a 2FA login challenge
+// token. Regular access tokens are refused.
+func ValidateTwoFactorChallenge(tokenString string, config JWTConfig) (*JWTClaims, error) {
+	claims, err := validateJWT(tokenString, config.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeTwoFactor {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
 
 func isPublicRoute(path string) bool {
 	publicRoutes := []string{
@@ -142,4 +249,96 @@ func isPublicRoute(path string) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
+
+// allowsPendingAction reports whether a user who still has to replace the
+// initial password or enroll in 2FA may call path. Both sets of routes stay
+// open while either is pending, so a user who has to do both is not stuck.
+func allowsPendingAction(path string) bool {
+	allowedRoutes := []string{
+		"/api/v1/auth/change-password",
+		"/api/v1/auth/2fa/enroll",
+		"/api/v1/auth/2fa/confirm",
+	}
+
+	for _, route := range allowedRoutes {
+		if path == route {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserRoles returns the roles of the authenticated caller. JWTAuthMiddleware
+// stores the roles claim as a comma separated string.
+func UserRoles(c *fiber.Ctx) ([]string, bool) {
+	switch value := c.Locals("user_roles").(type) {
+	case []string:
+		return value, true
+	case string:
+		return splitList(value), true
+	default:
+		return nil, false
+	}
+}
+
+// UserPermissions returns the permissions of the authenticated caller, stored
+// like the roles as a comma separated string.
+func UserPermissions(c *fiber.Ctx) []string {
+	switch value := c.Locals("user_permissions").(type) {
+	case []string:
+		return value
+	case string:
+		return splitList(value)
+	default:
+		return nil
+	}
+}
+
+// HasAnyPermission reports whether the caller holds one of permissions.
+func HasAnyPermission(c *fiber.Ctx, permissions ...string) bool {
+	for _, have := range UserPermissions(c) {
+		for _, permission := range permissions {
+			if have == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IdentityHeader returns the X-User-* headers that tell upstream services who
+// the authenticated caller is.
+func IdentityHeader(c *fiber.Ctx) http.Header {
+	header := make(http.Header)
+	if userID := c.Locals("user_id"); userID != nil {
+		header.Set("X-User-ID", fmt.Sprintf("%v", userID))
+	}
+	if userEmail := c.Locals("user_email"); userEmail != nil {
+		header.Set("X-User-email", fmt.Sprintf("%v", userEmail))
+	}
+	if userRoles := c.Locals("user_roles"); userRoles != nil {
+		header.Set("X-User-Roles", fmt.Sprintf("%v", userRoles))
+	}
+	if userPermissions := c.Locals("user_permissions"); userPermissions != nil {
+		header.Set("X-User-Permissions", strings.Join(UserPermissions(c), ","))
+	}
+	if sessionID := c.Locals("session_id"); sessionID != nil {
+		header.Set(SessionIDHeader, fmt.Sprintf("%v", sessionID))
+	}
+	if apiKeyID := c.Locals("api_key_id"); apiKeyID != nil {
+		header.Set("X-API-Key-ID", fmt.Sprintf("%v", apiKeyID))
+	}
+	return header
+}
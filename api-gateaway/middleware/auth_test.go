@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeSessions knows session 1 as active. Session 2 is signed out, and any
+// other session cannot be checked, as if user-service were down.
+type fakeSessions struct{}
+
+func (fakeSessions) VerifySession(ctx context.Context, sessionID, userID uint, ip string) error {
+	switch sessionID {
+	case 1:
+		return nil
+	case 2:
+		return ErrInvalidSession
+	}
+	return errors.New("user-service unavailable")
+}
+
+func newTestJWTConfig() JWTConfig {
+	return JWTConfig{
+		SecretKey: "test-secret",
+		Issuer:    "test",
+		Duration:  time.Minute,
+		Sessions:  fakeSessions{},
+	}
+}
+
+func sendToken(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestJWTAuthMiddlewareSessions(t *testing.T) {
+	config := newTestJWTConfig()
+	app := fiber.New()
+	app.Get("/api/v1/products", JWTAuthMiddleware(config), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		name      string
+		sessionID uint
+		status    int
+	}{
+		{"active session", 1, fiber.StatusOK},
+		{"signed out session", 2, fiber.StatusUnauthorized},
+		{"session that cannot be checked", 3, fiber.StatusServiceUnavailable},
+		{"token without a session", 0, fiber.StatusOK},
+	} {
+		token, err := GenerateJWT(JWTClaims{UserID: 5, SessionID: tc.sessionID}, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := sendToken(t, app, token); status != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, status, tc.status)
+		}
+	}
+}
+
+// A 2FA challenge token carries a session but cannot call the API.
+func TestJWTAuthMiddlewareRefusesChallengeTokens(t *testing.T) {
+	config := newTestJWTConfig()
+	app := fiber.New()
+	app.Get("/api/v1/products", JWTAuthMiddleware(config), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	token, err := GenerateJWT(JWTClaims{UserID: 5, SessionID: 1, Purpose: PurposeTwoFactor}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := sendToken(t, app, token); status != fiber.StatusUnauthorized {
+		t.Errorf("challenge token: status %d, want 401", status)
+	}
+}
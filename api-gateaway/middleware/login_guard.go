@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type LoginGuardConfig struct {
+	RedisClient *redis.Client
+	// FreeAttempts is the number of failed logins allowed before delays start.
+	FreeAttempts int
+	// BaseDelay is the wait after the first delayed failure; it doubles with
+	// every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// LoginGuard slows down repeated failed logins for one account at the
+// gateway. The hard lockout itself is enforced by user-service.
+type LoginGuard struct {
+	config LoginGuardConfig
+}
+
+func NewLoginGuard(config LoginGuardConfig) *LoginGuard {
+	if config.FreeAttempts <= 0 {
+		config.FreeAttempts = 3
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = time.Second
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 5 * time.Minute
+	}
+	if config.Window <= 0 {
+		config.Window = 15 * time.Minute
+	}
+	return &LoginGuard{config: config}
+}
+
+func (g *LoginGuard) key(email string) string {
+	return "login_failures:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// delayLua is the wait after count failures, shared by the scripts below.
+const delayLua = `
+local function delay(count, free, base, max)
+	local wait = base * 2 ^ (count - free)
+	if wait > max then
+		return max
+	end
+	return wait
+end
+`
+
+// reserveScript counts a login attempt unless the account is still waiting
+// out its delay, in one step so parallel attempts cannot all pass the check
+// before any of them failed. Every attempt counts as failed until it is
+// released or the account reset.
+//
+// KEYS[1] = key, ARGV = now (ms), free attempts, base delay (ms), max delay
+// (ms), window (ms). Returns the wait in ms, 0 when the attempt was counted.
+var reserveScript = redis.NewScript(delayLua + `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local free = tonumber(ARGV[2])
+local base = tonumber(ARGV[3])
+local max = tonumber(ARGV[4])
+
+local next = tonumber(redis.call('HGET', key, 'next') or '0')
+if now < next then
+	return next - now
+end
+
+local count = redis.call('HINCRBY', key, 'count', 1)
+redis.call('HSET', key, 'last', now)
+if count >= free then
+	redis.call('HSET', key, 'next', math.floor(now + delay(count, free, base, max)))
+end
+redis.call('PEXPIRE', key, ARGV[5])
+return 0
+`)
+
+// releaseScript uncounts an attempt that did not fail and puts the delay
+// back to the one of the failures left, counted from the last attempt. Below
+// the free attempts there is none.
+//
+// KEYS[1] = key, ARGV = free attempts, base delay (ms), max delay (ms).
+var releaseScript = redis.NewScript(delayLua + `
+local key = KEYS[1]
+local free = tonumber(ARGV[1])
+local base = tonumber(ARGV[2])
+local max = tonumber(ARGV[3])
+
+local count = tonumber(redis.call('HGET', key, 'count') or '0')
+if count <= 0 then
+	return 0
+end
+
+count = redis.call('HINCRBY', key, 'count', -1)
+if count < free then
+	redis.call('HDEL', key, 'next')
+else
+	local last = tonumber(redis.call('HGET', key, 'last') or '0')
+	redis.call('HSET', key, 'next', math.floor(last + delay(count, free, base, max)))
+end
+return 0
+`)
+
+// Reserve counts a login attempt for email. A non-zero wait means the
+// attempt is refused and the caller has to wait that long; nothing was
+// counted then. A counted attempt stays a failure unless it is released
+// with Release or the account reset with Reset.
+func (g *LoginGuard) Reserve(ctx context.Context, email string) (time.Duration, error) {
+	if g == nil || g.config.RedisClient == nil {
+		return 0, nil
+	}
+
+	wait, err := reserveScript.Run(ctx, g.config.RedisClient, []string{g.key(email)},
+		time.Now().UnixMilli(),
+		g.config.FreeAttempts,
+		g.config.BaseDelay.Milliseconds(),
+		g.config.MaxDelay.Milliseconds(),
+		g.config.Window.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("reserve login attempt: %w", err)
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Release uncounts an attempt of Reserve that ended without a wrong
+// password or code, e.g. because user-service did not answer.
+func (g *LoginGuard) Release(ctx context.Context, email string) error {
+	if g == nil || g.config.RedisClient == nil {
+		return nil
+	}
+
+	if err := releaseScript.Run(ctx, g.config.RedisClient, []string{g.key(email)},
+		g.config.FreeAttempts,
+		g.config.BaseDelay.Milliseconds(),
+		g.config.MaxDelay.Milliseconds(),
+	).Err(); err != nil {
+		return fmt.Errorf("release login attempt: %w", err)
+	}
+	return nil
+}
+
+func (g *LoginGuard) Reset(ctx context.Context, email string) error {
+	if g == nil || g.config.RedisClient == nil {
+		return nil
+	}
+	return g.config.RedisClient.Del(ctx, g.key(email)).Err()
+}
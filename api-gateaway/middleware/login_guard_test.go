@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const guardEmail = "ana@mail.com"
+
+func newTestLoginGuard(t *testing.T, config LoginGuardConfig) (*LoginGuard, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	config.RedisClient = client
+	return NewLoginGuard(config), server
+}
+
+func reserve(t *testing.T, guard *LoginGuard) time.Duration {
+	t.Helper()
+
+	wait, err := guard.Reserve(context.Background(), guardEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wait
+}
+
+func failures(t *testing.T, server *miniredis.Miniredis) string {
+	t.Helper()
+	return server.HGet("login_failures:"+guardEmail, "count")
+}
+
+func TestLoginGuardDelaysAfterFreeAttempts(t *testing.T) {
+	guard, server := newTestLoginGuard(t, LoginGuardConfig{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	// The third failure starts the delay of BaseDelay.
+	for i := 1; i <= 3; i++ {
+		if wait := reserve(t, guard); wait != 0 {
+			t.Fatalf("attempt %d: wait %v, want none", i, wait)
+		}
+	}
+	if got := failures(t, server); got != "3" {
+		t.Fatalf("%s failures counted, want 3", got)
+	}
+
+	wait := reserve(t, guard)
+	if wait <= 0 || wait > time.Minute {
+		t.Fatalf("attempt during the delay: wait %v, want up to a minute", wait)
+	}
+	// A refused attempt is not counted and does not extend the delay.
+	if got := failures(t, server); got != "3" {
+		t.Errorf("%s failures counted after a refused attempt, want 3", got)
+	}
+	if again := reserve(t, guard); again > wait {
+		t.Errorf("wait grew from %v to %v on a refused attempt", wait, again)
+	}
+}
+
+func TestLoginGuardDelayDoublesUpToMax(t *testing.T) {
+	guard, server := newTestLoginGuard(t, LoginGuardConfig{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute})
+	key := "login_failures:" + guardEmail
+
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		if wait := reserve(t, guard); wait != 0 {
+			t.Fatalf("failure %d: refused with wait %v", i+1, wait)
+		}
+		wait := reserve(t, guard)
+		if wait <= want-time.Second || wait > want {
+			t.Errorf("after failure %d: wait %v, want %v", i+1, wait, want)
+		}
+		// Let the delay pass.
+		server.HSet(key, "next", "0")
+	}
+}
+
+func TestLoginGuardRelease(t *testing.T) {
+	guard, server := newTestLoginGuard(t, LoginGuardConfig{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour})
+	ctx := context.Background()
+
+	reserve(t, guard)
+	reserve(t, guard)
+	if wait := reserve(t, guard); wait == 0 {
+		t.Fatal("no delay after the free attempts")
+	}
+
+	// The second attempt did not fail after all: the account is below the
+	// free attempts again and may try right away.
+	if err := guard.Release(ctx, guardEmail); err != nil {
+		t.Fatal(err)
+	}
+	if got := failures(t, server); got != "1" {
+		t.Errorf("%s failures after a release, want 1", got)
+	}
+	if wait := reserve(t, guard); wait != 0 {
+		t.Fatalf("wait %v after a release, want none", wait)
+	}
+
+	// Releasing above the free attempts shortens the delay to the one of
+	// the failures left.
+	server.HSet("login_failures:"+guardEmail, "next", "0")
+	reserve(t, guard)
+	if got := failures(t, server); got != "3" {
+		t.Fatalf("%s failures, want 3", got)
+	}
+	if wait := reserve(t, guard); wait <= time.Minute {
+		t.Fatalf("wait %v after 3 failures, want two minutes", wait)
+	}
+	if err := guard.Release(ctx, guardEmail); err != nil {
+		t.Fatal(err)
+	}
+	if wait := reserve(t, guard); wait <= 0 || wait > time.Minute {
+		t.Errorf("wait %v after releasing one of 3 failures, want up to a minute", wait)
+	}
+
+	// Releasing more than was reserved does not go below zero.
+	for i := 0; i < 5; i++ {
+		if err := guard.Release(ctx, guardEmail); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := failures(t, server); got != "0" {
+		t.Errorf("%s failures after releasing everything, want 0", got)
+	}
+}
+
+func TestLoginGuardResetAndWindow(t *testing.T) {
+	guard, server := newTestLoginGuard(t, LoginGuardConfig{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: 15 * time.Minute})
+
+	reserve(t, guard)
+	if err := guard.Reset(context.Background(), guardEmail); err != nil {
+		t.Fatal(err)
+	}
+	if wait := reserve(t, guard); wait != 0 {
+		t.Fatalf("wait %v after a reset, want none", wait)
+	}
+
+	// Failures are forgotten a window after the last one.
+	server.FastForward(15 * time.Minute)
+	if server.Exists("login_failures:" + guardEmail) {
+		t.Fatal("failures kept past the window")
+	}
+	if wait := reserve(t, guard); wait != 0 {
+		t.Errorf("wait %v after the window, want none", wait)
+	}
+}
+
+func TestLoginGuardWithoutRedis(t *testing.T) {
+	var guard *LoginGuard
+	if wait, err := guard.Reserve(context.Background(), guardEmail); wait != 0 || err != nil {
+		t.Errorf("nil guard: wait %v, error %v", wait, err)
+	}
+	if err := NewLoginGuard(LoginGuardConfig{}).Release(context.Background(), guardEmail); err != nil {
+		t.Errorf("guard without redis: %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"math/rand"
 	"strconv"
 	"time"
 
@@ -14,8 +17,20 @@ type RedisRateLimiterConfig struct {
 	Expiration  time.Duration
 	KeyPrefix   string
 	RedisClient *redis.Client
-}
 
+	// GlobalMax is the per-IP limit of the global limiter that runs before
+	// authentication. Zero falls back to Max.
+	GlobalMax int
+	// RoleQuotas overrides Max for authenticated callers holding one of the
+	// roles. When a caller has several roles the highest quota wins.
+	RoleQuotas map[string]int
+	// FailOpen lets requests through when Redis is unavailable instead of
+	// rejecting them.
+	FailOpen bool
+	// APIKeyMax is the limit for requests made with an API key, counted per
+	// key. Zero falls back to the user limits.
+	APIKeyMax int
+}
 
 func DefaultRateLimiterConfig() RedisRateLimiterConfig {
 	return RedisRateLimiterConfig{
@@ -25,153 +40,179 @@ func DefaultRateLimiterConfig() RedisRateLimiterConfig {
 		RedisClient: nil,
 	}
 }
-func RedisRateLimiter(config RedisRateLimiterConfig) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		ctx := c.Context()
 
-		key := fmt.Sprintf("%s:%s", config.KeyPrefix, c.IP())
+// slidingWindowScript keeps one sorted-set entry per request inside the
+// window, so the check and the increment happen atomically in Redis.
+//
+// KEYS[1] = key, ARGV = now (ms), window (ms), limit, unique member.
+// Returns {allowed, count, reset (ms)}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+type rateLimitResult struct {
+	allowed bool
+	limit   int
+	count   int
+	reset   time.Duration
+}
 
-		current, err := config.RedisClient.Get(ctx, key).Result()
-		if err != nil && err != redis.Nil{
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to get rate limit",
-			})
-		}
+func allow(c *fiber.Ctx, config RedisRateLimiterConfig, key string, limit int) (rateLimitResult, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int63())
 
-		if err == redis.Nil {
-			err = config.RedisClient.Set(ctx, key, "1", config.Expiration).Err()
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error" : "Internal Server Error",
-					"message" : "Failed to set rate limit",
-				})
-			}
+	values, err := slidingWindowScript.Run(c.Context(), config.RedisClient, []string{key},
+		now.UnixMilli(), config.Expiration.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+
+	return rateLimitResult{
+		allowed: values[0] == 1,
+		limit:   limit,
+		count:   int(values[1]),
+		reset:   time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// rateLimit is the shared limiter body. keyFunc builds the Redis key for the
+// request and limitFunc the number of requests allowed per window.
+func rateLimit(config RedisRateLimiterConfig, keyFunc func(c *fiber.Ctx) string, limitFunc func(c *fiber.Ctx) int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.RedisClient == nil {
 			return c.Next()
 		}
 
-		count, err := strconv.Atoi(current)
+		key := keyFunc(c)
+		result, err := allow(c, config, key, limitFunc(c))
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
+			log.Printf("Rate limiter error for key %s: %v", key, err)
+			if config.FailOpen {
+				return c.Next()
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error" : "Internal Server Error",
-				"message" : "Failed to parse rate limit count",
+				"message" : "Failed to check rate limit",
 			})
 		}
 
-		if count >= config.Max {
+		resetSeconds := int(math.Ceil(result.reset.Seconds()))
+		remaining := result.limit - result.count
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.limit, int(config.Expiration.Seconds())))
+
+		if !result.allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resetSeconds))
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error" : "Too Many Requests",
 				"message" : "Rate limit exceeded. Please try again later.",
-				"retry_after" : config.Expiration.Seconds(),
-				"limit" : config.Max,
-				"current" : count,
+				"retry_after" : resetSeconds,
+				"limit" : result.limit,
 			})
 		}
 
-		err = config.RedisClient.Incr(ctx, key).Err()
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to increment rate limit",
-			})
-		}
+		return c.Next()
+	}
+}
 
-		if count == 0 {
-			err = config.RedisClient.Expire(ctx, key, config.Expiration).Err()
-			if err != nil {
-				fmt.Printf("Failed to set expiration for key %s: %v\n", key, err)
-			}
-		}
+// clientKey identifies the caller by user ID once authenticated and by IP
+// otherwise, so users behind one NAT do not share a limit.
+func clientKey(c *fiber.Ctx) string {
+	if apiKeyID, ok := c.Locals("api_key_id").(uint); ok && apiKeyID != 0 {
+		return fmt.Sprintf("apikey:%d", apiKeyID)
+	}
+	if userID, ok := c.Locals("user_id").(uint); ok && userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + c.IP()
+}
 
-		c.Set("X-RateLimit-Limit", strconv.Itoa(config.Max))
-		c.Set("X-RateLimit-Limit", strconv.Itoa(config.Max-count-1))
-		c.Set("X-RateLimit-Limit", strconv.FormatInt(time.Now().Add(config.Expiration).Unix(), 10))
+// quotaFor returns the limit for the caller, taking role quotas into account.
+func quotaFor(c *fiber.Ctx, config RedisRateLimiterConfig) int {
+	limit := config.Max
+	if _, ok := c.Locals("api_key_id").(uint); ok && config.APIKeyMax > 0 {
+		return config.APIKeyMax
+	}
+	if len(config.RoleQuotas) == 0 {
+		return limit
+	}
 
-		return c.Next()
+	roles, _ := UserRoles(c)
+	best := 0
+	for _, role := range roles {
+		if quota, ok := config.RoleQuotas[role]; ok && quota > best {
+			best = quota
+		}
+	}
+	if best > 0 {
+		return best
 	}
+	return limit
+}
+
+func RedisRateLimiter(config RedisRateLimiterConfig) fiber.Handler {
+	return rateLimit(config, func(c *fiber.Ctx) string {
+		return fmt.Sprintf("%s:%s", config.KeyPrefix, clientKey(c))
+	}, func(c *fiber.Ctx) int {
+		return quotaFor(c, config)
+	})
 }
 
+// RedisGlobalRateLimiter runs before authentication and is keyed by IP only.
 func RedisGlobalRateLimiter(config RedisRateLimiterConfig) fiber.Handler {
 	config.KeyPrefix = "global"
+	if config.GlobalMax > 0 {
+		config.Max = config.GlobalMax
+	}
+	config.RoleQuotas = nil
 	return RedisRateLimiter(config)
 }
 
 func RedisAuthRateLimiter(config RedisRateLimiterConfig) fiber.Handler {
 	config.KeyPrefix = "auth"
+	config.RoleQuotas = nil
 	return RedisRateLimiter(config)
 }
 
 func RedisAPIRateLimiter(config RedisRateLimiterConfig) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		ctx := c.Context()
-
-		userID := c.Locals("user_id")
-		var key string
-		if userID != nil {
-			key = fmt.Sprintf("api:%s:%d", c.IP(), userID.(uint))
-		} else {
-			key = fmt.Sprintf("api:%s", c.IP())
-		}
-
-		current, err := config.RedisClient.Get(ctx, key).Result()
-		if err != nil && err != redis.Nil{
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to get rate limit",
-			})
-		}
-
-		if err == redis.Nil {
-			err = config.RedisClient.Set(ctx, key, "1", config.Expiration).Err()
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error" : "Internal Server Error",
-					"message" : "Failed to set rate limit",
-				})
-			}
-			return c.Next()
-		}
-
-		count, err := strconv.Atoi(current)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to parse rate limit count",
-			})
-		}
-
-		if count >= config.Max {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error" : "Too Many Requests",
-				"message" : "Rate limit exceeded. Please try again later.",
-				"retry_after" : config.Expiration.Seconds(),
-				"limit" : config.Max,
-				"current" : count,
-			})
-		}
-
-		err = config.RedisClient.Incr(ctx, key).Err()
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error" : "Internal Server Error",
-				"message" : "Failed to increment rate limit",
-			})
-		}
-
-		if count == 0 {
-			err = config.RedisClient.Expire(ctx, key, config.Expiration).Err()
-			if err != nil {
-				fmt.Printf("Failed to set expiration for key %s: %v\n", key, err)
-			}
-		}
-
-		c.Set("X-RateLimit-Limit", strconv.Itoa(config.Max))
-		c.Set("X-RateLimit-Limit", strconv.Itoa(config.Max-count-1))
-		c.Set("X-RateLimit-Limit", strconv.FormatInt(time.Now().Add(config.Expiration).Unix(), 10))
-
-		return c.Next()
-
-	}
+	config.KeyPrefix = "api"
+	return RedisRateLimiter(config)
 }
 
-
+// RedisRouteRateLimiter applies a separate quota of max requests per window
+// to one route. It is counted independently of the API tier limit.
+func RedisRouteRateLimiter(config RedisRateLimiterConfig, route string, max int) fiber.Handler {
+	return rateLimit(config, func(c *fiber.Ctx) string {
+		return fmt.Sprintf("route:%s:%s", route, clientKey(c))
+	}, func(c *fiber.Ctx) int {
+		return max
+	})
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newLimitedApp serves GET / behind limiter. The X-Test-User and
+// X-Test-Roles headers stand in for what the JWT middleware would set.
+func newLimitedApp(limiter fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if userID := c.Get("X-Test-User"); userID != "" {
+			id, _ := strconv.Atoi(userID)
+			c.Locals("user_id", uint(id))
+			c.Locals("user_roles", c.Get("X-Test-Roles"))
+		}
+		return c.Next()
+	}, limiter, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func newTestLimiterConfig(t *testing.T, max int, window time.Duration) (RedisRateLimiterConfig, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	// No retries, so the test with Redis down fails fast.
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1, DialerRetries: 1})
+	t.Cleanup(func() { client.Close() })
+
+	return RedisRateLimiterConfig{
+		Max:         max,
+		Expiration:  window,
+		KeyPrefix:   "test",
+		RedisClient: client,
+	}, server
+}
+
+func send(t *testing.T, app *fiber.App, user, roles string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+		req.Header.Set("X-Test-Roles", roles)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestRedisRateLimiterRejectsOverLimit(t *testing.T) {
+	config, _ := newTestLimiterConfig(t, 3, time.Minute)
+	app := newLimitedApp(RedisRateLimiter(config))
+
+	for i := 1; i <= 3; i++ {
+		resp := send(t, app, "", "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i, resp.StatusCode)
+		}
+		if got, want := resp.Header.Get("RateLimit-Remaining"), strconv.Itoa(3-i); got != want {
+			t.Errorf("request %d: RateLimit-Remaining %s, want %s", i, got, want)
+		}
+	}
+
+	resp := send(t, app, "", "")
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("request 4: status %d, want 429", resp.StatusCode)
+	}
+	retryAfter, err := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After %q, want 1 to 60 seconds", resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+	if got := resp.Header.Get("RateLimit-Policy"); got != "3;w=60" {
+		t.Errorf("RateLimit-Policy %q, want 3;w=60", got)
+	}
+}
+
+// The window slides: a request frees up once it is a full window old, not
+// when a fixed window starts over, and the requests after it still count.
+func TestRedisRateLimiterSlidingWindow(t *testing.T) {
+	const window = 400 * time.Millisecond
+	config, _ := newTestLimiterConfig(t, 2, window)
+	app := newLimitedApp(RedisRateLimiter(config))
+
+	start := time.Now()
+	if resp := send(t, app, "", ""); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("first request: status %d", resp.StatusCode)
+	}
+	time.Sleep(window / 2)
+	if resp := send(t, app, "", ""); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("second request: status %d", resp.StatusCode)
+	}
+	if resp := send(t, app, "", ""); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", resp.StatusCode)
+	}
+
+	// The first request leaves the window, the second is still in it.
+	time.Sleep(time.Until(start.Add(window + 50*time.Millisecond)))
+	if resp := send(t, app, "", ""); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("request after the first expired: status %d, want 200", resp.StatusCode)
+	}
+	if resp := send(t, app, "", ""); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("request while the second is in the window: status %d, want 429", resp.StatusCode)
+	}
+}
+
+// A refused request is not recorded, so hammering the limit does not push
+// the reset further out.
+func TestRedisRateLimiterDoesNotCountRejected(t *testing.T) {
+	config, server := newTestLimiterConfig(t, 2, time.Minute)
+	app := newLimitedApp(RedisRateLimiter(config))
+
+	for i := 0; i < 5; i++ {
+		send(t, app, "", "")
+	}
+
+	members, err := server.ZMembers("test:ip:0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Errorf("%d requests recorded, want 2", len(members))
+	}
+	if ttl := server.TTL("test:ip:0.0.0.0"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("key TTL %v, want up to the window", ttl)
+	}
+}
+
+func TestRedisRateLimiterCountsCallersSeparately(t *testing.T) {
+	config, _ := newTestLimiterConfig(t, 1, time.Minute)
+	config.RoleQuotas = map[string]int{"Manager": 3}
+	app := newLimitedApp(RedisRateLimiter(config))
+
+	if resp := send(t, app, "1", "Keeper"); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("user 1: status %d", resp.StatusCode)
+	}
+	if resp := send(t, app, "1", "Keeper"); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("user 1 again: status %d, want 429", resp.StatusCode)
+	}
+	if resp := send(t, app, "2", "Keeper"); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("user 2: status %d, want its own limit", resp.StatusCode)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if resp := send(t, app, "3", "Keeper,Manager"); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("manager request %d: status %d, want the role quota", i, resp.StatusCode)
+		}
+	}
+	if resp := send(t, app, "3", "Keeper,Manager"); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("manager request 4: status %d, want 429", resp.StatusCode)
+	}
+}
+
+func TestRedisRateLimiterRedisDown(t *testing.T) {
+	config, server := newTestLimiterConfig(t, 1, time.Minute)
+	server.Close()
+
+	if resp := send(t, newLimitedApp(RedisRateLimiter(config)), "", ""); resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("fail closed: status %d, want 500", resp.StatusCode)
+	}
+
+	config.FailOpen = true
+	if resp := send(t, newLimitedApp(RedisRateLimiter(config)), "", ""); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("fail open: status %d, want 200", resp.StatusCode)
+	}
+}
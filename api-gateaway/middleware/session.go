@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+)
+
+// SessionIDHeader tells upstream services which session the caller's token
+// belongs to.
+const SessionIDHeader = "X-Session-ID"
+
+var ErrInvalidSession = errors.New("invalid session")
+
+// SessionVerifier checks that the session of a token is still active. It
+// returns ErrInvalidSession once the session is signed out or expired.
+type SessionVerifier interface {
+	VerifySession(ctx context.Context, sessionID, userID uint, ip string) error
+}
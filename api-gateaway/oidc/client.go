@@ -0,0 +1,466 @@
+// Package oidc signs staff in through the company identity provider with
+// the OpenID Connect authorization code flow and PKCE. The gateway is the
+// relying party: it sends the browser to the provider, exchanges the code
+// and checks the ID token, then issues its own JWT like a password login.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken covers every reason to refuse an ID token or a code.
+var ErrInvalidToken = errors.New("invalid oidc token")
+
+type Config struct {
+	// Issuer is the provider's issuer URL; its discovery document is read
+	// from Issuer/.well-known/openid-configuration. SSO is off when empty.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is registered at the provider. It may be the gateway's
+	// callback or a frontend page that posts code and state to it.
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim names the ID token claim holding the user's groups.
+	GroupsClaim string
+	// GroupRoles maps provider groups to role names of user-service. Roles
+	// of SSO users follow their groups on every login.
+	GroupRoles map[string]string
+	// DefaultRole is given to SSO users none of whose groups is mapped.
+	DefaultRole string
+	// AutoProvision creates unknown users on their first login. Without it
+	// only users that already exist, matched by email, can sign in.
+	AutoProvision bool
+	// MFAMethods are the amr values and MFALevels the acr values that show
+	// the provider checked a second factor. TrustMFA takes every login at
+	// the provider as such, for providers that enforce MFA but do not say.
+	// Otherwise the user's own 2FA applies.
+	MFAMethods []string
+	MFALevels  []string
+	TrustMFA   bool
+	// StateTTL is how long a started login may take at the provider.
+	StateTTL time.Duration
+	Timeout  time.Duration
+	// KeysTTL is how long the provider's signing keys are reused. Unknown
+	// key ids trigger a refresh regardless, for key rotation.
+	KeysTTL time.Duration
+}
+
+func (c Config) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != ""
+}
+
+// IDToken holds the claims of a verified ID token that login needs.
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	// Methods and Level are the amr and acr claims.
+	Methods []string
+	Level   string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client talks to one provider. The discovery document is loaded on first
+// use and retried until it succeeds, so the gateway starts while the
+// provider is down.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu            sync.Mutex
+	discovery     *discovery
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewClient(config Config) *Client {
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.StateTTL <= 0 {
+		config.StateTTL = 10 * time.Minute
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.KeysTTL <= 0 {
+		config.KeysTTL = time.Hour
+	}
+
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// AuthorizationURL is where the browser goes to sign in. challenge is the
+// S256 PKCE challenge of the verifier kept for Exchange.
+func (c *Client) AuthorizationURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	d, err := c.loadDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.config.ClientID)
+	query.Set("redirect_uri", c.config.RedirectURL)
+	query.Set("scope", strings.Join(c.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades an authorization code for the raw ID token.
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	d, err := c.loadDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", c.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// Public clients rely on PKCE alone and send no secret.
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	// A used, expired or foreign code is answered with 400 invalid_grant.
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("%w: token endpoint answered %d: %s", ErrInvalidToken, resp.StatusCode, body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint answered %d", resp.StatusCode)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return "", err
+	}
+	if tokens.IDToken == "" {
+		return "", fmt.Errorf("%w: no id_token in the token response", ErrInvalidToken)
+	}
+
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken checks signature, issuer, audience, expiry and nonce of an
+// ID token and returns its claims.
+func (c *Client) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	d, err := c.loadDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.key(ctx, d, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(c.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidToken)
+	}
+	// With more than one audience the token must have been issued to us.
+	if audiences, _ := claims.GetAudience(); len(audiences) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.config.ClientID {
+			return nil, fmt.Errorf("%w: token was issued to %q", ErrInvalidToken, azp)
+		}
+	}
+
+	idToken := &IDToken{Issuer: d.Issuer}
+	idToken.Subject, _ = claims.GetSubject()
+	idToken.Email, _ = claims["email"].(string)
+	idToken.Name, _ = claims["name"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		idToken.EmailVerified = verified
+	case string:
+		idToken.EmailVerified = verified == "true"
+	}
+	idToken.Groups = stringList(claims[c.config.GroupsClaim])
+	idToken.Methods = stringList(claims["amr"])
+	idToken.Level, _ = claims["acr"].(string)
+
+	if idToken.Subject == "" {
+		return nil, fmt.Errorf("%w: sub is missing", ErrInvalidToken)
+	}
+	return idToken, nil
+}
+
+// RoleNames maps the groups of a user to role names, the default role when
+// none is mapped.
+func (c *Client) RoleNames(groups []string) []string {
+	roles := []string{}
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		role, ok := c.config.GroupRoles[group]
+		if ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 && c.config.DefaultRole != "" {
+		roles = append(roles, c.config.DefaultRole)
+	}
+	return roles
+}
+
+// MultiFactor reports whether the provider checked a second factor for the
+// login of idToken.
+func (c *Client) MultiFactor(idToken *IDToken) bool {
+	if c.config.TrustMFA {
+		return true
+	}
+	for _, method := range idToken.Methods {
+		if contains(c.config.MFAMethods, method) {
+			return true
+		}
+	}
+	return idToken.Level != "" && contains(c.config.MFALevels, idToken.Level)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) loadDiscovery(ctx context.Context) (*discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	var d discovery
+	if err := c.getJSON(ctx, c.config.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if d.Issuer != c.config.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match the configured %q", d.Issuer, c.config.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: endpoints are missing")
+	}
+
+	c.discovery = &d
+	return c.discovery, nil
+}
+
+// key returns the public key kid, reading the key set again when the key is
+// unknown or the cached set is old.
+func (c *Client) key(ctx context.Context, d *discovery, kid string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok && time.Since(c.keysFetchedAt) < c.config.KeysTTL {
+		return key, nil
+	}
+	// An unknown kid must not make every bad token refetch the keys.
+	if c.keys != nil && time.Since(c.keysFetchedAt) < 10*time.Second {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	c.keys = keys
+	c.keysFetchedAt = time.Now()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// Providers with a single key may leave kid out of the token.
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (c *Client) getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// stringList reads a claim that is a list of strings, or a single string.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,125 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnknownState is returned for a state that was never issued, has
+// expired or has been used already.
+var ErrUnknownState = errors.New("unknown or expired oidc state")
+
+// PendingLogin is what the gateway remembers between sending the browser to
+// the provider and the callback.
+type PendingLogin struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// StateStore keeps pending logins by state. Take returns a login once, so a
+// callback cannot be replayed.
+type StateStore interface {
+	Save(ctx context.Context, state string, login PendingLogin, ttl time.Duration) error
+	Take(ctx context.Context, state string) (*PendingLogin, error)
+}
+
+// NewStateStore keeps pending logins in Redis, shared by every gateway
+// instance, or in memory when Redis is not configured.
+func NewStateStore(client *redis.Client) StateStore {
+	if client == nil {
+		return &memoryStateStore{logins: make(map[string]memoryLogin)}
+	}
+	return &redisStateStore{client: client}
+}
+
+type redisStateStore struct {
+	client *redis.Client
+}
+
+func stateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+func (s *redisStateStore) Save(ctx context.Context, state string, login PendingLogin, ttl time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, stateKey(state), data, ttl).Err()
+}
+
+func (s *redisStateStore) Take(ctx context.Context, state string) (*PendingLogin, error) {
+	data, err := s.client.GetDel(ctx, stateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrUnknownState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var login PendingLogin
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
+
+type memoryLogin struct {
+	login   PendingLogin
+	expires time.Time
+}
+
+type memoryStateStore struct {
+	mu     sync.Mutex
+	logins map[string]memoryLogin
+}
+
+func (s *memoryStateStore) Save(ctx context.Context, state string, login PendingLogin, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, pending := range s.logins {
+		if now.After(pending.expires) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = memoryLogin{login: login, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStateStore) Take(ctx context.Context, state string) (*PendingLogin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.logins[state]
+	delete(s.logins, state)
+	if !ok || time.Now().After(pending.expires) {
+		return nil, ErrUnknownState
+	}
+	return &pending.login, nil
+}
+
+// RandomString returns a URL safe random value for state, nonce and the
+// PKCE verifier.
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Challenge is the S256 PKCE challenge of verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,206 @@
+package proxy
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type CircuitState string
+
+const (
+	StateClosed   CircuitState = "closed"
+	StateOpen     CircuitState = "open"
+	StateHalfOpen CircuitState = "half-open"
+)
+
+type BreakerConfig struct {
+	FailureThreshold    int
+	OpenTimeout         time.Duration
+	HalfOpenMaxRequests int
+}
+
+type BreakerStatus struct {
+	Name                string       `json:"name"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	TotalFailures       int64        `json:"total_failures"`
+	TotalSuccesses      int64        `json:"total_successes"`
+	TotalRejected       int64        `json:"total_rejected"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"`
+}
+
+// CircuitBreaker tracks the health of one upstream service. It opens after
+// FailureThreshold consecutive failures, rejects calls until OpenTimeout has
+// elapsed and then lets a limited number of trial calls through (half-open).
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	halfOpenInFlight    int
+	openedAt            time.Time
+	totalFailures       int64
+	totalSuccesses      int64
+	totalRejected       int64
+}
+
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+
+	return &CircuitBreaker{
+		name:   name,
+		config: config,
+		state:  StateClosed,
+	}
+}
+
+// Allow reports whether a call may be sent upstream. Every allowed call must
+// be followed by exactly one RecordSuccess or RecordFailure.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case StateOpen:
+		if time.Since(cb.openedAt) < cb.config.OpenTimeout {
+			cb.totalRejected++
+			return false
+		}
+		cb.state = StateHalfOpen
+		cb.halfOpenInFlight = 0
+		fallthrough
+	case StateHalfOpen:
+		if cb.halfOpenInFlight >= cb.config.HalfOpenMaxRequests {
+			cb.totalRejected++
+			return false
+		}
+		cb.halfOpenInFlight++
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.totalSuccesses++
+	cb.consecutiveFailures = 0
+	if cb.state == StateHalfOpen {
+		cb.state = StateClosed
+		cb.halfOpenInFlight = 0
+	}
+}
+
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.totalFailures++
+	cb.consecutiveFailures++
+
+	if cb.state == StateHalfOpen || cb.consecutiveFailures >= cb.config.FailureThreshold {
+		cb.state = StateOpen
+		cb.openedAt = time.Now()
+		cb.halfOpenInFlight = 0
+	}
+}
+
+// RetryAfter returns how long callers should wait before the breaker lets a
+// trial request through again.
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != StateOpen {
+		return 0
+	}
+	remaining := cb.config.OpenTimeout - time.Since(cb.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (cb *CircuitBreaker) Status() BreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := BreakerStatus{
+		Name:                cb.name,
+		State:               cb.state,
+		ConsecutiveFailures: cb.consecutiveFailures,
+		TotalFailures:       cb.totalFailures,
+		TotalSuccesses:      cb.totalSuccesses,
+		TotalRejected:       cb.totalRejected,
+	}
+
+	if cb.state != StateClosed {
+		openedAt := cb.openedAt
+		retryAt := openedAt.Add(cb.config.OpenTimeout)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
+
+// BreakerRegistry lazily creates one breaker per upstream name.
+type BreakerRegistry struct {
+	config BreakerConfig
+
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+func NewBreakerRegistry(config BreakerConfig) *BreakerRegistry {
+	return &BreakerRegistry{
+		config:   config,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+func (r *BreakerRegistry) Get(name string) *CircuitBreaker {
+	r.mu.RLock()
+	cb, ok := r.breakers[name]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+	cb = NewCircuitBreaker(name, r.config)
+	r.breakers[name] = cb
+	return cb
+}
+
+func (r *BreakerRegistry) Statuses() []BreakerStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]BreakerStatus, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		statuses = append(statuses, cb.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// UpstreamsFile is the on-disk format of the upstream list, e.g.
+//
+//	{
+//	  "upstreams": {
+//	    "merchant-service": {
+//	      "strategy": "least-connections",
+//	      "instances": ["http://merchant-1:8084", "http://merchant-2:8084"]
+//	    }
+//	  }
+//	}
+type UpstreamsFile struct {
+	Upstreams map[string]UpstreamConfig `json:"upstreams"`
+}
+
+func LoadUpstreamsFile(path string) (map[string]UpstreamConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file UpstreamsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for name, config := range file.Upstreams {
+		if err := config.Validate(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return file.Upstreams, nil
+}
+
+// WatchUpstreamsFile polls path for modifications and applies the new
+// upstream list without restarting the gateway. An invalid file is logged and
+// ignored, keeping the last good configuration.
+func WatchUpstreamsFile(ctx context.Context, path string, interval time.Duration, upstreams *Upstreams) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var lastModified time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || !info.ModTime().After(lastModified) {
+					continue
+				}
+				lastModified = info.ModTime()
+
+				configs, err := LoadUpstreamsFile(path)
+				if err != nil {
+					log.Printf("Error reloading upstreams file: %v", err)
+					continue
+				}
+				if err := upstreams.Update(configs); err != nil {
+					log.Printf("Error applying upstreams file: %v", err)
+					continue
+				}
+				log.Printf("Reloaded upstreams from %s", path)
+			}
+		}
+	}()
+}
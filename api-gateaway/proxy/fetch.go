@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnknownUpstream = errors.New("unknown upstream")
+	ErrCircuitOpen     = errors.New("circuit breaker is open")
+)
+
+// Response is an upstream answer to Send.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fetch performs a GET on path of the named upstream for requests the gateway
+// makes on its own behalf, e.g. to compose several upstream responses. It
+// goes through the same instance pool, circuit breaker and retry policy as
+// Forward. Any answer from the upstream is returned with its status code;
+// an error means no answer was received.
+func (p *Proxy) Fetch(ctx context.Context, name, path string, header http.Header, timeout time.Duration) (int, []byte, error) {
+	resp, err := p.Send(ctx, name, http.MethodGet, path, header, nil, timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, resp.Body, nil
+}
+
+// Send is Fetch for any method, with body as the request body. As in
+// Forward, only idempotent methods are retried.
+func (p *Proxy) Send(ctx context.Context, name, method, path string, header http.Header, body []byte, timeout time.Duration) (*Response, error) {
+	if timeout <= 0 {
+		timeout = p.timeout
+	}
+
+	pool, ok := p.upstreams.Pool(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownUpstream, name)
+	}
+	breaker := p.breakers.Get(name)
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts = p.retry.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, p.retry.backoff(attempt-1)); err != nil {
+				return nil, err
+			}
+		}
+
+		if !breaker.Allow() {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, name)
+		}
+
+		resp, err := p.sendOnce(ctx, pool, method, path, header, body, timeout)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			breaker.RecordSuccess()
+			return resp, nil
+		}
+
+		breaker.RecordFailure()
+		if err == nil {
+			if attempt == attempts {
+				return resp, nil
+			}
+			err = fmt.Errorf("%w: %d", errUpstreamUnhealthy, resp.StatusCode)
+		}
+		if errors.Is(err, ErrNoHealthyInstance) {
+			return nil, err
+		}
+		lastErr = err
+		log.Printf("Error sending %s %s to %s (attempt %d/%d): %v", method, path, name, attempt, attempts, err)
+	}
+
+	return nil, lastErr
+}
+
+func (p *Proxy) sendOnce(ctx context.Context, pool *Pool, method, path string, header http.Header, body []byte, timeout time.Duration) (*Response, error) {
+	instance, err := pool.Pick()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Release(instance)
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(attemptCtx, method, instance.URL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
+	req.Header.Set("X-Internal-Request", "true")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type HealthCheckConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	Path     string
+}
+
+// HealthChecker periodically calls every instance's health endpoint and marks
+// instances that fail as unhealthy so the pools stop routing to them.
+type HealthChecker struct {
+	upstreams *Upstreams
+	config    HealthCheckConfig
+	client    *http.Client
+}
+
+func NewHealthChecker(upstreams *Upstreams, config HealthCheckConfig) *HealthChecker {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+	if config.Path == "" {
+		config.Path = "/health"
+	}
+
+	return &HealthChecker{
+		upstreams: upstreams,
+		config:    config,
+		client:    &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Start runs the checks until ctx is cancelled.
+func (h *HealthChecker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(h.config.Interval)
+		defer ticker.Stop()
+
+		h.checkAll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.checkAll(ctx)
+			}
+		}
+	}()
+}
+
+func (h *HealthChecker) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, pool := range h.upstreams.Pools() {
+		for _, instance := range pool.instances {
+			wg.Add(1)
+			go func(name string, instance *Instance) {
+				defer wg.Done()
+				healthy := h.check(ctx, instance)
+				if healthy != instance.Healthy() {
+					log.Printf("Upstream %s instance %s is now healthy=%t", name, instance.URL, healthy)
+				}
+				instance.setHealthy(healthy)
+			}(pool.name, instance)
+		}
+	}
+	wg.Wait()
+}
+
+func (h *HealthChecker) check(ctx context.Context, instance *Instance) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, instance.URL+h.config.Path, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
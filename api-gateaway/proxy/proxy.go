@@ -0,0 +1,266 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Config struct {
+	Timeout time.Duration
+	Retry   RetryConfig
+	Breaker BreakerConfig
+}
+
+// Proxy forwards gateway requests to upstream services, balancing across
+// their instances, guarding every upstream with its own circuit breaker and
+// retrying idempotent requests.
+type Proxy struct {
+	client    *http.Client
+	timeout   time.Duration
+	retry     RetryConfig
+	breakers  *BreakerRegistry
+	upstreams *Upstreams
+}
+
+var errUpstreamUnhealthy = errors.New("upstream returned an unhealthy status")
+
+func NewProxy(config Config, upstreams *Upstreams) *Proxy {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Retry.MaxAttempts <= 0 {
+		config.Retry.MaxAttempts = 1
+	}
+
+	return &Proxy{
+		client:    &http.Client{},
+		timeout:   config.Timeout,
+		retry:     config.Retry,
+		breakers:  NewBreakerRegistry(config.Breaker),
+		upstreams: upstreams,
+	}
+}
+
+// Register creates the breaker for an upstream up front so it shows up in
+// the status endpoint before the first request hits it.
+func (p *Proxy) Register(name string) {
+	p.breakers.Get(name)
+}
+
+func (p *Proxy) Breakers() []BreakerStatus {
+	return p.breakers.Statuses()
+}
+
+func (p *Proxy) Upstreams() []PoolStatus {
+	return p.upstreams.Statuses()
+}
+
+// Forward sends the current request to path (including any query string) on
+// an instance of the named upstream and writes the upstream response back to
+// the client. prepare, if not nil, is called on every outgoing request to add
+// extra headers.
+func (p *Proxy) Forward(c *fiber.Ctx, name, path string, prepare func(req *http.Request)) error {
+	return p.ForwardWithTimeout(c, name, path, 0, prepare)
+}
+
+// ForwardWithTimeout is Forward with a per-attempt upstream timeout. A zero
+// timeout uses the proxy default.
+func (p *Proxy) ForwardWithTimeout(c *fiber.Ctx, name, path string, timeout time.Duration, prepare func(req *http.Request)) error {
+	if timeout <= 0 {
+		timeout = p.timeout
+	}
+
+	pool, ok := p.upstreams.Pool(name)
+	if !ok {
+		log.Printf("Unknown upstream: %s", name)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   "Bad Gateaway",
+			"message": "Service Unavailable",
+			"service": name,
+		})
+	}
+
+	breaker := p.breakers.Get(name)
+	method := c.Method()
+	body := c.Body()
+	// The fasthttp request context is done once the server shuts down, which
+	// cuts retries short. c.UserContext() is Background unless set.
+	ctx := c.Context()
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts = p.retry.MaxAttempts
+	}
+
+	var (
+		resp    *http.Response
+		lastErr error
+		cancels []context.CancelFunc
+	)
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, p.retry.backoff(attempt-1)); err != nil {
+				lastErr = err
+				break
+			}
+		}
+
+		instance, err := pool.Pick()
+		if err != nil {
+			log.Printf("Error picking instance: %v", err)
+			return noInstance(c, name)
+		}
+		fullURL := instance.URL + path
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		cancels = append(cancels, cancel)
+
+		req, err := http.NewRequestWithContext(attemptCtx, method, fullURL, bytes.NewReader(body))
+		if err != nil {
+			pool.Release(instance)
+			log.Printf("Error creating request: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Internal Server Error",
+				"message": "Failed to create request",
+			})
+		}
+
+		for key, values := range c.GetReqHeaders() {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		req.Header.Set("X-Gateaway", "warehouse-api-gateaway")
+		req.Header.Set("X-Internal-Request", "true")
+		// Audit events in the services record both; X-Real-IP replaces any
+		// value the client sent.
+		req.Header.Set(fiber.HeaderXRequestID, c.GetRespHeader(fiber.HeaderXRequestID))
+		req.Header.Set("X-Real-IP", c.IP())
+		if prepare != nil {
+			prepare(req)
+		}
+
+		if !breaker.Allow() {
+			pool.Release(instance)
+			return circuitOpen(c, name, breaker.RetryAfter())
+		}
+
+		resp, err = p.client.Do(req)
+		pool.Release(instance)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			breaker.RecordSuccess()
+			lastErr = nil
+			break
+		}
+
+		breaker.RecordFailure()
+		if err == nil {
+			err = fmt.Errorf("%w: %d", errUpstreamUnhealthy, resp.StatusCode)
+			if attempt < attempts {
+				resp.Body.Close()
+				resp = nil
+			}
+		}
+		lastErr = err
+		log.Printf("Error making request to %s (%s, attempt %d/%d): %v", fullURL, name, attempt, attempts, err)
+	}
+
+	// The last attempt got an answer from the upstream, even if it was a 5xx:
+	// pass it through so the client sees the real error body.
+	if resp == nil {
+		if lastErr != nil && isTimeout(lastErr) {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(fiber.Map{
+				"error":   "Gateway Timeout",
+				"message": "Service did not respond in time",
+				"service": name,
+			})
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   "Bad Gateaway",
+			"message": "Service Unavailable",
+			"service": name,
+		})
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading response body: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Internal Server Error",
+			"message": "Failed to read response",
+		})
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			c.Set(key, value)
+		}
+	}
+
+	return c.Status(resp.StatusCode).Send(respBody)
+}
+
+// circuitOpen is the fallback response served while an upstream's breaker is
+// open, so clients fail fast instead of waiting on a dead service.
+func circuitOpen(c *fiber.Ctx, name string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error":       "Service Unavailable",
+		"message":     "Service is temporarily unavailable. Please try again later.",
+		"service":     name,
+		"retry_after": seconds,
+	})
+}
+
+func noInstance(c *fiber.Ctx, name string) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error":   "Service Unavailable",
+		"message": "No healthy instance of the service is available",
+		"service": name,
+	})
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Strategy string
+
+const (
+	RoundRobin       Strategy = "round-robin"
+	LeastConnections Strategy = "least-connections"
+)
+
+var ErrNoHealthyInstance = errors.New("no healthy upstream instance")
+
+type UpstreamConfig struct {
+	Strategy  Strategy `json:"strategy"`
+	Instances []string `json:"instances"`
+}
+
+func (u UpstreamConfig) Validate(name string) error {
+	if len(u.Instances) == 0 {
+		return fmt.Errorf("upstream %s: at least one instance is required", name)
+	}
+	switch u.Strategy {
+	case "", RoundRobin, LeastConnections:
+	default:
+		return fmt.Errorf("upstream %s: unknown strategy %q", name, u.Strategy)
+	}
+	for _, instance := range u.Instances {
+		if !strings.HasPrefix(instance, "http://") && !strings.HasPrefix(instance, "https://") {
+			return fmt.Errorf("upstream %s: instance %q must be an http(s) URL", name, instance)
+		}
+	}
+	return nil
+}
+
+// Instance is one replica of an upstream service.
+type Instance struct {
+	URL string
+
+	healthy     atomic.Bool
+	active      atomic.Int64
+	lastChecked atomic.Int64
+}
+
+func newInstance(url string) *Instance {
+	instance := &Instance{URL: strings.TrimRight(url, "/")}
+	instance.healthy.Store(true)
+	return instance
+}
+
+func (i *Instance) Healthy() bool {
+	return i.healthy.Load()
+}
+
+func (i *Instance) setHealthy(healthy bool) {
+	i.healthy.Store(healthy)
+	i.lastChecked.Store(time.Now().Unix())
+}
+
+type InstanceStatus struct {
+	URL               string     `json:"url"`
+	Healthy           bool       `json:"healthy"`
+	ActiveConnections int64      `json:"active_connections"`
+	LastChecked       *time.Time `json:"last_checked,omitempty"`
+}
+
+func (i *Instance) Status() InstanceStatus {
+	status := InstanceStatus{
+		URL:               i.URL,
+		Healthy:           i.Healthy(),
+		ActiveConnections: i.active.Load(),
+	}
+	if checked := i.lastChecked.Load(); checked > 0 {
+		lastChecked := time.Unix(checked, 0)
+		status.LastChecked = &lastChecked
+	}
+	return status
+}
+
+// Pool balances requests for one upstream service across its instances.
+type Pool struct {
+	name      string
+	strategy  Strategy
+	instances []*Instance
+	next      atomic.Uint64
+}
+
+func newPool(name string, config UpstreamConfig, previous *Pool) *Pool {
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = RoundRobin
+	}
+
+	// Keep the health and connection counters of instances that survive a
+	// reload, otherwise a reload would briefly route to known-dead replicas.
+	existing := make(map[string]*Instance)
+	if previous != nil {
+		for _, instance := range previous.instances {
+			existing[instance.URL] = instance
+		}
+	}
+
+	pool := &Pool{name: name, strategy: strategy}
+	for _, url := range config.Instances {
+		instance := newInstance(url)
+		if old, ok := existing[instance.URL]; ok {
+			instance = old
+		}
+		pool.instances = append(pool.instances, instance)
+	}
+	return pool
+}
+
+// Pick selects a healthy instance. The caller must call Release on the
+// returned instance once the request has finished.
+func (p *Pool) Pick() (*Instance, error) {
+	healthy := make([]*Instance, 0, len(p.instances))
+	for _, instance := range p.instances {
+		if instance.Healthy() {
+			healthy = append(healthy, instance)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoHealthyInstance, p.name)
+	}
+
+	var picked *Instance
+	switch p.strategy {
+	case LeastConnections:
+		picked = healthy[0]
+		for _, instance := range healthy[1:] {
+			if instance.active.Load() < picked.active.Load() {
+				picked = instance
+			}
+		}
+	default:
+		picked = healthy[(p.next.Add(1)-1)%uint64(len(healthy))]
+	}
+
+	picked.active.Add(1)
+	return picked, nil
+}
+
+func (p *Pool) Release(instance *Instance) {
+	instance.active.Add(-1)
+}
+
+type PoolStatus struct {
+	Name      string           `json:"name"`
+	Strategy  Strategy         `json:"strategy"`
+	Instances []InstanceStatus `json:"instances"`
+}
+
+func (p *Pool) Status() PoolStatus {
+	status := PoolStatus{Name: p.name, Strategy: p.strategy}
+	for _, instance := range p.instances {
+		status.Instances = append(status.Instances, instance.Status())
+	}
+	return status
+}
+
+// Upstreams holds the instance pools of every upstream service. Pools can be
+// replaced at runtime with Update, which is how the config file reload works.
+type Upstreams struct {
+	mu    sync.RWMutex
+	pools map[string]*Pool
+}
+
+func NewUpstreams(configs map[string]UpstreamConfig) (*Upstreams, error) {
+	u := &Upstreams{pools: make(map[string]*Pool)}
+	if err := u.Update(configs); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Update validates configs and swaps in the new pools. Upstreams that are not
+// mentioned in configs are left untouched.
+func (u *Upstreams) Update(configs map[string]UpstreamConfig) error {
+	for name, config := range configs {
+		if err := config.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for name, config := range configs {
+		u.pools[name] = newPool(name, config, u.pools[name])
+	}
+	return nil
+}
+
+func (u *Upstreams) Pool(name string) (*Pool, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	pool, ok := u.pools[name]
+	return pool, ok
+}
+
+func (u *Upstreams) Pools() []*Pool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	pools := make([]*Pool, 0, len(u.pools))
+	for _, pool := range u.pools {
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+func (u *Upstreams) Statuses() []PoolStatus {
+	pools := u.Pools()
+	statuses := make([]PoolStatus, 0, len(pools))
+	for _, pool := range pools {
+		statuses = append(statuses, pool.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func (u *Upstreams) Names() []string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	names := make([]string, 0, len(u.pools))
+	for name := range u.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,199 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+
+	"github.com/streadway/amqp"
+)
+
+// Exchange is the topic exchange the services publish realtime events to,
+// with the event type as routing key.
+const Exchange = "realtime_events"
+
+type Config struct {
+	// URL is the RabbitMQ connection string.
+	URL string
+	// Heartbeat is how often an idle stream gets a comment line so proxies
+	// and clients do not drop the connection.
+	Heartbeat time.Duration
+	// Timeout bounds the lookup of the merchants a keeper is assigned to.
+	Timeout time.Duration
+	// BufferSize is how many events a slow subscriber may lag behind before
+	// further events are dropped for it.
+	BufferSize int
+	// ReconnectDelay is the wait between attempts to reach RabbitMQ.
+	ReconnectDelay time.Duration
+	// Recheck is how often an open stream checks that the caller's session
+	// or API key, and a keeper's merchant assignments, are still valid.
+	Recheck time.Duration
+
+	// Sessions and APIKeys, if set, are asked again on every recheck, so a
+	// stream ends once its session is signed out or its key revoked.
+	Sessions middleware.SessionVerifier
+	APIKeys  middleware.APIKeyVerifier
+}
+
+// Event is one message from the realtime exchange. Data is forwarded to the
+// clients as is; the other fields are only read for scoping.
+type Event struct {
+	Type       string
+	MerchantID uint
+	Data       []byte
+}
+
+type subscriber struct {
+	events chan Event
+	allow  func(Event) bool
+}
+
+// Hub consumes the realtime exchange and fans every event out to the
+// subscribers allowed to see it.
+type Hub struct {
+	proxy  *proxy.Proxy
+	config Config
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewHub(p *proxy.Proxy, config Config) *Hub {
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = 25 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 32
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = 5 * time.Second
+	}
+	if config.Recheck <= 0 {
+		config.Recheck = time.Minute
+	}
+
+	return &Hub{
+		proxy:       p,
+		config:      config,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Start consumes in the background until ctx is done, reconnecting whenever
+// the connection to RabbitMQ is lost.
+func (h *Hub) Start(ctx context.Context) {
+	go func() {
+		for {
+			if err := h.consume(ctx); err != nil {
+				log.Printf("Realtime consumer stopped: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(h.config.ReconnectDelay):
+			}
+		}
+	}()
+}
+
+func (h *Hub) consume(ctx context.Context) error {
+	conn, err := amqp.Dial(h.config.URL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(Exchange, "topic", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	// Every gateway instance needs its own copy of every event, so the queue
+	// is private to this connection and goes away with it.
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := ch.QueueBind(q.Name, "#", Exchange, false, nil); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(q.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Realtime consumer listening on %s", Exchange)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return amqp.ErrClosed
+			}
+			h.dispatch(msg.Body)
+		}
+	}
+}
+
+func (h *Hub) dispatch(body []byte) {
+	var header struct {
+		Type       string `json:"type"`
+		MerchantID uint   `json:"merchant_id"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil || header.Type == "" {
+		log.Printf("Dropping invalid realtime event: %s", body)
+		return
+	}
+
+	event := Event{Type: header.Type, MerchantID: header.MerchantID, Data: body}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		if !sub.allow(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			// A stalled client must not hold up everyone else.
+		}
+	}
+}
+
+func (h *Hub) subscribe(allow func(Event) bool) *subscriber {
+	sub := &subscriber{
+		events: make(chan Event, h.config.BufferSize),
+		allow:  allow,
+	}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+func (h *Hub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+}
@@ -0,0 +1,239 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Permissions deciding which events a caller receives.
+const (
+	PermissionDashboardAll      = "dashboard:all"
+	PermissionDashboardMerchant = "dashboard:merchant"
+)
+
+// Stream serves the events the caller may see as server-sent events.
+// Holders of dashboard:all receive everything, holders of dashboard:merchant
+// only the events of the merchants they are assigned to. It expects the JWT
+// middleware to have run already.
+//
+// The stream ends with a close event when the token or API key expires, and
+// when a recheck finds the session signed out, the key revoked or a keeper's
+// assignments changed. The client then reconnects with fresh credentials.
+func (h *Hub) Stream(c *fiber.Ctx) error {
+	caller := callerOf(c)
+
+	var allow func(Event) bool
+	var scope fiber.Map
+	var merchantIDs []uint
+	switch {
+	case middleware.HasAnyPermission(c, PermissionDashboardAll):
+		allow = func(Event) bool { return true }
+		scope = fiber.Map{"scope": "all"}
+	case middleware.HasAnyPermission(c, PermissionDashboardMerchant):
+		var status int
+		var err error
+		merchantIDs, status, err = h.keeperMerchants(c.UserContext(), caller)
+		if err != nil {
+			return c.Status(status).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		assigned := make(map[uint]bool, len(merchantIDs))
+		for _, merchantID := range merchantIDs {
+			assigned[merchantID] = true
+		}
+		allow = func(e Event) bool { return assigned[e.MerchantID] }
+		scope = fiber.Map{"scope": "merchant", "merchant_ids": merchantIDs}
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Insufficient permissions",
+		})
+	}
+
+	ready, err := json.Marshal(scope)
+	if err != nil {
+		return err
+	}
+
+	sub := h.subscribe(allow)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.unsubscribe(sub)
+
+		heartbeat := time.NewTicker(h.config.Heartbeat)
+		defer heartbeat.Stop()
+
+		recheck := time.NewTicker(h.config.Recheck)
+		defer recheck.Stop()
+
+		// A zero expiry, like that of a key without one, never fires.
+		var expired <-chan time.Time
+		if !caller.expiresAt.IsZero() {
+			expiry := time.NewTimer(time.Until(caller.expiresAt))
+			defer expiry.Stop()
+			expired = expiry.C
+		}
+
+		fmt.Fprintf(w, "retry: %d\n", h.config.ReconnectDelay.Milliseconds())
+		writeEvent(w, "ready", ready)
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		// A failed flush is the only sign that the client went away.
+		for {
+			select {
+			case event := <-sub.events:
+				writeEvent(w, event.Type, event.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-expired:
+				writeClose(w, "Token expired")
+				return
+			case <-recheck.C:
+				if reason := h.recheck(caller, merchantIDs); reason != "" {
+					writeClose(w, reason)
+					return
+				}
+				continue
+			}
+
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// caller is what a stream needs to check its credentials again after the
+// request context is gone.
+type caller struct {
+	userID    uint
+	sessionID uint
+	apiKey    string
+	ip        string
+	header    http.Header
+	expiresAt time.Time
+}
+
+func callerOf(c *fiber.Ctx) caller {
+	userID, _ := c.Locals("user_id").(uint)
+	sessionID, _ := c.Locals("session_id").(uint)
+	expiresAt, _ := c.Locals("expires_at").(time.Time)
+
+	var apiKey string
+	if c.Locals("api_key_id") != nil {
+		apiKey = c.Get(middleware.APIKeyHeader)
+	}
+
+	return caller{
+		userID:    userID,
+		sessionID: sessionID,
+		apiKey:    apiKey,
+		ip:        c.IP(),
+		header:    middleware.IdentityHeader(c),
+		expiresAt: expiresAt,
+	}
+}
+
+// recheck returns why the stream of the caller has to end, or "" if it may
+// go on. Lookups that fail end the stream too, the reconnect then gets a
+// proper error response.
+func (h *Hub) recheck(caller caller, merchantIDs []uint) string {
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+
+	if caller.sessionID != 0 && h.config.Sessions != nil {
+		if err := h.config.Sessions.VerifySession(ctx, caller.sessionID, caller.userID, caller.ip); err != nil {
+			return "Session expired or signed out"
+		}
+	}
+
+	if caller.apiKey != "" && h.config.APIKeys != nil {
+		if _, err := h.config.APIKeys.VerifyAPIKey(ctx, caller.apiKey); err != nil {
+			return "API key revoked or expired"
+		}
+	}
+
+	if merchantIDs != nil {
+		current, _, err := h.keeperMerchants(ctx, caller)
+		if err != nil || !sameMerchants(current, merchantIDs) {
+			return "Merchant assignments changed"
+		}
+	}
+
+	return ""
+}
+
+func sameMerchants(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[uint]bool, len(a))
+	for _, merchantID := range a {
+		seen[merchantID] = true
+	}
+	for _, merchantID := range b {
+		if !seen[merchantID] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeEvent(w *bufio.Writer, event string, data []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// writeClose tells the client why the stream ends. A failed flush does not
+// matter, the stream is over either way.
+func writeClose(w *bufio.Writer, reason string) {
+	data, _ := json.Marshal(fiber.Map{"message": reason})
+	writeEvent(w, "close", data)
+	w.Flush()
+}
+
+// keeperMerchants looks up the merchants the calling keeper is assigned to.
+func (h *Hub) keeperMerchants(ctx context.Context, caller caller) ([]uint, int, error) {
+	if caller.userID == 0 {
+		return nil, fiber.StatusUnauthorized, errors.New("Unauthorized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	path := fmt.Sprintf("/api/v1/merchants/keeper/%d", caller.userID)
+	status, body, err := h.proxy.Fetch(ctx, "merchant-service", path, caller.header, h.config.Timeout)
+	if err != nil || status != http.StatusOK {
+		return nil, fiber.StatusBadGateway, errors.New("Merchant could not be loaded")
+	}
+
+	var envelope struct {
+		Data struct {
+			MerchantIDs []uint `json:"merchant_ids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fiber.StatusBadGateway, errors.New("Invalid merchant response")
+	}
+	if len(envelope.Data.MerchantIDs) == 0 {
+		return nil, fiber.StatusNotFound, errors.New("No merchant is assigned to this keeper")
+	}
+
+	return envelope.Data.MerchantIDs, fiber.StatusOK, nil
+}
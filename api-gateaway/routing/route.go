@@ -0,0 +1,313 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TierAPI  = "api"
+	TierAuth = "auth"
+	TierNone = "none"
+)
+
+// Route maps a gateway path prefix onto an upstream service.
+type Route struct {
+	// Prefix is the path the gateway serves, e.g. /api/v1/products.
+	Prefix string `json:"prefix" yaml:"prefix"`
+	// Upstream is the upstream service name, e.g. product-service.
+	Upstream string `json:"upstream" yaml:"upstream"`
+	// Rewrite replaces Prefix in the upstream path. Empty keeps the path as is.
+	Rewrite string `json:"rewrite,omitempty" yaml:"rewrite,omitempty"`
+	// Methods limits the route to the given HTTP methods. Empty allows all.
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// Roles, if set, requires the caller to hold at least one of them.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	// Permissions, if set, requires the caller to hold at least one of them.
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// WritePermissions is like Permissions but only checked for methods
+	// other than GET, HEAD and OPTIONS.
+	WritePermissions []string `json:"write_permissions,omitempty" yaml:"write_permissions,omitempty"`
+	// RateLimitTier selects the rate limiter: api (default), auth or none.
+	RateLimitTier string `json:"rate_limit_tier,omitempty" yaml:"rate_limit_tier,omitempty"`
+	// RateLimit, if set, is an extra per-caller quota (requests per window)
+	// counted for this route only.
+	RateLimit int `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	// Timeout overrides the default upstream timeout, e.g. "30s".
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// CacheTTL, if set, caches successful GET responses in Redis for up to
+	// this long. Cached responses are shared by every caller allowed on the
+	// route, so only enable it where the response does not depend on who asks.
+	CacheTTL Duration `json:"cache_ttl,omitempty" yaml:"cache_ttl,omitempty"`
+	// CacheInvalidate lists the catalog event routing keys that drop the
+	// route's cached responses, e.g. product.*. * matches one word, # any.
+	CacheInvalidate []string `json:"cache_invalidate,omitempty" yaml:"cache_invalidate,omitempty"`
+	// AuthRequired defaults to true when omitted.
+	AuthRequired *bool `json:"auth_required,omitempty" yaml:"auth_required,omitempty"`
+}
+
+func (r Route) RequiresAuth() bool {
+	return r.AuthRequired == nil || *r.AuthRequired
+}
+
+func (r Route) Tier() string {
+	if r.RateLimitTier == "" {
+		return TierAPI
+	}
+	return r.RateLimitTier
+}
+
+// UpstreamPath returns the path to request on the upstream for a gateway path
+// matched by this route.
+func (r Route) UpstreamPath(path string) string {
+	if r.Rewrite == "" {
+		return path
+	}
+	return r.Rewrite + strings.TrimPrefix(path, r.Prefix)
+}
+
+// Duration is a time.Duration that is written as a string ("15s") in config
+// files.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	if d == 0 {
+		return "default"
+	}
+	return time.Duration(d).String()
+}
+
+func (d *Duration) parse(value string) error {
+	if value == "" {
+		*d = 0
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return d.parse(value)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+type File struct {
+	Routes []Route `json:"routes" yaml:"routes"`
+}
+
+// Load reads a route table from a .yaml, .yml or .json file.
+func Load(path string) ([]Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(path, data)
+}
+
+// Parse reads a route table from data, in the format the extension of name
+// says.
+func Parse(name string, data []byte) ([]Route, error) {
+	var file File
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("unsupported route file type: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	return file.Routes, nil
+}
+
+// Validate checks the route table against the known upstream names.
+func Validate(routes []Route, upstreams []string) error {
+	known := make(map[string]bool, len(upstreams))
+	for _, upstream := range upstreams {
+		known[upstream] = true
+	}
+
+	if len(routes) == 0 {
+		return fmt.Errorf("no routes configured")
+	}
+
+	seen := make(map[string]bool, len(routes))
+	var problems []string
+	for i, route := range routes {
+		where := fmt.Sprintf("route %d (%s)", i+1, route.Prefix)
+
+		if !strings.HasPrefix(route.Prefix, "/") {
+			problems = append(problems, where+": prefix must start with /")
+		}
+		if strings.HasSuffix(route.Prefix, "/") && route.Prefix != "/" {
+			problems = append(problems, where+": prefix must not end with /")
+		}
+		if seen[route.Prefix] {
+			problems = append(problems, where+": duplicate prefix")
+		}
+		seen[route.Prefix] = true
+
+		if !known[route.Upstream] {
+			problems = append(problems, fmt.Sprintf("%s: unknown upstream %q", where, route.Upstream))
+		}
+		if route.Rewrite != "" && !strings.HasPrefix(route.Rewrite, "/") {
+			problems = append(problems, where+": rewrite must start with /")
+		}
+
+		for _, method := range route.Methods {
+			if !isMethod(method) {
+				problems = append(problems, fmt.Sprintf("%s: unknown method %q", where, method))
+			}
+		}
+		for _, role := range route.Roles {
+			if strings.TrimSpace(role) == "" {
+				problems = append(problems, where+": empty role name")
+			}
+		}
+		if len(route.Roles) > 0 && !route.RequiresAuth() {
+			problems = append(problems, where+": roles require auth_required")
+		}
+		for _, permissions := range [][]string{route.Permissions, route.WritePermissions} {
+			for _, permission := range permissions {
+				if strings.TrimSpace(permission) == "" {
+					problems = append(problems, where+": empty permission name")
+				}
+			}
+		}
+		if (len(route.Permissions) > 0 || len(route.WritePermissions) > 0) && !route.RequiresAuth() {
+			problems = append(problems, where+": permissions require auth_required")
+		}
+
+		switch route.Tier() {
+		case TierAPI, TierAuth, TierNone:
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown rate_limit_tier %q", where, route.RateLimitTier))
+		}
+
+		if route.RateLimit < 0 {
+			problems = append(problems, where+": rate_limit must not be negative")
+		}
+
+		if route.Timeout < 0 {
+			problems = append(problems, where+": timeout must not be negative")
+		}
+
+		if route.CacheTTL < 0 {
+			problems = append(problems, where+": cache_ttl must not be negative")
+		}
+		if len(route.CacheInvalidate) > 0 && route.CacheTTL == 0 {
+			problems = append(problems, where+": cache_invalidate requires cache_ttl")
+		}
+		for _, pattern := range route.CacheInvalidate {
+			if strings.TrimSpace(pattern) == "" {
+				problems = append(problems, where+": empty cache_invalidate pattern")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid route configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Sorted returns the routes ordered so that longer prefixes are matched
+// before the shorter prefixes they extend (/api/v1/upload/photo before
+// /api/v1/upload).
+func Sorted(routes []Route) []Route {
+	sorted := make([]Route, len(routes))
+	copy(sorted, routes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return sorted
+}
+
+// Match returns the route serving path. routes must be sorted with Sorted so
+// the most specific prefix wins, as it does in the gateway router.
+func Match(routes []Route, path string) (Route, bool) {
+	for _, route := range routes {
+		if route.Matches(path) {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+func (r Route) Matches(path string) bool {
+	return path == r.Prefix || strings.HasPrefix(path, r.Prefix+"/")
+}
+
+func (r Route) AllowsMethod(method string) bool {
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, allowed := range r.Methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredPermissions returns the permissions a request with method needs,
+// any one of each returned set being enough.
+func (r Route) RequiredPermissions(method string) [][]string {
+	var required [][]string
+	if len(r.Permissions) > 0 {
+		required = append(required, r.Permissions)
+	}
+	if len(r.WritePermissions) > 0 && !IsReadMethod(method) {
+		required = append(required, r.WritePermissions)
+	}
+	return required
+}
+
+// IsReadMethod reports whether method only reads, which is what
+// WritePermissions leaves out.
+func IsReadMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func isMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
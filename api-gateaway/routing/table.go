@@ -0,0 +1,64 @@
+package routing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// PrintTable writes the effective route table in the order routes are
+// matched.
+func PrintTable(w io.Writer, routes []Route) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PREFIX\tMETHODS\tUPSTREAM\tREWRITE\tAUTH\tROLES\tPERMISSIONS\tRATE LIMIT\tTIMEOUT\tCACHE")
+
+	for _, route := range Sorted(routes) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%s\t%s\t%s\t%s\t%s\n",
+			route.Prefix,
+			orDash(strings.Join(route.Methods, ",")),
+			route.Upstream,
+			orDash(route.Rewrite),
+			route.RequiresAuth(),
+			orDash(strings.Join(route.Roles, ",")),
+			permissions(route),
+			rateLimit(route),
+			route.Timeout,
+			cache(route),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func permissions(route Route) string {
+	value := strings.Join(route.Permissions, ",")
+	if len(route.WritePermissions) > 0 {
+		if value != "" {
+			value += " "
+		}
+		value += "write=" + strings.Join(route.WritePermissions, ",")
+	}
+	return orDash(value)
+}
+
+func rateLimit(route Route) string {
+	if route.RateLimit > 0 {
+		return fmt.Sprintf("%s+%d", route.Tier(), route.RateLimit)
+	}
+	return route.Tier()
+}
+
+func cache(route Route) string {
+	if route.CacheTTL == 0 {
+		return "-"
+	}
+	return route.CacheTTL.String()
+}
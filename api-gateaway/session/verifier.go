@@ -0,0 +1,107 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"warehouse-go/api-gateaway/middleware"
+	"warehouse-go/api-gateaway/proxy"
+)
+
+type Config struct {
+	// Timeout bounds the call to user-service.
+	Timeout time.Duration
+	// CacheTTL is how long a verification result is reused. It is also the
+	// longest a signed out session keeps working, and how often last seen
+	// is updated at most.
+	CacheTTL time.Duration
+}
+
+type entry struct {
+	active  bool
+	expires time.Time
+}
+
+// Verifier checks token sessions against user-service and caches the
+// results, so a busy client does not cost a lookup per request.
+type Verifier struct {
+	proxy  *proxy.Proxy
+	config Config
+
+	mu      sync.Mutex
+	entries map[uint]entry
+}
+
+func NewVerifier(p *proxy.Proxy, config Config) *Verifier {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+
+	return &Verifier{
+		proxy:   p,
+		config:  config,
+		entries: make(map[uint]entry),
+	}
+}
+
+// VerifySession implements middleware.SessionVerifier.
+func (v *Verifier) VerifySession(ctx context.Context, sessionID, userID uint, ip string) error {
+	now := time.Now()
+	if active, ok := v.cached(sessionID, now); ok {
+		if !active {
+			return middleware.ErrInvalidSession
+		}
+		return nil
+	}
+
+	header := make(http.Header)
+	header.Set(middleware.SessionIDHeader, strconv.FormatUint(uint64(sessionID), 10))
+	header.Set("X-User-ID", strconv.FormatUint(uint64(userID), 10))
+	header.Set("X-Real-IP", ip)
+
+	status, _, err := v.proxy.Fetch(ctx, "user-service", "/internal/sessions/verify", header, v.config.Timeout)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK:
+		v.store(sessionID, true, now)
+		return nil
+	case http.StatusUnauthorized:
+		v.store(sessionID, false, now)
+		return middleware.ErrInvalidSession
+	default:
+		return fmt.Errorf("user-service answered %d", status)
+	}
+}
+
+func (v *Verifier) cached(sessionID uint, now time.Time) (bool, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	e, ok := v.entries[sessionID]
+	if !ok || now.After(e.expires) {
+		return false, false
+	}
+	return e.active, true
+}
+
+func (v *Verifier) store(sessionID uint, active bool, now time.Time) {
+	if v.config.CacheTTL <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for k, e := range v.entries {
+		if now.After(e.expires) {
+			delete(v.entries, k)
+		}
+	}
+	v.entries[sessionID] = entry{active: active, expires: now.Add(v.config.CacheTTL)}
+}
@@ -47,7 +47,7 @@ func RunServer() {
 	}
 
 	merchantProductRepo := repository.NewMerchantProductRepository(db.DB)
-	stockConsumer, err := rabbitmq.NewStockConsumer(cfg.RabbitMQ.URL(), merchantProductRepo)
+	stockConsumer, err := rabbitmq.NewStockConsumer(cfg.RabbitMQ.URL(), merchantProductRepo, cfg.App.LowStockThreshold)
 	if err != nil {
 		log.Fatalf("Failed to create stock consumer: %v", err)
 	} else {
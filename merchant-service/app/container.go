@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"log"
 	"warehouse-go/merchant-service/configs"
 	"warehouse-go/merchant-service/controller"
@@ -11,12 +12,14 @@ import (
 	"warehouse-go/merchant-service/pkg/storage"
 	"warehouse-go/merchant-service/repository"
 	"warehouse-go/merchant-service/usecase"
+	"warehouse-go/shared/audit"
 )
 
 type Container struct {
 	MerchantController controller.MerchantControllerInterface
 	MerchantProductController controller.MerchantProductControllerInterface
 	UploadController controller.UploadControllerInterface
+	AuditTracker *audit.Tracker
 }
 
 func BuildContainer() *Container {
@@ -25,6 +28,11 @@ func BuildContainer() *Container {
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
+	if !cfg.SqlDB.SkipMigrations {
+		if err := database.Migrate(context.Background(), db.DB); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
 
 	redisClient := redis.NewRedisClient(*cfg)
 	rabbitMQService, err := rabbitmq.NewRabbitMQService(cfg.RabbitMQ.URL())
@@ -40,20 +48,34 @@ func BuildContainer() *Container {
 	cachedProductClient := httpclient.NewCachedProductClient(productClient, redisClient)
 
 	merchantRepo := repository.NewMerchantRepository(db.DB)
-	merchantUsecase := usecase.NewMerchantUsecase(merchantRepo, cachedUserClient, cachedWarehouseClient, cachedProductClient)
+	merchantUsecase := usecase.NewMerchantUsecase(merchantRepo, cachedUserClient)
 	merchantController := controller.NewMerchantController(merchantUsecase)
 
 	merchantProductRepo := repository.NewMerchantProductRepository(db.DB)
-	merchantProductUsecase := usecase.NewMerchantProductUsecase(merchantProductRepo, cachedProductClient, cachedWarehouseClient, rabbitMQService)
+	merchantProductUsecase := usecase.NewMerchantProductUsecase(merchantProductRepo, merchantRepo, cachedProductClient, cachedWarehouseClient, rabbitMQService)
 	merchantProductController := controller.NewMerchantProductController(merchantProductUsecase)
 
 	supabaseStorage := storage.NewSupabaseStorage(*cfg)
 	uploadFileHelper := storage.NewUploadFileHelper(supabaseStorage, *cfg)
 	uploadController := controller.NewUploadController(uploadFileHelper)	
 
+	auditPublisher, err := audit.NewPublisher(cfg.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to rabbitmq: %v", err)
+	}
+
+	auditTracker := audit.NewTracker("merchant-service", auditPublisher)
+	auditTracker.Register("merchant", func(ctx context.Context, id uint) (interface{}, error) {
+		return merchantRepo.GetMerchantByID(ctx, id)
+	})
+	auditTracker.Register("merchant-product", func(ctx context.Context, id uint) (interface{}, error) {
+		return merchantProductRepo.GetMerchantProductByID(ctx, id)
+	})
+
 	return &Container {
 		MerchantController: merchantController,
 		MerchantProductController: merchantProductController,
 		UploadController: uploadController,
+		AuditTracker: auditTracker,
 	}
 }
\ No newline at end of file
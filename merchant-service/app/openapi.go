@@ -0,0 +1,37 @@
+package app
+
+import (
+	"warehouse-go/merchant-service/controller/request"
+	"warehouse-go/merchant-service/controller/response"
+	"warehouse-go/merchant-service/pkg/openapi"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "Merchant Service",
+	Version:     "1.0.0",
+	Description: "Merchants and their product stock",
+}
+
+// openAPIOperations documents the request and response types of the routes
+// registered in SetupRoutes.
+var openAPIOperations = []openapi.Operation{
+	{Method: "POST", Path: "/api/v1/merchants", Summary: "Create a merchant", Tags: []string{"merchants"}, Request: request.CreateMerchantRequest{}},
+	{Method: "GET", Path: "/api/v1/merchants", Summary: "List merchants", Tags: []string{"merchants"}, Query: request.GetMerchantProductRequest{}, Response: response.MerchantPaginationResponse{}},
+	{Method: "GET", Path: "/api/v1/merchants/keeper/:user_id", Summary: "Merchants a user keeps", Tags: []string{"merchants"}, Response: response.KeeperMerchantsResponse{}},
+	{Method: "GET", Path: "/api/v1/merchants/:id", Summary: "Get a merchant", Tags: []string{"merchants"}, Response: response.MerchantResponse{}},
+	{Method: "PUT", Path: "/api/v1/merchants/:id", Summary: "Update a merchant", Tags: []string{"merchants"}, Request: request.CreateMerchantRequest{}},
+	{Method: "DELETE", Path: "/api/v1/merchants/:id", Summary: "Delete a merchant", Tags: []string{"merchants"}},
+	{Method: "PUT", Path: "/api/v1/merchants/:id/keepers", Summary: "Replace the keepers of a merchant", Tags: []string{"merchants"}, Request: request.ReplaceMerchantKeepersRequest{}},
+
+	{Method: "POST", Path: "/api/v1/merchant-products", Summary: "Add stock to a merchant", Tags: []string{"merchant-products"}, Request: request.CreateMerchantProductRequest{}},
+	{Method: "GET", Path: "/api/v1/merchant-products", Summary: "List merchant products", Tags: []string{"merchant-products"}, Query: request.GetMerchantProductRequest{}, Response: response.GetAllMerchantProductResponse{}},
+	{Method: "GET", Path: "/api/v1/merchant-products/:id", Summary: "Get a merchant product", Tags: []string{"merchant-products"}, Response: response.MerchantProduct{}},
+	{Method: "GET", Path: "/api/v1/merchant-products/barcode/:barcode", Summary: "Get a merchant product by barcode", Tags: []string{"merchant-products"}, Response: response.MerchantProduct{}},
+	{Method: "PUT", Path: "/api/v1/merchant-products/:id", Summary: "Update a merchant product", Tags: []string{"merchant-products"}, Request: request.CreateMerchantProductRequest{}},
+	{Method: "DELETE", Path: "/api/v1/merchant-products/:id", Summary: "Delete a merchant product", Tags: []string{"merchant-products"}},
+	{Method: "DELETE", Path: "/api/v1/merchant-products/product/:product_id", Summary: "Delete a product from every merchant", Tags: []string{"merchant-products"}},
+	{Method: "GET", Path: "/api/v1/merchant-products/product/:product_id/total-stock", Summary: "Total stock of a product across merchants", Tags: []string{"merchant-products"}, Response: 0},
+	{Method: "GET", Path: "/api/v1/merchant-products/merchant/:merchant_id/in-stock", Summary: "IDs of the products a merchant has in stock", Tags: []string{"merchant-products"}, Response: []uint{}},
+
+	{Method: "POST", Path: "/api/v1/upload-merchant", Summary: "Upload a merchant photo", Tags: []string{"upload"}, Upload: true, Response: response.UploadResponse{}},
+}
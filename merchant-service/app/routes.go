@@ -1,26 +1,43 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"warehouse-go/merchant-service/pkg/middleware"
+	"warehouse-go/merchant-service/pkg/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, c *Container) {
-	api := app.Group("/api/v1")
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "merchant-service",
+		})
+	})
+	app.Get("/openapi.json", openapi.Handler(app, openAPIInfo, openAPIOperations))
+
+	api := app.Group("/api/v1", middleware.RequireIdentity())
+	track := c.AuditTracker
 
 	merchants := api.Group("/merchants")
-	merchants.Post("/", c.MerchantController.CreateMerchant)
+	merchants.Post("/", middleware.RequireUnscoped(), track.Track("merchant", ""), c.MerchantController.CreateMerchant)
 	merchants.Get("/", c.MerchantController.GetAllMerchant)
+	merchants.Get("/keeper/:user_id", c.MerchantController.GetKeeperMerchantIDs)
 	merchants.Get("/:id", c.MerchantController.GetMerchantByID)
-	merchants.Put("/:id", c.MerchantController.UpdateMerchant)
-	merchants.Delete("/:id", c.MerchantController.DeleteMerchant)
+	merchants.Put("/:id", track.Track("merchant", "id"), c.MerchantController.UpdateMerchant)
+	merchants.Delete("/:id", track.Track("merchant", "id"), c.MerchantController.DeleteMerchant)
+	merchants.Put("/:id/keepers", middleware.RequireUnscoped(), track.TrackAction("assign-keepers", "merchant", "id"), c.MerchantController.ReplaceMerchantKeepers)
 
 	merchantProducts := api.Group("/merchant-products")
-	merchantProducts.Post("/", c.MerchantProductController.CreateMerchantProduct)
+	merchantProducts.Post("/", track.Track("merchant-product", ""), c.MerchantProductController.CreateMerchantProduct)
 	merchantProducts.Get("/:id", c.MerchantProductController.GetMerchantProductByID)
 	merchantProducts.Get("/", c.MerchantProductController.GetMerchantProducts)
 	merchantProducts.Get("/barcode/:barcode", c.MerchantProductController.GetMerchantProductByBarcode)
-	merchantProducts.Put("/:id", c.MerchantProductController.UpdateMerchantProduct)
-	merchantProducts.Delete("/:id", c.MerchantProductController.DeleteMerchantProduct)
-	merchantProducts.Delete("/product/:product_id", c.MerchantProductController.DeleteAllProductMerchantProducts)
-	merchantProducts.Get("/product/:product_id/total-stock", c.MerchantProductController.GetProductTotalStock)
+	merchantProducts.Put("/:id", track.Track("merchant-product", "id"), c.MerchantProductController.UpdateMerchantProduct)
+	merchantProducts.Delete("/:id", track.Track("merchant-product", "id"), c.MerchantProductController.DeleteMerchantProduct)
+	merchantProducts.Delete("/product/:product_id", middleware.RequireUnscoped(), track.Track("product-merchant-products", "product_id"), c.MerchantProductController.DeleteAllProductMerchantProducts)
+	merchantProducts.Get("/product/:product_id/total-stock", middleware.RequireUnscoped(), c.MerchantProductController.GetProductTotalStock)
+	merchantProducts.Get("/merchant/:merchant_id/in-stock", c.MerchantProductController.GetInStockProductIDs)
 
 	api.Post("/upload-merchant", c.UploadController.UploadMerchantPhoto)
 }
\ No newline at end of file
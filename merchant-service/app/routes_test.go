@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"warehouse-go/merchant-service/controller"
+	"warehouse-go/merchant-service/model"
+	"warehouse-go/merchant-service/repository"
+	"warehouse-go/merchant-service/usecase"
+	"warehouse-go/shared/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeMerchantRepo knows merchants 1 and 2 and assigns user 5 to merchant 1
+// only.
+type fakeMerchantRepo struct {
+	repository.MerchantRepositoryInterface
+	listedFor uint
+}
+
+func (f *fakeMerchantRepo) IsMerchantKeeper(ctx context.Context, merchantID, userID uint) (bool, error) {
+	return merchantID == 1 && userID == 5, nil
+}
+
+func (f *fakeMerchantRepo) GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error) {
+	return &model.Merchant{ID: id, Name: "Merchant"}, nil
+}
+
+func (f *fakeMerchantRepo) GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string, keeperID uint) ([]model.Merchant, int64, error) {
+	f.listedFor = keeperID
+	return nil, 0, nil
+}
+
+type fakeMerchantProductRepo struct {
+	repository.MerchantProductRepositoryInterface
+}
+
+func (f *fakeMerchantProductRepo) GetInStockProductIDs(ctx context.Context, merchantID uint) ([]uint, error) {
+	return []uint{3, 4}, nil
+}
+
+type discardRecorder struct{}
+
+func (discardRecorder) RecordAuditEvent(ctx context.Context, event audit.Event) error {
+	return nil
+}
+
+func newTestApp(merchantRepo *fakeMerchantRepo) *fiber.App {
+	app := fiber.New()
+	SetupRoutes(app, &Container{
+		MerchantController:        controller.NewMerchantController(usecase.NewMerchantUsecase(merchantRepo, nil)),
+		MerchantProductController: controller.NewMerchantProductController(usecase.NewMerchantProductUsecase(&fakeMerchantProductRepo{}, merchantRepo, nil, nil, nil)),
+		UploadController:          controller.NewUploadController(nil),
+		AuditTracker:              audit.NewTracker("merchant-service", discardRecorder{}),
+	})
+	return app
+}
+
+// caller is the identity the api gateway forwards.
+type caller struct {
+	userID      string
+	permissions string
+	internal    bool
+}
+
+var (
+	keeper  = caller{userID: "5", permissions: "merchant:read,merchant:write"}
+	manager = caller{userID: "1", permissions: "merchant:read,merchant:write,merchant:all"}
+	service = caller{internal: true}
+	nobody  = caller{}
+)
+
+func send(t *testing.T, app *fiber.App, who caller, method, target, body string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if who.userID != "" {
+		req.Header.Set("X-User-ID", who.userID)
+		req.Header.Set("X-User-Permissions", who.permissions)
+	}
+	if who.internal {
+		req.Header.Set("X-Internal-Request", "true")
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestMerchantScoping(t *testing.T) {
+	app := newTestApp(&fakeMerchantRepo{})
+
+	for _, tc := range []struct {
+		name   string
+		who    caller
+		target string
+		status int
+	}{
+		{"keeper on their merchant", keeper, "/api/v1/merchants/1", fiber.StatusOK},
+		{"keeper on another merchant", keeper, "/api/v1/merchants/2", fiber.StatusForbidden},
+		{"merchant:all on any merchant", manager, "/api/v1/merchants/2", fiber.StatusOK},
+		{"another service", service, "/api/v1/merchants/2", fiber.StatusOK},
+		{"no identity", nobody, "/api/v1/merchants/1", fiber.StatusUnauthorized},
+		{"keeper on the stock of their merchant", keeper, "/api/v1/merchant-products/merchant/1/in-stock", fiber.StatusOK},
+		{"keeper on the stock of another merchant", keeper, "/api/v1/merchant-products/merchant/2/in-stock", fiber.StatusForbidden},
+		{"merchant:all on the stock of any merchant", manager, "/api/v1/merchant-products/merchant/2/in-stock", fiber.StatusOK},
+		{"no identity on stock", nobody, "/api/v1/merchant-products/merchant/1/in-stock", fiber.StatusUnauthorized},
+	} {
+		if status := send(t, app, tc.who, http.MethodGet, tc.target, ""); status != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, status, tc.status)
+		}
+	}
+}
+
+// Keepers only list their own merchants, whatever keeper_id asks for.
+func TestMerchantListScoping(t *testing.T) {
+	repo := &fakeMerchantRepo{}
+	app := newTestApp(repo)
+
+	for _, tc := range []struct {
+		name      string
+		who       caller
+		target    string
+		listedFor uint
+	}{
+		{"keeper", keeper, "/api/v1/merchants", 5},
+		{"keeper asking for another keeper", keeper, "/api/v1/merchants?keeper_id=7", 5},
+		{"merchant:all", manager, "/api/v1/merchants", 0},
+		{"merchant:all asking for a keeper", manager, "/api/v1/merchants?keeper_id=7", 7},
+	} {
+		repo.listedFor = 99
+		send(t, app, tc.who, http.MethodGet, tc.target, "")
+		if repo.listedFor != tc.listedFor {
+			t.Errorf("%s: listed for keeper %d, want %d", tc.name, repo.listedFor, tc.listedFor)
+		}
+	}
+}
+
+// Routes about every merchant refuse keepers before the handler runs.
+func TestUnscopedRoutes(t *testing.T) {
+	app := newTestApp(&fakeMerchantRepo{})
+
+	for _, tc := range []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPost, "/api/v1/merchants", `{"name":"New"}`},
+		{http.MethodPut, "/api/v1/merchants/1/keepers", `{"user_ids":[5]}`},
+		{http.MethodGet, "/api/v1/merchant-products/product/3/total-stock", ""},
+		{http.MethodDelete, "/api/v1/merchant-products/product/3", ""},
+	} {
+		if status := send(t, app, keeper, tc.method, tc.target, tc.body); status != fiber.StatusForbidden {
+			t.Errorf("keeper %s %s: status %d, want 403", tc.method, tc.target, status)
+		}
+		if status := send(t, app, nobody, tc.method, tc.target, tc.body); status != fiber.StatusUnauthorized {
+			t.Errorf("no identity %s %s: status %d, want 401", tc.method, tc.target, status)
+		}
+	}
+}
@@ -13,6 +13,8 @@ type App struct {
 	UrlProductService	string 		`json:"url_product_service"`
 	UrlUserService 		string	 	`json:"url_user_service"`
 	UrlWarehouseService string 		`json:"url_warehouse_service"`
+
+	LowStockThreshold	int 		`json:"low_stock_threshold"`
 }
 
 type SqlDB struct {
@@ -23,6 +25,9 @@ type SqlDB struct {
 	DBname   string `json:"db_name"`
 	DBMaxOpenConns	int    `json:"db_max_open_conns"`
 	DBMaxIdleConns	int    `json:"db_max_idle_conns"`
+	// SkipMigrations leaves migrating to the migrate command instead of
+	// applying pending migrations on start.
+	SkipMigrations bool `json:"skip_migrations"`
 }
 
 type Redis struct {
@@ -64,6 +69,7 @@ func NewConfig() *Config {
 			UrlProductService: viper.GetString("URL_PRODUCT_SERVICE"),
 			UrlUserService: viper.GetString("URL_USER_SERVICE"),
 			UrlWarehouseService: viper.GetString("URL_WAREHOUSE_SERVICE"),
+			LowStockThreshold: viper.GetInt("LOW_STOCK_THRESHOLD"),
 	},
 		SqlDB: SqlDB {
 			Host:     viper.GetString("DATABASE_HOST"),
@@ -73,6 +79,7 @@ func NewConfig() *Config {
 			DBname:   viper.GetString("DATABASE_NAME"),
 			DBMaxIdleConns: viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			DBMaxOpenConns: viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
+			SkipMigrations: viper.GetBool("DATABASE_SKIP_MIGRATIONS"),
 
 	},
 		RabbitMQ: RabbitMQ{
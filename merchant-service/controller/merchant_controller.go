@@ -1,17 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/merchant-service/controller/request"
 	"warehouse-go/merchant-service/controller/response"
 	"warehouse-go/merchant-service/model"
 	"warehouse-go/merchant-service/pkg/conv"
-	"warehouse-go/merchant-service/pkg/httpclient"
+	"warehouse-go/merchant-service/pkg/middleware"
 	"warehouse-go/merchant-service/pkg/pagination"
 	"warehouse-go/merchant-service/pkg/validator"
 	"warehouse-go/merchant-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type MerchantControllerInterface interface {
@@ -20,6 +22,8 @@ type MerchantControllerInterface interface {
 	GetMerchantByID(c *fiber.Ctx) error
 	UpdateMerchant(c *fiber.Ctx) error
 	DeleteMerchant(c *fiber.Ctx) error
+	GetKeeperMerchantIDs(c *fiber.Ctx) error
+	ReplaceMerchantKeepers(c *fiber.Ctx) error
 }
 
 type merchantController struct {
@@ -69,8 +73,13 @@ func (m *merchantController) DeleteMerchant(c *fiber.Ctx) error {
 
 	merchantID := conv.StringToUint(id)
 
-	if err := m.merchantUsecase.DeleteMerchant(c.Context(), merchantID); err != nil {
+	if err := m.merchantUsecase.DeleteMerchant(c.Context(), merchantID, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[MerchantController] DeleteMerchant - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": err.Error(),
 		})
@@ -99,87 +108,13 @@ func (m *merchantController) GetAllMerchant(c *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-if req.KeeperID != 0 {
-	productMap := make(map[uint]*httpclient.ProductResponse)
-	warehouseMap := make(map[uint]*httpclient.WarehouseResponse)
-
-	merchant, products, warehouses, err := m.merchantUsecase.GetMerchantByKeeperID(c.Context(), req.KeeperID)
-	if err != nil {
-		log.Errorf("[MerchantController] GetAllMerchant - 2: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message" : "failed to get merchant by keeper id",
-		})
-	}
-
-	if merchant.ID == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message" : "No merchant found",
-		})
-	} 
-
-		keeperName, err := m.merchantUsecase.GetKeeperName(c.Context(), merchant.KeeperID)
-		if err != nil {
-			log.Errorf("[MerchantController] GetAllMerchant - 3: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"message" : "Failed to get keeper name",
-			})
-		}
-
-		merchantResponse := response.MerchantWithProductResponse{
-			ID: merchant.ID,
-			Name: merchant.Name,
-			Address: merchant.Address,
-			Photo: merchant.Photo,
-			Phone: merchant.Phone,
-			KeeperID: merchant.KeeperID,
-			KeepersName: keeperName,
-		}
-
-		
-		if len(merchant.MerchantProducts) > 0 {
-			for i := range products {
-				productMap[products[i].ID] = &products[i]
-			}
-
-			for i := range warehouses {
-				warehouseMap[warehouses[i].ID] = &warehouses[i]
-			}
-
-			for _, mp := range merchant.MerchantProducts {
-				productResponse := response.MerchantProduct{
-					ID: mp.ID,
-					MerchantID: mp.MerchantID,
-					ProductID: mp.ProductID,
-					Stock: mp.Stock,
-					WarehouseID: mp.WarehouseID,
-				}
-
-				if product, exists := productMap[mp.ProductID]; exists {
-					productResponse.ProductName = product.Name
-					productResponse.ProductAbout = product.About
-					productResponse.ProductPhoto = product.Thumbnail
-					productResponse.ProductPrice = int(product.Price)
-					productResponse.ProductCategory = product.Category.Name	
-					productResponse.ProductCategoryphoto = product.Category.Photo
-				}
-
-				if warehouse, exists := warehouseMap[mp.WarehouseID]; exists {
-					productResponse.WarehouseName = warehouse.Name
-					productResponse.WarehousePhoto = warehouse.Photo
-					productResponse.WarehousePhone = warehouse.Phone
-				}
-
-				merchantResponse.MerchantProducts = append(merchantResponse.MerchantProducts, productResponse)
-			}
-		}
-		
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"data" : merchantResponse,
-			"message" : "Merchant products fetched successfully",
-		})
+	// Keepers only see the merchants they keep, whatever keeper_id says.
+	keeperID := middleware.ScopedUserID(c)
+	if keeperID == 0 {
+		keeperID = req.KeeperID
 	}
 
-	merchants, total, err := m.merchantUsecase.GetAllMerchants(c.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	merchants, total, err := m.merchantUsecase.GetAllMerchants(c.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, keeperID)
 	if err != nil {
 		log.Errorf("[MerchantController] GetAllMerchant - 4: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -213,6 +148,7 @@ if req.KeeperID != 0 {
 			Phone: merchant.Phone,
 			KeeperID: merchant.KeeperID,
 			KeepersName: keeperName,
+			KeeperIDs: merchant.KeeperIDs(),
 			ProductCount: len(merchant.MerchantProducts),
 		})
 	}
@@ -235,9 +171,14 @@ func (m *merchantController) GetMerchantByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id := conv.StringToUint(idStr)
 
-	merchant, err := m.merchantUsecase.GetMerchantByID(c.Context(), id)
+	merchant, err := m.merchantUsecase.GetMerchantByID(c.Context(), id, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[MerchantController] GetMerchantByID - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get merchant by id",
 		})
@@ -260,6 +201,7 @@ func (m *merchantController) GetMerchantByID(c *fiber.Ctx) error {
 			Phone: merchant.Phone,
 			KeeperID: merchant.KeeperID,
 			KeepersName: keeperName,
+			KeeperIDs: merchant.KeeperIDs(),
 			ProductCount: len(merchant.MerchantProducts),
 		}
 
@@ -299,8 +241,13 @@ func (m *merchantController) UpdateMerchant(c *fiber.Ctx) error {
 		Photo: req.Photo,
 	}
 
-	if err := m.merchantUsecase.UpdateMerchant(c.Context(), &reqModel); err != nil {
+	if err := m.merchantUsecase.UpdateMerchant(c.Context(), &reqModel, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[MerchantController] UpdateMerchant - 3: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to update merchant", 
 		})
@@ -312,6 +259,75 @@ func (m *merchantController) UpdateMerchant(c *fiber.Ctx) error {
 
 }
 
+// GetKeeperMerchantIDs implements MerchantControllerInterface. It tells
+// other services and the gateway which merchants a user keeps.
+func (m *merchantController) GetKeeperMerchantIDs(c *fiber.Ctx) error {
+	userID := conv.StringToUint(c.Params("user_id"))
+
+	if keeperID := middleware.ScopedUserID(c); keeperID != 0 && keeperID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Insufficient permissions",
+		})
+	}
+
+	merchantIDs, err := m.merchantUsecase.GetMerchantIDsByKeeperID(c.Context(), userID)
+	if err != nil {
+		log.Errorf("[MerchantController] GetKeeperMerchantIDs - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get merchants of keeper",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchants of keeper fetched successfully",
+		"data": response.KeeperMerchantsResponse{
+			UserID:      userID,
+			MerchantIDs: merchantIDs,
+		},
+	})
+}
+
+// ReplaceMerchantKeepers implements MerchantControllerInterface.
+func (m *merchantController) ReplaceMerchantKeepers(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.ReplaceMerchantKeepersRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantController] ReplaceMerchantKeepers - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantController] ReplaceMerchantKeepers - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := m.merchantUsecase.ReplaceMerchantKeepers(c.Context(), merchantID, req.UserIDs); err != nil {
+		log.Errorf("[MerchantController] ReplaceMerchantKeepers - 3: %v", err)
+		switch {
+		case errors.Is(err, usecase.ErrUnknownKeeper):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Unknown keeper",
+			})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to assign keepers",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Keepers assigned successfully",
+	})
+}
+
 func NewMerchantController(merchantUsecase usecase.MerchantUsecaseInterface) MerchantControllerInterface {
 	return &merchantController{
 		merchantUsecase: merchantUsecase,
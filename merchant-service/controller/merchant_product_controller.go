@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/merchant-service/controller/request"
 	"warehouse-go/merchant-service/controller/response"
 	"warehouse-go/merchant-service/model"
 	"warehouse-go/merchant-service/pkg/conv"
 	"warehouse-go/merchant-service/pkg/httpclient"
+	"warehouse-go/merchant-service/pkg/middleware"
 	"warehouse-go/merchant-service/pkg/pagination"
 	"warehouse-go/merchant-service/pkg/validator"
 	"warehouse-go/merchant-service/usecase"
@@ -23,6 +25,7 @@ type MerchantProductControllerInterface interface {
 	DeleteMerchantProduct(c *fiber.Ctx) error
 	DeleteAllProductMerchantProducts(c *fiber.Ctx) error
 	GetProductTotalStock(c *fiber.Ctx) error
+	GetInStockProductIDs(c *fiber.Ctx) error
 }
 
 type merchantProductController struct {
@@ -49,12 +52,23 @@ func (m *merchantProductController) CreateMerchantProduct(c *fiber.Ctx) error {
 	
 	reqModel := model.MerchantProduct{
 		ProductID: req.ProductID,
+		VariantID: req.VariantID,
 		WarehouseID: req.WarehouseID,
 		Stock: req.Stock,
 		MerchantID: req.MerchantID,
 	}
 
-	if err := m.merchantProductUsecase.CreateMerchantProduct(ctx, &reqModel); err != nil {
+	if err := m.merchantProductUsecase.CreateMerchantProduct(ctx, &reqModel, req.Unit, middleware.ScopedUserID(c)); err != nil {
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
+		if errors.Is(err, httpclient.ErrUnknownVariant) || errors.Is(err, httpclient.ErrUnknownUnit) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to create merchant",
 		})
@@ -89,8 +103,13 @@ func (m *merchantProductController) DeleteMerchantProduct(c *fiber.Ctx) error {
 	merchantProductID := c.Params("merchant_product_id")
 	merchantProductIDUint := conv.StringToUint(merchantProductID)
 
-	if err := m.merchantProductUsecase.DeleteMerchantProduct(ctx, merchantProductIDUint); err != nil {
+	if err := m.merchantProductUsecase.DeleteMerchantProduct(ctx, merchantProductIDUint, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[MerchantProductController] DeleteMerchantProduct - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to delete merchant product",
 		})
@@ -111,9 +130,14 @@ func (m *merchantProductController) GetMerchantProductByBarcode(c *fiber.Ctx) er
 		merchantIDUint = conv.StringToUint(c.Query("merchant_id"))
 	}
 
-	merchantProduct, product, warehouse, err := m.merchantProductUsecase.GetMerchantProductByBarcode(ctx, barcode, merchantIDUint)
+	merchantProduct, product, warehouse, err := m.merchantProductUsecase.GetMerchantProductByBarcode(ctx, barcode, merchantIDUint, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[MerchantProductController] GetMerchantProductByBarcode - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get merchant product by barcode",
 		})
@@ -125,6 +149,7 @@ func (m *merchantProductController) GetMerchantProductByBarcode(c *fiber.Ctx) er
 	productResponse.ID = merchantProduct.ID
 	productResponse.MerchantID = merchantProduct.MerchantID
 	productResponse.ProductID = merchantProduct.ProductID
+	productResponse.VariantID = merchantProduct.VariantID
 	productResponse.Stock = merchantProduct.Stock
 	productResponse.WarehouseID = merchantProduct.WarehouseID
 	productResponse.WarehouseName = warehouseResponse.WarehouseName
@@ -145,9 +170,14 @@ func (m *merchantProductController) GetMerchantProductByID(c *fiber.Ctx) error {
 	merchantProductID := c.Params("merchant_product_id")
 	merchantProductIDUint := conv.StringToUint(merchantProductID)
 
-	merchantProduct, product, warehouse, err := m.merchantProductUsecase.GetMerchantProductByID(ctx, merchantProductIDUint)
+	merchantProduct, product, warehouse, err := m.merchantProductUsecase.GetMerchantProductByID(ctx, merchantProductIDUint, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[MerchantProductController] GetMerchantProductByID - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"Message" : "Failed to get merchant product by id",
 		})
@@ -159,6 +189,7 @@ func (m *merchantProductController) GetMerchantProductByID(c *fiber.Ctx) error {
 	productResponse.ID = merchantProduct.ID
 	productResponse.MerchantID = merchantProduct.MerchantID
 	productResponse.ProductID = merchantProduct.ProductID
+	productResponse.VariantID = merchantProduct.VariantID
 	productResponse.Stock = merchantProduct.Stock
 	productResponse.WarehouseID = merchantProduct.WarehouseID
 	productResponse.WarehouseName = warehouseResponse.WarehouseName
@@ -191,7 +222,7 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	merchantProducts, products, warehouses, total, err := m.merchantProductUsecase.GetMerchantProducts(ctx, req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, req.MerchantID, req.ProductID)
+	merchantProducts, products, warehouses, total, err := m.merchantProductUsecase.GetMerchantProducts(ctx, req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, req.MerchantID, req.ProductID, req.VariantID, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[MerchantProductController] GetMerchantProducts - 2: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -216,6 +247,7 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 			ID: mp.ID,
 			MerchantID: mp.MerchantID,
 			ProductID: mp.ProductID,
+			VariantID: mp.VariantID,
 			Stock: mp.Stock,
 			WarehouseID: mp.WarehouseID,
 		}
@@ -227,6 +259,7 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 			productResponse.ProductPrice = int(product.Price)
 			productResponse.ProductCategory = product.Category.Name
 			productResponse.ProductCategoryphoto = product.Category.Photo
+			productResponse.BaseUnit = product.BaseUnit
 
 		}
 
@@ -271,6 +304,31 @@ func (m *merchantProductController) GetProductTotalStock(c *fiber.Ctx) error {
 	})
 }
 
+// GetInStockProductIDs implements MerchantProductControllerInterface. The
+// product service filters its search on it.
+func (m *merchantProductController) GetInStockProductIDs(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Params("merchant_id"))
+
+	productIDs, err := m.merchantProductUsecase.GetInStockProductIDs(ctx, merchantID, middleware.ScopedUserID(c))
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetInStockProductIDs - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : "Failed to get in stock products",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "In stock products fetched successfully",
+		"data" : productIDs,
+	})
+}
+
 // UpdateMerchantProduct implements MerchantProductControllerInterface.
 func (m *merchantProductController) UpdateMerchantProduct(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -295,13 +353,24 @@ func (m *merchantProductController) UpdateMerchantProduct(c *fiber.Ctx) error {
 		reqModel := model.MerchantProduct{
 			ID: merchantProductIDuint,
 			ProductID: req.ProductID,
+			VariantID: req.VariantID,
 			WarehouseID: req.WarehouseID,
 			Stock: req.Stock,
 			MerchantID: req.MerchantID,
 		}
 
-		if err := m.merchantProductUsecase.UpdateMerchantProduct(ctx, &reqModel); err != nil {
+		if err := m.merchantProductUsecase.UpdateMerchantProduct(ctx, &reqModel, req.Unit, middleware.ScopedUserID(c)); err != nil {
 			log.Errorf("[MerchantProductController] UpdateMerchantProduct - 3: %v", err)
+			if errors.Is(err, usecase.ErrMerchantNotAssigned) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"message" : "You are not assigned to this merchant",
+				})
+			}
+			if errors.Is(err, httpclient.ErrUnknownVariant) || errors.Is(err, httpclient.ErrUnknownUnit) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"message" : err.Error(),
+				})
+			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"message" : "Failed to update merchant product",
 			})
@@ -2,8 +2,12 @@ package request
 
 type CreateMerchantProductRequest struct {
 	ProductID	 	uint 	`json:"product_id" validate:"required"`
+	VariantID		uint	`json:"variant_id"`
 	WarehouseID 	uint 	`json:"warehouse_id" validate:"required"`
 	Stock 			int 	`json:"stock" validate:"required"`
+	// Unit is what Stock is counted in, the product's base unit when empty.
+	// It is stored converted to base units.
+	Unit			string	`json:"unit"`
 	MerchantID 		uint	`json:"merchant_id" validate:"required"`
 }
 
@@ -16,6 +20,8 @@ type GetMerchantProductRequest struct {
 	SortOrder 	string 	`query:"sort_order" validate:"omitempty,oneof=asc desc"`
 	MerchantID 	uint	`query:"merchant_id" validate:"omitempty"`
 	ProductID 	uint 	`query:"product_id" validate:"omitempty"`
+	// VariantID filters on a variant when set, 0 being the product itself.
+	VariantID 	*uint 	`query:"variant_id" validate:"omitempty"`
 	KeeperID 	uint 	`query:"keeper_id" validate:"omitempty"`
 }
 
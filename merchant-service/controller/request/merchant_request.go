@@ -6,4 +6,10 @@ type CreateMerchantRequest struct {
 	Address 	string `json:"address" validate:"required"`
 	Phone 		string `json:"phone" validate:"required"`
 	Photo 		string `json:"photo" validate:"required"`
-}
\ No newline at end of file
+}
+
+// ReplaceMerchantKeepersRequest sets every keeper of a merchant; the first
+// one becomes the main keeper. An empty list removes them all.
+type ReplaceMerchantKeepersRequest struct {
+	UserIDs []uint `json:"user_ids" validate:"dive,required"`
+}
@@ -9,6 +9,7 @@ type MerchantProductResponse struct {
 	ID          uint      `json:"id:"`
 	MerchantID  uint      `json:"merchant_id"`
 	ProductID   uint      `json:"product_id"`
+	VariantID   uint      `json:"variant_id"`
 	Stock       int       `json:"stock"`
 	WarehouseID uint      `json:"warehouse_id"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -22,6 +23,7 @@ type MerchantProduct struct {
 	ID						uint 	`json:"id"`
 	MerchantID  			uint    `json:"merchant_id"`
 	ProductID   			uint    `json:"product_id"`
+	VariantID   			uint    `json:"variant_id"`
 	ProductName 			string	`json:"product_name"`
 	ProductAbout 			string	`json:"product_about"`
 	ProductPhoto			string 	`json:"product_photo"`
@@ -29,6 +31,7 @@ type MerchantProduct struct {
 	ProductCategory 		string 	`json:"product_category"`
 	ProductCategoryphoto 	string 	`json:"product_category_photo"`
 	Stock 					int 	`json:"stock"`
+	BaseUnit 				string 	`json:"base_unit"`
 	WarehouseID 			uint    `json:"warehouse_id"`
 	WarehouseName 			string 	`json:"warehouse_name"`
 	WarehousePhoto 			string 	`json:"warehouse_photo"`
@@ -10,18 +10,13 @@ type MerchantResponse struct {
 	Phone        string `json:"phone"`
 	KeeperID     uint   `json:"keeper_id"`
 	KeepersName  string `json:"keepers_name"`
+	KeeperIDs    []uint `json:"keeper_ids"`
 	ProductCount int    `json:"product_count"`
 }
 
-type MerchantWithProductResponse struct {
-	ID               uint              `json:"id"`
-	Name             string            `json:"name"`
-	Address          string            `json:"address"`
-	Photo            string            `json:"photo"`
-	Phone            string            `json:"phone"`
-	KeeperID         uint              `json:"keeper_id"`
-	KeepersName      string            `json:"keepers_name"`
-	MerchantProducts []MerchantProduct `json:"merchant_products"`
+type KeeperMerchantsResponse struct {
+	UserID      uint   `json:"user_id"`
+	MerchantIDs []uint `json:"merchant_ids"`
 }
 
 type MerchantPaginationResponse struct {
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"warehouse-go/shared/migrate"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func NewMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrationFiles, "migrations", "merchant-service")
+}
+
+// Migrate applies the pending migrations. Replicas starting together take
+// turns, the first one migrates.
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "merchant_products";
+DROP TABLE IF EXISTS "merchants";
@@ -0,0 +1,32 @@
+-- The schema AutoMigrate created before any of the later changes, which
+-- follow in their own migrations. Every statement is conditional, so
+-- databases that already have it adopt this migration unchanged.
+
+CREATE TABLE IF NOT EXISTS "merchants" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"address" text,
+	"photo" text,
+	"phone" text,
+	"keeper_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "merchant_products" (
+	"id" bigserial,
+	"merchant_id" bigint NOT NULL,
+	"product_id" bigint NOT NULL,
+	"warehouse_id" bigint NOT NULL,
+	"stock" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_merchants_merchant_products" FOREIGN KEY ("merchant_id") REFERENCES "merchants"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_merchant_products_warehouse_id" ON "merchant_products" ("warehouse_id");
+CREATE INDEX IF NOT EXISTS "idx_merchant_products_product_id" ON "merchant_products" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_merchant_products_merchant_id" ON "merchant_products" ("merchant_id");
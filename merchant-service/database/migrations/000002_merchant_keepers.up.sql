@@ -0,0 +1,14 @@
+-- A merchant can have several keepers. AutoMigrate created the table on
+-- databases that ran the service in between, so it is only created when
+-- missing.
+
+CREATE TABLE IF NOT EXISTS "merchant_keepers" (
+	"id" bigserial,
+	"merchant_id" bigint NOT NULL,
+	"user_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_merchants_keepers" FOREIGN KEY ("merchant_id") REFERENCES "merchants"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_merchant_keeper" ON "merchant_keepers" ("merchant_id", "user_id");
+CREATE INDEX IF NOT EXISTS "idx_merchant_keepers_user_id" ON "merchant_keepers" ("user_id");
@@ -0,0 +1,2 @@
+-- Nothing to revert: backfilled keepers cannot be told apart from keepers
+-- assigned later, and the schema did not change.
@@ -0,0 +1,7 @@
+-- Assigns the main keeper to merchants that have no keepers yet, which
+-- covers merchants created before keepers were a list. Merchants whose
+-- keepers were all removed have keeper_id 0 and stay empty.
+INSERT INTO "merchant_keepers" ("merchant_id", "user_id", "created_at")
+SELECT m."id", m."keeper_id", now() FROM "merchants" m
+WHERE m."keeper_id" <> 0
+	AND NOT EXISTS (SELECT 1 FROM "merchant_keepers" mk WHERE mk."merchant_id" = m."id");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_merchant_products_stock";
+ALTER TABLE "merchant_products" DROP COLUMN IF EXISTS "variant_id";
@@ -0,0 +1,5 @@
+-- Stock of a product variant is kept on its own row. 0 is the product
+-- itself, for products without variants. Stock stays in base units.
+
+ALTER TABLE "merchant_products" ADD COLUMN IF NOT EXISTS "variant_id" bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_merchant_products_stock" ON "merchant_products" ("merchant_id", "product_id", "variant_id");
@@ -3,7 +3,6 @@ package database
 import (
 	"fmt"
 	"warehouse-go/merchant-service/configs"
-	"warehouse-go/merchant-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/driver/postgres"
@@ -29,7 +28,6 @@ func ConnectPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Merchant{}, &model.MerchantProduct{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] Connection Postgres - 2: %v", err)
@@ -40,4 +38,4 @@ func ConnectPostgres(cfg configs.Config) (*Postgres, error) {
 	sqlDB.SetMaxOpenConns(100)
 
 	return &Postgres{DB: db}, nil
-}
\ No newline at end of file
+}
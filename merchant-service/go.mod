@@ -53,5 +53,8 @@ require (
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
-	gorm.io/gorm v1.25.10
+	gorm.io/gorm v1.31.0
+	warehouse-go/shared v0.0.0
 )
+
+replace warehouse-go/shared => ../shared
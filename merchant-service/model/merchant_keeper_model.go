@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// MerchantKeeper assigns a user to a merchant. A user can keep several
+// merchants and a merchant can have several keepers.
+type MerchantKeeper struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	MerchantID uint      `json:"merchant_id" gorm:"not null;uniqueIndex:idx_merchant_keeper"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_merchant_keeper;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -8,11 +8,23 @@ type Merchant struct {
 	Address		string			`json:"address" gorm:"type:text"`
 	Photo      	string			`json:"photo"`
 	Phone      	string			`json:"phone"`
+	// KeeperID is the main keeper, shown on the merchant. Access follows
+	// Keepers, which includes it.
 	KeeperID	uint			`json:"keeper_id" gorm:"not null"`
 	CreatedAt 	time.Time 		`json:"created_at"`
 	UpdatedAt 	*time.Time 		`json:"updated_at"`
 	DeletedAt 	*time.Time  	`json:"deleted_at"`
 
 	MerchantProducts []MerchantProduct `json:"merchant_products" gorm:"foreignKey:MerchantID"`
+	Keepers          []MerchantKeeper  `json:"keepers" gorm:"foreignKey:MerchantID"`
+}
+
+// KeeperIDs returns the ids of every user assigned to the merchant.
+func (m Merchant) KeeperIDs() []uint {
+	ids := make([]uint, 0, len(m.Keepers))
+	for _, keeper := range m.Keepers {
+		ids = append(ids, keeper.UserID)
+	}
+	return ids
 }
  
\ No newline at end of file
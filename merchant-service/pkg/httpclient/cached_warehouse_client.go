@@ -28,8 +28,8 @@ func (cwc *CachedWarehouseClient) generateCacheKey(prefix string, id uint) strin
 	return fmt.Sprintf("warehouse:%s:%d", prefix, id)
 }
 
-func (cwc *CachedWarehouseClient) generateProductStockCacheKey(warehouseID uint, productID uint) string {
-	return fmt.Sprintf("warehouse:product_stock:%d:%d", warehouseID, productID)
+func (cwc *CachedWarehouseClient) generateProductStockCacheKey(warehouseID uint, productID uint, variantID uint) string {
+	return fmt.Sprintf("warehouse:product_stock:%d:%d:%d", warehouseID, productID, variantID)
 }
 
 func (cwc *CachedWarehouseClient) GetWarehouseByID(ctx context.Context, warehouseID uint) (*WarehouseResponse, error) {
@@ -62,9 +62,9 @@ func (cwc *CachedWarehouseClient) GetWarehouseByID(ctx context.Context, warehous
 	return warehouse, nil
 }
 
-func (cwc *CachedWarehouseClient) GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint) (*WarehouseProductStockResponse, error) {
+func (cwc *CachedWarehouseClient) GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint, variantID uint) (*WarehouseProductStockResponse, error) {
 	// PERBAIKAN 1: Gunakan cache key yang spesifik untuk product stock
-	cacheKey := cwc.generateProductStockCacheKey(warehouseID, productID)
+	cacheKey := cwc.generateProductStockCacheKey(warehouseID, productID, variantID)
 
 	var cachedWarehouseProductStock WarehouseProductStockResponse
 	err := cwc.redis.Get(ctx, cacheKey, &cachedWarehouseProductStock)
@@ -81,7 +81,7 @@ func (cwc *CachedWarehouseClient) GetWarehouseProductStock(ctx context.Context,
 	}
 
 	// Fetch dari API
-	warehouseProductStock, err := cwc.client.GetWarehouseProductStock(ctx, warehouseID, productID)
+	warehouseProductStock, err := cwc.client.GetWarehouseProductStock(ctx, warehouseID, productID, variantID)
 	if err != nil {
 		log.Errorf("[CachedWarehouse] GetWarehouseProductStock - API error: %v", err)
 		return nil, err
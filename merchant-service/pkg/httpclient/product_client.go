@@ -177,6 +177,12 @@ type ProductResponse struct {
 		Name  string `json:"name"`
 		Photo string `json:"photo"`
 	} `json:"category"`
+	BaseUnit  string                   `json:"base_unit"`
+	Variants  []ProductVariantResponse `json:"variants"`
+	Units     []ProductUnitResponse    `json:"units"`
+	// Set when a barcode lookup matched a variant or a unit barcode.
+	ScannedVariantID uint   `json:"scanned_variant_id"`
+	ScannedUnit      string `json:"scanned_unit"`
 }
 
 type ProductServiceResponse struct {
@@ -15,6 +15,7 @@ func MapProductResponseToMerchantProduct(product *ProductResponse) *response.Mer
 		ProductCategory: product.Category.Name,
 		ProductCategoryphoto: product.Category.Photo,
 		Stock: 0,
+		BaseUnit: product.BaseUnit,
 		WarehouseID: 0,
 		WarehouseName: "",
 		WarehousePhoto: "",
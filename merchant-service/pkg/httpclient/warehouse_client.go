@@ -13,9 +13,13 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
+// internalRequestHeader tells warehouse-service the call comes from this service
+// rather than from a user, so it is not refused for lacking one.
+const internalRequestHeader = "X-Internal-Request"
+
 type WarehouseClientInterface interface {
 	GetWarehouseByID(ctx context.Context, warehouseID uint) (*WarehouseResponse, error)
-	GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint) (*WarehouseProductStockResponse, error)
+	GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint, variantID uint) (*WarehouseProductStockResponse, error)
 }
 
 type WarehouseClient struct {
@@ -32,6 +36,7 @@ func (w *WarehouseClient) GetWarehouseByID(ctx context.Context, warehouseID uint
 		log.Errorf("[WarehouseClient] GetWarehouseByID - 1: %v", err)
 		return nil, err
 	}
+	req.Header.Set(internalRequestHeader, "true")
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
@@ -62,8 +67,8 @@ func (w *WarehouseClient) GetWarehouseByID(ctx context.Context, warehouseID uint
 }
 
 // GetWarehouseProductsStock implements WarehouseClientInterface.
-func (w *WarehouseClient) GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint) (*WarehouseProductStockResponse, error) {
-    url := fmt.Sprintf("%s/api/v1/warehouse-products/%d/detail/%d", w.urlWarehouseService, warehouseID, productID)
+func (w *WarehouseClient) GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint, variantID uint) (*WarehouseProductStockResponse, error) {
+    url := fmt.Sprintf("%s/api/v1/warehouse-products/%d/detail/%d?variant_id=%d", w.urlWarehouseService, warehouseID, productID, variantID)
 	var ErrWarehouseProductNotFound = errors.New("warehouse product not found")
     log.Infof("[WarehouseClient] GetWarehouseProductStock - URL: %s", url)
 
@@ -72,6 +77,7 @@ func (w *WarehouseClient) GetWarehouseProductStock(ctx context.Context, warehous
         log.Errorf("[WarehouseClient] GetWarehouseProductStock - 1: %v", err)
         return nil, err
     }
+    req.Header.Set(internalRequestHeader, "true")
 
     resp, err := w.httpClient.Do(req)
     if err != nil {
@@ -125,6 +131,7 @@ type WarehouseServiceResponse struct {
 type WarehouseProductStockResponse struct {
 	ID          uint `json:"id"`
 	ProductID   uint `json:"product_id"`
+	VariantID   uint `json:"variant_id"`
 	Stock       int  `json:"stock"`
 	WarehouseID uint `json:"warehouse_id"`
 }
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The api gateway sets these headers from the JWT and drops any value sent
+// by clients. Calls from other services come without them.
+const (
+	UserIDHeader      = "X-User-ID"
+	PermissionsHeader = "X-User-Permissions"
+)
+
+// PermissionMerchantAll lets a user reach every merchant instead of only the
+// ones they are assigned to.
+const PermissionMerchantAll = "merchant:all"
+
+// InternalRequestHeader marks calls from other services, which act for no
+// user. Only they may come without UserIDHeader.
+const InternalRequestHeader = "X-Internal-Request"
+
+// callerID returns the user the request acts for, or 0 when UserIDHeader is
+// missing or invalid.
+func callerID(c *fiber.Ctx) uint {
+	userID, err := strconv.ParseUint(c.Get(UserIDHeader), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(userID)
+}
+
+func isInternal(c *fiber.Ctx) bool {
+	return c.Get(InternalRequestHeader) == "true"
+}
+
+// RequireIdentity answers 401 to requests that carry neither a user nor the
+// mark of a call from another service. Without it ScopedUserID could not
+// tell an anonymous request from an unscoped one.
+func RequireIdentity() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if callerID(c) == 0 && !isInternal(c) {
+			return unauthorized(c)
+		}
+		return c.Next()
+	}
+}
+
+func unauthorized(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"message": "Unauthorized",
+	})
+}
+
+// ScopedUserID returns the user whose merchant assignments limit the
+// request, or 0 when the request is not limited: it comes from another
+// service or the caller holds merchant:all. It expects RequireIdentity to
+// have refused requests without either.
+func ScopedUserID(c *fiber.Ctx) uint {
+	userID := callerID(c)
+	if userID == 0 || HasPermission(c, PermissionMerchantAll) {
+		return 0
+	}
+	return userID
+}
+
+// RequireUnscoped answers 403 to callers limited to their assigned
+// merchants, for routes that are about every merchant.
+func RequireUnscoped() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if callerID(c) == 0 && !isInternal(c) {
+			return unauthorized(c)
+		}
+		if ScopedUserID(c) != 0 {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Insufficient permissions",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// HasPermission reports whether the caller holds permission.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	for _, have := range strings.Split(c.Get(PermissionsHeader), ",") {
+		if strings.TrimSpace(have) == permission {
+			return true
+		}
+	}
+	return false
+}
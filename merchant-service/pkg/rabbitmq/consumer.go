@@ -19,6 +19,8 @@ type StockReducedEvent struct {
 
 type StockReducedEventProduct struct {
 	ProductID	uint `json:"product_id"`
+	VariantID	uint `json:"variant_id"`
+	// Quantity is in base units.
 	Quantity 	int  `json:"quantity"`
 }
 
@@ -26,9 +28,10 @@ type StockConsumer struct {
 	conn 			*amqp.Connection
 	ch 				*amqp.Channel
 	merchantRepo 	repository.MerchantProductRepositoryInterface
+	lowStockThreshold int
 }
 
-func NewStockConsumer(url string, merchantRepo repository.MerchantProductRepositoryInterface) (*StockConsumer, error) {
+func NewStockConsumer(url string, merchantRepo repository.MerchantProductRepositoryInterface, lowStockThreshold int) (*StockConsumer, error) {
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		log.Errorf("[StockConsumer] NewStockConsumer - 1: %v", err)
@@ -85,10 +88,20 @@ func NewStockConsumer(url string, merchantRepo repository.MerchantProductReposit
 		return nil, err
 	}
 
+	if err := declareRealtimeExchange(ch); err != nil {
+		log.Errorf("[StockConsumer] NewStockConsumer - 6: %v", err)
+		return nil, err
+	}
+
+	if lowStockThreshold <= 0 {
+		lowStockThreshold = DefaultLowStockThreshold
+	}
+
 	return &StockConsumer{
 		conn: conn,
 		ch: ch,
 		merchantRepo: merchantRepo,
+		lowStockThreshold: lowStockThreshold,
 	}, nil
 }
 
@@ -130,7 +143,7 @@ func (sc *StockConsumer) handleStockReductionEvent(msg amqp.Delivery) error {
 	}
 
 	for _, product := range event.Products {
-		if err := sc.reduceStock(event.MerchantID, product.ProductID, product.Quantity); err != nil {
+		if err := sc.reduceStock(event.MerchantID, product.ProductID, product.VariantID, product.Quantity); err != nil {
 			log.Errorf("[StockConsumer] handleStockReductionEvent - 2: %v", err)
 			continue
 		}
@@ -141,13 +154,19 @@ func (sc *StockConsumer) handleStockReductionEvent(msg amqp.Delivery) error {
 	return nil
 }
 
-func (sc *StockConsumer) reduceStock(merchantID uint, productID uint, quantity int) error {
-	err := sc.merchantRepo.ReduceStock(context.Background(), merchantID, productID, int64(quantity))
+func (sc *StockConsumer) reduceStock(merchantID uint, productID uint, variantID uint, quantity int) error {
+	merchantProduct, err := sc.merchantRepo.ReduceStock(context.Background(), merchantID, productID, variantID, int64(quantity))
 	if err != nil {
 		log.Errorf("[StockConsumer] reduceStock - 1: %v", err)
 		return err
 	}
 
+	for _, event := range stockEvents(merchantID, merchantProduct.WarehouseID, productID, merchantProduct.Stock, sc.lowStockThreshold) {
+		if err := publishRealtimeEvent(sc.ch, event); err != nil {
+			log.Errorf("[StockConsumer] reduceStock - 2: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -17,6 +17,8 @@ type RabbitMQService struct {
 type StockReductionEvent struct {
 	WarehouseID 	uint 		`json:"warehouse_id"`
 	ProductID		uint 		`json:"product_id"`
+	VariantID		uint		`json:"variant_id"`
+	// Stock is in base units.
 	Stock 			int 		`json:"stock"`
 	MerchantID 		uint 		`json:"merchant_id"`
 	Timestamp 		time.Time 	`json:"timestamp"`
@@ -10,11 +10,13 @@ import (
 
 type MerchantRepositoryInterface interface {
 	CreateMerchant(ctx context.Context, merchant *model.Merchant) error
-	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Merchant, int64, error)
+	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string, keeperID uint) ([]model.Merchant, int64, error)
 	GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error)
 	UpdateMerchant(ctx context.Context, merchant *model.Merchant) error
 	DeleteMerchant(ctx context.Context, id uint) error
-	GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, error)
+	GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error)
+	IsMerchantKeeper(ctx context.Context, merchantID, userID uint) (bool, error)
+	ReplaceMerchantKeepers(ctx context.Context, merchantID uint, userIDs []uint) error
 }
 
 type merchantRepository struct {
@@ -39,12 +41,18 @@ func (m *merchantRepository) DeleteMerchant(ctx context.Context, id uint) error
 		log.Errorf("[MerchantRepository] DeleteMerchant - 1: %v", ctx.Err())
 		return ctx.Err()
 	default:
-		return m.db.WithContext(ctx).Delete(&model.Merchant{}, id).Error
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("merchant_id = ?", id).Delete(&model.MerchantKeeper{}).Error; err != nil {
+				log.Errorf("[MerchantRepository] DeleteMerchant - 2: %v", err)
+				return err
+			}
+			return tx.Delete(&model.Merchant{}, id).Error
+		})
 	}
 }
 
 // GetAllMerchants implements MerchantRepositoryInterface.
-func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Merchant, int64, error) {
+func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, keeperID uint) ([]model.Merchant, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantRepository] GetAllMerchants - 1: %v", ctx.Err())
@@ -77,12 +85,16 @@ func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limi
 			query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+search+"%")
 		}
 
+		if keeperID != 0 {
+			query = query.Where("id IN (?)", m.db.Model(&model.MerchantKeeper{}).Select("merchant_id").Where("user_id = ?", keeperID))
+		}
+
 		if err := query.Count(&totalRecords).Error; err != nil {
 			log.Errorf("[MerchantRepository] GetAllMerchants - 2: %v", err)
 			return nil, 0, err
 		}
 
-		if err := query.Preload("MerchantProducts").Order(sortBy + " " + sortOrder).
+		if err := query.Preload("MerchantProducts").Preload("Keepers").Order(sortBy + " " + sortOrder).
 			WithContext(ctx).
 			Offset(offset).
 			Limit(limit).
@@ -104,7 +116,7 @@ func (m *merchantRepository) GetMerchantByID(ctx context.Context, id uint) (*mod
 	default:
 		modelMerchant := model.Merchant{}
 
-		if err := m.db.WithContext(ctx).Where("id = ?", id).Preload("MerchantProducts").First(&modelMerchant).Error; err != nil {
+		if err := m.db.WithContext(ctx).Where("id = ?", id).Preload("MerchantProducts").Preload("Keepers").First(&modelMerchant).Error; err != nil {
 			log.Errorf("[MerchantRepository] GetMerchantProduct - 2: %v", err)
 			return nil, err
 		}
@@ -112,21 +124,87 @@ func (m *merchantRepository) GetMerchantByID(ctx context.Context, id uint) (*mod
 	}
 }
 
-// GetMerchantByKeeperID implements MerchantRepositoryInterface.
-func (m *merchantRepository) GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, error) {
+// GetMerchantIDsByKeeperID implements MerchantRepositoryInterface.
+func (m *merchantRepository) GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error) {
 	select {
 	case <-ctx.Done():
-		log.Errorf("[MerchantRepository] GetMerchantByKeeperID - 1: %v", ctx.Err())
+		log.Errorf("[MerchantRepository] GetMerchantIDsByKeeperID - 1: %v", ctx.Err())
 		return nil, ctx.Err()
 	default:
-		modelMerchant := model.Merchant{}
+		merchantIDs := []uint{}
 
-		if err := m.db.WithContext(ctx).Where("keeper_id = ?", keeperID).Preload("MerchantProducts").First(&modelMerchant).Error; err != nil {
-			log.Errorf("[MerchantRepository] GetMerchantByKeeperID - 2: %v", err)
+		if err := m.db.WithContext(ctx).Model(&model.MerchantKeeper{}).
+			Where("user_id = ?", keeperID).
+			Order("merchant_id").
+			Pluck("merchant_id", &merchantIDs).Error; err != nil {
+			log.Errorf("[MerchantRepository] GetMerchantIDsByKeeperID - 2: %v", err)
 			return nil, err
 		}
-		return &modelMerchant, nil
+		return merchantIDs, nil
+	}
+}
+
+// IsMerchantKeeper implements MerchantRepositoryInterface.
+func (m *merchantRepository) IsMerchantKeeper(ctx context.Context, merchantID uint, userID uint) (bool, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] IsMerchantKeeper - 1: %v", ctx.Err())
+		return false, ctx.Err()
+	default:
+		var count int64
+
+		if err := m.db.WithContext(ctx).Model(&model.MerchantKeeper{}).
+			Where("merchant_id = ? AND user_id = ?", merchantID, userID).
+			Count(&count).Error; err != nil {
+			log.Errorf("[MerchantRepository] IsMerchantKeeper - 2: %v", err)
+			return false, err
+		}
+		return count > 0, nil
+	}
+}
 
+// ReplaceMerchantKeepers implements MerchantRepositoryInterface. The first
+// user becomes the main keeper of the merchant.
+func (m *merchantRepository) ReplaceMerchantKeepers(ctx context.Context, merchantID uint, userIDs []uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] ReplaceMerchantKeepers - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			keeperID := uint(0)
+			if len(userIDs) > 0 {
+				keeperID = userIDs[0]
+			}
+
+			result := tx.Model(&model.Merchant{}).Where("id = ?", merchantID).Update("keeper_id", keeperID)
+			if result.Error != nil {
+				log.Errorf("[MerchantRepository] ReplaceMerchantKeepers - 2: %v", result.Error)
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+
+			if err := tx.Where("merchant_id = ?", merchantID).Delete(&model.MerchantKeeper{}).Error; err != nil {
+				log.Errorf("[MerchantRepository] ReplaceMerchantKeepers - 3: %v", err)
+				return err
+			}
+
+			if len(userIDs) == 0 {
+				return nil
+			}
+
+			keepers := make([]model.MerchantKeeper, 0, len(userIDs))
+			for _, userID := range userIDs {
+				keepers = append(keepers, model.MerchantKeeper{MerchantID: merchantID, UserID: userID})
+			}
+			if err := tx.Create(&keepers).Error; err != nil {
+				log.Errorf("[MerchantRepository] ReplaceMerchantKeepers - 4: %v", err)
+				return err
+			}
+			return nil
+		})
 	}
 }
 
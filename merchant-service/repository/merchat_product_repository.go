@@ -12,14 +12,16 @@ import (
 type MerchantProductRepositoryInterface interface {
 	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
 	GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, error)
-	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, productID uint) ([]model.MerchantProduct, int64, error)
-	GetMerchantProductByProductIDAndMerchantID(ctx context.Context, productID uint, merchantID uint) (*model.MerchantProduct, error)
+	// variantID filters on a variant when not nil.
+	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, productID uint, variantID *uint, keeperID uint) ([]model.MerchantProduct, int64, error)
+	GetMerchantProductByProductIDAndMerchantID(ctx context.Context, productID, variantID, merchantID uint) (*model.MerchantProduct, error)
 	UpdateMerchantProduct(ctx context.Context, merchantProuduct *model.MerchantProduct) error
 	DeleteMerchantProduct(ctx context.Context, id uint) error
 	DeleteAllProductMerchantProducts(ctx context.Context, productID uint) error
 
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
-	ReduceStock(ctx context.Context, merchantID uint, productID uint, quantity int64) error
+	GetInStockProductIDs(ctx context.Context, merchantID uint) ([]uint, error)
+	ReduceStock(ctx context.Context, merchantID, productID, variantID uint, quantity int64) (*model.MerchantProduct, error)
 }
 
 type merchantProductRepository struct {
@@ -82,14 +84,14 @@ func (m *merchantProductRepository) GetMerchantProductByID(ctx context.Context,
 }
 
 // GetMerchantProductByProductIDAndMerchantID implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) GetMerchantProductByProductIDAndMerchantID(ctx context.Context, productID uint, merchantID uint) (*model.MerchantProduct, error) {
+func (m *merchantProductRepository) GetMerchantProductByProductIDAndMerchantID(ctx context.Context, productID uint, variantID uint, merchantID uint) (*model.MerchantProduct, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[MerchantProductRepository] GetMerchantProductByProductIDAndMerchantID - 1: %v", ctx.Err())
 		return nil, ctx.Err()
 	default:
 		var merchantProduct model.MerchantProduct
-		if err := m.db.WithContext(ctx).Where("product_id = ? AND merchant_id = ?", productID, merchantID).First(&merchantProduct).Error; err != nil {
+		if err := m.db.WithContext(ctx).Where("product_id = ? AND variant_id = ? AND merchant_id = ?", productID, variantID, merchantID).First(&merchantProduct).Error; err != nil {
 			log.Errorf("[MerchantProductRepository] GetMerchantProductByProductIDAndMerchantID - 2: %v", err)
 			return nil, err
 		}
@@ -98,7 +100,7 @@ func (m *merchantProductRepository) GetMerchantProductByProductIDAndMerchantID(c
 }
 
 // GetMerchantProducts implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint) ([]model.MerchantProduct, int64, error) {
+func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint, variantID *uint, keeperID uint) ([]model.MerchantProduct, int64, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[MerchantProductRepository] GetMerchantProducts - 1: %v", ctx.Err())
@@ -135,6 +137,14 @@ func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, pag
 			query = query.Where("product_id = ?", productID)
 		}
 
+		if variantID != nil {
+			query = query.Where("variant_id = ?", *variantID)
+		}
+
+		if keeperID != 0 {
+			query = query.Where("merchant_id IN (?)", m.db.Model(&model.MerchantKeeper{}).Select("merchant_id").Where("user_id = ?", keeperID))
+		}
+
 		if err := query.Count(&totalRecords).Error; err != nil {
 			log.Errorf("[MerchantProductRepository] GetMerchantProducts - 2: %v", err)
 			return nil, 0, err
@@ -176,28 +186,56 @@ func (m *merchantProductRepository) GetProductTotalStock(ctx context.Context, pr
 	}
 }
 
+// GetInStockProductIDs implements MerchantProductRepositoryInterface. A
+// product is in stock when any of its variants is.
+func (m *merchantProductRepository) GetInStockProductIDs(ctx context.Context, merchantID uint) ([]uint, error) {
+	select {
+	case <- ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetInStockProductIDs - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		productIDs := []uint{}
+		if err := m.db.WithContext(ctx).Model(&model.MerchantProduct{}).
+		Where("merchant_id = ? AND stock > 0", merchantID).
+		Distinct().
+		Order("product_id").
+		Pluck("product_id", &productIDs).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetInStockProductIDs - 2: %v", err)
+			return nil, err
+		}
+
+		return productIDs, nil
+	}
+}
+
 // ReduceStock implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) ReduceStock(ctx context.Context, merchantID uint, productID uint, quantity int64) error {
+func (m *merchantProductRepository) ReduceStock(ctx context.Context, merchantID uint, productID uint, variantID uint, quantity int64) (*model.MerchantProduct, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 1: %v", ctx.Err())
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 		var merchantProduct model.MerchantProduct
-		err := m.db.WithContext(ctx).Where("merchant_id = ? AND product_id = ?", merchantID, productID).First(&merchantProduct).Error
+		err := m.db.WithContext(ctx).Where("merchant_id = ? AND product_id = ? AND variant_id = ?", merchantID, productID, variantID).First(&merchantProduct).Error
 		if err != nil {
 			log.Errorf("[MerchantProductRepository] ReduceStock - 2: %v", err)
-			return err
+			return nil, err
 		} 
 
 		if merchantProduct.Stock < int(quantity) {
 			log.Errorf("[MerchantProductRepository] ReduceStock - 3: %v", errors.New("stock not enough"))
-			return errors.New("stock not enough")
+			return nil, errors.New("stock not enough")
 		}
 		
 		newStock := merchantProduct.Stock - int(quantity)
 
-		return m.db.WithContext(ctx).Model(&merchantProduct).Update("stock", newStock).Error
+		if err := m.db.WithContext(ctx).Model(&merchantProduct).Update("stock", newStock).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] ReduceStock - 4: %v", err)
+			return nil, err
+		}
+
+		merchantProduct.Stock = newStock
+		return &merchantProduct, nil
 	}
 }
 
@@ -215,8 +253,9 @@ func (m *merchantProductRepository) UpdateMerchantProduct(ctx context.Context, m
 		}
 
 		existingMerchantProduct.Stock = merchantProuduct.Stock
-		existingMerchantProduct.ID = merchantProuduct.MerchantID
-		existingMerchantProduct.ProductID = merchantProuduct.ID
+		existingMerchantProduct.MerchantID = merchantProuduct.MerchantID
+		existingMerchantProduct.ProductID = merchantProuduct.ProductID
+		existingMerchantProduct.VariantID = merchantProuduct.VariantID
 		existingMerchantProduct.WarehouseID = merchantProuduct.WarehouseID
 
 		return m.db.WithContext(ctx).Save(&existingMerchantProduct).Error
@@ -12,42 +12,53 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
+// As in MerchantUsecaseInterface, keeperID limits a call to the merchants
+// that user keeps and 0 means no limit.
 type MerchantProductUsecaseInterface interface {
-	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
-	GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
-	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID uint, productID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error)
-	GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
-	UpdateMerchantProduct(ctx context.Context, merchantProuduct *model.MerchantProduct) error
-	DeleteMerchantProduct(ctx context.Context, id uint) error
+	// unit is what the stock of merchantProduct is counted in, the base unit
+	// of the product when empty. It is stored in base units.
+	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, unit string, keeperID uint) error
+	GetMerchantProductByID(ctx context.Context, id, keeperID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
+	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, productID uint, variantID *uint, keeperID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error)
+	GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID, keeperID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
+	UpdateMerchantProduct(ctx context.Context, merchantProuduct *model.MerchantProduct, unit string, keeperID uint) error
+	DeleteMerchantProduct(ctx context.Context, id, keeperID uint) error
 	DeleteAllProductMerchantProducts(ctx context.Context, productID uint) error
 
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
+	GetInStockProductIDs(ctx context.Context, merchantID uint, keeperID uint) ([]uint, error)
 }
 
 type merchantProductUsecase struct {
 	merchantProductRepo repository.MerchantProductRepositoryInterface
+	merchantRepo        repository.MerchantRepositoryInterface
 	productClient       httpclient.ProductClientInterface
 	warehouseClient     httpclient.WarehouseClientInterface
 	rabbitMQService     *rabbitmq.RabbitMQService
 }
 
 // GetMerchantProductByBarcode implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
-	product, err := m.productClient.GetProductByBarcode(ctx, barcode)
-	if err != nil {
+func (m *merchantProductUsecase) GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint, keeperID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, merchantID, keeperID); err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 1: %v", err)
 		return nil, nil, nil, err
 	}
 
-	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByProductIDAndMerchantID(ctx, product.ID, merchantID)
+	product, err := m.productClient.GetProductByBarcode(ctx, barcode)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 2: %v", err)
+		return nil, nil, nil, err
+	}
+
+	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByProductIDAndMerchantID(ctx, product.ID, product.ScannedVariantID, merchantID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 3: %v", err)
 		return nil, nil, nil, err 
 	}
 
 	warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, merchantProduct.WarehouseID)
 	if err != nil {
-		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 2: %v", err)
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 4: %v", err)
 		return nil, nil, nil, err 
 	}
 
@@ -55,33 +66,44 @@ func (m *merchantProductUsecase) GetMerchantProductByBarcode(ctx context.Context
 }
 
 // CreateMerchantProduct implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error {
-	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProduct.WarehouseID, merchantProduct.ProductID)
-	if err != nil {
+func (m *merchantProductUsecase) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, unit string, keeperID uint) error {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, merchantProduct.MerchantID, keeperID); err != nil {
 		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 1: %v", err)
 		return err
 	}
 
-	if warehouseProductStock.Stock < merchantProduct.Stock {
+	if err := m.toBaseUnits(ctx, merchantProduct, unit); err != nil {
+		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 6: %v", err)
+		return err
+	}
+
+	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProduct.WarehouseID, merchantProduct.ProductID, merchantProduct.VariantID)
+	if err != nil {
 		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 2: %v", err)
+		return err
+	}
+
+	if warehouseProductStock.Stock < merchantProduct.Stock {
+		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 3: %v", err)
 		return errors.New("stock not enough")
 	}
 
 	if err := m.merchantProductRepo.CreateMerchantProduct(ctx, merchantProduct); err != nil {
-		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 3: %v", err)
+		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 4: %v", err)
 		return err
 	}
 
 	stockReductionEvent := rabbitmq.StockReductionEvent{
 		WarehouseID: merchantProduct.WarehouseID,
 		ProductID:   merchantProduct.ProductID,
+		VariantID:   merchantProduct.VariantID,
 		Stock:       merchantProduct.Stock,
 		MerchantID:  merchantProduct.MerchantID,
 		Timestamp:   time.Now(),
 	}
 
 	if err := m.rabbitMQService.PublishStockReductionEvent(ctx, stockReductionEvent); err != nil {
-		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 3: %v", err)
+		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 5: %v", err)
 	}
 	return nil
 }
@@ -92,27 +114,43 @@ func (m *merchantProductUsecase) DeleteAllProductMerchantProducts(ctx context.Co
 }
 
 // DeleteMerchantProduct implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) DeleteMerchantProduct(ctx context.Context, id uint) error {
+func (m *merchantProductUsecase) DeleteMerchantProduct(ctx context.Context, id uint, keeperID uint) error {
+	if keeperID != 0 {
+		existing, err := m.merchantProductRepo.GetMerchantProductByID(ctx, id)
+		if err != nil {
+			log.Errorf("[MerchantProductUsecase] DeleteMerchantProduct - 1: %v", err)
+			return err
+		}
+		if err := checkMerchantKeeper(ctx, m.merchantRepo, existing.MerchantID, keeperID); err != nil {
+			log.Errorf("[MerchantProductUsecase] DeleteMerchantProduct - 2: %v", err)
+			return err
+		}
+	}
 	return m.merchantProductRepo.DeleteMerchantProduct(ctx, id)
 }
 
 // GetMerchantProductByID implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
+func (m *merchantProductUsecase) GetMerchantProductByID(ctx context.Context, id uint, keeperID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
 	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByID(ctx, id)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 1: %v", err)
 		return nil, nil, nil, err
 	}
 
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, merchantProduct.MerchantID, keeperID); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 2: %v", err)
+		return nil, nil, nil, err
+	}
+
 	product, err := m.productClient.GetProductByID(ctx, merchantProduct.ProductID)
 	if err != nil {
-		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 2: %v", err)
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 3: %v", err)
 		return nil, nil, nil, err
 	}
 
 	warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, merchantProduct.WarehouseID)
 	if err != nil {
-		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 2: %v", err)
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 4: %v", err)
 		return nil, nil, nil, err
 	}
 
@@ -121,8 +159,8 @@ func (m *merchantProductUsecase) GetMerchantProductByID(ctx context.Context, id
 }
 
 // GetMerchantProducts implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error) {
-	merchantProducts, total, err := m.merchantProductRepo.GetMerchantProducts(ctx, page, limit, search, sortBy, sortOrder, merchantID, productID)
+func (m *merchantProductUsecase) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint, variantID *uint, keeperID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error) {
+	merchantProducts, total, err := m.merchantProductRepo.GetMerchantProducts(ctx, page, limit, search, sortBy, sortOrder, merchantID, productID, variantID, keeperID)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProducts - 1: %v", err)
 		return nil, nil, nil, 0, err
@@ -152,31 +190,84 @@ func (m *merchantProductUsecase) GetMerchantProducts(ctx context.Context, page i
 	return merchantProducts, products, warehouses, total, nil
 }
 
+// GetInStockProductIDs implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetInStockProductIDs(ctx context.Context, merchantID uint, keeperID uint) ([]uint, error) {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, merchantID, keeperID); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetInStockProductIDs - 1: %v", err)
+		return nil, err
+	}
+
+	return m.merchantProductRepo.GetInStockProductIDs(ctx, merchantID)
+}
+
 // GetProductTotalStock implements MerchantProductUsecaseInterface.
 func (m *merchantProductUsecase) GetProductTotalStock(ctx context.Context, productID uint) (int, error) {
 	return m.merchantProductRepo.GetProductTotalStock(ctx, productID)
 }
 
 // UpdateMerchantProduct implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) UpdateMerchantProduct(ctx context.Context, merchantProuduct *model.MerchantProduct) error {
-	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProuduct.WarehouseID, merchantProuduct.ProductID)
+func (m *merchantProductUsecase) UpdateMerchantProduct(ctx context.Context, merchantProuduct *model.MerchantProduct, unit string, keeperID uint) error {
+	if keeperID != 0 {
+		existing, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProuduct.ID)
 		if err != nil {
-		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 1: %v", err)
+			log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 1: %v", err)
+			return err
+		}
+		for _, merchantID := range []uint{existing.MerchantID, merchantProuduct.MerchantID} {
+			if err := checkMerchantKeeper(ctx, m.merchantRepo, merchantID, keeperID); err != nil {
+				log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 2: %v", err)
+				return err
+			}
+		}
+	}
+
+	if err := m.toBaseUnits(ctx, merchantProuduct, unit); err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 5: %v", err)
+		return err
+	}
+
+	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProuduct.WarehouseID, merchantProuduct.ProductID, merchantProuduct.VariantID)
+		if err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 3: %v", err)
 		return err
 	}
 
 	if warehouseProductStock.Stock < merchantProuduct.Stock {
-		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 2: %v", err)
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 4: %v", err)
 		return errors.New("stock not enough")
 	}
 
 	return m.merchantProductRepo.UpdateMerchantProduct(ctx, merchantProuduct)
 }
 
+// toBaseUnits checks the variant belongs to the product and converts the
+// stock, counted in unit, to the base unit of the product.
+func (m *merchantProductUsecase) toBaseUnits(ctx context.Context, merchantProduct *model.MerchantProduct, unit string) error {
+	if merchantProduct.VariantID == 0 && unit == "" {
+		return nil
+	}
+
+	product, err := m.productClient.GetProductByID(ctx, merchantProduct.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := product.CheckVariant(merchantProduct.VariantID); err != nil {
+		return err
+	}
+
+	stock, err := product.BaseQuantity(unit, merchantProduct.Stock)
+	if err != nil {
+		return err
+	}
+	merchantProduct.Stock = stock
+	return nil
+}
+
 
-func NewMerchantProductUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, productClient httpclient.ProductClientInterface, warehouseClient httpclient.WarehouseClientInterface, rabbitMQService *rabbitmq.RabbitMQService) MerchantProductUsecaseInterface {
+func NewMerchantProductUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, productClient httpclient.ProductClientInterface, warehouseClient httpclient.WarehouseClientInterface, rabbitMQService *rabbitmq.RabbitMQService) MerchantProductUsecaseInterface {
 	return &merchantProductUsecase{
 		merchantProductRepo: merchantProductRepo,
+		merchantRepo:        merchantRepo,
 		productClient:       productClient,
 		warehouseClient:     warehouseClient,
 		rabbitMQService:     rabbitMQService,
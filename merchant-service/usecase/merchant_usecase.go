@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"warehouse-go/merchant-service/model"
 	"warehouse-go/merchant-service/pkg/httpclient"
 	"warehouse-go/merchant-service/repository"
@@ -9,36 +10,82 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
+// ErrMerchantNotAssigned is returned when a caller limited to their assigned
+// merchants touches another one.
+var ErrMerchantNotAssigned = errors.New("merchant is not assigned to the user")
+
+// ErrUnknownKeeper is returned when a keeper to assign does not exist.
+var ErrUnknownKeeper = errors.New("keeper does not exist")
+
+// The keeperID arguments limit a call to the merchants that user keeps, 0
+// means no limit.
 type MerchantUsecaseInterface interface {
 	CreateMerchant(ctx context.Context, merchant *model.Merchant) error
-	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Merchant, int64, error)
-	GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error)
-	UpdateMerchant(ctx context.Context, merchant *model.Merchant) error
-	DeleteMerchant(ctx context.Context, id uint) error
-	GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error)
+	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string, keeperID uint) ([]model.Merchant, int64, error)
+	GetMerchantByID(ctx context.Context, id, keeperID uint) (*model.Merchant, error)
+	UpdateMerchant(ctx context.Context, merchant *model.Merchant, keeperID uint) error
+	DeleteMerchant(ctx context.Context, id, keeperID uint) error
+	GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error)
+	ReplaceMerchantKeepers(ctx context.Context, merchantID uint, userIDs []uint) error
 	GetKeeperName(ctx context.Context, keeperID uint) (string, error)
 }
 
 type merchantUsecase struct {
-	merchantRepo    repository.MerchantRepositoryInterface
-	userClient      httpclient.UserClientInterface
-	warehouseClient httpclient.WarehouseClientInterface
-	productClient   httpclient.ProductClientInterface
+	merchantRepo repository.MerchantRepositoryInterface
+	userClient   httpclient.UserClientInterface
 }
 
-// CreateMerchant implements MerchantUsecaseInterface.
+// CreateMerchant implements MerchantUsecaseInterface. The main keeper is
+// assigned to the new merchant.
 func (m *merchantUsecase) CreateMerchant(ctx context.Context, merchant *model.Merchant) error {
+	if merchant.KeeperID != 0 {
+		merchant.Keepers = []model.MerchantKeeper{{UserID: merchant.KeeperID}}
+	}
 	return m.merchantRepo.CreateMerchant(ctx, merchant)
 }
 
 // DeleteMerchant implements MerchantUsecaseInterface.
-func (m *merchantUsecase) DeleteMerchant(ctx context.Context, id uint) error {
+func (m *merchantUsecase) DeleteMerchant(ctx context.Context, id uint, keeperID uint) error {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, id, keeperID); err != nil {
+		log.Errorf("[MerchantUsecase] DeleteMerchant - 1: %v", err)
+		return err
+	}
 	return m.merchantRepo.DeleteMerchant(ctx, id)
 }
 
 // GetAllMerchants implements MerchantUsecaseInterface.
-func (m *merchantUsecase) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Merchant, int64, error) {
-	return m.merchantRepo.GetAllMerchants(ctx, page, limit, search, sortBy, sortOrder)
+func (m *merchantUsecase) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, keeperID uint) ([]model.Merchant, int64, error) {
+	return m.merchantRepo.GetAllMerchants(ctx, page, limit, search, sortBy, sortOrder, keeperID)
+}
+
+// GetMerchantIDsByKeeperID implements MerchantUsecaseInterface.
+func (m *merchantUsecase) GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error) {
+	return m.merchantRepo.GetMerchantIDsByKeeperID(ctx, keeperID)
+}
+
+// ReplaceMerchantKeepers implements MerchantUsecaseInterface. Every user
+// must exist in user-service; duplicates are dropped.
+func (m *merchantUsecase) ReplaceMerchantKeepers(ctx context.Context, merchantID uint, userIDs []uint) error {
+	seen := make(map[uint]bool, len(userIDs))
+	keepers := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		if _, err := m.userClient.GetUserByID(ctx, userID); err != nil {
+			log.Errorf("[MerchantUsecase] ReplaceMerchantKeepers - 1: %v", err)
+			return ErrUnknownKeeper
+		}
+		keepers = append(keepers, userID)
+	}
+
+	if err := m.merchantRepo.ReplaceMerchantKeepers(ctx, merchantID, keepers); err != nil {
+		log.Errorf("[MerchantUsecase] ReplaceMerchantKeepers - 2: %v", err)
+		return err
+	}
+	return nil
 }
 
 func (m *merchantUsecase) GetKeeperName(ctx context.Context, keeperID uint) (string, error) {
@@ -73,52 +120,44 @@ func (m *merchantUsecase) GetKeeperName(ctx context.Context, keeperID uint) (str
 // }
 
 // GetMerchantByID implements MerchantUsecaseInterface.
-func (m *merchantUsecase) GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error) {
+func (m *merchantUsecase) GetMerchantByID(ctx context.Context, id uint, keeperID uint) (*model.Merchant, error) {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, id, keeperID); err != nil {
+		log.Errorf("[MerchantUsecase] GetMerchantByID - 1: %v", err)
+		return nil, err
+	}
 	return m.merchantRepo.GetMerchantByID(ctx, id)
 }
 
-// GetMerchantByKeeperID implements MerchantUsecaseInterface.
-func (m *merchantUsecase) GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error) {
-	merchant, err := m.merchantRepo.GetMerchantByKeeperID(ctx, keeperID)
-	if err != nil {
-		log.Errorf("[MerchantUsecase] GetMerchantByKeeperID - 1: %v", err)
-		return nil, nil, nil, err
+// UpdateMerchant implements MerchantUsecaseInterface.
+func (m *merchantUsecase) UpdateMerchant(ctx context.Context, merchant *model.Merchant, keeperID uint) error {
+	if err := checkMerchantKeeper(ctx, m.merchantRepo, merchant.ID, keeperID); err != nil {
+		log.Errorf("[MerchantUsecase] UpdateMerchant - 1: %v", err)
+		return err
 	}
+	return m.merchantRepo.UpdateMerchant(ctx, merchant)
+}
 
-	var products []httpclient.ProductResponse
-	var warehouses []httpclient.WarehouseResponse
-
-	if len(merchant.MerchantProducts) > 0 {
-		for _, mp := range merchant.MerchantProducts {
-			product, err := m.productClient.GetProductByID(ctx, mp.ProductID)
-			if err != nil {
-				log.Errorf("[MerchantUsecase] GetMerchantByID - 2: %v", err)
-				return nil, nil, nil, err
-			}
-			products = append(products, *product)
-
-			warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, mp.WarehouseID)
-			if err != nil {
-				log.Errorf("[MerchantUsecase] GetMerchantByID - 3: %v", err)
-				return nil, nil, nil, err
-			}
-			warehouses = append(warehouses, *warehouse)
-		}
+// checkMerchantKeeper returns ErrMerchantNotAssigned unless keeperID is 0 or
+// keeps the merchant.
+func checkMerchantKeeper(ctx context.Context, merchantRepo repository.MerchantRepositoryInterface, merchantID, keeperID uint) error {
+	if keeperID == 0 {
+		return nil
 	}
-	return merchant, products, warehouses, nil
-}
 
-// UpdateMerchant implements MerchantUsecaseInterface.
-func (m *merchantUsecase) UpdateMerchant(ctx context.Context, merchant *model.Merchant) error {
-	return m.merchantRepo.UpdateMerchant(ctx, merchant)
+	assigned, err := merchantRepo.IsMerchantKeeper(ctx, merchantID, keeperID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrMerchantNotAssigned
+	}
+	return nil
 }
 
-func NewMerchantUsecase(merchantRepo repository.MerchantRepositoryInterface, userClient httpclient.UserClientInterface, warehouseClient httpclient.WarehouseClientInterface, productClient httpclient.ProductClientInterface) MerchantUsecaseInterface {
+func NewMerchantUsecase(merchantRepo repository.MerchantRepositoryInterface, userClient httpclient.UserClientInterface) MerchantUsecaseInterface {
 	return &merchantUsecase{
-		merchantRepo:    merchantRepo,
-		userClient:      userClient,
-		warehouseClient: warehouseClient,
-		productClient:   productClient,
+		merchantRepo: merchantRepo,
+		userClient:   userClient,
 	}
 }
 
@@ -56,6 +56,13 @@ func RunServer() {
 	
 	BuildContainer(rabbitMQService, emailService)
 
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "notification-service",
+		})
+	})
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
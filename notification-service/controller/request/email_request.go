@@ -5,10 +5,3 @@ type SendEmailRequest struct {
 	Subject	string `json:"subject" validate:"required"`
 	Body 	string `json:"body" validate:"required"`
 } 
-
-type SendWelcomeEmailRequest struct {
-	Email string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-	UserID uint `json:"user_id" validate:"required"`
-	Name string `json:"name" validate:"required"`
-}
\ No newline at end of file
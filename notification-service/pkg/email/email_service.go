@@ -12,16 +12,21 @@ import (
 )
 
 type EmailServiceInterface interface {
-	SendWelcomeEmail(ctx context.Context, payload EmailPayload) error
+	SendInvitationEmail(ctx context.Context, payload EmailPayload) error
 	SendCustomEmail(ctx context.Context, to, subject, body string) error
+	SendAccountLockedEmail(ctx context.Context, payload EmailPayload) error
+	SendPasswordResetEmail(ctx context.Context, payload EmailPayload) error
 }
 
 type EmailPayload struct {
 	Email    string `json:"email"`
-	Password string `json:"password"`
 	Type     string `json:"type"`
 	UserID   uint   `json:"user_id"`
 	Name     string `json:"name"`
+	LockedUntil string `json:"locked_until,omitempty"`
+	ResetLink   string `json:"reset_link,omitempty"`
+	InvitationLink string `json:"invitation_link,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
 }
 
 type emailService struct {
@@ -38,7 +43,7 @@ func (e *emailService) SendCustomEmail(ctx context.Context, to string, subject s
 	m.SetHeader("From", e.cfg.Email.From)
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
-	m.SetHeader("text/html", body)
+	m.SetBody("text/html", body)
 
 	d := gomail.NewDialer(e.cfg.Email.Host, e.cfg.Email.Port, e.cfg.Email.User, e.cfg.Email.Password)
 
@@ -50,20 +55,20 @@ func (e *emailService) SendCustomEmail(ctx context.Context, to string, subject s
 	return nil
 }
 
-// SendWelcomeEmail implements EmailServiceInterface.
-func (e *emailService) SendWelcomeEmail(ctx context.Context, payload EmailPayload) error {
-	subject := "Selamat Datang di Warehouse Management System"
-	
+// SendInvitationEmail implements EmailServiceInterface.
+func (e *emailService) SendInvitationEmail(ctx context.Context, payload EmailPayload) error {
+	subject := "Undangan ke Warehouse Management System"
+
 	htmlTemplate := `
 		<!DOCTYPE html>
 		<html>
 		<head>
 			<meta charset="UTF-8">
-			<title>Selamat Datang</title>
+			<title>Undangan</title>
 			<style>
-				body { font-family: Arial, sans-serif: line-height: 1.6; color: #333; }
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
 				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { backgroun-color: #4CAF50; color: white; padding: 20px; text-align:center; }
+				.header { background-color: #4CAF50; color: white; padding: 20px; text-align:center; }
 				.content { padding: 20px; background-color: #f9f9f9; }
 				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
 				.button { display: inline-block; padding: 10px 20px; background-color: #4CAF50; color: white; text-decoration: none; border-radius: 5px; }
@@ -76,10 +81,10 @@ func (e *emailService) SendWelcomeEmail(ctx context.Context, payload EmailPayloa
 				</div>
 				<div class="content">
 					<h2> Halo {{.Name}},</h2>
-					<p> Selamat datang di Warehouse Management System! Akun anda telah berhasil dibuat.</p>
-					<p><strong>Email:</strong> {{.Email}}</p>
-					<p><strong>Password:</strong> {{.Password}}</p>
-					<p>Silahkan login dengan kredensial di atas dan jangan lupa untuk mengganti password Anda setelah login pertama kali.<p>
+					<p>Akun Warehouse Management System untuk <strong>{{.Email}}</strong> telah dibuat.</p>
+					<p>Klik tombol di bawah ini untuk mengaktifkan akun dan membuat password Anda.</p>
+					<p><a class="button" href="{{.InvitationLink}}">Aktifkan Akun</a></p>
+					<p>Link ini hanya dapat digunakan satu kali dan berlaku hingga <strong>{{.ExpiresAt}}</strong>. Jika sudah kedaluwarsa, minta Manager untuk mengirim ulang undangan.</p>
 					<br>
 					<p>Terima Kasih telah bergabung dengan kami!</p>
 				</div>
@@ -87,25 +92,133 @@ func (e *emailService) SendWelcomeEmail(ctx context.Context, payload EmailPayloa
 					<p>Email ini dikirim otomatis, mohon tidak membalas email ini.</p>
 				</div>
 			</div>
-		<body>
-		<html>`
-	tmpl, err := template.New("welcome").Parse(htmlTemplate)
+		</body>
+		</html>`
+	tmpl, err := template.New("invitation").Parse(htmlTemplate)
 	if err != nil {
-		log.Errorf("[EmailService] SendWelcomeEmail - 1: %v", err)
+		log.Errorf("[EmailService] SendInvitationEmail - 1: %v", err)
 		return fmt.Errorf("failed to parse email template: %v", err)
 	}
 
 	var body strings.Builder
-	err = tmpl.Execute(&body, payload)
+	if err := tmpl.Execute(&body, payload); err != nil {
+		log.Errorf("[EmailService] SendInvitationEmail - 2: %v", err)
+		return fmt.Errorf("failed to execute email template: %v", err)
+	}
+
+	if err := e.SendCustomEmail(ctx, payload.Email, subject, body.String()); err != nil {
+		log.Errorf("[EmailService] SendInvitationEmail - 3: %v", err)
+		return fmt.Errorf("failed to send invitation email: %v", err)
+	}
+	return nil
+}
+
+// SendAccountLockedEmail implements EmailServiceInterface.
+func (e *emailService) SendAccountLockedEmail(ctx context.Context, payload EmailPayload) error {
+	subject := "Akun Warehouse Management System Anda Dikunci Sementara"
+
+	htmlTemplate := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Akun Dikunci</title>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #E53935; color: white; padding: 20px; text-align:center; }
+				.content { padding: 20px; background-color: #f9f9f9; }
+				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>AKUN DIKUNCI</h1>
+				</div>
+				<div class="content">
+					<h2> Halo {{.Name}},</h2>
+					<p>Kami mendeteksi terlalu banyak percobaan login yang gagal pada akun <strong>{{.Email}}</strong>.</p>
+					<p>Untuk keamanan, akun Anda dikunci sementara hingga <strong>{{.LockedUntil}}</strong>.</p>
+					<p>Jika ini bukan Anda, segera hubungi Manager untuk membuka kunci akun dan mengganti password Anda.</p>
+				</div>
+				<div class="footer">
+					<p>Email ini dikirim otomatis, mohon tidak membalas email ini.</p>
+				</div>
+			</div>
+		</body>
+		</html>`
+	tmpl, err := template.New("account_locked").Parse(htmlTemplate)
 	if err != nil {
-		log.Errorf("[EmailService] SendWelcomeEmail - 2: %v", err)
+		log.Errorf("[EmailService] SendAccountLockedEmail - 1: %v", err)
+		return fmt.Errorf("failed to parse email template: %v", err)
+	}
+
+	var body strings.Builder
+	if err := tmpl.Execute(&body, payload); err != nil {
+		log.Errorf("[EmailService] SendAccountLockedEmail - 2: %v", err)
 		return fmt.Errorf("failed to execute email template: %v", err)
 	}
 
-	err = e.SendCustomEmail(ctx, payload.Email, subject, body.String())
+	if err := e.SendCustomEmail(ctx, payload.Email, subject, body.String()); err != nil {
+		log.Errorf("[EmailService] SendAccountLockedEmail - 3: %v", err)
+		return fmt.Errorf("failed to send account locked email: %v", err)
+	}
+	return nil
+}
+
+// SendPasswordResetEmail implements EmailServiceInterface.
+func (e *emailService) SendPasswordResetEmail(ctx context.Context, payload EmailPayload) error {
+	subject := "Reset Password Warehouse Management System"
+
+	htmlTemplate := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Reset Password</title>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #1E88E5; color: white; padding: 20px; text-align:center; }
+				.content { padding: 20px; background-color: #f9f9f9; }
+				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
+				.button { display: inline-block; padding: 10px 20px; background-color: #1E88E5; color: white; text-decoration: none; border-radius: 5px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>RESET PASSWORD</h1>
+				</div>
+				<div class="content">
+					<h2> Halo {{.Name}},</h2>
+					<p>Kami menerima permintaan untuk mereset password akun <strong>{{.Email}}</strong>.</p>
+					<p><a class="button" href="{{.ResetLink}}">Reset Password</a></p>
+					<p>Link ini hanya dapat digunakan satu kali dan berlaku hingga <strong>{{.ExpiresAt}}</strong>.</p>
+					<p>Jika Anda tidak meminta reset password, abaikan email ini. Password Anda tidak akan berubah.</p>
+				</div>
+				<div class="footer">
+					<p>Email ini dikirim otomatis, mohon tidak membalas email ini.</p>
+				</div>
+			</div>
+		</body>
+		</html>`
+	tmpl, err := template.New("password_reset").Parse(htmlTemplate)
 	if err != nil {
-		log.Errorf("[EmailService] SendWelcomeEmail - 3: %v", err)
-		return fmt.Errorf("failed to send welcome email: %v", err)
+		log.Errorf("[EmailService] SendPasswordResetEmail - 1: %v", err)
+		return fmt.Errorf("failed to parse email template: %v", err)
+	}
+
+	var body strings.Builder
+	if err := tmpl.Execute(&body, payload); err != nil {
+		log.Errorf("[EmailService] SendPasswordResetEmail - 2: %v", err)
+		return fmt.Errorf("failed to execute email template: %v", err)
+	}
+
+	if err := e.SendCustomEmail(ctx, payload.Email, subject, body.String()); err != nil {
+		log.Errorf("[EmailService] SendPasswordResetEmail - 3: %v", err)
+		return fmt.Errorf("failed to send password reset email: %v", err)
 	}
 	return nil
 }
@@ -65,8 +65,12 @@ func (r *rabbitMQService) ConsumeEmail(ctx context.Context, emailService email.E
 				//Process email based on type
 				var err error
 				switch emailPayload.Type {
-				case "welcome", "welcome_email":
-					err = emailService.SendWelcomeEmail(ctx, emailPayload)
+				case "invitation":
+					err = emailService.SendInvitationEmail(ctx, emailPayload)
+				case "account_locked":
+					err = emailService.SendAccountLockedEmail(ctx, emailPayload)
+				case "password_reset":
+					err = emailService.SendPasswordResetEmail(ctx, emailPayload)
 				default:
 					log.Errorf("[RabbitMQService] ConsumeEmail - 3: %s", "unknown email type")
 					msg.Nack(false, false)
@@ -17,15 +17,3 @@ func NewEmailUsecase(emailService email.EmailServiceInterface) *EmailUseCase {
 func (u *EmailUseCase) SendEmail(ctx context.Context, req request.SendEmailRequest) error {
 	return u.emailService.SendCustomEmail(ctx, req.To, req.Subject, req.Body)
 }
-
-func (u *EmailUseCase) SendWelcomeEmail(ctx context.Context, req request.SendWelcomeEmailRequest) error {
-	payload := email.EmailPayload{
-		Email: req.Email,
-		Password: req.Password,
-		Type: "welcome",
-		UserID: req.UserID,
-		Name: req.Name,
-	}
-
-	return u.emailService.SendWelcomeEmail(ctx, payload)
-}
\ No newline at end of file
@@ -38,6 +38,10 @@ func RunServer() {
 	container := BuildContainer()
 	SetupRoutes(app, container)
 
+	importCtx, stopImports := context.WithCancel(context.Background())
+	defer stopImports()
+	go container.ProductImportUsecase.RunImports(importCtx)
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
@@ -1,21 +1,31 @@
 package app
 
 import (
+	"context"
 	"warehouse-go/product-service/configs"
 	"warehouse-go/product-service/controller"
 	"warehouse-go/product-service/database"
+	"warehouse-go/product-service/pkg/httpclient"
+	"warehouse-go/product-service/pkg/rabbitmq"
 	"warehouse-go/product-service/pkg/storage"
 	"warehouse-go/product-service/repository"
 	"warehouse-go/product-service/usecase"
+	"warehouse-go/shared/audit"
 
 	"github.com/gofiber/fiber/v2/log"
 )
 
 type Container struct {
 	ProductController controller.ProductControllerInterface
+	ProductVariantController controller.ProductVariantControllerInterface
+	ProductUnitController controller.ProductUnitControllerInterface
+	ProductImportController controller.ProductImportControllerInterface
 	CategoryController controller.CategoryControllerInterface
 	UploadController controller.UploadControllerInterface
+	AuditTracker *audit.Tracker
 
+	// ProductImportUsecase runs the queued catalog imports, see RunServer.
+	ProductImportUsecase usecase.ProductImportUsecaseInterface
 }
 
 func BuildContainer() *Container {
@@ -24,22 +34,66 @@ func BuildContainer() *Container {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if !config.SqlDB.SkipMigrations {
+		if err := database.Migrate(context.Background(), db.DB); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	//RabbitMQ Client
+	rabbitMQService, err := rabbitmq.NewRabbitMQService(config.RabitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
 
 	categoryRepo := repository.NewCategoryRepository(db.DB)
-	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, rabbitMQService)
 	categoryController := controller.NewCategoryController(categoryUsecase)
 
 	productRepo := repository.NewProductRepository(db.DB)
-	productUsecase := usecase.NewProductUsecase(productRepo)
+	variantRepo := repository.NewProductVariantRepository(db.DB)
+	unitRepo := repository.NewProductUnitRepository(db.DB)
+	merchantClient := httpclient.NewMerchantClient(*config)
+	productUsecase := usecase.NewProductUsecase(productRepo, variantRepo, unitRepo, rabbitMQService, merchantClient)
 	productController := controller.NewProductController(productUsecase)
 
+	variantUsecase := usecase.NewProductVariantUsecase(productRepo, variantRepo, unitRepo, rabbitMQService)
+	variantController := controller.NewProductVariantController(variantUsecase)
+	unitUsecase := usecase.NewProductUnitUsecase(productRepo, variantRepo, unitRepo, rabbitMQService)
+	unitController := controller.NewProductUnitController(unitUsecase)
+
 	supabaseStorage := storage.NewSupabaseStorage(*config)
 	fileUploadHelper := storage.NewUploadFileHelper(supabaseStorage, *config)
 	uploadController := controller.NewUploadController(fileUploadHelper)
 
+	importJobRepo := repository.NewProductImportJobRepository(db.DB)
+	importUsecase := usecase.NewProductImportUsecase(importJobRepo, productRepo, categoryRepo, productUsecase, categoryUsecase, fileUploadHelper)
+	importController := controller.NewProductImportController(importUsecase)
+
+	auditPublisher, err := audit.NewPublisher(config.RabitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
+
+	auditTracker := audit.NewTracker("product-service", auditPublisher)
+	auditTracker.Register("product", func(ctx context.Context, id uint) (interface{}, error) {
+		return productRepo.GetProductByID(ctx, id)
+	})
+	auditTracker.Register("category", func(ctx context.Context, id uint) (interface{}, error) {
+		return categoryRepo.GetCategoryByID(ctx, id)
+	})
+	auditTracker.Register("product-import", func(ctx context.Context, id uint) (interface{}, error) {
+		return importJobRepo.GetJobByID(ctx, id)
+	})
+
 	return &Container{
 		ProductController: productController,
+		ProductVariantController: variantController,
+		ProductUnitController: unitController,
 		CategoryController: categoryController,
 		UploadController: uploadController,
+		AuditTracker: auditTracker,
+		ProductImportController: importController,
+		ProductImportUsecase: importUsecase,
 	}
 }
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"warehouse-go/product-service/controller/request"
+	"warehouse-go/product-service/controller/response"
+	"warehouse-go/product-service/pkg/openapi"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "Product Service",
+	Version:     "1.0.0",
+	Description: "Product catalogue and categories",
+}
+
+// openAPIOperations documents the request and response types of the routes
+// registered in SetupRoutes.
+var openAPIOperations = []openapi.Operation{
+	{Method: "POST", Path: "/api/v1/categories", Summary: "Create a category", Tags: []string{"categories"}, Request: request.CreateCategoryRequest{}},
+	{Method: "GET", Path: "/api/v1/categories", Summary: "List categories", Tags: []string{"categories"}, Query: request.GetAllCategoryRequest{}, Response: response.GetAllCategoryResponse{}},
+	{Method: "GET", Path: "/api/v1/categories/:id", Summary: "Get a category", Tags: []string{"categories"}, Response: response.CategoryResponse{}},
+	{Method: "PUT", Path: "/api/v1/categories/:id", Summary: "Update a category", Tags: []string{"categories"}, Request: request.CreateCategoryRequest{}},
+	{Method: "DELETE", Path: "/api/v1/categories/:id", Summary: "Delete a category", Tags: []string{"categories"}},
+
+	{Method: "POST", Path: "/api/v1/products", Summary: "Create a product", Tags: []string{"products"}, Request: request.CreateProductRequest{}},
+	{Method: "GET", Path: "/api/v1/products", Summary: "Search products with filters and category facets", Tags: []string{"products"}, Query: request.GetAllProductRequest{}, Response: response.GetAllProductResponse{}},
+	{Method: "POST", Path: "/api/v1/products/imports", Summary: "Queue an import of a CSV or XLSX catalog with name, barcode, category_tagline and price columns, upserting by barcode", Tags: []string{"products"}, Upload: true, UploadField: "file", Response: response.ProductImportJobResponse{}},
+	{Method: "GET", Path: "/api/v1/products/imports/:id", Summary: "Get the progress and row errors of an import", Tags: []string{"products"}, Response: response.ProductImportJobResponse{}},
+	{Method: "GET", Path: "/api/v1/products/export", Summary: "Download the whole catalog as CSV or XLSX", Tags: []string{"products"}, Query: request.ExportProductsRequest{}},
+	{Method: "GET", Path: "/api/v1/products/:id", Summary: "Get a product", Tags: []string{"products"}, Response: response.ProductResponse{}},
+	{Method: "GET", Path: "/api/v1/products/barcode/:barcode", Summary: "Get a product by barcode", Tags: []string{"products"}, Response: response.ProductResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id", Summary: "Update a product", Tags: []string{"products"}, Request: request.CreateProductRequest{}},
+	{Method: "DELETE", Path: "/api/v1/products/:id", Summary: "Delete a product", Tags: []string{"products"}},
+	{Method: "GET", Path: "/api/v1/products/:id/variants", Summary: "List the variants of a product", Tags: []string{"products"}, Response: []response.ProductVariantResponse{}},
+	{Method: "POST", Path: "/api/v1/products/:id/variants", Summary: "Add a variant to a product", Tags: []string{"products"}, Request: request.ProductVariantRequest{}, Response: response.ProductVariantResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id/variants/:variantId", Summary: "Update a variant", Tags: []string{"products"}, Request: request.ProductVariantRequest{}, Response: response.ProductVariantResponse{}},
+	{Method: "DELETE", Path: "/api/v1/products/:id/variants/:variantId", Summary: "Delete a variant", Tags: []string{"products"}},
+	{Method: "GET", Path: "/api/v1/products/:id/units", Summary: "List the units of measure of a product", Tags: []string{"products"}, Response: []response.ProductUnitResponse{}},
+	{Method: "POST", Path: "/api/v1/products/:id/units", Summary: "Add a unit of measure to a product", Tags: []string{"products"}, Request: request.ProductUnitRequest{}, Response: response.ProductUnitResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id/units/:unitId", Summary: "Update a unit of measure", Tags: []string{"products"}, Request: request.ProductUnitRequest{}, Response: response.ProductUnitResponse{}},
+	{Method: "DELETE", Path: "/api/v1/products/:id/units/:unitId", Summary: "Delete a unit of measure", Tags: []string{"products"}},
+
+	{Method: "POST", Path: "/api/v1/upload/product", Summary: "Upload a product image", Tags: []string{"upload"}, Upload: true, Response: response.UploadResponse{}},
+	{Method: "POST", Path: "/api/v1/upload/category-image", Summary: "Upload a category image", Tags: []string{"upload"}, Upload: true, Response: response.UploadResponse{}},
+}
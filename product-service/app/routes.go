@@ -1,26 +1,53 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"warehouse-go/product-service/pkg/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, container *Container) {
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "product-service",
+		})
+	})
+	app.Get("/openapi.json", openapi.Handler(app, openAPIInfo, openAPIOperations))
+
 	api := app.Group("/api/v1")
 	categories := api.Group("/categories")
 	products := api.Group("/products")
 	uploads := api.Group("/upload")
+	track := container.AuditTracker
 
-	categories.Post("/", container.CategoryController.CreateCategory)
+	categories.Post("/", track.Track("category", ""), container.CategoryController.CreateCategory)
 	categories.Get("/", container.CategoryController.GetAllCategories)
 	categories.Get("/:id", container.CategoryController.GetCategoryByID)
-	categories.Put("/:id", container.CategoryController.UpdateCategory)
-	categories.Delete("/:id", container.CategoryController.DeleteCategory)
+	categories.Put("/:id", track.Track("category", "id"), container.CategoryController.UpdateCategory)
+	categories.Delete("/:id", track.Track("category", "id"), container.CategoryController.DeleteCategory)
 
 
-	products.Post("/", container.ProductController.CreateProduct)
+	products.Post("/", track.Track("product", ""), container.ProductController.CreateProduct)
 	products.Get("/", container.ProductController.GetAllProducts)
+	// The products of an import are audited as the import job.
+	products.Post("/imports", track.Track("product-import", ""), container.ProductImportController.ImportProducts)
+	products.Get("/imports/:id", container.ProductImportController.GetImportJob)
+	products.Get("/export", container.ProductImportController.ExportProducts)
 	products.Get("/:id", container.ProductController.GetProductByID)
 	products.Get("/barcode/:barcode", container.ProductController.GetProductByBarcode)
-	products.Put("/:id", container.ProductController.UpdateProduct)
-	products.Delete("/:id", container.ProductController.DeleteProduct)
+	products.Put("/:id", track.Track("product", "id"), container.ProductController.UpdateProduct)
+	products.Delete("/:id", track.Track("product", "id"), container.ProductController.DeleteProduct)
+
+	// Variants and units are part of the product, changes are audited on it.
+	products.Get("/:id/variants", container.ProductVariantController.GetVariants)
+	products.Post("/:id/variants", track.Track("product", "id"), container.ProductVariantController.CreateVariant)
+	products.Put("/:id/variants/:variantId", track.Track("product", "id"), container.ProductVariantController.UpdateVariant)
+	products.Delete("/:id/variants/:variantId", track.Track("product", "id"), container.ProductVariantController.DeleteVariant)
+	products.Get("/:id/units", container.ProductUnitController.GetUnits)
+	products.Post("/:id/units", track.Track("product", "id"), container.ProductUnitController.CreateUnit)
+	products.Put("/:id/units/:unitId", track.Track("product", "id"), container.ProductUnitController.UpdateUnit)
+	products.Delete("/:id/units/:unitId", track.Track("product", "id"), container.ProductUnitController.DeleteUnit)
 
 	uploads.Post("/product", container.UploadController.UploadProductImage )
 	uploads.Post("/category-image", container.UploadController.UploadCategoryImage)
@@ -1,6 +1,10 @@
 package configs
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
 
 type App struct {
 	AppPort string `json:"app_port"`
@@ -18,6 +22,9 @@ type SqlDB struct {
 	DBname   string `json:"db_name"`
 	DBMaxOpenConns	int    `json:"db_max_open_conns"`
 	DBMaxIdleConns	int    `json:"db_max_idle_conns"`
+	// SkipMigrations leaves migrating to the migrate command instead of
+	// applying pending migrations on start.
+	SkipMigrations bool `json:"skip_migrations"`
 }
 
 type Redis struct {
@@ -32,6 +39,10 @@ type RabbitMQ struct {
 	Password string `json:"password"`
 }
 
+func (r *RabbitMQ) URL() string {
+	return fmt.Sprintf("amqp://%s:%s@%s:%s/", r.Username, r.Password, r.Host, r.Port)
+}
+
 type Supabase struct {
 	Url string `json:"url"`
 	Key string `json:"key"`
@@ -62,6 +73,7 @@ func NewConfig() *Config {
 			DBname:   viper.GetString("DATABASE_NAME"),
 			DBMaxIdleConns: viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			DBMaxOpenConns: viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
+			SkipMigrations: viper.GetBool("DATABASE_SKIP_MIGRATIONS"),
 
 	},
 		RabitMQ: RabbitMQ{
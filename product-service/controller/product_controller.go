@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/product-service/controller/request"
 	"warehouse-go/product-service/controller/response"
 	"warehouse-go/product-service/model"
 	"warehouse-go/product-service/pkg/conv"
+	"warehouse-go/product-service/pkg/httpclient"
 	"warehouse-go/product-service/pkg/pagination"
 	"warehouse-go/product-service/pkg/validator"
+	"warehouse-go/product-service/repository"
 	"warehouse-go/product-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
@@ -51,13 +54,12 @@ func (p *productController) CreateProduct(ctx *fiber.Ctx) error {
 		About: req.About,
 		Price: float64(req.Price),
 		IsPopular: req.IsPopular,
+		BaseUnit: req.BaseUnit,
 	}
 
 	if err := p.productUsecase.CreateProduct(ctx.Context(), &reqModel); err != nil {
 		log.Errorf("[ProductController] CreateProduct - 3: %v", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message" : "Failed to create product.",
-		})
+		return catalogError(ctx, err, "Failed to create product.")
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -92,6 +94,35 @@ func (p *productController) GetAllProducts(ctx *fiber.Ctx) error {
 		})
 	}
 
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[ProductController] GetAllProducts - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	filter := repository.ProductFilter{
+		Search: req.Search,
+		CategoryID: req.CategoryID,
+		MinPrice: req.MinPrice,
+		MaxPrice: req.MaxPrice,
+		IsPopular: req.IsPopular,
+	}
+
+	if req.IDs != "" {
+		ids, err := conv.StringToUints(req.IDs)
+		if err != nil || len(ids) > request.MaxIDs {
+			log.Errorf("[ProductController] GetAllProducts - 3: invalid ids %q", req.IDs)
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : "ids must be a comma separated list of at most 100 ids",
+			})
+		}
+		filter.ProductIDs = ids
+		if req.Limit == 0 {
+			req.Limit = len(ids)
+		}
+	}
+
 	if req.Page <= 0 {
 		req.Page = 1
 	}
@@ -100,9 +131,19 @@ func (p *productController) GetAllProducts(ctx *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	products, total, err := p.productUsecase.GetAllProducts(ctx.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	caller := httpclient.Caller{
+		UserID: ctx.Get(httpclient.UserIDHeader),
+		Permissions: ctx.Get(httpclient.PermissionsHeader),
+	}
+
+	products, total, facets, err := p.productUsecase.GetAllProducts(ctx.Context(), req.Page, req.Limit, filter, req.MerchantID, caller, req.SortBy, req.SortOrder)
 	if err != nil {
-		log.Errorf("[ProductController] GetAllProducts - 1: %v", err)
+		log.Errorf("[ProductController] GetAllProducts - 4: %v", err)
+		if errors.Is(err, httpclient.ErrMerchantNotAssigned) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get all products.",
 		})
@@ -120,12 +161,35 @@ func (p *productController) GetAllProducts(ctx *fiber.Ctx) error {
 			About			: product.About,
 			Price			: int(product.Price),
 			IsPopular		: product.IsPopular,
+			Category		: response.CategoryResponse{
+				ID			: product.Category.ID,
+				Name		: product.Category.Name,
+				Tagline		: product.Category.Tagline,
+				Photo		: product.Category.Photo,
+			},
+			BaseUnit		: product.BaseUnit,
+		})
+	}
+
+	categoryFacets := []response.CategoryFacetResponse{}
+	for _, facet := range facets {
+		categoryFacets = append(categoryFacets, response.CategoryFacetResponse{
+			ID				: facet.CategoryID,
+			Name			: facet.CategoryName,
+			Count			: facet.Count,
 		})
 	}
 
 	response := response.GetAllProductResponse {
 		Products : productsResponse,
 		Pagination: pagination,
+		Facets: response.ProductFacetsResponse{Categories: categoryFacets},
+	}
+
+	// Stock changes do not invalidate the gateway cache, so a list filtered
+	// by merchant stock must not be stored there.
+	if req.MerchantID != 0 {
+		ctx.Set(fiber.HeaderCacheControl, "no-store")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -142,9 +206,7 @@ func (p *productController) GetProductByBarcode(ctx *fiber.Ctx) error {
 	product, err := p.productUsecase.GetProductByBarcode(ctx.Context(), barcode)
 	if err != nil {
 		log.Errorf("[ProductController] GetProductByBarcode - 1: %v", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to get product by barcode.",
-		})
+		return catalogError(ctx, err, "Failed to get product by barcode.")
 	}
 
 	response:= response.ProductResponse{
@@ -162,6 +224,11 @@ func (p *productController) GetProductByBarcode(ctx *fiber.Ctx) error {
 			Tagline	: product.Category.Tagline,
 			Photo 	: product.Category.Photo,
 		},
+		BaseUnit	: product.BaseUnit,
+		Variants	: variantResponses(product.Variants),
+		Units		: unitResponses(product.Units),
+		ScannedVariantID : product.ScannedVariantID,
+		ScannedUnit : product.ScannedUnit,
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -198,6 +265,11 @@ func (p *productController) GetProductByID(ctx *fiber.Ctx) error {
 			Tagline	: product.Category.Tagline,
 			Photo 	: product.Category.Photo,
 		},
+		BaseUnit	: product.BaseUnit,
+		Variants	: variantResponses(product.Variants),
+		Units		: unitResponses(product.Units),
+		ScannedVariantID : product.ScannedVariantID,
+		ScannedUnit : product.ScannedUnit,
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -235,13 +307,12 @@ func (p *productController) UpdateProduct(ctx *fiber.Ctx) error {
 		About		: req.About,
 		Price		: float64(req.Price),
 		IsPopular	: req.IsPopular,
+		BaseUnit	: req.BaseUnit,
 	}
 
 	if err := p.productUsecase.UpdateProduct(ctx.Context(), &reqModel); err != nil {
 		log.Errorf("[ProductController] UpdateProduct - 3: %v", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message" : "Failed to update product",
-		})
+		return catalogError(ctx, err, "Failed to update product")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
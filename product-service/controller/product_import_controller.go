@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"warehouse-go/product-service/controller/request"
+	"warehouse-go/product-service/controller/response"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/conv"
+	"warehouse-go/product-service/pkg/validator"
+	"warehouse-go/product-service/usecase"
+	"warehouse-go/shared/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ProductImportControllerInterface interface {
+	ImportProducts(ctx *fiber.Ctx) error
+	GetImportJob(ctx *fiber.Ctx) error
+	ExportProducts(ctx *fiber.Ctx) error
+}
+
+type productImportController struct {
+	importUsecase usecase.ProductImportUsecaseInterface
+}
+
+// ImportProducts implements ProductImportControllerInterface. The file is
+// imported in the background; the answer is the queued job, to be followed
+// with GetImportJob.
+func (p *productImportController) ImportProducts(ctx *fiber.Ctx) error {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		log.Errorf("[ProductImportController] ImportProducts - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Failed to get file",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Errorf("[ProductImportController] ImportProducts - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Failed to read file",
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Errorf("[ProductImportController] ImportProducts - 3: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Failed to read file",
+		})
+	}
+
+	createdBy := conv.StringToUint(ctx.Get(audit.UserIDHeader))
+	job, err := p.importUsecase.CreateImportJob(ctx.Context(), fileHeader.Filename, data, createdBy)
+	if err != nil {
+		log.Errorf("[ProductImportController] ImportProducts - 4: %v", err)
+		if errors.Is(err, usecase.ErrInvalidImportFile) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : "Failed to import products",
+		})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message" : fmt.Sprintf("Import of %d rows queued", job.TotalRows),
+		"data" : importJobResponse(*job),
+	})
+}
+
+// GetImportJob implements ProductImportControllerInterface.
+func (p *productImportController) GetImportJob(ctx *fiber.Ctx) error {
+	job, err := p.importUsecase.GetImportJob(ctx.Context(), conv.StringToUint(ctx.Params("id")))
+	if err != nil {
+		log.Errorf("[ProductImportController] GetImportJob - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message" : "Import not found",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : "Failed to get import",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Import fetched successfully",
+		"data" : importJobResponse(*job),
+	})
+}
+
+// ExportProducts implements ProductImportControllerInterface. The file can
+// be edited and sent back to ImportProducts.
+func (p *productImportController) ExportProducts(ctx *fiber.Ctx) error {
+	var req request.ExportProductsRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		log.Errorf("[ProductImportController] ExportProducts - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[ProductImportController] ExportProducts - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+	if req.Format == "" {
+		req.Format = model.ImportFormatCSV
+	}
+
+	var buf bytes.Buffer
+	if err := p.importUsecase.ExportProducts(ctx.Context(), &buf, req.Format); err != nil {
+		log.Errorf("[ProductImportController] ExportProducts - 3: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message" : "Failed to export products",
+		})
+	}
+
+	if req.Format == model.ImportFormatXLSX {
+		ctx.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	ctx.Attachment(fmt.Sprintf("products-%s.%s", time.Now().Format("20060102"), req.Format))
+	return ctx.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+func importJobResponse(job model.ProductImportJob) response.ProductImportJobResponse {
+	jobResponse := response.ProductImportJobResponse{
+		ID				: job.ID,
+		Status			: job.Status,
+		FileName		: job.FileName,
+		Format			: job.Format,
+		CreatedBy		: job.CreatedBy,
+		TotalRows		: job.TotalRows,
+		ProcessedRows	: job.ProcessedRows,
+		Created			: job.CreatedCount,
+		Updated			: job.UpdatedCount,
+		Failed			: job.FailedCount,
+		Errors			: []response.ProductImportRowError{},
+		Error			: job.Error,
+		CreatedAt		: job.CreatedAt,
+		StartedAt		: job.StartedAt,
+		FinishedAt		: job.FinishedAt,
+	}
+	for _, rowError := range job.RowErrors {
+		jobResponse.Errors = append(jobResponse.Errors, response.ProductImportRowError{
+			Row		: rowError.Row,
+			Barcode	: rowError.Barcode,
+			Errors	: rowError.Errors,
+		})
+	}
+	return jobResponse
+}
+
+func NewProductImportController(importUsecase usecase.ProductImportUsecaseInterface) ProductImportControllerInterface {
+	return &productImportController{
+		importUsecase: importUsecase,
+	}
+}
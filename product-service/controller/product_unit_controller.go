@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"warehouse-go/product-service/controller/request"
+	"warehouse-go/product-service/controller/response"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/conv"
+	"warehouse-go/product-service/pkg/validator"
+	"warehouse-go/product-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type ProductUnitControllerInterface interface {
+	CreateUnit(ctx *fiber.Ctx) error
+	GetUnits(ctx *fiber.Ctx) error
+	UpdateUnit(ctx *fiber.Ctx) error
+	DeleteUnit(ctx *fiber.Ctx) error
+}
+
+type productUnitController struct {
+	unitUsecase usecase.ProductUnitUsecaseInterface
+}
+
+// CreateUnit implements ProductUnitControllerInterface.
+func (p *productUnitController) CreateUnit(ctx *fiber.Ctx) error {
+	var req request.ProductUnitRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[ProductUnitController] CreateUnit - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Invalid body request",
+		})
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		log.Errorf("[ProductUnitController] CreateUnit - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	unit := model.ProductUnit{
+		ProductID	: conv.StringToUint(ctx.Params("id")),
+		Name		: req.Name,
+		Factor		: req.Factor,
+		Barcode		: req.Barcode,
+	}
+
+	if err := p.unitUsecase.CreateUnit(ctx.Context(), &unit); err != nil {
+		log.Errorf("[ProductUnitController] CreateUnit - 3: %v", err)
+		return catalogError(ctx, err, "Failed to create unit")
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message" : "Unit created successfully",
+		"data" : unitResponse(unit),
+	})
+}
+
+// GetUnits implements ProductUnitControllerInterface.
+func (p *productUnitController) GetUnits(ctx *fiber.Ctx) error {
+	units, err := p.unitUsecase.GetUnits(ctx.Context(), conv.StringToUint(ctx.Params("id")))
+	if err != nil {
+		log.Errorf("[ProductUnitController] GetUnits - 1: %v", err)
+		return catalogError(ctx, err, "Failed to get units")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Units fetched successfully",
+		"data" : unitResponses(units),
+	})
+}
+
+// UpdateUnit implements ProductUnitControllerInterface.
+func (p *productUnitController) UpdateUnit(ctx *fiber.Ctx) error {
+	var req request.ProductUnitRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[ProductUnitController] UpdateUnit - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Invalid body request",
+		})
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		log.Errorf("[ProductUnitController] UpdateUnit - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	unit := model.ProductUnit{
+		ID			: conv.StringToUint(ctx.Params("unitId")),
+		ProductID	: conv.StringToUint(ctx.Params("id")),
+		Name		: req.Name,
+		Factor		: req.Factor,
+		Barcode		: req.Barcode,
+	}
+
+	if err := p.unitUsecase.UpdateUnit(ctx.Context(), &unit); err != nil {
+		log.Errorf("[ProductUnitController] UpdateUnit - 3: %v", err)
+		return catalogError(ctx, err, "Failed to update unit")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Unit updated successfully",
+		"data" : unitResponse(unit),
+	})
+}
+
+// DeleteUnit implements ProductUnitControllerInterface.
+func (p *productUnitController) DeleteUnit(ctx *fiber.Ctx) error {
+	productID := conv.StringToUint(ctx.Params("id"))
+	unitID := conv.StringToUint(ctx.Params("unitId"))
+
+	if err := p.unitUsecase.DeleteUnit(ctx.Context(), productID, unitID); err != nil {
+		log.Errorf("[ProductUnitController] DeleteUnit - 1: %v", err)
+		return catalogError(ctx, err, "Failed to delete unit")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Unit deleted successfully",
+	})
+}
+
+func unitResponse(unit model.ProductUnit) response.ProductUnitResponse {
+	return response.ProductUnitResponse{
+		ID			: unit.ID,
+		ProductID	: unit.ProductID,
+		Name		: unit.Name,
+		Factor		: unit.Factor,
+		Barcode		: unit.Barcode,
+	}
+}
+
+func unitResponses(units []model.ProductUnit) []response.ProductUnitResponse {
+	responses := make([]response.ProductUnitResponse, 0, len(units))
+	for _, unit := range units {
+		responses = append(responses, unitResponse(unit))
+	}
+	return responses
+}
+
+func NewProductUnitController(unitUsecase usecase.ProductUnitUsecaseInterface) ProductUnitControllerInterface {
+	return &productUnitController{unitUsecase: unitUsecase}
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"warehouse-go/product-service/controller/request"
+	"warehouse-go/product-service/controller/response"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/conv"
+	"warehouse-go/product-service/pkg/validator"
+	"warehouse-go/product-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ProductVariantControllerInterface interface {
+	CreateVariant(ctx *fiber.Ctx) error
+	GetVariants(ctx *fiber.Ctx) error
+	UpdateVariant(ctx *fiber.Ctx) error
+	DeleteVariant(ctx *fiber.Ctx) error
+}
+
+type productVariantController struct {
+	variantUsecase usecase.ProductVariantUsecaseInterface
+}
+
+// CreateVariant implements ProductVariantControllerInterface.
+func (p *productVariantController) CreateVariant(ctx *fiber.Ctx) error {
+	var req request.ProductVariantRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[ProductVariantController] CreateVariant - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Invalid body request",
+		})
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		log.Errorf("[ProductVariantController] CreateVariant - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	variant := model.ProductVariant{
+		ProductID	: conv.StringToUint(ctx.Params("id")),
+		SKU			: req.SKU,
+		Barcode		: req.Barcode,
+		Price		: float64(req.Price),
+		Attributes	: req.Attributes,
+	}
+
+	if err := p.variantUsecase.CreateVariant(ctx.Context(), &variant); err != nil {
+		log.Errorf("[ProductVariantController] CreateVariant - 3: %v", err)
+		return catalogError(ctx, err, "Failed to create variant")
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message" : "Variant created successfully",
+		"data" : variantResponse(variant),
+	})
+}
+
+// GetVariants implements ProductVariantControllerInterface.
+func (p *productVariantController) GetVariants(ctx *fiber.Ctx) error {
+	variants, err := p.variantUsecase.GetVariants(ctx.Context(), conv.StringToUint(ctx.Params("id")))
+	if err != nil {
+		log.Errorf("[ProductVariantController] GetVariants - 1: %v", err)
+		return catalogError(ctx, err, "Failed to get variants")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Variants fetched successfully",
+		"data" : variantResponses(variants),
+	})
+}
+
+// UpdateVariant implements ProductVariantControllerInterface.
+func (p *productVariantController) UpdateVariant(ctx *fiber.Ctx) error {
+	var req request.ProductVariantRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[ProductVariantController] UpdateVariant - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Invalid body request",
+		})
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		log.Errorf("[ProductVariantController] UpdateVariant - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	variant := model.ProductVariant{
+		ID			: conv.StringToUint(ctx.Params("variantId")),
+		ProductID	: conv.StringToUint(ctx.Params("id")),
+		SKU			: req.SKU,
+		Barcode		: req.Barcode,
+		Price		: float64(req.Price),
+		Attributes	: req.Attributes,
+	}
+
+	if err := p.variantUsecase.UpdateVariant(ctx.Context(), &variant); err != nil {
+		log.Errorf("[ProductVariantController] UpdateVariant - 3: %v", err)
+		return catalogError(ctx, err, "Failed to update variant")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Variant updated successfully",
+		"data" : variantResponse(variant),
+	})
+}
+
+// DeleteVariant implements ProductVariantControllerInterface.
+func (p *productVariantController) DeleteVariant(ctx *fiber.Ctx) error {
+	productID := conv.StringToUint(ctx.Params("id"))
+	variantID := conv.StringToUint(ctx.Params("variantId"))
+
+	if err := p.variantUsecase.DeleteVariant(ctx.Context(), productID, variantID); err != nil {
+		log.Errorf("[ProductVariantController] DeleteVariant - 1: %v", err)
+		return catalogError(ctx, err, "Failed to delete variant")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message" : "Variant deleted successfully",
+	})
+}
+
+// catalogError answers the errors products, variants and units share, and
+// message with a 500 for anything else.
+func catalogError(ctx *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message" : "Not found",
+		})
+	case errors.Is(err, usecase.ErrBarcodeTaken), errors.Is(err, usecase.ErrSKUTaken), errors.Is(err, usecase.ErrUnitNameTaken):
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	case errors.Is(err, usecase.ErrUnitIsBase), errors.Is(err, usecase.ErrUnitFactor):
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message" : message,
+	})
+}
+
+func variantResponse(variant model.ProductVariant) response.ProductVariantResponse {
+	attributes := map[string]string(variant.Attributes)
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+
+	return response.ProductVariantResponse{
+		ID			: variant.ID,
+		ProductID	: variant.ProductID,
+		SKU			: variant.SKU,
+		Barcode		: variant.Barcode,
+		Price		: int(variant.Price),
+		Attributes	: attributes,
+	}
+}
+
+func variantResponses(variants []model.ProductVariant) []response.ProductVariantResponse {
+	responses := make([]response.ProductVariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		responses = append(responses, variantResponse(variant))
+	}
+	return responses
+}
+
+func NewProductVariantController(variantUsecase usecase.ProductVariantUsecaseInterface) ProductVariantControllerInterface {
+	return &productVariantController{variantUsecase: variantUsecase}
+}
@@ -8,12 +8,32 @@ type CreateProductRequest struct {
 	CategoryID 	uint 	`json:"category_id" validate:"required"`
 	Thumbnail 	string 	`json:"thumbnail" validate:"required"`
 	IsPopular 	bool	`json:"is_popular"` 
+	// BaseUnit is what stock of the product is counted in, pcs when empty.
+	BaseUnit 	string 	`json:"base_unit"`
 }
 
+// MaxIDs is how many products one ids filter may list.
+const MaxIDs = 100
+
+// GetAllProductRequest searches the catalog. Without sort_by a search is
+// sorted by relevance. merchant_id keeps the products that merchant has in
+// stock. ids, a comma separated list, keeps the listed products and loads
+// them all on one page unless limit says otherwise.
 type GetAllProductRequest struct {
 	Page 		int 	`query:"page"`
 	Limit		int 	`query:"limit"`
 	Search  	string 	`query:"search"`
-	SortBy		string 	`query:"sort_by"`
-	SortOrder 	string 	`query:"sort_order"` 
+	SortBy		string 	`query:"sort_by" validate:"omitempty,oneof=id name price created_at"`
+	SortOrder 	string 	`query:"sort_order" validate:"omitempty,oneof=asc desc"` 
+	CategoryID 	uint 	`query:"category_id"`
+	MinPrice 	*int 	`query:"min_price"`
+	MaxPrice 	*int 	`query:"max_price"`
+	IsPopular 	*bool 	`query:"is_popular"`
+	MerchantID 	uint 	`query:"merchant_id"`
+	IDs 		string 	`query:"ids"`
+}
+
+// ExportProductsRequest picks the file format of an export, csv when empty.
+type ExportProductsRequest struct {
+	Format 		string 	`query:"format" validate:"omitempty,oneof=csv xlsx"`
 }
@@ -0,0 +1,16 @@
+package request
+
+type ProductVariantRequest struct {
+	SKU        string            `json:"sku" validate:"required"`
+	Barcode    string            `json:"barcode"`
+	Price      int               `json:"price" validate:"required"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type ProductUnitRequest struct {
+	Name string `json:"name" validate:"required"`
+	// Factor is the number of base units in one unit, e.g. 24 for a carton
+	// of 24 pcs.
+	Factor  int    `json:"factor" validate:"required"`
+	Barcode string `json:"barcode"`
+}
@@ -0,0 +1,29 @@
+package response
+
+import "time"
+
+// ProductImportJobResponse is the state of an import. Counts grow while the
+// job runs; errors lists the rows that were skipped.
+type ProductImportJobResponse struct {
+	ID            uint                     `json:"id"`
+	Status        string                   `json:"status"`
+	FileName      string                   `json:"file_name"`
+	Format        string                   `json:"format"`
+	CreatedBy     uint                     `json:"created_by"`
+	TotalRows     int                      `json:"total_rows"`
+	ProcessedRows int                      `json:"processed_rows"`
+	Created       int                      `json:"created"`
+	Updated       int                      `json:"updated"`
+	Failed        int                      `json:"failed"`
+	Errors        []ProductImportRowError  `json:"errors"`
+	Error         string                   `json:"error,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+	StartedAt     *time.Time               `json:"started_at"`
+	FinishedAt    *time.Time               `json:"finished_at"`
+}
+
+type ProductImportRowError struct {
+	Row     int      `json:"row"`
+	Barcode string   `json:"barcode"`
+	Errors  []string `json:"errors"`
+}
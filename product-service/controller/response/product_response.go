@@ -12,9 +12,28 @@ type ProductResponse struct {
 	Thumbnail    string `json:"thumbail"`
 	IsPopular    bool   `json:"thubmnail"`
 	Category     CategoryResponse `json:"category"`
+	BaseUnit     string `json:"base_unit"`
+	Variants     []ProductVariantResponse `json:"variants,omitempty"`
+	Units        []ProductUnitResponse `json:"units,omitempty"`
+	// Set when a barcode lookup matched a variant or a unit.
+	ScannedVariantID uint   `json:"scanned_variant_id,omitempty"`
+	ScannedUnit      string `json:"scanned_unit,omitempty"`
 }
 
 type GetAllProductResponse struct {
 	Products   	[]ProductResponse 				`json:"products"`
 	Pagination	 pagination.PaginationResponse 	`json:"pagination"`
+	Facets		 ProductFacetsResponse			`json:"facets"`
+}
+
+type ProductFacetsResponse struct {
+	Categories	[]CategoryFacetResponse	`json:"categories"`
+}
+
+// CategoryFacetResponse counts the products of a search in a category,
+// whatever category_id filter the search had.
+type CategoryFacetResponse struct {
+	ID		uint	`json:"id"`
+	Name	string	`json:"name"`
+	Count	int64	`json:"count"`
 }
@@ -0,0 +1,18 @@
+package response
+
+type ProductVariantResponse struct {
+	ID         uint              `json:"id"`
+	ProductID  uint              `json:"product_id"`
+	SKU        string            `json:"sku"`
+	Barcode    string            `json:"barcode"`
+	Price      int               `json:"price"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type ProductUnitResponse struct {
+	ID        uint   `json:"id"`
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	Factor    int    `json:"factor"`
+	Barcode   string `json:"barcode"`
+}
@@ -0,0 +1,31 @@
+-- The schema AutoMigrate created before any of the later changes, which
+-- follow in their own migrations. Every statement is conditional, so
+-- databases that already have it adopt this migration unchanged.
+
+CREATE TABLE IF NOT EXISTS "categories" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"tagline" varchar(100),
+	"photo" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_categories_tagline" ON "categories" ("tagline");
+
+CREATE TABLE IF NOT EXISTS "products" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"barcode" varchar(100),
+	"category_id" bigint,
+	"thumbnail" text,
+	"about" text,
+	"price" decimal NOT NULL,
+	"is_popular" boolean DEFAULT false,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_categories_products" FOREIGN KEY ("category_id") REFERENCES "categories"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_products_barcode" ON "products" ("barcode");
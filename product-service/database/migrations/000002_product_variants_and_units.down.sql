@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "product_units";
+DROP TABLE IF EXISTS "product_variants";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "base_unit";
@@ -0,0 +1,35 @@
+-- Variants are sold and stocked on their own, units convert quantities to
+-- the base unit stock is kept in. A barcode may be empty, only barcodes
+-- that are set have to be unique.
+
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "base_unit" varchar(50) NOT NULL DEFAULT 'pcs';
+
+CREATE TABLE "product_variants" (
+	"id" bigserial,
+	"product_id" bigint NOT NULL,
+	"sku" varchar(100) NOT NULL,
+	"barcode" varchar(100) NOT NULL DEFAULT '',
+	"price" decimal NOT NULL,
+	"attributes" jsonb NOT NULL DEFAULT '{}',
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_products_variants" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
+CREATE INDEX "idx_product_variants_product_id" ON "product_variants" ("product_id");
+CREATE UNIQUE INDEX "idx_product_variants_sku" ON "product_variants" ("sku");
+CREATE UNIQUE INDEX "idx_product_variants_barcode" ON "product_variants" ("barcode") WHERE "barcode" <> '';
+
+CREATE TABLE "product_units" (
+	"id" bigserial,
+	"product_id" bigint NOT NULL,
+	"name" varchar(50) NOT NULL,
+	"factor" integer NOT NULL CHECK ("factor" > 1),
+	"barcode" varchar(100) NOT NULL DEFAULT '',
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_products_units" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX "idx_product_units_name" ON "product_units" ("product_id", lower("name"));
+CREATE UNIQUE INDEX "idx_product_units_barcode" ON "product_units" ("barcode") WHERE "barcode" <> '';
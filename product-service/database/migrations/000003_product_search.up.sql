@@ -0,0 +1,17 @@
+-- Product search matches whole words of the name and about with full-text
+-- search, and partial or misspelled names and barcode prefixes with the
+-- indexes below. The 'simple' configuration does no stemming, names are
+-- not in one language.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "search_vector" tsvector
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce("name", '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce("about", '')), 'B')
+	) STORED;
+
+CREATE INDEX IF NOT EXISTS "idx_products_search_vector" ON "products" USING gin ("search_vector");
+CREATE INDEX IF NOT EXISTS "idx_products_name_trgm" ON "products" USING gin ("name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_products_barcode_prefix" ON "products" ("barcode" varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products" ("category_id");
@@ -0,0 +1,2 @@
+ALTER TABLE "products" DROP COLUMN IF EXISTS "thumbnail_source";
+DROP TABLE IF EXISTS "product_import_jobs";
@@ -0,0 +1,28 @@
+-- A catalog import runs in the background. The job keeps the uploaded file
+-- until it is done, so a job cut short by a restart is picked up again.
+
+CREATE TABLE IF NOT EXISTS "product_import_jobs" (
+	"id" bigserial,
+	"status" varchar(20) NOT NULL DEFAULT 'pending',
+	"file_name" varchar(255) NOT NULL DEFAULT '',
+	"format" varchar(10) NOT NULL,
+	"file" bytea,
+	"created_by" bigint NOT NULL DEFAULT 0,
+	"total_rows" bigint NOT NULL DEFAULT 0,
+	"processed_rows" bigint NOT NULL DEFAULT 0,
+	"created_count" bigint NOT NULL DEFAULT 0,
+	"updated_count" bigint NOT NULL DEFAULT 0,
+	"failed_count" bigint NOT NULL DEFAULT 0,
+	"row_errors" jsonb NOT NULL DEFAULT '[]',
+	"error" text NOT NULL DEFAULT '',
+	"started_at" timestamptz,
+	"finished_at" timestamptz,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_import_jobs_status" ON "product_import_jobs" ("status", "id");
+
+-- Where an import downloaded the thumbnail from, importing the same file
+-- again does not download it again.
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "thumbnail_source" text NOT NULL DEFAULT '';
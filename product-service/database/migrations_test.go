@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+	"warehouse-go/product-service/model"
+	"warehouse-go/shared/migrate/migratetest"
+
+	"gorm.io/gorm"
+)
+
+func upDown(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migratetest.UpDown(t, db, migrator)
+}
+
+func TestMigrationsOnEmptySchema(t *testing.T) {
+	upDown(t, migratetest.Schema(t))
+}
+
+// Databases from before the migrations were built by AutoMigrate at start
+// up, the migrations adopt them.
+func TestMigrationsOnAutoMigrateSchema(t *testing.T) {
+	db := migratetest.Schema(t)
+	if err := db.AutoMigrate(&model.Category{}, &model.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	upDown(t, db)
+}
@@ -4,6 +4,8 @@ go 1.24.4
 
 require (
 	github.com/spf13/viper v1.21.0
+	github.com/streadway/amqp v1.1.0
+	github.com/xuri/excelize/v2 v2.9.1
 	gorm.io/gorm v1.31.0
 )
 
@@ -19,7 +21,13 @@ require (
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
+	github.com/richardlehane/mscfb v1.0.4 // indirect
+	github.com/richardlehane/msoleps v1.0.4 // indirect
+	github.com/tiendc/go-deepcopy v1.6.0 // indirect
+	github.com/xuri/efp v0.0.1 // indirect
+	github.com/xuri/nfp v0.0.1 // indirect
 	golang.org/x/crypto v0.42.0 // indirect
+	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/sync v0.17.0 // indirect
 )
 
@@ -52,4 +60,7 @@ require (
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 	gorm.io/driver/postgres v1.6.0
+	warehouse-go/shared v0.0.0
 )
+
+replace warehouse-go/shared => ../shared
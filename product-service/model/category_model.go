@@ -11,4 +11,11 @@ type Category struct {
 	UpdatedAt 	*time.Time   `json:"updated_at"`
 
 	Products 	[]Product	 `json:"products" gorm:"foreignKey:CategoryID"`
-}
\ No newline at end of file
+}
+
+// CategoryFacet is how many products of a search fall in a category.
+type CategoryFacet struct {
+	CategoryID   uint   `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Count        int64  `json:"count"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+)
+
+// ProductImportJob is a catalog file being imported in the background. A
+// completed job may still have rows that failed, listed in RowErrors; a
+// failed job could not read its file at all, Error tells why.
+type ProductImportJob struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	Status        string          `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	FileName      string          `json:"file_name" gorm:"type:varchar(255);not null;default:''"`
+	Format        string          `json:"format" gorm:"type:varchar(10);not null"`
+	File          []byte          `json:"-" gorm:"type:bytea"`
+	CreatedBy     uint            `json:"created_by" gorm:"not null;default:0"`
+	TotalRows     int             `json:"total_rows" gorm:"not null;default:0"`
+	ProcessedRows int             `json:"processed_rows" gorm:"not null;default:0"`
+	CreatedCount  int             `json:"created_count" gorm:"not null;default:0"`
+	UpdatedCount  int             `json:"updated_count" gorm:"not null;default:0"`
+	FailedCount   int             `json:"failed_count" gorm:"not null;default:0"`
+	RowErrors     ImportRowErrors `json:"row_errors" gorm:"type:jsonb;not null;default:'[]'"`
+	Error         string          `json:"error" gorm:"type:text;not null;default:''"`
+	StartedAt     *time.Time      `json:"started_at"`
+	FinishedAt    *time.Time      `json:"finished_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     *time.Time      `json:"updated_at"`
+}
+
+// ImportRowError is why a row of an import was skipped. Row is the line of
+// the file, counting the header.
+type ImportRowError struct {
+	Row     int      `json:"row"`
+	Barcode string   `json:"barcode"`
+	Errors  []string `json:"errors"`
+}
+
+type ImportRowErrors []ImportRowError
+
+// Value implements driver.Valuer.
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+// Scan implements sql.Scanner.
+func (e *ImportRowErrors) Scan(src interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*e = ImportRowErrors{}
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return errors.New("import row errors must be json")
+	}
+	return json.Unmarshal(data, e)
+}
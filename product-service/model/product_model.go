@@ -1,9 +1,13 @@
 package model
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
+// DefaultBaseUnit is the base unit of a product that does not name one.
+const DefaultBaseUnit = "pcs"
 
 type Product struct {
 	ID        	uint    	 `json:"id" gorm:"primaryKey"`
@@ -11,12 +15,38 @@ type Product struct {
 	Barcode   	string    	 `json:"barcode" gorm:"type:varchar(100);uniqueIndex"`
 	CategoryID  uint		 `json:"category_id"`
 	Thumbnail	string		 `json:"thubmnail"`
+	// ThumbnailSource is the url an import downloaded Thumbnail from, so the
+	// next import of the same file skips the download. Empty otherwise.
+	ThumbnailSource string	 `json:"-" gorm:"type:text;not null;default:''"`
 	About		string		 `json:"about" gorm:"type:text"`
 	Price		float64		 `json:"price" gorm:"not null"`
 	IsPopular 	bool		 `json:"is_popular" gorm:"default:false"`
+	BaseUnit	string		 `json:"base_unit" gorm:"type:varchar(50);not null;default:pcs"`
 	CreatedAt 	time.Time 	 `json:"created_at"`
 	UpdatedAt 	*time.Time   `json:"updated_at"`
 	DeletedAt 	*time.Time	 `json:"deleted_at"`	
 
 	Category 	Category	  `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Variants	[]ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	Units		[]ProductUnit	 `json:"units,omitempty" gorm:"foreignKey:ProductID"`
+
+	// Set by a barcode lookup that matched a variant or unit barcode rather
+	// than the product's own.
+	ScannedVariantID uint	 `json:"-" gorm:"-"`
+	ScannedUnit 	 string	 `json:"-" gorm:"-"`
+}
+
+// UnitFactor returns how many base units one unit holds. The base unit
+// itself, or an empty name, is 1.
+func (p Product) UnitFactor(unit string) (int, error) {
+	unit = strings.TrimSpace(unit)
+	if unit == "" || strings.EqualFold(unit, p.BaseUnit) {
+		return 1, nil
+	}
+	for _, productUnit := range p.Units {
+		if strings.EqualFold(productUnit.Name, unit) {
+			return productUnit.Factor, nil
+		}
+	}
+	return 0, fmt.Errorf("product %d has no unit %q", p.ID, unit)
 }
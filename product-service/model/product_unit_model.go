@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ProductUnit is a packaging a product is counted in besides its base unit,
+// e.g. a carton of 24 pcs has Factor 24. Stock is always kept in base units,
+// a unit only converts quantities.
+type ProductUnit struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	ProductID uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_product_units_name"`
+	Name      string     `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_product_units_name"`
+	Factor    int        `json:"factor" gorm:"not null"`
+	Barcode   string     `json:"barcode" gorm:"type:varchar(100)"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ProductVariant is a version of a product, like a size or a color, sold
+// under its own SKU, barcode and price. Stock of a variant is kept apart
+// from the stock of the product and its other variants.
+type ProductVariant struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	ProductID  uint              `json:"product_id" gorm:"not null;index"`
+	SKU        string            `json:"sku" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Barcode    string            `json:"barcode" gorm:"type:varchar(100)"`
+	Price      float64           `json:"price" gorm:"not null"`
+	Attributes VariantAttributes `json:"attributes" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  *time.Time        `json:"updated_at"`
+}
+
+// VariantAttributes are what sets a variant apart, e.g. size=XL, color=red.
+type VariantAttributes map[string]string
+
+// Value implements driver.Valuer.
+func (a VariantAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	value, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+// Scan implements sql.Scanner.
+func (a *VariantAttributes) Scan(src interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*a = VariantAttributes{}
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return errors.New("variant attributes must be json")
+	}
+	return json.Unmarshal(data, a)
+}
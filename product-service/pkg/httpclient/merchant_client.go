@@ -1,11 +1,44 @@
 package httpclient
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 	"warehouse-go/product-service/configs"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// internalRequestHeader tells merchant-service the call comes from this service
+// rather than from a user, so it is not refused for lacking one.
+const internalRequestHeader = "X-Internal-Request"
+
+// The api gateway sets these headers on the requests it forwards.
+const (
+	UserIDHeader      = "X-User-ID"
+	PermissionsHeader = "X-User-Permissions"
 )
 
+// ErrMerchantNotAssigned is returned when merchant-service refuses the caller
+// a merchant they are not assigned to.
+var ErrMerchantNotAssigned = errors.New("caller is not assigned to this merchant")
+
+// Caller is the user a request acts for, as the api gateway sent them. It is
+// passed on to merchant-service so it limits the answer to their merchants.
+// The zero Caller stands for this service itself.
+type Caller struct {
+	UserID      string
+	Permissions string
+}
+
+type MerchantClientInterface interface {
+	GetInStockProductIDs(ctx context.Context, merchantID uint, caller Caller) ([]uint, error)
+}
+
 type MerchantClient struct {
 	urlMerchantService string
 	httpClient         *http.Client
@@ -22,6 +55,60 @@ type MerchantProductServiceResponse struct {
 	Error	string `json:"error,omitempty"`
 }
 
+// GetInStockProductIDs implements MerchantClientInterface.
+func (m *MerchantClient) GetInStockProductIDs(ctx context.Context, merchantID uint, caller Caller) ([]uint, error) {
+	url := fmt.Sprintf("%s/api/v1/merchant-products/merchant/%d/in-stock", m.urlMerchantService, merchantID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetInStockProductIDs - 1: %v", err)
+		return nil, err
+	}
+	req.Header.Set(internalRequestHeader, "true")
+	if caller.UserID != "" {
+		req.Header.Set(UserIDHeader, caller.UserID)
+		req.Header.Set(PermissionsHeader, caller.Permissions)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetInStockProductIDs - 2: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetInStockProductIDs - 3: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, ErrMerchantNotAssigned
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[MerchantClient] GetInStockProductIDs - 4: Status=%d, Body=%s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to get in stock products: status=%d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data []uint `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		log.Errorf("[MerchantClient] GetInStockProductIDs - 5: %v", err)
+		return nil, err
+	}
+
+	if response.Data == nil {
+		response.Data = []uint{}
+	}
+
+	return response.Data, nil
+}
+
 func NewMerchantClient(cfg configs.Config) *MerchantClient {
 	return &MerchantClient{
 		httpClient: &http.Client{
@@ -30,5 +117,3 @@ func NewMerchantClient(cfg configs.Config) *MerchantClient {
 		urlMerchantService: cfg.App.UrlMerchantService,
 	}
 }
-
-
@@ -0,0 +1,116 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+// CatalogExchange carries product and category changes. The routing key is
+// the event type, e.g. product.updated; the api gateway uses them to drop
+// cached catalog responses.
+const CatalogExchange = "catalog_events"
+
+const (
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+type CatalogEvent struct {
+	Type       string    `json:"type"`
+	ProductID  uint      `json:"product_id,omitempty"`
+	CategoryID uint      `json:"category_id,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// CatalogPublisher is implemented by RabbitMQService. The usecases depend on
+// it rather than on the connection.
+type CatalogPublisher interface {
+	PublishCatalogEvent(event CatalogEvent) error
+}
+
+type RabbitMQService struct {
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
+	conn, err := amqp.Dial(rabbitMQUrl)
+	if err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 2: %v", err)
+		return nil, err
+	}
+
+	err = ch.ExchangeDeclare(
+		CatalogExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 3: %v", err)
+		return nil, err
+	}
+
+	return &RabbitMQService{
+		conn: conn,
+		ch:   ch,
+	}, nil
+}
+
+// PublishCatalogEvent is fire and forget: a lost event only means a cached
+// response lives until its TTL runs out.
+func (r *RabbitMQService) PublishCatalogEvent(event CatalogEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishCatalogEvent - 1: %v", err)
+		return err
+	}
+
+	err = r.ch.Publish(
+		CatalogExchange,
+		event.Type,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+			Timestamp:   event.Timestamp,
+		},
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishCatalogEvent - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RabbitMQService) Close() error {
+	if r.ch != nil {
+		r.ch.Close()
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
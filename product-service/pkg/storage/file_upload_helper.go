@@ -2,10 +2,19 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 	"warehouse-go/product-service/configs"
 
 
@@ -18,15 +27,41 @@ const (
 	AllowedImageExtensions = ".jpg, .jpeg, .png, .webp, .svg"
 )
 
+// ErrPrivateAddress is returned for an image URL that points into the
+// network the service runs in.
+var ErrPrivateAddress = errors.New("image url points to a private address")
+
 type FileUploadHelper struct {
 	storage SupabaseInterface
 	cfg     configs.Config
+	// downloader fetches images named by URL. It refuses private addresses,
+	// so an import cannot reach the other services.
+	downloader *http.Client
 }
 
 func NewUploadFileHelper(storage SupabaseInterface, cfg configs.Config) *FileUploadHelper {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+
 	return &FileUploadHelper{
 		storage: storage, 
 		cfg: cfg,
+		downloader: &http.Client{
+			Timeout: 20 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
 	}
 }
 
@@ -46,6 +81,79 @@ func (h *FileUploadHelper) UploadPhoto(ctx context.Context, file *multipart.File
 
 }
 
+// IsStoredURL tells whether imageURL is already in our storage, e.g. the
+// thumbnail of an exported product.
+func (h *FileUploadHelper) IsStoredURL(imageURL string) bool {
+	return h.cfg.Supabase.Url != "" && strings.HasPrefix(imageURL, h.cfg.Supabase.Url)
+}
+
+// UploadPhotoFromURL downloads an image and stores it like UploadPhoto does
+// with a form file, with the same size and type limits.
+func (h *FileUploadHelper) UploadPhotoFromURL(ctx context.Context, imageURL string, folder string) (*UploadResult, error) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("image url must be an http or https url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.downloader.Do(req)
+	if err != nil {
+		log.Errorf("failed to download image: %v", err)
+		if errors.Is(err, ErrPrivateAddress) {
+			return nil, ErrPrivateAddress
+		}
+		return nil, fmt.Errorf("failed to download image")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImageSize+1))
+	if err != nil {
+		log.Errorf("failed to read image: %v", err)
+		return nil, fmt.Errorf("failed to download image")
+	}
+	if !validateFileSize(int64(len(data)), MaxImageSize) {
+		return nil, fmt.Errorf("file size exceeds the maximum allowed size")
+	}
+
+	// The extension of the url decides, like the file name of an upload.
+	// Urls without one fall back to the content type.
+	name := path.Base(parsed.Path)
+	if !validateFileExtension(getFileExtension(name), AllowedImageExtensions) {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		ext := imageExtensions[mediaType]
+		if ext == "" {
+			return nil, fmt.Errorf("invalid file extension")
+		}
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		if base == "" || base == "/" || base == "." {
+			base = "image"
+		}
+		name = base + ext
+	}
+
+	result, err := h.storage.UploadBytes(ctx, data, name, folder)
+	if err != nil {
+		log.Errorf("failed to upload file: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
 func (h *FileUploadHelper) validateImageFile(file *multipart.FileHeader, maxSize int64) error{
 	if !validateFileSize(file.Size, maxSize) {
 		return fmt.Errorf("file size exceeds the maximum allowed size")
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
@@ -15,6 +17,7 @@ import (
 
 type SupabaseInterface interface {
 	UploadFile(ctx context.Context,  file *multipart.FileHeader, folder string) (*UploadResult, error)
+	UploadBytes(ctx context.Context, data []byte, name string, folder string) (*UploadResult, error)
 }
 
 type SupabaseStorage struct {
@@ -41,28 +44,30 @@ func (s *SupabaseStorage) UploadFile(ctx context.Context, file *multipart.FileHe
 	//Use the simpler implementation with proper Content-Type
 	contentType := file.Header.Get("Content-Type")
 	if contentType == "" {
-		//Set default content type based on file extension
-		switch strings.ToLower(ext){
-		case ".jpg", ".jpeg":
-			contentType = "image/jpeg"
-		case ".png":
-			contentType = "image/png"
-		case ".webp":
-			contentType = "image/webp"
-		case ".svg":
-			contentType = "image/svg+xml"
-		default:
-			contentType = "application/octet-stream"
-		}
+		contentType = contentTypeOf(ext)
 	}
 
+	return s.upload(src, filePath, filename, contentType)
+}
+
+// UploadBytes implements SupabaseInterface, for images that did not come in
+// a form, e.g. downloaded by an import.
+func (s *SupabaseStorage) UploadBytes(ctx context.Context, data []byte, name string, folder string) (*UploadResult, error) {
+	ext := filepath.Ext(name)
+	filename := fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), time.Now().Unix(), ext)
+	filePath := fmt.Sprintf("%s/%s", folder, filename)
+
+	return s.upload(bytes.NewReader(data), filePath, filename, contentTypeOf(ext))
+}
+
+func (s *SupabaseStorage) upload(src io.Reader, filePath, filename, contentType string) (*UploadResult, error) {
 	//Create Client with proper Content-Type
 	client := storage_go.NewClient(s.cfg.Supabase.Url, s.cfg.Supabase.Key, map[string]string{
 		"Content-Type": contentType,
 	})
 
 	//Upload File
-	_, err = client.UploadFile(s.cfg.Supabase.Bucket, filePath, src)
+	_, err := client.UploadFile(s.cfg.Supabase.Bucket, filePath, src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload supabase: %w", err)
 	}
@@ -77,6 +82,22 @@ func (s *SupabaseStorage) UploadFile(ctx context.Context, file *multipart.FileHe
 	}, nil
 }
 
+//Set default content type based on file extension
+func contentTypeOf(ext string) string {
+	switch strings.ToLower(ext){
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	case ".svg":
+		return "image/svg+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
 
 type UploadResult struct {
 	URL      string `json:"url"`
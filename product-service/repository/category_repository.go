@@ -13,6 +13,7 @@ type CategoryRepositoryInterface interface {
 	CreateCategory(ctx context.Context, category *model.Category) error
 	GetAllCategories(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Category, int64, error)
 	GetCategoryByID(ctx context.Context, id uint) (*model.Category, error)
+	GetCategoryByTagline(ctx context.Context, tagline string) (*model.Category, error)
 	UpdateCategory(ctx context.Context, category *model.Category) error
 	DeleteCategory(ctx context.Context, id uint) error
 }
@@ -124,6 +125,22 @@ func (c *categoryRepository) GetCategoryByID(ctx context.Context, id uint) (*mod
 	}
 }
 
+// GetCategoryByTagline implements CategoryRepositoryInterface. Unlike
+// GetCategoryByID it does not load the products.
+func (c *categoryRepository) GetCategoryByTagline(ctx context.Context, tagline string) (*model.Category, error) {
+	select {
+	case <- ctx.Done():
+		log.Errorf("[CategoryRepository] GetCategoryByTagline - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var modelCategory model.Category
+		if err := c.db.WithContext(ctx).Where("tagline = ?", tagline).First(&modelCategory).Error; err != nil {
+			log.Errorf("[CategoryRepository] GetCategoryByTagline - 2: %v", err)
+			return nil, err
+		}
+		return &modelCategory, nil
+	}
+}
 
 // UpdateCategory implements CategoryRepositoryInterface.
 func (c *categoryRepository) UpdateCategory(ctx context.Context, category *model.Category) error {
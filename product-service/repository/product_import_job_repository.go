@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/product-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ProductImportJobRepositoryInterface interface {
+	CreateJob(ctx context.Context, job *model.ProductImportJob) error
+	// GetJobByID leaves out the file.
+	GetJobByID(ctx context.Context, id uint) (*model.ProductImportJob, error)
+	// ClaimJob marks the oldest pending job running and returns it, with its
+	// file and its counters reset. A running job not updated since
+	// staleBefore was cut short and is claimed again. Without a job to run
+	// it returns gorm.ErrRecordNotFound.
+	ClaimJob(ctx context.Context, staleBefore time.Time) (*model.ProductImportJob, error)
+	// UpdateJobProgress saves the counters and row errors of a running job.
+	UpdateJobProgress(ctx context.Context, job *model.ProductImportJob) error
+	// FinishJob saves the outcome of a job and drops its file.
+	FinishJob(ctx context.Context, job *model.ProductImportJob) error
+}
+
+type productImportJobRepository struct {
+	db *gorm.DB
+}
+
+// CreateJob implements ProductImportJobRepositoryInterface.
+func (p *productImportJobRepository) CreateJob(ctx context.Context, job *model.ProductImportJob) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductImportJobRepository] CreateJob - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := p.db.WithContext(ctx).Create(job).Error; err != nil {
+			log.Errorf("[ProductImportJobRepository] CreateJob - 2: %v", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// GetJobByID implements ProductImportJobRepositoryInterface.
+func (p *productImportJobRepository) GetJobByID(ctx context.Context, id uint) (*model.ProductImportJob, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductImportJobRepository] GetJobByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		job := model.ProductImportJob{}
+		if err := p.db.WithContext(ctx).Omit("file").Where("id = ?", id).First(&job).Error; err != nil {
+			log.Errorf("[ProductImportJobRepository] GetJobByID - 2: %v", err)
+			return nil, err
+		}
+		return &job, nil
+	}
+}
+
+// ClaimJob implements ProductImportJobRepositoryInterface. SKIP LOCKED lets
+// every replica run a worker without two of them taking the same job.
+func (p *productImportJobRepository) ClaimJob(ctx context.Context, staleBefore time.Time) (*model.ProductImportJob, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductImportJobRepository] ClaimJob - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		job := model.ProductImportJob{}
+		if err := p.db.WithContext(ctx).Raw(`
+			UPDATE product_import_jobs
+			SET status = ?, started_at = now(), updated_at = now(), processed_rows = 0,
+				created_count = 0, updated_count = 0, failed_count = 0, row_errors = '[]'
+			WHERE id = (
+				SELECT id FROM product_import_jobs
+				WHERE status = ? OR (status = ? AND updated_at < ?)
+				ORDER BY id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			model.ImportStatusRunning, model.ImportStatusPending, model.ImportStatusRunning, staleBefore,
+		).Scan(&job).Error; err != nil {
+			log.Errorf("[ProductImportJobRepository] ClaimJob - 2: %v", err)
+			return nil, err
+		}
+		if job.ID == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &job, nil
+	}
+}
+
+// UpdateJobProgress implements ProductImportJobRepositoryInterface. It also
+// tells ClaimJob that the job is still alive.
+func (p *productImportJobRepository) UpdateJobProgress(ctx context.Context, job *model.ProductImportJob) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductImportJobRepository] UpdateJobProgress - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		now := time.Now()
+		job.UpdatedAt = &now
+		if err := p.db.WithContext(ctx).Model(&model.ProductImportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"total_rows":     job.TotalRows,
+			"processed_rows": job.ProcessedRows,
+			"created_count":  job.CreatedCount,
+			"updated_count":  job.UpdatedCount,
+			"failed_count":   job.FailedCount,
+			"row_errors":     job.RowErrors,
+			"updated_at":     job.UpdatedAt,
+		}).Error; err != nil {
+			log.Errorf("[ProductImportJobRepository] UpdateJobProgress - 2: %v", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// FinishJob implements ProductImportJobRepositoryInterface.
+func (p *productImportJobRepository) FinishJob(ctx context.Context, job *model.ProductImportJob) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductImportJobRepository] FinishJob - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		now := time.Now()
+		job.FinishedAt = &now
+		job.UpdatedAt = &now
+		job.File = nil
+		if err := p.db.WithContext(ctx).Model(&model.ProductImportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":         job.Status,
+			"error":          job.Error,
+			"file":           gorm.Expr("NULL"),
+			"total_rows":     job.TotalRows,
+			"processed_rows": job.ProcessedRows,
+			"created_count":  job.CreatedCount,
+			"updated_count":  job.UpdatedCount,
+			"failed_count":   job.FailedCount,
+			"row_errors":     job.RowErrors,
+			"finished_at":    job.FinishedAt,
+			"updated_at":     job.UpdatedAt,
+		}).Error; err != nil {
+			log.Errorf("[ProductImportJobRepository] FinishJob - 2: %v", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func NewProductImportJobRepository(db *gorm.DB) ProductImportJobRepositoryInterface {
+	return &productImportJobRepository{db: db}
+}
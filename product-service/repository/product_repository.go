@@ -2,15 +2,35 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"warehouse-go/product-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ProductFilter narrows GetAllProducts and GetCategoryFacets. Empty fields
+// do not filter. Search matches words of the name or about, a partial or
+// slightly misspelled name, and a barcode or SKU prefix of the product or
+// one of its variants. ProductIDs does not filter when nil and matches
+// nothing when empty.
+type ProductFilter struct {
+	Search     string
+	CategoryID uint
+	MinPrice   *int
+	MaxPrice   *int
+	IsPopular  *bool
+	ProductIDs []uint
+}
+
 type ProductRepositoryInterface interface {
 	CreateProduct(ctx context.Context, product *model.Product) error
-	GetAllProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Product, int64, error)
+	// An empty sortBy sorts a search by relevance and the rest by newest.
+	GetAllProducts(ctx context.Context, page, limit int, filter ProductFilter, sortBy, sortOrder string) ([]model.Product, int64, error)
+	// GetCategoryFacets counts the products matching filter per category,
+	// ignoring filter.CategoryID so every category keeps its count.
+	GetCategoryFacets(ctx context.Context, filter ProductFilter) ([]model.CategoryFacet, error)
 	GetProductByID(ctx context.Context, id uint) (*model.Product, error)
 	GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error)
 	UpdateProduct(ctx context.Context, product *model.Product) error
@@ -50,7 +70,7 @@ func (p *productRepository) DeleteProduct(ctx context.Context, id uint) error {
 }
 
 // GetAllProducts implements ProductRepositoryInterface.
-func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Product, int64, error) {
+func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit int, filter ProductFilter, sortBy string, sortOrder string) ([]model.Product, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[ProductRepository] GetAllProducts - 1: %v", ctx.Err())
@@ -62,19 +82,16 @@ func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit
 		if limit <= 0 {
 			limit = 10
 		}
-		if sortBy == "" {
-			sortBy = "created_at"
-		}
-		if sortOrder == "" {
+		if sortOrder != "asc" {
 			sortOrder = "desc"
 		}
 
 		offset := (page - 1) * limit
+		search := strings.TrimSpace(filter.Search)
 
-		query := p.db.Model(&model.Product{})
-
-		if search != "" {
-			query = query.Where("name ILIKE ? OR barcode ILIKE ? OR about ILIKE ?", "%"+search+"%", "%"+search+"%")
+		query := filterProducts(p.db.WithContext(ctx).Model(&model.Product{}), filter)
+		if filter.CategoryID != 0 {
+			query = query.Where("products.category_id = ?", filter.CategoryID)
 		}
 
 		var total int64
@@ -83,10 +100,20 @@ func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit
 			return nil, 0, err
 		}
 
+		switch {
+		case sortBy == "" && search != "":
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "ts_rank(products.search_vector, websearch_to_tsquery('simple', ?)) + word_similarity(?, products.name) DESC, products.id",
+				Vars: []interface{}{search, search},
+			}})
+		case sortBy == "id" || sortBy == "name" || sortBy == "price" || sortBy == "created_at":
+			query = query.Order("products." + sortBy + " " + sortOrder)
+		default:
+			query = query.Order("products.created_at " + sortOrder)
+		}
+
 		var products []model.Product
 		if err := query.
-			Order(sortBy + " " + sortOrder).
-			WithContext(ctx).
 			Preload("Category").
 			Offset(offset).
 			Limit(limit).
@@ -100,6 +127,60 @@ func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit
 }
 
 
+// GetCategoryFacets implements ProductRepositoryInterface.
+func (p *productRepository) GetCategoryFacets(ctx context.Context, filter ProductFilter) ([]model.CategoryFacet, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductRepository] GetCategoryFacets - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		facets := []model.CategoryFacet{}
+		if err := filterProducts(p.db.WithContext(ctx).Model(&model.Product{}), filter).
+			Joins("LEFT JOIN categories ON categories.id = products.category_id").
+			Select("products.category_id, COALESCE(categories.name, '') AS category_name, COUNT(*) AS count").
+			Group("products.category_id, categories.name").
+			Order("count DESC, category_name").
+			Scan(&facets).Error; err != nil {
+			log.Errorf("[ProductRepository] GetCategoryFacets - 2: %v", err)
+			return nil, err
+		}
+
+		return facets, nil
+	}
+}
+
+// filterProducts applies every filter but the category one, which facets
+// leave out.
+func filterProducts(query *gorm.DB, filter ProductFilter) *gorm.DB {
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		contains := "%" + escapeLike(search) + "%"
+		prefix := escapeLike(search) + "%"
+		query = query.Where(
+			"products.search_vector @@ websearch_to_tsquery('simple', ?) OR products.name ILIKE ? OR ? <% products.name OR products.barcode LIKE ? OR products.id IN (?)",
+			search, contains, search, prefix,
+			gorm.Expr("SELECT product_id FROM product_variants WHERE barcode LIKE ? OR sku ILIKE ?", prefix, prefix),
+		)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("products.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	}
+	if filter.IsPopular != nil {
+		query = query.Where("products.is_popular = ?", *filter.IsPopular)
+	}
+	if filter.ProductIDs != nil {
+		query = query.Where("products.id IN ?", filter.ProductIDs)
+	}
+	return query
+}
+
+// escapeLike makes s match literally in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // GetProductByBarcode implements ProductRepositoryInterface.
 func (p *productRepository) GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error) {
 	select {
@@ -108,7 +189,7 @@ func (p *productRepository) GetProductByBarcode(ctx context.Context, barcode str
 		return nil, ctx.Err()
 	default:
 		modelProduct := model.Product{}
-		if err := p.db.WithContext(ctx).Where("barcode = ?", barcode).First(&modelProduct).Error; err != nil {
+		if err := p.db.WithContext(ctx).Where("barcode = ?", barcode).Preload("Category").Preload("Variants", orderByID).Preload("Units", orderByID).First(&modelProduct).Error; err != nil {
 			log.Errorf("[ProductRepository] GetProductByBarcode - 2: %v", err)
 			return nil, err
 		}
@@ -126,7 +207,7 @@ func (p *productRepository) GetProductByID(ctx context.Context, id uint) (*model
 		return nil, ctx.Err()
 	default:
 		modelProduct := model.Product{}
-		if err := p.db.WithContext(ctx).Where("id = ?", id).Preload("Category").First(&modelProduct).Error; err != nil {
+		if err := p.db.WithContext(ctx).Where("id = ?", id).Preload("Category").Preload("Variants", orderByID).Preload("Units", orderByID).First(&modelProduct).Error; err != nil {
 			log.Errorf("[ProductRepository] GetProductByID - 2: %v", err)
 			return nil, err
 		}
@@ -156,14 +237,22 @@ func (p *productRepository) UpdateProduct(ctx context.Context, product *model.Pr
 			"about"			:	product.About,
 			"category_id"	:	product.CategoryID,
 			"thumbnail"		: 	product.Thumbnail,
+			"thumbnail_source"	: 	product.ThumbnailSource,
 			"is_popular"	: 	product.IsPopular,
 			"updated_at"	: 	product.UpdatedAt,
 		}
+		if product.BaseUnit != "" {
+			updates["base_unit"] = product.BaseUnit
+		}
 
 		return p.db.WithContext(ctx).Model(&existingProduct).Updates(updates).Error
 	}
 }
 
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
 func NewProductRepository(db *gorm.DB) ProductRepositoryInterface {
 	return &productRepository{db: db}
 }
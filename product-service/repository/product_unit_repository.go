@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"warehouse-go/product-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ProductUnitRepositoryInterface interface {
+	CreateUnit(ctx context.Context, unit *model.ProductUnit) error
+	GetUnitsByProductID(ctx context.Context, productID uint) ([]model.ProductUnit, error)
+	GetUnitByID(ctx context.Context, productID, id uint) (*model.ProductUnit, error)
+	GetUnitByBarcode(ctx context.Context, barcode string) (*model.ProductUnit, error)
+	UpdateUnit(ctx context.Context, unit *model.ProductUnit) error
+	DeleteUnit(ctx context.Context, productID, id uint) error
+}
+
+type productUnitRepository struct {
+	db *gorm.DB
+}
+
+// CreateUnit implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) CreateUnit(ctx context.Context, unit *model.ProductUnit) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] CreateUnit - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := p.db.WithContext(ctx).Create(unit).Error; err != nil {
+			log.Errorf("[ProductUnitRepository] CreateUnit - 2: %v", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// GetUnitsByProductID implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) GetUnitsByProductID(ctx context.Context, productID uint) ([]model.ProductUnit, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] GetUnitsByProductID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		units := []model.ProductUnit{}
+		if err := p.db.WithContext(ctx).Where("product_id = ?", productID).Order("id").Find(&units).Error; err != nil {
+			log.Errorf("[ProductUnitRepository] GetUnitsByProductID - 2: %v", err)
+			return nil, err
+		}
+		return units, nil
+	}
+}
+
+// GetUnitByID implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) GetUnitByID(ctx context.Context, productID uint, id uint) (*model.ProductUnit, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] GetUnitByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		unit := model.ProductUnit{}
+		if err := p.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&unit).Error; err != nil {
+			log.Errorf("[ProductUnitRepository] GetUnitByID - 2: %v", err)
+			return nil, err
+		}
+		return &unit, nil
+	}
+}
+
+// GetUnitByBarcode implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) GetUnitByBarcode(ctx context.Context, barcode string) (*model.ProductUnit, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] GetUnitByBarcode - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		unit := model.ProductUnit{}
+		if err := p.db.WithContext(ctx).Where("barcode = ?", barcode).First(&unit).Error; err != nil {
+			return nil, err
+		}
+		return &unit, nil
+	}
+}
+
+// UpdateUnit implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) UpdateUnit(ctx context.Context, unit *model.ProductUnit) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] UpdateUnit - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		updates := map[string]interface{}{
+			"name":       unit.Name,
+			"factor":     unit.Factor,
+			"barcode":    unit.Barcode,
+			"updated_at": unit.UpdatedAt,
+		}
+
+		result := p.db.WithContext(ctx).Model(&model.ProductUnit{}).
+			Where("id = ? AND product_id = ?", unit.ID, unit.ProductID).
+			Updates(updates)
+		if result.Error != nil {
+			log.Errorf("[ProductUnitRepository] UpdateUnit - 2: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+}
+
+// DeleteUnit implements ProductUnitRepositoryInterface.
+func (p *productUnitRepository) DeleteUnit(ctx context.Context, productID uint, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductUnitRepository] DeleteUnit - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := p.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).Delete(&model.ProductUnit{})
+		if result.Error != nil {
+			log.Errorf("[ProductUnitRepository] DeleteUnit - 2: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+}
+
+func NewProductUnitRepository(db *gorm.DB) ProductUnitRepositoryInterface {
+	return &productUnitRepository{db: db}
+}
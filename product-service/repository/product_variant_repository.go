@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"warehouse-go/product-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ProductVariantRepositoryInterface interface {
+	CreateVariant(ctx context.Context, variant *model.ProductVariant) error
+	GetVariantsByProductID(ctx context.Context, productID uint) ([]model.ProductVariant, error)
+	GetVariantByID(ctx context.Context, productID, id uint) (*model.ProductVariant, error)
+	GetVariantByBarcode(ctx context.Context, barcode string) (*model.ProductVariant, error)
+	GetVariantBySKU(ctx context.Context, sku string) (*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *model.ProductVariant) error
+	DeleteVariant(ctx context.Context, productID, id uint) error
+}
+
+type productVariantRepository struct {
+	db *gorm.DB
+}
+
+// CreateVariant implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) CreateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] CreateVariant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := p.db.WithContext(ctx).Create(variant).Error; err != nil {
+			log.Errorf("[ProductVariantRepository] CreateVariant - 2: %v", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// GetVariantsByProductID implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) GetVariantsByProductID(ctx context.Context, productID uint) ([]model.ProductVariant, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] GetVariantsByProductID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		variants := []model.ProductVariant{}
+		if err := p.db.WithContext(ctx).Where("product_id = ?", productID).Order("id").Find(&variants).Error; err != nil {
+			log.Errorf("[ProductVariantRepository] GetVariantsByProductID - 2: %v", err)
+			return nil, err
+		}
+		return variants, nil
+	}
+}
+
+// GetVariantByID implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) GetVariantByID(ctx context.Context, productID uint, id uint) (*model.ProductVariant, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] GetVariantByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		variant := model.ProductVariant{}
+		if err := p.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&variant).Error; err != nil {
+			log.Errorf("[ProductVariantRepository] GetVariantByID - 2: %v", err)
+			return nil, err
+		}
+		return &variant, nil
+	}
+}
+
+// GetVariantByBarcode implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) GetVariantByBarcode(ctx context.Context, barcode string) (*model.ProductVariant, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] GetVariantByBarcode - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		variant := model.ProductVariant{}
+		if err := p.db.WithContext(ctx).Where("barcode = ?", barcode).First(&variant).Error; err != nil {
+			return nil, err
+		}
+		return &variant, nil
+	}
+}
+
+// GetVariantBySKU implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) GetVariantBySKU(ctx context.Context, sku string) (*model.ProductVariant, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] GetVariantBySKU - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		variant := model.ProductVariant{}
+		if err := p.db.WithContext(ctx).Where("sku = ?", sku).First(&variant).Error; err != nil {
+			return nil, err
+		}
+		return &variant, nil
+	}
+}
+
+// UpdateVariant implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) UpdateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] UpdateVariant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		updates := map[string]interface{}{
+			"sku":        variant.SKU,
+			"barcode":    variant.Barcode,
+			"price":      variant.Price,
+			"attributes": variant.Attributes,
+			"updated_at": variant.UpdatedAt,
+		}
+
+		result := p.db.WithContext(ctx).Model(&model.ProductVariant{}).
+			Where("id = ? AND product_id = ?", variant.ID, variant.ProductID).
+			Updates(updates)
+		if result.Error != nil {
+			log.Errorf("[ProductVariantRepository] UpdateVariant - 2: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+}
+
+// DeleteVariant implements ProductVariantRepositoryInterface.
+func (p *productVariantRepository) DeleteVariant(ctx context.Context, productID uint, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductVariantRepository] DeleteVariant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := p.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).Delete(&model.ProductVariant{})
+		if result.Error != nil {
+			log.Errorf("[ProductVariantRepository] DeleteVariant - 2: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+}
+
+func NewProductVariantRepository(db *gorm.DB) ProductVariantRepositoryInterface {
+	return &productVariantRepository{db: db}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// ErrBarcodeTaken is returned for a barcode that another product, variant or
+// unit already has. A scanned barcode has to point to exactly one of them.
+var ErrBarcodeTaken = errors.New("barcode is already used by another product, variant or unit")
+
+// barcodeOwner is the record a barcode is checked for, so it does not
+// conflict with itself. Only one of the ids is set.
+type barcodeOwner struct {
+	productID uint
+	variantID uint
+	unitID    uint
+}
+
+type barcodeChecker struct {
+	productRepo repository.ProductRepositoryInterface
+	variantRepo repository.ProductVariantRepositoryInterface
+	unitRepo    repository.ProductUnitRepositoryInterface
+}
+
+// check returns ErrBarcodeTaken when barcode belongs to anything but owner.
+// Products, variants and units share one barcode space.
+func (b barcodeChecker) check(ctx context.Context, barcode string, owner barcodeOwner) error {
+	if barcode == "" {
+		return nil
+	}
+
+	product, err := b.productRepo.GetProductByBarcode(ctx, barcode)
+	switch {
+	case err == nil:
+		if product.ID != owner.productID {
+			return ErrBarcodeTaken
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Errorf("[BarcodeChecker] check - 1: %v", err)
+		return err
+	}
+
+	variant, err := b.variantRepo.GetVariantByBarcode(ctx, barcode)
+	switch {
+	case err == nil:
+		if variant.ID != owner.variantID {
+			return ErrBarcodeTaken
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Errorf("[BarcodeChecker] check - 2: %v", err)
+		return err
+	}
+
+	unit, err := b.unitRepo.GetUnitByBarcode(ctx, barcode)
+	switch {
+	case err == nil:
+		if unit.ID != owner.unitID {
+			return ErrBarcodeTaken
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Errorf("[BarcodeChecker] check - 3: %v", err)
+		return err
+	}
+
+	return nil
+}
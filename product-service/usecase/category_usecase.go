@@ -3,7 +3,10 @@ package usecase
 import (
 	"context"
 	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/rabbitmq"
 	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
 type CategoryUsecaseInterface interface {
@@ -16,16 +19,27 @@ type CategoryUsecaseInterface interface {
 
 type categoryUsecase struct {
 	categoryRepo repository.CategoryRepositoryInterface
+	publisher    rabbitmq.CatalogPublisher
 }
 
 //CreateCategory implements CategoryUsecaseInterface
 func (c *categoryUsecase) CreateCategory(ctx context.Context, category *model.Category) error {
-	return c.categoryRepo.CreateCategory(ctx, category)
+	if err := c.categoryRepo.CreateCategory(ctx, category); err != nil {
+		return err
+	}
+
+	c.publish(rabbitmq.EventCategoryCreated, category.ID)
+	return nil
 }
 
 //DeleteCategory implements CategoryUsecaseInterface
 func (c *categoryUsecase) DeleteCategory(ctx context.Context, id uint) error {
-	return c.categoryRepo.DeleteCategory(ctx, id)
+	if err := c.categoryRepo.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+
+	c.publish(rabbitmq.EventCategoryDeleted, id)
+	return nil
 }
 
 //GetAllCategories implements CategoryUsecaseInterface
@@ -40,9 +54,23 @@ func (c *categoryUsecase) GetCategoryByID(ctx context.Context, id uint) (*model.
 
 //UpdateCategory implements CategoryUsecaseInterface
 func (c *categoryUsecase) UpdateCategory(ctx context.Context, category *model.Category) error {
-	return c.categoryRepo.UpdateCategory(ctx, category)
+	if err := c.categoryRepo.UpdateCategory(ctx, category); err != nil {
+		return err
+	}
+
+	c.publish(rabbitmq.EventCategoryUpdated, category.ID)
+	return nil
+}
+
+// publish announces a committed change. A failure is only logged, the change
+// itself already succeeded.
+func (c *categoryUsecase) publish(eventType string, id uint) {
+	event := rabbitmq.CatalogEvent{Type: eventType, CategoryID: id}
+	if err := c.publisher.PublishCatalogEvent(event); err != nil {
+		log.Errorf("[CategoryUsecase] publish - 1: %v", err)
+	}
 }
 
-func NewCategoryUsecase(categoryRepo repository.CategoryRepositoryInterface) CategoryUsecaseInterface {
-	return &categoryUsecase{categoryRepo: categoryRepo}
+func NewCategoryUsecase(categoryRepo repository.CategoryRepositoryInterface, publisher rabbitmq.CatalogPublisher) CategoryUsecaseInterface {
+	return &categoryUsecase{categoryRepo: categoryRepo, publisher: publisher}
 }	
\ No newline at end of file
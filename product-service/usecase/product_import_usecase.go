@@ -0,0 +1,647 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/storage"
+	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// maxImportRows bounds one file. A supplier catalog of a few thousand
+// products fits easily.
+const maxImportRows = 10000
+
+const (
+	importPollInterval = 5 * time.Second
+	// importStaleAfter is how long a running job may go without saving
+	// progress before a worker takes it over. Between two saves a worker
+	// waits on at most importProgressEvery image downloads.
+	importStaleAfter    = 10 * time.Minute
+	importProgressEvery = 20
+	exportPageSize      = 500
+)
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// An import matches columns by header name and ignores the ones it does not
+// know, so an export can be edited and imported again. category_name names
+// a category the import creates and is ignored for existing ones.
+var (
+	importRequiredColumns = []string{"name", "barcode", "category_tagline", "price"}
+	catalogColumns        = []string{"id", "name", "barcode", "category_tagline", "category_name", "price", "about", "image_url", "is_popular", "base_unit", "created_at"}
+)
+
+type ProductImportUsecaseInterface interface {
+	// CreateImportJob checks that the file can be read and queues it. Rows
+	// are checked when the job runs.
+	CreateImportJob(ctx context.Context, fileName string, data []byte, createdBy uint) (*model.ProductImportJob, error)
+	GetImportJob(ctx context.Context, id uint) (*model.ProductImportJob, error)
+	// RunImports runs queued jobs one after the other until ctx is done.
+	RunImports(ctx context.Context)
+	// ExportProducts writes the whole catalog as csv or xlsx, in the columns
+	// an import reads.
+	ExportProducts(ctx context.Context, w io.Writer, format string) error
+}
+
+type productImportUsecase struct {
+	jobRepo         repository.ProductImportJobRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	categoryRepo    repository.CategoryRepositoryInterface
+	productUsecase  ProductUsecaseInterface
+	categoryUsecase CategoryUsecaseInterface
+	uploader        *storage.FileUploadHelper
+	// wake starts the worker on a new job without waiting for the next poll.
+	wake chan struct{}
+}
+
+type catalogRow struct {
+	row             int
+	name            string
+	barcode         string
+	categoryTagline string
+	categoryName    string
+	price           string
+	about           string
+	imageURL        string
+	isPopular       string
+	baseUnit        string
+}
+
+// importRun is the state of one job while it runs.
+type importRun struct {
+	job        *model.ProductImportJob
+	categories map[string]*model.Category
+	seen       map[string]int
+}
+
+// CreateImportJob implements ProductImportUsecaseInterface.
+func (p *productImportUsecase) CreateImportJob(ctx context.Context, fileName string, data []byte, createdBy uint) (*model.ProductImportJob, error) {
+	format, err := importFormat(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := readCatalogRows(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ProductImportJob{
+		Status:    model.ImportStatusPending,
+		FileName:  filepath.Base(fileName),
+		Format:    format,
+		File:      data,
+		CreatedBy: createdBy,
+		TotalRows: len(rows),
+		RowErrors: model.ImportRowErrors{},
+	}
+	if err := p.jobRepo.CreateJob(ctx, job); err != nil {
+		log.Errorf("[ProductImportUsecase] CreateImportJob - 1: %v", err)
+		return nil, err
+	}
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// GetImportJob implements ProductImportUsecaseInterface.
+func (p *productImportUsecase) GetImportJob(ctx context.Context, id uint) (*model.ProductImportJob, error) {
+	return p.jobRepo.GetJobByID(ctx, id)
+}
+
+// RunImports implements ProductImportUsecaseInterface. A job cut short by a
+// shutdown stays running and is started over once it goes stale.
+func (p *productImportUsecase) RunImports(ctx context.Context) {
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := p.jobRepo.ClaimJob(ctx, time.Now().Add(-importStaleAfter))
+			if err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Errorf("[ProductImportUsecase] RunImports - 1: %v", err)
+				}
+				break
+			}
+			p.runJob(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+func (p *productImportUsecase) runJob(ctx context.Context, job *model.ProductImportJob) {
+	log.Infof("[ProductImportUsecase] runJob - Job %d started: %s", job.ID, job.FileName)
+
+	rows, err := readCatalogRows(job.Format, job.File)
+	if err != nil {
+		job.Status = model.ImportStatusFailed
+		job.Error = err.Error()
+		if err := p.jobRepo.FinishJob(ctx, job); err != nil {
+			log.Errorf("[ProductImportUsecase] runJob - 1: %v", err)
+		}
+		return
+	}
+
+	run := &importRun{
+		job:        job,
+		categories: make(map[string]*model.Category),
+		seen:       make(map[string]int, len(rows)),
+	}
+	job.TotalRows = len(rows)
+	job.RowErrors = model.ImportRowErrors{}
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+
+		created, rowErrors := p.importRow(ctx, run, row)
+		switch {
+		case len(rowErrors) > 0:
+			job.FailedCount++
+			job.RowErrors = append(job.RowErrors, model.ImportRowError{Row: row.row, Barcode: row.barcode, Errors: rowErrors})
+		case created:
+			job.CreatedCount++
+		default:
+			job.UpdatedCount++
+		}
+		job.ProcessedRows++
+
+		if job.ProcessedRows%importProgressEvery == 0 {
+			if err := p.jobRepo.UpdateJobProgress(ctx, job); err != nil {
+				log.Errorf("[ProductImportUsecase] runJob - 2: %v", err)
+			}
+		}
+	}
+
+	job.Status = model.ImportStatusCompleted
+	if err := p.jobRepo.FinishJob(ctx, job); err != nil {
+		log.Errorf("[ProductImportUsecase] runJob - 3: %v", err)
+		return
+	}
+
+	log.Infof("[ProductImportUsecase] runJob - Job %d done: %d created, %d updated, %d failed",
+		job.ID, job.CreatedCount, job.UpdatedCount, job.FailedCount)
+}
+
+// importRow creates or updates the product with the barcode of row. It
+// returns why the row was skipped, if it was.
+func (p *productImportUsecase) importRow(ctx context.Context, run *importRun, row catalogRow) (bool, []string) {
+	var rowErrors []string
+	required := func(value, column string, maxLength int) {
+		switch {
+		case value == "":
+			rowErrors = append(rowErrors, column+" is required")
+		case utf8.RuneCountInString(value) > maxLength:
+			rowErrors = append(rowErrors, fmt.Sprintf("%s is longer than %d characters", column, maxLength))
+		}
+	}
+	required(row.name, "name", 100)
+	required(row.barcode, "barcode", 100)
+	required(row.categoryTagline, "category_tagline", 100)
+	if utf8.RuneCountInString(row.categoryName) > 100 {
+		rowErrors = append(rowErrors, "category_name is longer than 100 characters")
+	}
+	if utf8.RuneCountInString(row.baseUnit) > 50 {
+		rowErrors = append(rowErrors, "base_unit is longer than 50 characters")
+	}
+
+	price, err := strconv.ParseFloat(row.price, 64)
+	if row.price == "" {
+		rowErrors = append(rowErrors, "price is required")
+	} else if err != nil || math.IsNaN(price) || math.IsInf(price, 0) || price <= 0 {
+		rowErrors = append(rowErrors, "price must be a number above 0")
+	}
+
+	var isPopular *bool
+	if row.isPopular != "" {
+		value, err := strconv.ParseBool(row.isPopular)
+		if err != nil {
+			rowErrors = append(rowErrors, "is_popular must be true or false")
+		}
+		isPopular = &value
+	}
+
+	if row.barcode != "" {
+		if first, ok := run.seen[row.barcode]; ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("barcode is already used in row %d", first))
+		} else {
+			run.seen[row.barcode] = row.row
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return false, rowErrors
+	}
+
+	product, err := p.productRepo.GetProductByBarcode(ctx, row.barcode)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("[ProductImportUsecase] importRow - 1: %v", err)
+		return false, []string{"could not look up the barcode"}
+	}
+	created := product == nil
+	if created {
+		if row.about == "" {
+			rowErrors = append(rowErrors, "about is required for a new product")
+		}
+		if row.imageURL == "" {
+			rowErrors = append(rowErrors, "image_url is required for a new product")
+		}
+		if len(rowErrors) > 0 {
+			return false, rowErrors
+		}
+		product = &model.Product{Barcode: row.barcode}
+	}
+
+	category, err := p.importCategory(ctx, run, row)
+	if err != nil {
+		log.Errorf("[ProductImportUsecase] importRow - 2: %v", err)
+		return false, []string{"could not create category " + row.categoryTagline}
+	}
+
+	// Empty about, image_url, is_popular and base_unit keep what the
+	// product has.
+	product.Name = row.name
+	product.Price = price
+	product.CategoryID = category.ID
+	if row.about != "" {
+		product.About = row.about
+	}
+	if isPopular != nil {
+		product.IsPopular = *isPopular
+	}
+	if row.baseUnit != "" {
+		product.BaseUnit = row.baseUnit
+	}
+	if row.imageURL != "" && row.imageURL != product.Thumbnail && row.imageURL != product.ThumbnailSource {
+		if p.uploader.IsStoredURL(row.imageURL) {
+			product.Thumbnail = row.imageURL
+			product.ThumbnailSource = ""
+		} else {
+			result, err := p.uploader.UploadPhotoFromURL(ctx, row.imageURL, "products")
+			if err != nil {
+				return false, []string{"image_url: " + err.Error()}
+			}
+			product.Thumbnail = result.URL
+			product.ThumbnailSource = row.imageURL
+		}
+	}
+
+	if created {
+		err = p.productUsecase.CreateProduct(ctx, product)
+	} else {
+		now := time.Now()
+		product.UpdatedAt = &now
+		err = p.productUsecase.UpdateProduct(ctx, product)
+	}
+	switch {
+	case errors.Is(err, ErrBarcodeTaken):
+		return false, []string{"barcode is used by a variant or unit of another product"}
+	case errors.Is(err, ErrUnitIsBase):
+		return false, []string{"base_unit is already a unit of the product"}
+	case err != nil:
+		log.Errorf("[ProductImportUsecase] importRow - 3: %v", err)
+		return false, []string{"could not save the product"}
+	}
+
+	return created, nil
+}
+
+// importCategory finds the category of row by tagline, creating it when
+// there is none.
+func (p *productImportUsecase) importCategory(ctx context.Context, run *importRun, row catalogRow) (*model.Category, error) {
+	if category, ok := run.categories[row.categoryTagline]; ok {
+		return category, nil
+	}
+
+	category, err := p.categoryRepo.GetCategoryByTagline(ctx, row.categoryTagline)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		category = &model.Category{Name: row.categoryName, Tagline: row.categoryTagline}
+		if category.Name == "" {
+			category.Name = row.categoryTagline
+		}
+		err = p.categoryUsecase.CreateCategory(ctx, category)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	run.categories[row.categoryTagline] = category
+	return category, nil
+}
+
+// ExportProducts implements ProductImportUsecaseInterface.
+func (p *productImportUsecase) ExportProducts(ctx context.Context, w io.Writer, format string) error {
+	if format == model.ImportFormatXLSX {
+		return p.exportXLSX(ctx, w)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(catalogColumns); err != nil {
+		log.Errorf("[ProductImportUsecase] ExportProducts - 1: %v", err)
+		return err
+	}
+
+	err := p.eachProduct(ctx, func(product model.Product) error {
+		record := catalogRecord(product)
+		for idx, value := range record {
+			record[idx] = escapeCSVCell(value)
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		log.Errorf("[ProductImportUsecase] ExportProducts - 2: %v", err)
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (p *productImportUsecase) exportXLSX(ctx context.Context, w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	const sheet = "Products"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		log.Errorf("[ProductImportUsecase] exportXLSX - 1: %v", err)
+		return err
+	}
+
+	line := 1
+	writeRow := func(values []string) error {
+		cells := make([]interface{}, len(values))
+		for idx, value := range values {
+			cells[idx] = value
+		}
+		cell, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+		line++
+		return stream.SetRow(cell, cells)
+	}
+
+	if err := writeRow(catalogColumns); err != nil {
+		log.Errorf("[ProductImportUsecase] exportXLSX - 2: %v", err)
+		return err
+	}
+	// Every cell is text, so barcodes do not turn into numbers.
+	if err := p.eachProduct(ctx, func(product model.Product) error {
+		return writeRow(catalogRecord(product))
+	}); err != nil {
+		log.Errorf("[ProductImportUsecase] exportXLSX - 3: %v", err)
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		log.Errorf("[ProductImportUsecase] exportXLSX - 4: %v", err)
+		return err
+	}
+	return file.Write(w)
+}
+
+// eachProduct calls fn with every product, oldest first, so products created
+// while exporting end up on the last page instead of shifting the others.
+func (p *productImportUsecase) eachProduct(ctx context.Context, fn func(product model.Product) error) error {
+	for page := 1; ; page++ {
+		products, total, err := p.productRepo.GetAllProducts(ctx, page, exportPageSize, repository.ProductFilter{}, "id", "asc")
+		if err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+
+		if len(products) < exportPageSize || int64(page*exportPageSize) >= total {
+			return nil
+		}
+	}
+}
+
+func catalogRecord(product model.Product) []string {
+	return []string{
+		strconv.FormatUint(uint64(product.ID), 10),
+		product.Name,
+		product.Barcode,
+		product.Category.Tagline,
+		product.Category.Name,
+		strconv.FormatFloat(product.Price, 'f', -1, 64),
+		product.About,
+		product.Thumbnail,
+		strconv.FormatBool(product.IsPopular),
+		product.BaseUnit,
+		product.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func importFormat(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return model.ImportFormatCSV, nil
+	case ".xlsx":
+		return model.ImportFormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: only .csv and .xlsx files can be imported", ErrInvalidImportFile)
+}
+
+// readCatalogRows reads the header and the rows below it. Blank rows are
+// skipped; rows are numbered by their line in the file. An xlsx file is read
+// from its first sheet.
+func readCatalogRows(format string, data []byte) ([]catalogRow, error) {
+	var records [][]string
+	var lines []int
+	var err error
+	if format == model.ImportFormatXLSX {
+		records, lines, err = readXLSXRecords(data)
+	} else {
+		records, lines, err = readCSVRecords(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImportFile)
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for idx, name := range records[0] {
+		// Spreadsheets like to start the file with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: column %q is missing", ErrInvalidImportFile, name)
+		}
+	}
+
+	cell := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return unescapeCSVCell(strings.TrimSpace(record[idx]))
+	}
+
+	rows := []catalogRow{}
+	for idx, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d products can be imported at once", ErrInvalidImportFile, maxImportRows)
+		}
+
+		rows = append(rows, catalogRow{
+			row:             lines[idx+1],
+			name:            cell(record, "name"),
+			barcode:         cell(record, "barcode"),
+			categoryTagline: cell(record, "category_tagline"),
+			categoryName:    cell(record, "category_name"),
+			price:           cell(record, "price"),
+			about:           cell(record, "about"),
+			imageURL:        cell(record, "image_url"),
+			isPopular:       cell(record, "is_popular"),
+			baseUnit:        cell(record, "base_unit"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no products", ErrInvalidImportFile)
+	}
+	return rows, nil
+}
+
+func readCSVRecords(data []byte) ([][]string, []int, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, lines, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+}
+
+// readXLSXRecords reads raw cell values, so long barcodes and prices are not
+// shown in a number format.
+func readXLSXRecords(data []byte) ([][]string, []int, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, errors.New("the workbook has no sheets")
+	}
+	rows, err := file.Rows(sheets[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var records [][]string
+	var lines []int
+	for line := 1; rows.Next(); line++ {
+		record, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, rows.Error()
+}
+
+// escapeCSVCell keeps spreadsheets from running a cell as a formula.
+// Negative numbers are left alone.
+func escapeCSVCell(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if len(value) == 1 || value[1] < '0' || value[1] > '9' {
+			return "'" + value
+		}
+	}
+	return value
+}
+
+// unescapeCSVCell undoes escapeCSVCell for a file that went through an
+// export.
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && escapeCSVCell(value[1:]) == value {
+		return value[1:]
+	}
+	return value
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func NewProductImportUsecase(jobRepo repository.ProductImportJobRepositoryInterface, productRepo repository.ProductRepositoryInterface, categoryRepo repository.CategoryRepositoryInterface, productUsecase ProductUsecaseInterface, categoryUsecase CategoryUsecaseInterface, uploader *storage.FileUploadHelper) ProductImportUsecaseInterface {
+	return &productImportUsecase{
+		jobRepo:         jobRepo,
+		productRepo:     productRepo,
+		categoryRepo:    categoryRepo,
+		productUsecase:  productUsecase,
+		categoryUsecase: categoryUsecase,
+		uploader:        uploader,
+		wake:            make(chan struct{}, 1),
+	}
+}
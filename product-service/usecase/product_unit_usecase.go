@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/rabbitmq"
+	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var (
+	ErrUnitNameTaken = errors.New("the product already has a unit with this name")
+	ErrUnitIsBase    = errors.New("a unit cannot be named after the base unit of the product")
+	ErrUnitFactor    = errors.New("a unit has to hold at least 2 base units")
+)
+
+type ProductUnitUsecaseInterface interface {
+	CreateUnit(ctx context.Context, unit *model.ProductUnit) error
+	GetUnits(ctx context.Context, productID uint) ([]model.ProductUnit, error)
+	UpdateUnit(ctx context.Context, unit *model.ProductUnit) error
+	DeleteUnit(ctx context.Context, productID, id uint) error
+}
+
+type productUnitUsecase struct {
+	productRepo repository.ProductRepositoryInterface
+	unitRepo    repository.ProductUnitRepositoryInterface
+	barcodes    barcodeChecker
+	publisher   rabbitmq.CatalogPublisher
+}
+
+// CreateUnit implements ProductUnitUsecaseInterface.
+func (p *productUnitUsecase) CreateUnit(ctx context.Context, unit *model.ProductUnit) error {
+	if err := p.checkUnit(ctx, unit); err != nil {
+		return err
+	}
+
+	if err := p.unitRepo.CreateUnit(ctx, unit); err != nil {
+		return err
+	}
+
+	p.publish(unit.ProductID)
+	return nil
+}
+
+// GetUnits implements ProductUnitUsecaseInterface.
+func (p *productUnitUsecase) GetUnits(ctx context.Context, productID uint) ([]model.ProductUnit, error) {
+	if _, err := p.productRepo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return p.unitRepo.GetUnitsByProductID(ctx, productID)
+}
+
+// UpdateUnit implements ProductUnitUsecaseInterface. Stock is kept in base
+// units, so changing a factor only changes how later quantities convert.
+func (p *productUnitUsecase) UpdateUnit(ctx context.Context, unit *model.ProductUnit) error {
+	if _, err := p.unitRepo.GetUnitByID(ctx, unit.ProductID, unit.ID); err != nil {
+		return err
+	}
+
+	if err := p.checkUnit(ctx, unit); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	unit.UpdatedAt = &now
+	if err := p.unitRepo.UpdateUnit(ctx, unit); err != nil {
+		return err
+	}
+
+	p.publish(unit.ProductID)
+	return nil
+}
+
+// DeleteUnit implements ProductUnitUsecaseInterface.
+func (p *productUnitUsecase) DeleteUnit(ctx context.Context, productID uint, id uint) error {
+	if err := p.unitRepo.DeleteUnit(ctx, productID, id); err != nil {
+		return err
+	}
+
+	p.publish(productID)
+	return nil
+}
+
+// checkUnit makes sure the unit holds more than one base unit, its name is
+// unique within the product and differs from the base unit, and the barcode
+// is not used elsewhere.
+func (p *productUnitUsecase) checkUnit(ctx context.Context, unit *model.ProductUnit) error {
+	unit.Name = strings.TrimSpace(unit.Name)
+	unit.Barcode = strings.TrimSpace(unit.Barcode)
+	if unit.Factor < 2 {
+		return ErrUnitFactor
+	}
+
+	product, err := p.productRepo.GetProductByID(ctx, unit.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(unit.Name, product.BaseUnit) {
+		return ErrUnitIsBase
+	}
+	for _, existing := range product.Units {
+		if existing.ID != unit.ID && strings.EqualFold(existing.Name, unit.Name) {
+			return ErrUnitNameTaken
+		}
+	}
+
+	return p.barcodes.check(ctx, unit.Barcode, barcodeOwner{unitID: unit.ID})
+}
+
+// publish announces the change of a unit as a change of its product.
+func (p *productUnitUsecase) publish(productID uint) {
+	event := rabbitmq.CatalogEvent{Type: rabbitmq.EventProductUpdated, ProductID: productID}
+	if err := p.publisher.PublishCatalogEvent(event); err != nil {
+		log.Errorf("[ProductUnitUsecase] publish - 1: %v", err)
+	}
+}
+
+func NewProductUnitUsecase(productRepo repository.ProductRepositoryInterface, variantRepo repository.ProductVariantRepositoryInterface, unitRepo repository.ProductUnitRepositoryInterface, publisher rabbitmq.CatalogPublisher) ProductUnitUsecaseInterface {
+	return &productUnitUsecase{
+		productRepo: productRepo,
+		unitRepo:    unitRepo,
+		barcodes:    barcodeChecker{productRepo: productRepo, variantRepo: variantRepo, unitRepo: unitRepo},
+		publisher:   publisher,
+	}
+}
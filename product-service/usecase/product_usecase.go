@@ -2,13 +2,22 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/httpclient"
+	"warehouse-go/product-service/pkg/rabbitmq"
 	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type ProductUsecaseInterface interface {
 	CreateProduct(ctx context.Context, product *model.Product) error
-	GetAllProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Product, int64, error)
+	// merchantID limits the call to products that merchant has in stock, 0
+	// means no limit. The facets count every category of the result.
+	GetAllProducts(ctx context.Context, page, limit int, filter repository.ProductFilter, merchantID uint, caller httpclient.Caller, sortBy, sortOrder string) ([]model.Product, int64, []model.CategoryFacet, error)
 	GetProductByID(ctx context.Context, id uint) (*model.Product, error)
 	GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error)
 	UpdateProduct(ctx context.Context, product *model.Product) error
@@ -17,26 +26,121 @@ type ProductUsecaseInterface interface {
 
 type productUsecase struct {
 	productRepo repository.ProductRepositoryInterface
+	variantRepo repository.ProductVariantRepositoryInterface
+	unitRepo    repository.ProductUnitRepositoryInterface
+	barcodes    barcodeChecker
+	publisher   rabbitmq.CatalogPublisher
+	merchantClient httpclient.MerchantClientInterface
 }
 
 // CreateProduct implements ProductUsecaseInterface.
 func (p *productUsecase) CreateProduct(ctx context.Context, product *model.Product) error {
-	return p.productRepo.CreateProduct(ctx, product)
+	if product.BaseUnit == "" {
+		product.BaseUnit = model.DefaultBaseUnit
+	}
+
+	if err := p.barcodes.check(ctx, product.Barcode, barcodeOwner{}); err != nil {
+		return err
+	}
+
+	if err := p.productRepo.CreateProduct(ctx, product); err != nil {
+		return err
+	}
+
+	p.publish(rabbitmq.EventProductCreated, product.ID)
+	return nil
 }
 
 // DeleteProduct implements ProductUsecaseInterface.
 func (p *productUsecase) DeleteProduct(ctx context.Context, id uint) error {
-	return p.productRepo.DeleteProduct(ctx, id)
+	if err := p.productRepo.DeleteProduct(ctx, id); err != nil {
+		return err
+	}
+
+	p.publish(rabbitmq.EventProductDeleted, id)
+	return nil
 }
 
 // GetAllProducts implements ProductUsecaseInterface.
-func (p *productUsecase) GetAllProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Product, int64, error) {
-	return p.productRepo.GetAllProducts(ctx, page, limit, search, sortBy, sortOrder)
+func (p *productUsecase) GetAllProducts(ctx context.Context, page int, limit int, filter repository.ProductFilter, merchantID uint, caller httpclient.Caller, sortBy string, sortOrder string) ([]model.Product, int64, []model.CategoryFacet, error) {
+	if merchantID != 0 {
+		productIDs, err := p.merchantClient.GetInStockProductIDs(ctx, merchantID, caller)
+		if err != nil {
+			log.Errorf("[ProductUsecase] GetAllProducts - 1: %v", err)
+			return nil, 0, nil, err
+		}
+		if filter.ProductIDs != nil {
+			productIDs = intersectIDs(filter.ProductIDs, productIDs)
+		}
+		filter.ProductIDs = productIDs
+	}
+
+	products, total, err := p.productRepo.GetAllProducts(ctx, page, limit, filter, sortBy, sortOrder)
+	if err != nil {
+		log.Errorf("[ProductUsecase] GetAllProducts - 2: %v", err)
+		return nil, 0, nil, err
+	}
+
+	facets, err := p.productRepo.GetCategoryFacets(ctx, filter)
+	if err != nil {
+		log.Errorf("[ProductUsecase] GetAllProducts - 3: %v", err)
+		return nil, 0, nil, err
+	}
+
+	return products, total, facets, nil
 }
 
-// GetProductByBarcode implements ProductUsecaseInterface.
+// intersectIDs keeps the ids of a that are in b as well. It returns an empty
+// slice, not nil, when none are, so the result still filters.
+func intersectIDs(a, b []uint) []uint {
+	in := make(map[uint]bool, len(b))
+	for _, id := range b {
+		in[id] = true
+	}
+	ids := []uint{}
+	for _, id := range a {
+		if in[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GetProductByBarcode implements ProductUsecaseInterface. A variant or unit
+// barcode finds its product too, with ScannedVariantID or ScannedUnit set.
 func (p *productUsecase) GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error) {
-	return p.productRepo.GetProductByBarcode(ctx, barcode)
+	product, err := p.productRepo.GetProductByBarcode(ctx, barcode)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return product, err
+	}
+
+	variant, err := p.variantRepo.GetVariantByBarcode(ctx, barcode)
+	if err == nil {
+		product, err := p.productRepo.GetProductByID(ctx, variant.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		product.ScannedVariantID = variant.ID
+		return product, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("[ProductUsecase] GetProductByBarcode - 1: %v", err)
+		return nil, err
+	}
+
+	unit, err := p.unitRepo.GetUnitByBarcode(ctx, barcode)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Errorf("[ProductUsecase] GetProductByBarcode - 2: %v", err)
+		}
+		return nil, err
+	}
+	product, err = p.productRepo.GetProductByID(ctx, unit.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	product.ScannedUnit = unit.Name
+	return product, nil
 }
 
 // GetProductByID implements ProductUsecaseInterface.
@@ -46,9 +150,46 @@ func (p *productUsecase) GetProductByID(ctx context.Context, id uint) (*model.Pr
 
 // UpdateProduct implements ProductUsecaseInterface.
 func (p *productUsecase) UpdateProduct(ctx context.Context, product *model.Product) error {
-	return p.productRepo.UpdateProduct(ctx, product)
+	if err := p.barcodes.check(ctx, product.Barcode, barcodeOwner{productID: product.ID}); err != nil {
+		return err
+	}
+
+	if product.BaseUnit != "" {
+		units, err := p.unitRepo.GetUnitsByProductID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		for _, unit := range units {
+			if strings.EqualFold(unit.Name, product.BaseUnit) {
+				return ErrUnitIsBase
+			}
+		}
+	}
+
+	if err := p.productRepo.UpdateProduct(ctx, product); err != nil {
+		return err
+	}
+
+	p.publish(rabbitmq.EventProductUpdated, product.ID)
+	return nil
+}
+
+// publish announces a committed change. A failure is only logged, the change
+// itself already succeeded.
+func (p *productUsecase) publish(eventType string, id uint) {
+	event := rabbitmq.CatalogEvent{Type: eventType, ProductID: id}
+	if err := p.publisher.PublishCatalogEvent(event); err != nil {
+		log.Errorf("[ProductUsecase] publish - 1: %v", err)
+	}
 }
 
-func NewProductUsecase(productRepo repository.ProductRepositoryInterface) ProductUsecaseInterface {
-	return &productUsecase{productRepo: productRepo}
+func NewProductUsecase(productRepo repository.ProductRepositoryInterface, variantRepo repository.ProductVariantRepositoryInterface, unitRepo repository.ProductUnitRepositoryInterface, publisher rabbitmq.CatalogPublisher, merchantClient httpclient.MerchantClientInterface) ProductUsecaseInterface {
+	return &productUsecase{
+		productRepo: productRepo,
+		variantRepo: variantRepo,
+		unitRepo:    unitRepo,
+		barcodes:    barcodeChecker{productRepo: productRepo, variantRepo: variantRepo, unitRepo: unitRepo},
+		publisher:   publisher,
+		merchantClient: merchantClient,
+	}
 }
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"warehouse-go/product-service/model"
+	"warehouse-go/product-service/pkg/rabbitmq"
+	"warehouse-go/product-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+var ErrSKUTaken = errors.New("sku is already used by another variant")
+
+type ProductVariantUsecaseInterface interface {
+	CreateVariant(ctx context.Context, variant *model.ProductVariant) error
+	GetVariants(ctx context.Context, productID uint) ([]model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *model.ProductVariant) error
+	DeleteVariant(ctx context.Context, productID, id uint) error
+}
+
+type productVariantUsecase struct {
+	productRepo repository.ProductRepositoryInterface
+	variantRepo repository.ProductVariantRepositoryInterface
+	barcodes    barcodeChecker
+	publisher   rabbitmq.CatalogPublisher
+}
+
+// CreateVariant implements ProductVariantUsecaseInterface.
+func (p *productVariantUsecase) CreateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	if _, err := p.productRepo.GetProductByID(ctx, variant.ProductID); err != nil {
+		return err
+	}
+
+	if err := p.checkVariant(ctx, variant); err != nil {
+		return err
+	}
+
+	if err := p.variantRepo.CreateVariant(ctx, variant); err != nil {
+		return err
+	}
+
+	p.publish(variant.ProductID)
+	return nil
+}
+
+// GetVariants implements ProductVariantUsecaseInterface.
+func (p *productVariantUsecase) GetVariants(ctx context.Context, productID uint) ([]model.ProductVariant, error) {
+	if _, err := p.productRepo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return p.variantRepo.GetVariantsByProductID(ctx, productID)
+}
+
+// UpdateVariant implements ProductVariantUsecaseInterface.
+func (p *productVariantUsecase) UpdateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	if _, err := p.variantRepo.GetVariantByID(ctx, variant.ProductID, variant.ID); err != nil {
+		return err
+	}
+
+	if err := p.checkVariant(ctx, variant); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	variant.UpdatedAt = &now
+	if err := p.variantRepo.UpdateVariant(ctx, variant); err != nil {
+		return err
+	}
+
+	p.publish(variant.ProductID)
+	return nil
+}
+
+// DeleteVariant implements ProductVariantUsecaseInterface.
+func (p *productVariantUsecase) DeleteVariant(ctx context.Context, productID uint, id uint) error {
+	if err := p.variantRepo.DeleteVariant(ctx, productID, id); err != nil {
+		return err
+	}
+
+	p.publish(productID)
+	return nil
+}
+
+// checkVariant makes sure the SKU and barcode are not used elsewhere.
+func (p *productVariantUsecase) checkVariant(ctx context.Context, variant *model.ProductVariant) error {
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	variant.Barcode = strings.TrimSpace(variant.Barcode)
+
+	existing, err := p.variantRepo.GetVariantBySKU(ctx, variant.SKU)
+	if err == nil && existing.ID != variant.ID {
+		return ErrSKUTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("[ProductVariantUsecase] checkVariant - 1: %v", err)
+		return err
+	}
+
+	return p.barcodes.check(ctx, variant.Barcode, barcodeOwner{variantID: variant.ID})
+}
+
+// publish announces the change of a variant as a change of its product,
+// which is what caches of other services hold.
+func (p *productVariantUsecase) publish(productID uint) {
+	event := rabbitmq.CatalogEvent{Type: rabbitmq.EventProductUpdated, ProductID: productID}
+	if err := p.publisher.PublishCatalogEvent(event); err != nil {
+		log.Errorf("[ProductVariantUsecase] publish - 1: %v", err)
+	}
+}
+
+func NewProductVariantUsecase(productRepo repository.ProductRepositoryInterface, variantRepo repository.ProductVariantRepositoryInterface, unitRepo repository.ProductUnitRepositoryInterface, publisher rabbitmq.CatalogPublisher) ProductVariantUsecaseInterface {
+	return &productVariantUsecase{
+		productRepo: productRepo,
+		variantRepo: variantRepo,
+		barcodes:    barcodeChecker{productRepo: productRepo, variantRepo: variantRepo, unitRepo: unitRepo},
+		publisher:   publisher,
+	}
+}
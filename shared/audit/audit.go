@@ -0,0 +1,249 @@
+// Package audit records who changed what. Mutating routes are wrapped with
+// Tracker.Track, which hands an Event to a Recorder once the handler has
+// succeeded. user-service stores the events and serves them at /audit, the
+// other services publish theirs to the audit_events exchange.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// The api gateway sets these headers; X-User-ID is missing on calls from
+// other services, which are recorded with actor 0.
+const (
+	UserIDHeader    = "X-User-ID"
+	RequestIDHeader = "X-Request-ID"
+	RealIPHeader    = "X-Real-IP"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Event is one change. Before and After are the state of the entity as JSON
+// with secrets removed, Changes the top level fields that differ between
+// them.
+type Event struct {
+	Service   string            `json:"service"`
+	Action    string            `json:"action"`
+	Entity    string            `json:"entity"`
+	EntityID  string            `json:"entity_id,omitempty"`
+	ActorID   uint              `json:"actor_id"`
+	Before    json.RawMessage   `json:"before,omitempty"`
+	After     json.RawMessage   `json:"after,omitempty"`
+	Changes   map[string]Change `json:"changes,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Recorder keeps or forwards audit events.
+type Recorder interface {
+	RecordAuditEvent(ctx context.Context, event Event) error
+}
+
+// Loader reads the current state of an entity.
+type Loader func(ctx context.Context, id uint) (interface{}, error)
+
+type Tracker struct {
+	service  string
+	recorder Recorder
+	loaders  map[string]Loader
+}
+
+func NewTracker(service string, recorder Recorder) *Tracker {
+	return &Tracker{
+		service:  service,
+		recorder: recorder,
+		loaders:  make(map[string]Loader),
+	}
+}
+
+// Register sets how an entity is loaded, so that changes to it record its
+// state before and after. Entities without a loader record the request body
+// as their new state. Creates take the id of the new entity from data.id of
+// the response.
+func (t *Tracker) Register(entity string, loader Loader) {
+	t.loaders[entity] = loader
+}
+
+// Track records a create, update or delete of entity, after the method of
+// the request. param names the route parameter holding the entity id and
+// may be empty.
+func (t *Tracker) Track(entity, param string) fiber.Handler {
+	return t.TrackAction("", entity, param)
+}
+
+// TrackCreate records a create whose response carries the new id in
+// data.<field> instead of data.id.
+func (t *Tracker) TrackCreate(entity, field string) fiber.Handler {
+	return t.track(ActionCreate, entity, field, func(c *fiber.Ctx) string {
+		return ""
+	})
+}
+
+// TrackAction is Track for requests that are not plain creates, updates or
+// deletes, e.g. unlocking a user.
+func (t *Tracker) TrackAction(action, entity, param string) fiber.Handler {
+	return t.track(action, entity, "id", func(c *fiber.Ctx) string {
+		return c.Params(param)
+	})
+}
+
+// Skip tells the tracker that the request changed nothing, e.g. a dry run,
+// so no event is recorded for it.
+func Skip(c *fiber.Ctx) {
+	c.Locals(skipLocal, true)
+}
+
+const skipLocal = "audit_skip"
+
+// TrackSelf records action on the caller's own entity, e.g. a user changing
+// their password.
+func (t *Tracker) TrackSelf(action, entity string) fiber.Handler {
+	return t.track(action, entity, "id", func(c *fiber.Ctx) string {
+		return c.Get(UserIDHeader)
+	})
+}
+
+func (t *Tracker) track(action, entity, idField string, entityID func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		event := Event{
+			Service:  t.service,
+			Action:   action,
+			Entity:   entity,
+			EntityID: entityID(c),
+		}
+		if event.Action == "" {
+			event.Action = methodAction(c.Method())
+		}
+
+		loader := t.loaders[entity]
+		if id := parseID(event.EntityID); loader != nil && id != 0 {
+			event.Before = t.load(c, loader, id)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status < 200 || status >= 300 {
+			return nil
+		}
+		if skip, _ := c.Locals(skipLocal).(bool); skip {
+			return nil
+		}
+
+		if event.EntityID == "" {
+			event.EntityID = responseID(c.Response().Body(), idField)
+		}
+		if event.Action != ActionDelete {
+			if id := parseID(event.EntityID); loader != nil && id != 0 {
+				event.After = t.load(c, loader, id)
+			} else if event.After = redact(c.Body()); event.After == nil {
+				// Uploads are no JSON, the response tells what was done.
+				event.After = responseData(c.Response().Body())
+			}
+		}
+
+		event.Changes = diff(event.Before, event.After)
+		event.ActorID = actorID(c)
+		event.RequestID = c.Get(RequestIDHeader)
+		event.IP = clientIP(c)
+		event.Timestamp = time.Now()
+
+		// The change is done, a lost audit event must not fail the request.
+		if err := t.recorder.RecordAuditEvent(c.Context(), event); err != nil {
+			log.Errorf("[Audit] %s %s %s - %v", event.Action, event.Entity, event.EntityID, err)
+		}
+		return nil
+	}
+}
+
+func (t *Tracker) load(c *fiber.Ctx, loader Loader, id uint) json.RawMessage {
+	value, err := loader(c.Context(), id)
+	if err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return redact(data)
+}
+
+func parseID(value string) uint {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+func methodAction(method string) string {
+	switch method {
+	case fiber.MethodPost:
+		return ActionCreate
+	case fiber.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionUpdate
+	}
+}
+
+func actorID(c *fiber.Ctx) uint {
+	return parseID(c.Get(UserIDHeader))
+}
+
+func clientIP(c *fiber.Ctx) string {
+	if ip := c.Get(RealIPHeader); ip != "" {
+		return ip
+	}
+	return c.IP()
+}
+
+// responseData returns data of a standard response, without secrets.
+func responseData(body []byte) json.RawMessage {
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Data) == 0 {
+		return nil
+	}
+	return redact(response.Data)
+}
+
+// responseID returns data.<field> of a standard response, for creates whose
+// route has no id yet.
+func responseID(body []byte, field string) string {
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return ""
+	}
+	switch id := response.Data[field].(type) {
+	case json.Number:
+		return id.String()
+	case string:
+		return id
+	default:
+		return ""
+	}
+}
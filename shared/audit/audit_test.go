@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResponseID(t *testing.T) {
+	for _, tc := range []struct {
+		body  string
+		field string
+		want  string
+	}{
+		{`{"message":"created","data":{"id":42}}`, "id", "42"},
+		{`{"data":{"id":12345678901}}`, "id", "12345678901"},
+		{`{"data":{"job_id":"7f3c"}}`, "job_id", "7f3c"},
+		{`{"data":{"id":42}}`, "job_id", ""},
+		{`{"data":[{"id":1}]}`, "id", ""},
+		{`{"data":{"id":true}}`, "id", ""},
+		{`not json`, "id", ""},
+	} {
+		if got := responseID([]byte(tc.body), tc.field); got != tc.want {
+			t.Errorf("responseID(%s, %s) = %q, want %q", tc.body, tc.field, got, tc.want)
+		}
+	}
+}
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) RecordAuditEvent(ctx context.Context, event Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+// newTrackedApp serves POST /items/:id behind tracker.Track. The handler
+// answers with the status in ?status= and calls Skip for ?skip=1.
+func newTrackedApp(tracker *Tracker) *fiber.App {
+	app := fiber.New()
+	app.Post("/items/:id", tracker.Track("item", "id"), func(c *fiber.Ctx) error {
+		if c.Query("skip") == "1" {
+			Skip(c)
+		}
+		return c.Status(c.QueryInt("status", fiber.StatusOK)).JSON(fiber.Map{
+			"data": fiber.Map{"id": 7},
+		})
+	})
+	return app
+}
+
+func post(t *testing.T, app *fiber.App, target string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"name":"box","password":"x"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(UserIDHeader, "3")
+	req.Header.Set(RequestIDHeader, "req-1")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestTrackRecordsSuccess(t *testing.T) {
+	recorded := &recorder{}
+	post(t, newTrackedApp(NewTracker("test-service", recorded)), "/items/7")
+
+	if len(recorded.events) != 1 {
+		t.Fatalf("%d events recorded, want 1", len(recorded.events))
+	}
+	event := recorded.events[0]
+	if event.Service != "test-service" || event.Action != ActionCreate || event.Entity != "item" || event.EntityID != "7" {
+		t.Errorf("event %s %s %s %s, want test-service create item 7", event.Service, event.Action, event.Entity, event.EntityID)
+	}
+	if event.ActorID != 3 || event.RequestID != "req-1" {
+		t.Errorf("actor %d request %q, want 3 and req-1", event.ActorID, event.RequestID)
+	}
+	if string(event.After) != `{"name":"box"}` {
+		t.Errorf("after %s, want the body without the password", event.After)
+	}
+}
+
+func TestTrackLoadsStateBeforeAndAfter(t *testing.T) {
+	stock := 1
+	recorded := &recorder{}
+	tracker := NewTracker("test-service", recorded)
+	tracker.Register("item", func(ctx context.Context, id uint) (interface{}, error) {
+		return map[string]interface{}{"id": id, "stock": stock}, nil
+	})
+
+	app := fiber.New()
+	app.Put("/items/:id", tracker.Track("item", "id"), func(c *fiber.Ctx) error {
+		stock = 5
+		return c.SendStatus(fiber.StatusOK)
+	})
+	resp, err := app.Test(httptest.NewRequest(http.MethodPut, "/items/7", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(recorded.events) != 1 {
+		t.Fatalf("%d events recorded, want 1", len(recorded.events))
+	}
+	changes, _ := json.Marshal(recorded.events[0].Changes)
+	if string(changes) != `{"stock":{"before":1,"after":5}}` {
+		t.Errorf("changes %s, want stock from 1 to 5", changes)
+	}
+}
+
+func TestTrackRecordsNothing(t *testing.T) {
+	for _, target := range []string{
+		"/items/7?status=400",
+		"/items/7?status=404",
+		"/items/7?status=500",
+		"/items/7?skip=1",
+	} {
+		recorded := &recorder{}
+		post(t, newTrackedApp(NewTracker("test-service", recorded)), target)
+
+		if len(recorded.events) != 0 {
+			t.Errorf("%s: %d events recorded, want none", target, len(recorded.events))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+// Exchange carries audit events to user-service, which stores them. The
+// routing key is <service>.<entity>.<action>.
+const Exchange = "audit_events"
+
+// Publisher is the Recorder of the services other than user-service.
+type Publisher struct {
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+func NewPublisher(url string) (*Publisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		log.Errorf("[AuditPublisher] NewPublisher - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[AuditPublisher] NewPublisher - 2: %v", err)
+		return nil, err
+	}
+
+	err = ch.ExchangeDeclare(
+		Exchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		log.Errorf("[AuditPublisher] NewPublisher - 3: %v", err)
+		return nil, err
+	}
+
+	return &Publisher{conn: conn, ch: ch}, nil
+}
+
+// RecordAuditEvent implements Recorder. Unlike realtime events audit
+// events are persistent, they must survive a broker restart.
+func (a *Publisher) RecordAuditEvent(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return a.ch.Publish(
+		Exchange,
+		event.Service+"."+event.Entity+"."+event.Action,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+			Timestamp:    event.Timestamp,
+		},
+	)
+}
+
+func (a *Publisher) Close() error {
+	if a.ch != nil {
+		a.ch.Close()
+	}
+	if a.conn != nil {
+		return a.conn.Close()
+	}
+	return nil
+}
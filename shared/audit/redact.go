@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// secretFields are dropped from every recorded state, at any depth.
+var secretFields = map[string]bool{
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"token":            true,
+	"challenge_token":  true,
+	"secret":           true,
+	"code":             true,
+	"recovery_codes":   true,
+	"key":              true,
+	"signature_key":    true,
+}
+
+// redact returns data without secret fields, or nil when data is not JSON.
+func redact(data []byte) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(strip(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func strip(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if secretFields[key] {
+				delete(v, key)
+				continue
+			}
+			v[key] = strip(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = strip(v[i])
+		}
+	}
+	return value
+}
+
+// diff compares two JSON objects field by field. updated_at is left out, it
+// changes with everything else.
+func diff(before, after json.RawMessage) map[string]Change {
+	if before == nil || after == nil {
+		return nil
+	}
+
+	var was, now map[string]interface{}
+	if json.Unmarshal(before, &was) != nil || json.Unmarshal(after, &now) != nil {
+		return nil
+	}
+
+	changes := make(map[string]Change)
+	for key, value := range now {
+		if key != "updated_at" && !reflect.DeepEqual(was[key], value) {
+			changes[key] = Change{Before: was[key], After: value}
+		}
+	}
+	for key, value := range was {
+		if _, ok := now[key]; !ok {
+			changes[key] = Change{Before: value}
+		}
+	}
+	return changes
+}
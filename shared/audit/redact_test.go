@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"top level", `{"email":"a@b.c","password":"x","new_password":"y"}`, `{"email":"a@b.c"}`},
+		{"nested", `{"user":{"name":"a","token":"t"},"keys":[{"id":1,"key":"k"}]}`, `{"keys":[{"id":1}],"user":{"name":"a"}}`},
+		{"array", `[{"code":"123456","id":2}]`, `[{"id":2}]`},
+		{"nothing secret", `{"name":"a","stock":3}`, `{"name":"a","stock":3}`},
+	} {
+		got := redact([]byte(tc.in))
+		if string(got) != tc.want {
+			t.Errorf("%s: redact = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	if got := redact([]byte("--boundary\r\nnot json")); got != nil {
+		t.Errorf("redact of a multipart body = %s, want nil", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		before string
+		after  string
+		want   map[string]Change
+	}{
+		{
+			name:   "changed, added and removed fields",
+			before: `{"name":"a","stock":1,"note":"x","updated_at":"2024-01-01"}`,
+			after:  `{"name":"b","stock":1,"city":"y","updated_at":"2024-01-02"}`,
+			want: map[string]Change{
+				"name": {Before: "a", After: "b"},
+				"city": {After: "y"},
+				"note": {Before: "x"},
+			},
+		},
+		{
+			name:   "nested values compare deeply",
+			before: `{"roles":["Keeper"]}`,
+			after:  `{"roles":["Keeper","Manager"]}`,
+			want: map[string]Change{
+				"roles": {Before: []interface{}{"Keeper"}, After: []interface{}{"Keeper", "Manager"}},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: `{"name":"a"}`,
+			after:  `{"name":"a","updated_at":"2024-01-02"}`,
+			want:   map[string]Change{},
+		},
+	} {
+		got := diff(json.RawMessage(tc.before), json.RawMessage(tc.after))
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: diff = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if got := diff(nil, json.RawMessage(`{"name":"a"}`)); got != nil {
+		t.Errorf("diff without a before state = %v, want nil", got)
+	}
+	if got := diff(json.RawMessage(`[1]`), json.RawMessage(`{"name":"a"}`)); got != nil {
+		t.Errorf("diff of an array = %v, want nil", got)
+	}
+}
@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Command returns the migrate command of a service, with the up, down and
+// status subcommands. open connects to the service database when one of
+// them runs.
+func Command(open func() (*Migrator, error)) *cobra.Command {
+	var downSteps int
+
+	migrator := func() *Migrator {
+		migrator, err := open()
+		if err != nil {
+			log.Fatalf("Failed to open migrations: %v", err)
+		}
+		return migrator
+	}
+
+	upCmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply every pending migration",
+		Run: func(cmd *cobra.Command, args []string) {
+			applied, err := migrator().Up(context.Background())
+			for _, migration := range applied {
+				fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+			}
+			if err != nil {
+				log.Fatalf("Failed to migrate database: %v", err)
+			}
+			if len(applied) == 0 {
+				fmt.Println("no pending migrations")
+			}
+		},
+	}
+
+	downCmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the last applied migrations",
+		Run: func(cmd *cobra.Command, args []string) {
+			reverted, err := migrator().Down(context.Background(), downSteps)
+			for _, migration := range reverted {
+				fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+			}
+			if err != nil {
+				log.Fatalf("Failed to revert migrations: %v", err)
+			}
+		},
+	}
+	downCmd.Flags().IntVar(&downSteps, "steps", 1, "number of migrations to revert")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "List the migrations and whether they are applied",
+		Run: func(cmd *cobra.Command, args []string) {
+			statuses, err := migrator().Status(context.Background())
+			if err != nil {
+				log.Fatalf("Failed to read migrations: %v", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+			for _, status := range statuses {
+				appliedAt := "pending"
+				if status.AppliedAt != nil {
+					appliedAt = status.AppliedAt.Format(time.RFC3339)
+				}
+				if status.Missing {
+					appliedAt += " (file missing)"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+			}
+			w.Flush()
+		},
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, revert or list the database migrations",
+	}
+	migrateCmd.AddCommand(upCmd, downCmd, statusCmd)
+	return migrateCmd
+}
@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadSortsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/000010_add_price.up.sql":      {Data: []byte("ALTER TABLE items ADD COLUMN price bigint")},
+		"migrations/000002_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id bigint)")},
+		"migrations/000002_create_items.down.sql": {Data: []byte("DROP TABLE items")},
+		"migrations/000001_create_schema.up.sql":  {Data: []byte("SELECT 1")},
+		"migrations/archive/000003_old.up.sql":    {Data: []byte("SELECT 1")},
+	}
+
+	migrations, err := load(fsys, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "create_schema", Up: "SELECT 1"},
+		{Version: 2, Name: "create_items", Up: "CREATE TABLE items (id bigint)", Down: "DROP TABLE items"},
+		{Version: 10, Name: "add_price", Up: "ALTER TABLE items ADD COLUMN price bigint"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d", len(migrations), len(want))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files []string
+		err   string
+	}{
+		{"bad name", []string{"1-create.up.sql"}, "name must be"},
+		{"upper case name", []string{"000001_Create.up.sql"}, "name must be"},
+		{"two names", []string{"000001_create.up.sql", "000001_other.down.sql"}, "has two names"},
+		{"no up file", []string{"000001_create.down.sql"}, "has no up file"},
+	} {
+		fsys := fstest.MapFS{}
+		for _, file := range tc.files {
+			fsys["migrations/"+file] = &fstest.MapFile{Data: []byte("SELECT 1")}
+		}
+
+		_, err := load(fsys, "migrations")
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error %v, want one containing %q", tc.name, err, tc.err)
+		}
+	}
+}
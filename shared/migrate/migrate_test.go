@@ -0,0 +1,88 @@
+package migrate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"warehouse-go/shared/migrate"
+	"warehouse-go/shared/migrate/migratetest"
+
+	"gorm.io/gorm"
+)
+
+// items follows the rules for real migrations: the first one is the schema
+// AutoMigrate created, later ones only add what is missing.
+var items = fstest.MapFS{
+	"migrations/000001_create_items.up.sql":   {Data: []byte(`CREATE TABLE IF NOT EXISTS "items" ("id" bigserial PRIMARY KEY, "name" text)`)},
+	"migrations/000001_create_items.down.sql": {Data: []byte(`DROP TABLE IF EXISTS "items"`)},
+	"migrations/000002_item_price.up.sql":     {Data: []byte(`ALTER TABLE "items" ADD COLUMN IF NOT EXISTS "price" bigint NOT NULL DEFAULT 0`)},
+	"migrations/000002_item_price.down.sql":   {Data: []byte(`ALTER TABLE "items" DROP COLUMN IF EXISTS "price"`)},
+}
+
+// item is items as AutoMigrate saw it before the migrations.
+type item struct {
+	ID    uint
+	Name  string
+	Price int64
+}
+
+func newMigrator(t *testing.T, db *gorm.DB, fsys fstest.MapFS) *migrate.Migrator {
+	t.Helper()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, fsys, "migrations", "migratetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func TestUpDownOnEmptySchema(t *testing.T) {
+	db := migratetest.Schema(t)
+	migratetest.UpDown(t, db, newMigrator(t, db, items))
+}
+
+func TestUpDownOnAutoMigrateSchema(t *testing.T) {
+	db := migratetest.Schema(t)
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	migratetest.UpDown(t, db, newMigrator(t, db, items))
+}
+
+// A failing migration is rolled back with its bookkeeping, the ones before
+// it stay applied.
+func TestFailedMigrationLeavesNothing(t *testing.T) {
+	db := migratetest.Schema(t)
+	fsys := fstest.MapFS{
+		"migrations/000003_broken.up.sql": {Data: []byte(`ALTER TABLE "items" ADD COLUMN "sku" text; SELECT 1/0`)},
+	}
+	for name, file := range items {
+		fsys[name] = file
+	}
+	migrator := newMigrator(t, db, fsys)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	if err == nil {
+		t.Fatal("up succeeded with a failing migration")
+	}
+	if len(applied) != 2 {
+		t.Errorf("applied %d migrations before the failing one, want 2", len(applied))
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := statuses[len(statuses)-1]; last.Version != 3 || last.AppliedAt != nil {
+		t.Errorf("failing migration status %+v, want pending", last)
+	}
+	if db.Migrator().HasColumn("items", "sku") {
+		t.Error("column of the failing migration was kept")
+	}
+}
@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"log"
+	"warehouse-go/shared/audit"
 	"warehouse-go/transaction-service/configs"
 	"warehouse-go/transaction-service/controller"
 	"warehouse-go/transaction-service/database"
@@ -14,6 +16,7 @@ import (
 
 type Container struct {
 	TransactionController controller.TransactionControllerInterface
+	AuditTracker *audit.Tracker
 }
 
 func BuildContainer() *Container {
@@ -23,12 +26,16 @@ func BuildContainer() *Container {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if !cfg.SqlDB.SkipMigrations {
+		if err := database.Migrate(context.Background(), db.DB); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 
 	transactionRepo := repository.NewTransactionRepository(db.DB)
 
 	//HTTP Clients
 	merchantClient := httpclient.NewMerchantClient(*cfg)
-	userClient := httpclient.NewUserClient(*cfg)
 	productClient := httpclient.NewProductClient(*cfg)
 
 	//RabbitMQ Client
@@ -37,11 +44,21 @@ func BuildContainer() *Container {
 		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
 
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, merchantClient, rabbitMQService, productClient, userClient )
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, merchantClient, rabbitMQService, productClient)
 	midtransService := midtrans.NewMidtransService(cfg)
 	transactionController := controller.NewTransactionController(transactionUsecase, midtransService)
-	
+
+	auditPublisher, err := audit.NewPublisher(cfg.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
+
+	// Transactions have no loader: a create records the order as requested,
+	// a payment callback the status Midtrans reported.
+	auditTracker := audit.NewTracker("transaction-service", auditPublisher)
+
 	return &Container{
 		TransactionController: transactionController,
+		AuditTracker: auditTracker,
 	}
 }
\ No newline at end of file
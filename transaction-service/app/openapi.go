@@ -0,0 +1,30 @@
+package app
+
+import (
+	"warehouse-go/transaction-service/controller/request"
+	"warehouse-go/transaction-service/controller/response"
+	"warehouse-go/transaction-service/pkg/openapi"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "Transaction Service",
+	Version:     "1.0.0",
+	Description: "Sales transactions, payments and dashboards",
+}
+
+type createTransactionResponse struct {
+	PaymentToken string `json:"payment_token"`
+	OrderID      string `json:"order_id"`
+}
+
+// openAPIOperations documents the request and response types of the routes
+// registered in SetupRoutes.
+var openAPIOperations = []openapi.Operation{
+	{Method: "POST", Path: "/api/v1/midtrans/callback", Summary: "Midtrans payment notification", Tags: []string{"midtrans"}, Request: request.MidtransCallbackRequest{}},
+
+	{Method: "GET", Path: "/api/v1/dashboard/manager", Summary: "Manager dashboard", Tags: []string{"dashboard"}, Response: response.DashboardResponse{}},
+	{Method: "GET", Path: "/api/v1/dashboard/keeper/merchant/:merchant_id", Summary: "Keeper dashboard for one merchant", Tags: []string{"dashboard"}, Response: response.DashboardByMerchantResponse{}},
+
+	{Method: "POST", Path: "/api/v1/transactions", Summary: "Create a transaction and start payment", Tags: []string{"transactions"}, Request: request.CreateTransactionWithProductRequest{}, Response: createTransactionResponse{}},
+	{Method: "GET", Path: "/api/v1/transactions", Summary: "List transactions", Tags: []string{"transactions"}, Query: request.GetAllTransactionRequest{}, Response: response.GetAllTransactionResponse{}},
+}
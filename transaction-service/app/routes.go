@@ -1,17 +1,30 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"warehouse-go/transaction-service/pkg/middleware"
+	"warehouse-go/transaction-service/pkg/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, container *Container) {
-	app.Post("/api/v1/midtrans/callback", container.TransactionController.MidtransCallback)
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "transaction-service",
+		})
+	})
+	app.Get("/openapi.json", openapi.Handler(app, openAPIInfo, openAPIOperations))
+
+	app.Post("/api/v1/midtrans/callback", container.AuditTracker.TrackAction("payment-status", "transaction", ""), container.TransactionController.MidtransCallback)
 
-	api := app.Group("api/v1")
+	api := app.Group("api/v1", middleware.RequireIdentity())
 
 	dashboard := api.Group("/dashboard")
-	dashboard.Get("/manager", container.TransactionController.GetManagerDashboard)
-	dashboard.Get("/keeper/merchant/:merchant_id", container.TransactionController.GetDashboardByMerchant)
+	dashboard.Get("/manager", middleware.RequirePermission(middleware.PermissionDashboardAll), container.TransactionController.GetManagerDashboard)
+	dashboard.Get("/keeper/merchant/:merchant_id", middleware.RequirePermission(middleware.PermissionDashboardAll, middleware.PermissionDashboardMerchant), container.TransactionController.GetDashboardByMerchant)
 
 	transactions := api.Group("/transactions")
-	transactions.Post("/", container.TransactionController.CreateTransaction)
+	transactions.Post("/", container.AuditTracker.TrackCreate("transaction", "order_id"), container.TransactionController.CreateTransaction)
 	transactions.Get("/", container.TransactionController.GetTransactions)
 }
\ No newline at end of file
@@ -23,6 +23,9 @@ type SqlDB struct {
 	DBname   string `json:"db_name"`
 	DBMaxOpenConns	int    `json:"db_max_open_conns"`
 	DBMaxIdleConns	int    `json:"db_max_idle_conns"`
+	// SkipMigrations leaves migrating to the migrate command instead of
+	// applying pending migrations on start.
+	SkipMigrations bool `json:"skip_migrations"`
 }
 
 type Redis struct {
@@ -82,6 +85,7 @@ func NewConfig() *Config {
 			DBname:   viper.GetString("DATABASE_NAME"),
 			DBMaxIdleConns: viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			DBMaxOpenConns: viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
+			SkipMigrations: viper.GetBool("DATABASE_SKIP_MIGRATIONS"),
 
 	},
 		RabbitMQ: RabbitMQ{
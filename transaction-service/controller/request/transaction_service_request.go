@@ -21,6 +21,8 @@ type CreateTransactionRequest struct {
 
 type CreateTransactionProductRequest struct {
 	ProductID 	uint 	`json:"product_id" validate:"required"`
+	VariantID 	uint 	`json:"variant_id" validate:"omitempty"`
+	Unit 		string 	`json:"unit" validate:"omitempty"`
 	Quantity 	int64 	`json:"quantity" validate:"required,min=1"`
 	Price   	int64 	`json:"price" validate:"required,min=1"`
 }	
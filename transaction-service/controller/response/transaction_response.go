@@ -27,6 +27,8 @@ type TransactionProductResponse struct {
 	ProductName   string `json:"product_name"`
 	ProductPhoto  string `json:"product_photo"`
 	ProductAbout  string `json:"product_about"`
+	VariantID     uint   `json:"variant_id"`
+	Unit          string `json:"unit"`
 	Quantity      int64  `json:"quantity"`
 	Price         int64  `json:"price"`
 	SubTotal      int64  `json:"sub_total"`
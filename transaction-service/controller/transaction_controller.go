@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"time"
 	"warehouse-go/transaction-service/controller/request"
 	"warehouse-go/transaction-service/controller/response"
 	"warehouse-go/transaction-service/model"
 	"warehouse-go/transaction-service/pkg/conv"
+	"warehouse-go/transaction-service/pkg/httpclient"
+	"warehouse-go/transaction-service/pkg/middleware"
 	"warehouse-go/transaction-service/pkg/midtrans"
 	"warehouse-go/transaction-service/pkg/pagination"
 	"warehouse-go/transaction-service/usecase"
@@ -78,15 +81,27 @@ func (t *transactionController) CreateTransaction(ctx *fiber.Ctx) error {
 	for _, product := range req.Products {
 		transaction.TransactionProducts = append(transaction.TransactionProducts, model.TransactionProduct{
 			ProductID: product.ProductID,
+			VariantID: product.VariantID,
+			Unit: product.Unit,
 			Quantity: product.Quantity,
 			Price: product.Price,
 			SubTotal: product.Price * product.Quantity,
 		})
 	}
 
-	_, err := t.transactionUsecase.CreateTransaction(ctx.Context(), transaction)
+	_, err := t.transactionUsecase.CreateTransaction(ctx.Context(), transaction, middleware.ScopedUserID(ctx))
 	if err != nil {
 		log.Errorf("[TransactionController] CreateTransaction - 2: %v", err)
+		if errors.Is(err, usecase.ErrMerchantAccessDenied) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
+		if errors.Is(err, httpclient.ErrUnknownVariant) || errors.Is(err, httpclient.ErrUnknownUnit) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to create transaction",
 		})
@@ -124,7 +139,7 @@ func (t *transactionController) CreateTransaction(ctx *fiber.Ctx) error {
 func (t *transactionController) GetManagerDashboard(c *fiber.Ctx) error {
 	ctx := c.Context()
 
-	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStats(ctx, 1)
+	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStats(ctx)
 	if err != nil {
 		log.Errorf("[TransactionController] GetManagerDashboard - 1: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -151,9 +166,17 @@ func (t *transactionController) GetDashboardByMerchant(c *fiber.Ctx) error {
 	merchantIDStr := c.Params("merchant_id")
 	merchantID := conv.StringToUint(merchantIDStr)
 
-	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStatsByMerchant(ctx, 1, merchantID)
+	userID := conv.StringToUint(c.Get(middleware.UserIDHeader))
+	viewAll := middleware.HasPermission(c, middleware.PermissionDashboardAll)
+
+	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStatsByMerchant(ctx, userID, merchantID, viewAll)
 	if err != nil {
 		log.Errorf("[TransactionController] GetDashboardByMerchant - 1: %v", err)
+		if errors.Is(err, usecase.ErrMerchantAccessDenied) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You do not keep this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get dashboard stats by merchant",
 		})
@@ -198,9 +221,14 @@ func (t *transactionController) GetTransactions(c *fiber.Ctx) error {
 
 	merchantID := conv.StringToUint(query.MerchantID)
 
-	transactions, total, err := t.transactionUsecase.GetTransactions(ctx, query.Page, query.Limit, query.Search, query.SortBy, query.SortOrder, merchantID)
+	transactions, total, err := t.transactionUsecase.GetTransactions(ctx, query.Page, query.Limit, query.Search, query.SortBy, query.SortOrder, merchantID, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[TransactionController] GetTransactions - 2: %v", err)
+		if errors.Is(err, usecase.ErrMerchantAccessDenied) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this merchant",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get transactions",
 		})
@@ -216,6 +244,8 @@ func (t *transactionController) GetTransactions(c *fiber.Ctx) error {
 				ProductName: tp.ProductName,
 				ProductPhoto: tp.ProductPhoto,
 				ProductAbout: tp.ProductAbout,
+				VariantID: tp.VariantID,
+				Unit: tp.Unit,
 				Quantity: tp.Quantity,
 				Price: tp.Price,
 				SubTotal: tp.SubTotal,
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transaction_products";
+DROP TABLE IF EXISTS "transactions";
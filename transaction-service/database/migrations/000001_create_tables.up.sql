@@ -0,0 +1,46 @@
+-- The schema AutoMigrate created before any of the later changes, which
+-- follow in their own migrations. Every statement is conditional, so
+-- databases that already have it adopt this migration unchanged.
+
+CREATE TABLE IF NOT EXISTS "transactions" (
+	"id" bigserial,
+	"name" varchar(255) NOT NULL,
+	"phone" varchar(20) NOT NULL,
+	"email" varchar(255),
+	"address" text,
+	"sub_total" bigint NOT NULL,
+	"tax_total" bigint,
+	"grand_total" bigint NOT NULL,
+	"merchant_id" bigint NOT NULL,
+	"payment_status" varchar(50) DEFAULT 'pending',
+	"payment_method" varchar(50),
+	"payment_code" varchar(100),
+	"order_id" varchar(100),
+	"transaction_code" varchar(100),
+	"payment_token" text,
+	"callback_url" text,
+	"expired_at" timestamptz,
+	"notes" text,
+	"currency" varchar(10) DEFAULT 'IDR',
+	"fraud_status" varchar(50),
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_transactions_deleted_at" ON "transactions" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_transactions_order_id" ON "transactions" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "transaction_products" (
+	"id" bigserial,
+	"product_id" bigint NOT NULL,
+	"quantity" bigint NOT NULL,
+	"price" bigint NOT NULL,
+	"sub_total" bigint NOT NULL,
+	"transaction_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_transactions_transaction_products" FOREIGN KEY ("transaction_id") REFERENCES "transactions"("id")
+);
@@ -0,0 +1,3 @@
+ALTER TABLE "transaction_products" DROP COLUMN IF EXISTS "unit_factor";
+ALTER TABLE "transaction_products" DROP COLUMN IF EXISTS "unit";
+ALTER TABLE "transaction_products" DROP COLUMN IF EXISTS "variant_id";
@@ -0,0 +1,7 @@
+-- A line may be a product variant and may be sold in a unit other than the
+-- base unit. quantity is counted in unit, quantity * unit_factor is the stock
+-- it took.
+
+ALTER TABLE "transaction_products" ADD COLUMN IF NOT EXISTS "variant_id" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "transaction_products" ADD COLUMN IF NOT EXISTS "unit" varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE "transaction_products" ADD COLUMN IF NOT EXISTS "unit_factor" bigint NOT NULL DEFAULT 1;
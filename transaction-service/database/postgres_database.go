@@ -3,7 +3,6 @@ package database
 import (
 	"fmt"
 	"warehouse-go/transaction-service/configs"
-	"warehouse-go/transaction-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/driver/postgres"
@@ -29,7 +28,6 @@ func ConnectPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Transaction{}, &model.TransactionProduct{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] Connection Postgres - 2: %v", err)
@@ -51,4 +51,7 @@ require (
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 	gorm.io/driver/postgres v1.6.0
+	warehouse-go/shared v0.0.0
 )
+
+replace warehouse-go/shared => ../shared
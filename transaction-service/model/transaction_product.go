@@ -9,6 +9,9 @@ import (
 type TransactionProduct struct {
 	ID            uint  		`json:"id" gorm:"primaryKey;autoIncrement"`
 	ProductID     uint  		`json:"product_id" gorm:"type:bigint;not null"`
+	VariantID     uint  		`json:"variant_id" gorm:"type:bigint;not null;default:0"`
+	Unit          string 		`json:"unit" gorm:"type:varchar(50);not null;default:''"`
+	UnitFactor    int64 		`json:"unit_factor" gorm:"type:bigint;not null;default:1"`
 	Quantity      int64 		`json:"quantity" gorm:"type:bigint;not null"`
 	Price         int64 		`json:"price" gorm:"type:bigint;not null"`
 	SubTotal      int64 		`json:"sub_total" gorm:"type:bigint;not null"`
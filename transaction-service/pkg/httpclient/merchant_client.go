@@ -13,11 +13,15 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
+// internalRequestHeader tells merchant-service the call comes from this service
+// rather than from a user, so it is not refused for lacking one.
+const internalRequestHeader = "X-Internal-Request"
+
 type MerchantClientInterface interface {
-	GetMerchantsByKeeperID(ctx context.Context, keeperID uint) ([]Merchant, error)
+	GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error)
 	GetMerchantByID(ctx context.Context, merchantID uint) (*Merchant, error)
 	GetMerchantProducts(ctx context.Context, merchantID uint) ([]MerchantProduct, error)
-	GetMerchantProductstock(ctx context.Context, merchantID uint, productID uint) (*MerchantProduct, error)
+	GetMerchantProductstock(ctx context.Context, merchantID uint, productID uint, variantID uint) (*MerchantProduct, error)
 }
 type MerchantClient struct {
 	urlMerchantService string
@@ -33,6 +37,7 @@ func (m *MerchantClient) GetMerchantByID(ctx context.Context, merchantID uint) (
 		log.Errorf("[MerchantClient] GetMerchantByID - 1: %v", err)
 		return nil, err
 	}
+	req.Header.Set(internalRequestHeader, "true")
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
@@ -74,6 +79,7 @@ func (m *MerchantClient) GetMerchantProducts(ctx context.Context, merchantID uin
 		log.Errorf("[MerchantClient] GetMerchantProducts - 1: %v", err)
 		return nil, err
 	}
+	req.Header.Set(internalRequestHeader, "true")
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
@@ -107,14 +113,15 @@ func (m *MerchantClient) GetMerchantProducts(ctx context.Context, merchantID uin
 }
 
 // GetMerchantProductstock implements MerchantClientInterface.
-func (m *MerchantClient) GetMerchantProductstock(ctx context.Context, merchantID uint, productID uint) (*MerchantProduct, error) {
-	url := fmt.Sprintf("%s/api/v1/merchant-products?merchant_id=%d&product_id=%d", m.urlMerchantService, merchantID, productID)
+func (m *MerchantClient) GetMerchantProductstock(ctx context.Context, merchantID uint, productID uint, variantID uint) (*MerchantProduct, error) {
+	url := fmt.Sprintf("%s/api/v1/merchant-products?merchant_id=%d&product_id=%d&variant_id=%d", m.urlMerchantService, merchantID, productID, variantID)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		log.Errorf("[MerchantClient] GetMerchantProductStock - 1: %v", err)
 		return nil, err
 	}
+	req.Header.Set(internalRequestHeader, "true")
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
@@ -147,7 +154,7 @@ func (m *MerchantClient) GetMerchantProductstock(ctx context.Context, merchantID
 	}
 	
 	for _, product := range response.Data.MerchantProducts {
-		if product.ProductID == productID {
+		if product.ProductID == productID && product.VariantID == variantID {
 			log.Infof("[MerchantClient] GetMerchantProductStock - Found Prodouct %d with stock %d", productID, product.Stock)
 			return &product, nil
 		}
@@ -157,19 +164,20 @@ func (m *MerchantClient) GetMerchantProductstock(ctx context.Context, merchantID
 }
 
 
-// GetMerchantsByKeeperID implements MerchantClientInterface.
-func (m *MerchantClient) GetMerchantsByKeeperID(ctx context.Context, keeperID uint) ([]Merchant, error) {
-	url := fmt.Sprintf("%s/api/v1/merchants?keeper_id=%d", m.urlMerchantService, keeperID)
+// GetMerchantIDsByKeeperID implements MerchantClientInterface.
+func (m *MerchantClient) GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error) {
+	url := fmt.Sprintf("%s/api/v1/merchants/keeper/%d", m.urlMerchantService, keeperID)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 1: %v", err)
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 1: %v", err)
 		return nil, err
 	}
+	req.Header.Set(internalRequestHeader, "true")
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
-		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 2: %v", err)
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 2: %v", err)
 		return nil, err
 	}
 
@@ -177,24 +185,26 @@ func (m *MerchantClient) GetMerchantsByKeeperID(ctx context.Context, keeperID ui
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 3: %v", err)
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 3: %v", err)
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 4: %s", string(body))
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 4: %s", string(body))
 		return nil, errors.New("failed to get merchants by keeper id")
 	}
 
 	var response struct {
-		Data []Merchant `json:"data"`
+		Data struct {
+			MerchantIDs []uint `json:"merchant_ids"`
+		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &response); err != nil {
-		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 5: %v", err)
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 5: %v", err)
 		return nil, err
 	}
 
-	return response.Data, nil
+	return response.Data.MerchantIDs, nil
 }
 
 type Merchant struct {
@@ -209,6 +219,7 @@ type MerchantProduct struct {
 	ID                   uint   `json:"id"`
 	MerchantID           uint   `json:"merchant_id"`
 	ProductID            uint   `json:"product_id"`
+	VariantID            uint   `json:"variant_id"`
 	ProductName          string `json:"product_name"`
 	ProductAbout         string `json:"product_about"`
 	ProductPhoto         string `json:"product_photo"`
@@ -172,11 +172,14 @@ type ProductResponse struct {
 	Price     int64  `json:"price"`
 	Barcode   string `json:"barcode"`
 	Thumbnail string `json:"thumbnail"`
+	BaseUnit  string `json:"base_unit"`
 	Category  struct {
 		ID    uint   `json:"id"`
 		Name  string `json:"name"`
 		Photo string `json:"photo"`
 	} `json:"category"`
+	Variants []ProductVariantResponse `json:"variants"`
+	Units    []ProductUnitResponse    `json:"units"`
 }
 
 type ProductServiceResponse struct {
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionsHeader is set by the api gateway to the comma separated
+// permissions of the caller. Clients cannot send it themselves, the gateway
+// drops it from incoming requests.
+const PermissionsHeader = "X-User-Permissions"
+
+const (
+	PermissionDashboardAll      = "dashboard:all"
+	PermissionDashboardMerchant = "dashboard:merchant"
+)
+
+// RequirePermission answers 403 unless the caller holds at least one of
+// the given permissions.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, permission := range permissions {
+			if HasPermission(c, permission) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Insufficient permissions",
+		})
+	}
+}
+
+// HasPermission reports whether the caller holds permission.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	for _, have := range strings.Split(c.Get(PermissionsHeader), ",") {
+		if strings.TrimSpace(have) == permission {
+			return true
+		}
+	}
+	return false
+}
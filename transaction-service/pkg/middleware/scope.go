@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserIDHeader is set by the api gateway to the id of the caller. Calls from
+// other services come without it.
+const UserIDHeader = "X-User-ID"
+
+// PermissionMerchantAll lets a user reach the transactions of every merchant
+// instead of only the ones they are assigned to.
+const PermissionMerchantAll = "merchant:all"
+
+// InternalRequestHeader marks calls from other services, which act for no
+// user. Only they may come without UserIDHeader.
+const InternalRequestHeader = "X-Internal-Request"
+
+// callerID returns the user the request acts for, or 0 when UserIDHeader is
+// missing or invalid.
+func callerID(c *fiber.Ctx) uint {
+	userID, err := strconv.ParseUint(c.Get(UserIDHeader), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(userID)
+}
+
+func isInternal(c *fiber.Ctx) bool {
+	return c.Get(InternalRequestHeader) == "true"
+}
+
+// RequireIdentity answers 401 to requests that carry neither a user nor the
+// mark of a call from another service. Without it ScopedUserID could not
+// tell an anonymous request from an unscoped one.
+func RequireIdentity() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if callerID(c) == 0 && !isInternal(c) {
+			return unauthorized(c)
+		}
+		return c.Next()
+	}
+}
+
+func unauthorized(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"message": "Unauthorized",
+	})
+}
+
+// ScopedUserID returns the user whose merchant assignments limit the
+// request, or 0 when the request is not limited: it comes from another
+// service or the caller holds merchant:all. It expects RequireIdentity to
+// have refused requests without either.
+func ScopedUserID(c *fiber.Ctx) uint {
+	userID := callerID(c)
+	if userID == 0 || HasPermission(c, PermissionMerchantAll) {
+		return 0
+	}
+	return userID
+}
@@ -19,6 +19,7 @@ type StockReducedEvent struct {
 
 type StockReducedEventProduct struct {
 	ProductID	uint `json:"product_id"`
+	VariantID	uint `json:"variant_id"`
 	Quantity 	int  `json:"quantity"`
 }
 
@@ -84,6 +84,11 @@ func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
 		return nil, err
 	}
 
+	if err := declareRealtimeExchange(ch); err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 5: %v", err)
+		return nil, err
+	}
+
 	return &RabbitMQService{
 		conn: conn,
 		ch: ch,
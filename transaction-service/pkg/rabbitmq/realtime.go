@@ -0,0 +1,74 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+// RealtimeExchange carries the events the API gateway pushes to connected
+// clients. The routing key is the event type.
+const RealtimeExchange = "realtime_events"
+
+const (
+	EventPaymentStatus = "payment.status"
+	EventStockChanged  = "stock.changed"
+	EventStockLow      = "stock.low"
+)
+
+type RealtimeEvent struct {
+	Type          string    `json:"type"`
+	MerchantID    uint      `json:"merchant_id,omitempty"`
+	WarehouseID   uint      `json:"warehouse_id,omitempty"`
+	ProductID     uint      `json:"product_id,omitempty"`
+	Stock         *int      `json:"stock,omitempty"`
+	OrderID       string    `json:"order_id,omitempty"`
+	PaymentStatus string    `json:"payment_status,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func declareRealtimeExchange(ch *amqp.Channel) error {
+	return ch.ExchangeDeclare(
+		RealtimeExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+}
+
+// PublishRealtimeEvent is fire and forget: the events are only a hint for
+// connected clients, so they are neither persisted nor retried.
+func (r *RabbitMQService) PublishRealtimeEvent(event RealtimeEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishRealtimeEvent - 1: %v", err)
+		return err
+	}
+
+	err = r.ch.Publish(
+		RealtimeExchange,
+		event.Type,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+			Timestamp:   event.Timestamp,
+		},
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishRealtimeEvent - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -11,11 +11,11 @@ import (
 type TransactionRepositoryInterface interface {
 	GetDashboardStats(ctx context.Context) (int64, int64, int64, error)
 	GetDashboardStatsByMerchant(ctx context.Context, merchantID uint) (int64, int64, int64, error)
-	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID uint) ([]model.Transaction, int64, error)
+	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantIDs []uint) ([]model.Transaction, int64, error)
 	CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error)
 
 	//Midtrans WebHook
-	UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus, paymentMethod, transactionID, fraudStatus string) error
+	UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus, paymentMethod, transactionID, fraudStatus string) (*model.Transaction, error)
 }
 
 type transactionRepository struct {
@@ -54,6 +54,9 @@ func (t *transactionRepository) CreateTransaction(ctx context.Context, transacti
 		for _, product := range products {
 			modelTransactionProduct := model.TransactionProduct{
 				ProductID:     product.ProductID,
+				VariantID:     product.VariantID,
+				Unit:          product.Unit,
+				UnitFactor:    product.UnitFactor,
 				Quantity:      product.Quantity,
 				Price:         product.Price,
 				SubTotal:      product.SubTotal,
@@ -98,7 +101,7 @@ func (t *transactionRepository) GetDashboardStats(ctx context.Context) (int64, i
 		err := t.db.WithContext(ctx).Model(&model.TransactionProduct{}).
 			Joins("JOIN transactions ON transaction_products.transaction_id = transactions.id").
 			Where("transactions.payment_status = ?", model.PaymentStatusSuccess).
-			Select("COALESCE(SUM(transaction_products.quantity), 0) as products_sold").
+			Select("COALESCE(SUM(transaction_products.quantity * transaction_products.unit_factor), 0) as products_sold").
 			Scan(&productSold).Error
 
 		if err != nil {
@@ -147,7 +150,7 @@ func (t *transactionRepository) GetDashboardStatsByMerchant(ctx context.Context,
 		err = t.db.WithContext(ctx).Model(&model.TransactionProduct{}).
 			Joins("JOIN transactions ON transaction_products.transaction_id = transactions.id").
 			Where("transactions.merchant_id = ? AND transactions.payment_satus = ?", merchantID, model.PaymentStatusSuccess).
-			Select("COALESCE(SUM(transaction_products.quantity), 0) as products_sold").
+			Select("COALESCE(SUM(transaction_products.quantity * transaction_products.unit_factor), 0) as products_sold").
 			Scan(&productSold).Error
 
 		if err != nil {
@@ -160,8 +163,9 @@ func (t *transactionRepository) GetDashboardStatsByMerchant(ctx context.Context,
 }
 
 
-// GetTransactions implements TransactionRepositoryInterface.
-func (t *transactionRepository) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) {
+// GetTransactions implements TransactionRepositoryInterface. A nil
+// merchantIDs lists the transactions of every merchant.
+func (t *transactionRepository) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantIDs []uint) ([]model.Transaction, int64, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[TransactionRepository] - GetTransactions - 1: %v", ctx.Err())
@@ -194,8 +198,8 @@ func (t *transactionRepository) GetTransactions(ctx context.Context, page int, l
 							searchTerm, searchTerm)
 		}
 
-		if merchantID != 0 {
-			baseSql = baseSql.Where("merchant_id = ?", merchantID)
+		if merchantIDs != nil {
+			baseSql = baseSql.Where("merchant_id IN ?", merchantIDs)
 		}
 
 		var totalRecords int64
@@ -223,16 +227,17 @@ func (t *transactionRepository) GetTransactions(ctx context.Context, page int, l
 
 
 // UpdatePaymentStatus implements TransactionRepositoryInterface.
-func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus string, paymentMethod string, transactionID string, fraudStatus string) error {
+func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus string, paymentMethod string, transactionID string, fraudStatus string) (*model.Transaction, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[TransactionRepository] UpdatePaymentStatus - 1: %v", ctx.Err())
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 
-		if err := t.db.WithContext(ctx).Model(&model.Transaction{}).Where("order_id = ?", orderID).First(&model.Transaction{}).Error; err != nil{
+		var transaction model.Transaction
+		if err := t.db.WithContext(ctx).Where("order_id = ?", orderID).First(&transaction).Error; err != nil{
 			log.Errorf("[TransactionRepository] UpdatePayementStatus - 2: %v", err)
-			return err
+			return nil, err
 		}
 
 		updates := map[string]interface{}{
@@ -249,16 +254,14 @@ func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID
 			updates["fraud_status"] = fraudStatus
 		}
 
-		err := t.db.WithContext(ctx).Model(&model.Transaction{}).
-			   Where("order_id =?", orderID).
-			   Updates(updates).Error
+		err := t.db.WithContext(ctx).Model(&transaction).Updates(updates).Error
 		
 		if err != nil {
 			log.Errorf("[TransactionRepository] UpdatePaymentStatus - 3: %v", err)
-			return err
+			return nil, err
 		}
 
-		return  nil
+		return &transaction, nil
 	}
 }
 
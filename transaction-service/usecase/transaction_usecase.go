@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"warehouse-go/transaction-service/model"
@@ -12,12 +13,16 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
+var ErrMerchantAccessDenied = errors.New("user tidak memiliki akses ke merchant")
+
 type TransactionUsecaseInterface interface {
-	GetDashboardStats(ctx context.Context, userID uint) (int64, int64, int64, error)
-	GetDashboardStatsByMerchant(ctx context.Context, userID uint, merchantID uint) (int64, int64, int64, error)
+	GetDashboardStats(ctx context.Context) (int64, int64, int64, error)
+	GetDashboardStatsByMerchant(ctx context.Context, userID uint, merchantID uint, viewAll bool) (int64, int64, int64, error)
 
-	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID uint) ([]model.Transaction, int64, error)
-	CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error)
+	// keeperID limits the call to the merchants that user keeps, 0 means
+	// no limit.
+	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, keeperID uint) ([]model.Transaction, int64, error)
+	CreateTransaction(ctx context.Context, transaction model.Transaction, keeperID uint) (int64, error)
 
 	//Midtrans Update status transaction
 	UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus, paymentMethod, transactionID, fraudStatus string) error
@@ -28,51 +33,43 @@ type transactionUsecase struct {
 	merchantClient 	httpclient.MerchantClientInterface
 	rabbitMQService *rabbitmq.RabbitMQService
 	productClient   httpclient.ProductClientInterface
-	userClient      httpclient.UserClientInterface
 }
 
 // CreateTransaction implements TransactionUsecaseInterface.
-func (t *transactionUsecase) CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error) {
+func (t *transactionUsecase) CreateTransaction(ctx context.Context, transaction model.Transaction, keeperID uint) (int64, error) {
+	if keeperID != 0 {
+		if err := t.checkMerchantKeeper(ctx, transaction.MerchantID, keeperID); err != nil {
+			log.Errorf("[TransactionUsecase] CreateTransaction - 1: %v", err)
+			return 0, err
+		}
+	}
+
 	if err := t.validateProductStocks(ctx, transaction); err != nil {
-		log.Errorf("[TransactionUsecase] CreateTransaction - 1: %v", err)
+		log.Errorf("[TransactionUsecase] CreateTransaction - 2: %v", err)
 		return 0, err
 	}
 
 	transactionID, err := t.transactionRepo.CreateTransaction(ctx, transaction)
 	if err != nil {
-		log.Errorf("[TransactionUsecase] CreateTransaction - 2: %v", err)
+		log.Errorf("[TransactionUsecase] CreateTransaction - 3: %v", err)
 		return 0, err
 	}
 
 	go func() {
 		if err := t.publishStockReducedEvent(ctx, transaction); err != nil {
-			log.Errorf("[TransactionUsecase] CreateTransaction - 3: %v", err)
+			log.Errorf("[TransactionUsecase] CreateTransaction - 4: %v", err)
 		}
 	}()
 
 	return transactionID, nil
 }
 
-// GetDashboardStats implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetDashboardStats(ctx context.Context, userID uint) (int64, int64, int64, error) {
-	user, err := t.userClient.GetUserByID(ctx, userID)
-	if err != nil {
-		log.Errorf("[TransactionUsecase] GetDashboardStats - 1: %v", err)
-		return 0, 0, 0, err
-	}
-
-	isManager := false
-	if user.RoleName == "Manager" {
-		isManager = true
-	}
-
-	if !isManager {
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke dashboard")
-	}
-
+// GetDashboardStats implements TransactionUsecaseInterface. The route only
+// lets callers with dashboard:all through.
+func (t *transactionUsecase) GetDashboardStats(ctx context.Context) (int64, int64, int64, error) {
 	totalRevenue, totalTransactions, productsSold, err := t.transactionRepo.GetDashboardStats(ctx)
 	if err != nil {
-		log.Errorf("[TransactionUsecase] GetDashboardStats - 2: %v", err)
+		log.Errorf("[TransactionUsecase] GetDashboardStats - 1: %v", err)
 		return 0, 0, 0, err
 	}
 
@@ -81,31 +78,13 @@ func (t *transactionUsecase) GetDashboardStats(ctx context.Context, userID uint)
 }
 
 // GetDashboardStatsByMerchant implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetDashboardStatsByMerchant(ctx context.Context, userID uint, merchantID uint) (int64, int64, int64, error) {
-	user, err := t.userClient.GetUserByID(ctx, userID)
-	if err != nil {
-		log.Errorf("[TransactionRepository] GetDashboardStatsByMerchant - 1: %v", err)
-		return 0, 0, 0, err
-	}
-
-	isManager := false
-	if user.RoleName == "Manager" {
-		isManager = true
-	}
-
-	if isManager {
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke dashboard")
-	}
-
-	merchant, err := t.merchantClient.GetMerchantByID(ctx, merchantID)
-	if err != nil {
-		log.Errorf("[TransactionRepository] GetDashboardStatsByMerchant - 2: %v", err)
-		return 0, 0, 0, err
-	}
-
-	if merchant.KeeperID != userID {
-		log.Errorf("[TransactionRepository] GetDashboardStatsByMercgant - 3: %v", err)
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke merchant")
+// Without viewAll (dashboard:all) only the keepers of the merchant may see it.
+func (t *transactionUsecase) GetDashboardStatsByMerchant(ctx context.Context, userID uint, merchantID uint, viewAll bool) (int64, int64, int64, error) {
+	if !viewAll {
+		if err := t.checkMerchantKeeper(ctx, merchantID, userID); err != nil {
+			log.Errorf("[TransactionRepository] GetDashboardStatsByMerchant - 2: %v", err)
+			return 0, 0, 0, err
+		}
 	}
 
 	totalRevenue, totalTransactions, productsSold, err := t.transactionRepo.GetDashboardStatsByMerchant(ctx, merchantID)
@@ -118,10 +97,30 @@ func (t *transactionUsecase) GetDashboardStatsByMerchant(ctx context.Context, us
 }
 
 // GetTransactions implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) {
-	transactions, total, err := t.transactionRepo.GetTransactions(ctx, page, limit, search, sortBy, sortOrder, merchantID)
+func (t *transactionUsecase) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, keeperID uint) ([]model.Transaction, int64, error) {
+	var merchantIDs []uint
+	if merchantID != 0 {
+		merchantIDs = []uint{merchantID}
+	}
+
+	if keeperID != 0 {
+		kept, err := t.merchantClient.GetMerchantIDsByKeeperID(ctx, keeperID)
+		if err != nil {
+			log.Errorf("[TransactionRepository] GetTransactions - 1: %v", err)
+			return nil, 0, err
+		}
+
+		if merchantID == 0 {
+			merchantIDs = append([]uint{}, kept...)
+		} else if !containsID(kept, merchantID) {
+			log.Errorf("[TransactionRepository] GetTransactions - 2: %v", ErrMerchantAccessDenied)
+			return nil, 0, ErrMerchantAccessDenied
+		}
+	}
+
+	transactions, total, err := t.transactionRepo.GetTransactions(ctx, page, limit, search, sortBy, sortOrder, merchantIDs)
 	if err != nil {
-		log.Errorf("[TransactionRepository] GetTransactions - 1: %v", err)
+		log.Errorf("[TransactionRepository] GetTransactions - 3: %v", err)
 		return nil, 0, err
 	}
 
@@ -142,43 +141,105 @@ func (t *transactionUsecase) GetTransactions(ctx context.Context, page int, limi
 
 // UpdatePaymentStatus implements TransactionUsecaseInterface.
 func (t *transactionUsecase) UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus string, paymentMethod string, transactionID string, fraudStatus string) error {
-	return t.transactionRepo.UpdatePaymentStatus(ctx, orderID, paymentMethod, paymentStatus, transactionID, fraudStatus)
+	transaction, err := t.transactionRepo.UpdatePaymentStatus(ctx, orderID, paymentStatus, paymentMethod, transactionID, fraudStatus)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] UpdatePaymentStatus - 1: %v", err)
+		return err
+	}
+
+	// The payment is already stored, a lost notification must not fail the webhook.
+	event := rabbitmq.RealtimeEvent{
+		Type:          rabbitmq.EventPaymentStatus,
+		MerchantID:    transaction.MerchantID,
+		OrderID:       transaction.OrderID,
+		PaymentStatus: paymentStatus,
+	}
+	if err := t.rabbitMQService.PublishRealtimeEvent(event); err != nil {
+		log.Errorf("[TransactionUsecase] UpdatePaymentStatus - 2: %v", err)
+	}
+
+	return nil
 }
 
-func NewTransactionUsecase(transacntionRepo repository.TransactionRepositoryInterface, merchantClient httpclient.MerchantClientInterface, rabbitMQService *rabbitmq.RabbitMQService, productClient httpclient.ProductClientInterface, userClient httpclient.UserClientInterface) TransactionUsecaseInterface {
+func NewTransactionUsecase(transacntionRepo repository.TransactionRepositoryInterface, merchantClient httpclient.MerchantClientInterface, rabbitMQService *rabbitmq.RabbitMQService, productClient httpclient.ProductClientInterface) TransactionUsecaseInterface {
 	return &transactionUsecase{
 		transactionRepo: transacntionRepo,
 		merchantClient:  merchantClient,
 		rabbitMQService: rabbitMQService,
 		productClient:   productClient,
-		userClient:      userClient,
 	}
 }
 
+// checkMerchantKeeper returns ErrMerchantAccessDenied unless the user is
+// assigned to the merchant.
+func (t *transactionUsecase) checkMerchantKeeper(ctx context.Context, merchantID, userID uint) error {
+	kept, err := t.merchantClient.GetMerchantIDsByKeeperID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !containsID(kept, merchantID) {
+		return ErrMerchantAccessDenied
+	}
+	return nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, have := range ids {
+		if have == id {
+			return true
+		}
+	}
+	return false
+}
+
+// validateProductStocks also records the unit factor of every line, stock is
+// checked and reduced in base units.
 func (tu *transactionUsecase) validateProductStocks(ctx context.Context, transaction model.Transaction) error {
 
-	for _, product := range transaction.TransactionProducts {
+	for i := range transaction.TransactionProducts {
+		product := &transaction.TransactionProducts[i]
+
+		productDetail, err := tu.productClient.GetProductByID(ctx, product.ProductID)
+		if err != nil {
+			log.Errorf("[TransactionUsecase] validateProductStocks - 1: %v", err)
+			return err
+		}
+
+		if err := productDetail.CheckVariant(product.VariantID); err != nil {
+			log.Errorf("[TransactionUsecase] validateProductStocks - 2: %v", err)
+			return err
+		}
+
+		factor, err := productDetail.BaseQuantity(product.Unit, 1)
+		if err != nil {
+			log.Errorf("[TransactionUsecase] validateProductStocks - 3: %v", err)
+			return err
+		}
+		product.UnitFactor = int64(factor)
+		required := product.Quantity * product.UnitFactor
+
 		//Get Stock information from merchant service
 		merchantProduct, err := tu.merchantClient.GetMerchantProductstock(
 			ctx,
 			transaction.MerchantID,
 			product.ProductID,
+			product.VariantID,
 		)
 		if err != nil {
-			log.Errorf("[TransactionUsecase] validateProductStocks - 3: %v", err)
+			log.Errorf("[TransactionUsecase] validateProductStocks - 4: %v", err)
 			return err
 		}
 
 		//Check if available stock in sufficient
-		if merchantProduct.Stock < int(product.Quantity) {
+		if int64(merchantProduct.Stock) < required {
 			log.Errorf("[TransactionUsecase] validateProductStocks - Insufficient stock for product %d. Required: %d, Available: %d",
-				product.ProductID, product.Quantity, merchantProduct.Stock)
+				product.ProductID, required, merchantProduct.Stock)
 			return fmt.Errorf("stock tidak mencukupi untuk product '%s'. Dibutuhkan: %d, Tersedia: %d",
-				merchantProduct.ProductName, product.Quantity, merchantProduct.Stock)
+				merchantProduct.ProductName, required, merchantProduct.Stock)
 		}
 
 		log.Infof("[TransactionUsecase] validateProductStocks - Stock validation passed for product: %d (%s). Required: %d, Available: %d",
-			product.ProductID, merchantProduct.ProductName, product.Quantity, merchantProduct.Stock)
+			product.ProductID, merchantProduct.ProductName, required, merchantProduct.Stock)
 	}
 
 	return nil
@@ -189,7 +250,8 @@ func (tu *transactionUsecase) publishStockReducedEvent(ctx context.Context, tran
 	for _, product := range transaction.TransactionProducts {
 		products = append(products, rabbitmq.StockReducedEventProduct{
 			ProductID: product.ProductID,
-			Quantity:  int(product.Quantity),
+			VariantID: product.VariantID,
+			Quantity:  int(product.Quantity * product.UnitFactor),
 		})
 	}
 
@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 	"warehouse-go/user-service/configs"
+	"warehouse-go/user-service/service"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"    
@@ -37,6 +38,20 @@ func RunServer() {
 	container := BuildContainer()
 	SetupRoutes(app, container)
 
+	auditConsumer, err := service.NewAuditConsumer(*cfg, container.AuditUsecase)
+	if err != nil {
+		log.Fatalf("Failed to create audit consumer: %v", err)
+	}
+	defer auditConsumer.Close()
+
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	defer stopConsumer()
+	go func() {
+		if err := auditConsumer.ConsumeAuditEvents(consumerCtx); err != nil {
+			log.Printf("Failed to consume audit events: %v", err)
+		}
+	}()
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
@@ -1,10 +1,13 @@
 package app
 
 import (
+	"context"
 	"log"
+	"warehouse-go/shared/audit"
 	"warehouse-go/user-service/configs"
 	"warehouse-go/user-service/controller"
 	"warehouse-go/user-service/database"
+	"warehouse-go/user-service/pkg/httpclient"
 	"warehouse-go/user-service/pkg/storage"
 	"warehouse-go/user-service/repository"
 	"warehouse-go/user-service/service"
@@ -14,8 +17,17 @@ import (
 type Container struct {
 	RoleController controller.RoleControllerInterface
 	UserController controller.UserControllerInterface
+	UserCSVController controller.UserCSVControllerInterface
 	AuthController controller.AuthControllerInterface
 	UploadController controller.UploadControllerInterface 
+	APIKeyController controller.APIKeyControllerInterface
+	InvitationController controller.InvitationControllerInterface
+	TwoFactorController controller.TwoFactorControllerInterface
+	AuditController controller.AuditControllerInterface
+	SessionController controller.SessionControllerInterface
+	SSOController controller.SSOControllerInterface
+	AuditUsecase usecase.AuditUsecaseInterface
+	AuditTracker *audit.Tracker
 }
 
 func BuildContainer() *Container {
@@ -24,6 +36,11 @@ func BuildContainer() *Container {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if !config.SqlDB.SkipMigrations {
+		if err := database.Migrate(context.Background(), db.DB); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 
 	rabbitMQService, err := service.NewRabbitMQService(*config)
 	if err != nil {
@@ -36,19 +53,76 @@ func BuildContainer() *Container {
 
 	roleRepo := repository.NewRoleRepository(db.DB)
 	userRepo := repository.NewUserRepository(db.DB)
-	roleUsecase := usecase.NewRoleUsecase(roleRepo)
+	permissionRepo := repository.NewPermissionRepository(db.DB)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo, permissionRepo)
 	roleController := controller.NewRoleController(roleUsecase)
-	UserUsecase := usecase.NewUserUsecase(userRepo, rabbitMQService)
-	UserController := controller.NewUserController(UserUsecase)
+	invitationRepo := repository.NewInvitationRepository(db.DB)
+	invitationUsecase := usecase.NewInvitationUsecase(invitationRepo, userRepo, rabbitMQService, config.Auth)
+	invitationController := controller.NewInvitationController(invitationUsecase)
+
+	sessionRepo := repository.NewSessionRepository(db.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+	merchantClient := httpclient.NewMerchantClient(*config)
 
-	authController := controller.NewAuthController(UserUsecase)
+	UserUsecase := usecase.NewUserUsecase(userRepo, sessionRepo, apiKeyRepo, merchantClient)
+	UserController := controller.NewUserController(UserUsecase, invitationUsecase)
+	userCSVUsecase := usecase.NewUserCSVUsecase(userRepo, roleRepo, invitationUsecase)
+	userCSVController := controller.NewUserCSVController(userCSVUsecase)
+
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db.DB)
+	passwordResetRepo := repository.NewPasswordResetRepository(db.DB)
+	twoFactorRepo := repository.NewTwoFactorRepository(db.DB)
+	authUsecase := usecase.NewAuthUsecase(userRepo, loginAttemptRepo, passwordResetRepo, twoFactorRepo, sessionRepo, rabbitMQService, config.Auth)
+	authController := controller.NewAuthController(authUsecase)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(twoFactorRepo, userRepo, config.Auth)
+	twoFactorController := controller.NewTwoFactorController(twoFactorUsecase)
+	identityRepo := repository.NewIdentityRepository(db.DB)
+	ssoUsecase := usecase.NewSSOUsecase(identityRepo, userRepo, roleRepo)
+	ssoController := controller.NewSSOController(ssoUsecase)
 
 	uploadController := controller.NewUploadController(fileUploadHelper)
 
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, userRepo)
+	apiKeyController := controller.NewAPIKeyController(apiKeyUsecase)
+
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepo)
+	sessionController := controller.NewSessionController(sessionUsecase)
+
+	auditRepo := repository.NewAuditRepository(db.DB)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	auditController := controller.NewAuditController(auditUsecase)
+
+	// user-service stores its own events without going through RabbitMQ.
+	auditTracker := audit.NewTracker("user-service", auditUsecase)
+	auditTracker.Register("user", func(ctx context.Context, id uint) (interface{}, error) {
+		return userRepo.GetUserByID(ctx, id)
+	})
+	auditTracker.Register("role", func(ctx context.Context, id uint) (interface{}, error) {
+		return roleRepo.GetAllRoleByID(ctx, id)
+	})
+	auditTracker.Register("user-role", func(ctx context.Context, id uint) (interface{}, error) {
+		return userRepo.GetUserByRoleID(ctx, id)
+	})
+	auditTracker.Register("invitation", func(ctx context.Context, id uint) (interface{}, error) {
+		return invitationRepo.GetInvitationByID(ctx, id)
+	})
+	auditTracker.Register("session", func(ctx context.Context, id uint) (interface{}, error) {
+		return sessionRepo.GetSessionByID(ctx, id)
+	})
+
 	return &Container{
 		RoleController: roleController,
 		UserController: UserController,
+		UserCSVController: userCSVController,
 		AuthController: authController,
 		UploadController: uploadController,
+		APIKeyController: apiKeyController,
+		InvitationController: invitationController,
+		TwoFactorController: twoFactorController,
+		AuditController: auditController,
+		SessionController: sessionController,
+		SSOController: ssoController,
+		AuditUsecase: auditUsecase,
+		AuditTracker: auditTracker,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/pkg/openapi"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "User Service",
+	Version:     "1.0.0",
+	Description: "Users, roles, role assignment and authentication",
+}
+
+// openAPIOperations documents the request and response types of the routes
+// registered in SetupRoutes.
+var openAPIOperations = []openapi.Operation{
+	{Method: "POST", Path: "/api/v1/roles", Summary: "Create a role", Tags: []string{"roles"}, Request: request.CreateRoleRequest{}},
+	{Method: "GET", Path: "/api/v1/roles", Summary: "List roles", Tags: []string{"roles"}, Response: []response.RoleResponse{}},
+	{Method: "GET", Path: "/api/v1/roles/:id", Summary: "Get a role", Tags: []string{"roles"}, Response: response.RoleResponse{}},
+	{Method: "PUT", Path: "/api/v1/roles/:id", Summary: "Update a role", Tags: []string{"roles"}, Request: request.CreateRoleRequest{}},
+	{Method: "DELETE", Path: "/api/v1/roles/:id", Summary: "Delete a role", Tags: []string{"roles"}},
+	{Method: "GET", Path: "/api/v1/permissions", Summary: "List the permissions roles can be given", Tags: []string{"roles"}, Response: []response.PermissionResponse{}},
+
+	{Method: "POST", Path: "/api/v1/users", Summary: "Create a user and email an invitation", Tags: []string{"users"}, Request: request.CreateUserRequest{}, Response: response.InvitationResponse{}},
+	{Method: "GET", Path: "/api/v1/users", Summary: "List users", Tags: []string{"users"}, Query: request.GetAllUsersRequest{}, Response: response.GetAllUsersResponse{}},
+	{Method: "POST", Path: "/api/v1/users/import", Summary: "Create users from a CSV with name, email, phone and optional roles and photo columns, all rows or none", Tags: []string{"users"}, Query: request.ImportUsersRequest{}, Upload: true, UploadField: "file", Response: response.ImportUsersResponse{}},
+	{Method: "GET", Path: "/api/v1/users/export", Summary: "Download users as CSV", Tags: []string{"users"}, Query: request.ExportUsersRequest{}},
+	{Method: "GET", Path: "/api/v1/users/:id", Summary: "Get a user", Tags: []string{"users"}, Response: response.UserResponse{}},
+	{Method: "GET", Path: "/api/v1/users/email/:email", Summary: "Get a user by email", Tags: []string{"users"}, Response: response.UserResponse{}},
+	{Method: "PUT", Path: "/api/v1/users/:id", Summary: "Update a user", Tags: []string{"users"}, Request: request.UpdateUserRequest{}},
+	{Method: "DELETE", Path: "/api/v1/users/:id", Summary: "Deactivate a user and sign out their sessions", Tags: []string{"users"}},
+	{Method: "POST", Path: "/api/v1/users/:id/restore", Summary: "Restore a deactivated user", Tags: []string{"users"}},
+	{Method: "POST", Path: "/api/v1/users/:id/unlock", Summary: "Unlock a locked account", Tags: []string{"users"}},
+	{Method: "POST", Path: "/api/v1/users/:id/2fa/reset", Summary: "Remove the two-factor enrollment of a user who lost the device", Tags: []string{"users"}},
+	{Method: "GET", Path: "/api/v1/users/me", Summary: "Get the caller's profile", Tags: []string{"profile"}, Response: response.ProfileResponse{}},
+	{Method: "PUT", Path: "/api/v1/users/me", Summary: "Update the caller's name, phone and photo", Tags: []string{"profile"}, Request: request.UpdateProfileRequest{}, Response: response.ProfileResponse{}},
+	{Method: "GET", Path: "/api/v1/users/me/sessions", Summary: "List the caller's active sessions", Tags: []string{"profile"}, Response: []response.SessionResponse{}},
+	{Method: "DELETE", Path: "/api/v1/users/me/sessions/:id", Summary: "Sign out one of the caller's sessions", Tags: []string{"profile"}},
+	{Method: "GET", Path: "/api/v1/users/role/:roleName", Summary: "List users with a role", Tags: []string{"users"}, Response: []response.UserResponse{}},
+
+	{Method: "POST", Path: "/api/v1/assign-role", Summary: "Assign a role to a user", Tags: []string{"assign-role"}, Request: request.AssignUserToRoleRequest{}},
+	{Method: "GET", Path: "/api/v1/assign-role", Summary: "List role assignments", Tags: []string{"assign-role"}, Query: request.GetAllUsersRequest{}, Response: response.GetAllUserRolesResponse{}},
+	{Method: "GET", Path: "/api/v1/assign-role/:userRoleID", Summary: "Get a role assignment", Tags: []string{"assign-role"}, Response: response.UserRoleResponse{}},
+	{Method: "PUT", Path: "/api/v1/assign-role/:userRoleID", Summary: "Update a role assignment", Tags: []string{"assign-role"}, Request: request.AssignUserToRoleRequest{}},
+
+	{Method: "POST", Path: "/api/v1/auth/login", Summary: "Log in", Tags: []string{"auth"}, Request: request.LoginRequest{}, Response: response.LoginResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/forgot-password", Summary: "Email a password reset link", Tags: []string{"auth"}, Request: request.ForgotPasswordRequest{}},
+	{Method: "POST", Path: "/api/v1/auth/reset-password", Summary: "Set a new password with a reset token", Tags: []string{"auth"}, Request: request.ResetPasswordRequest{}},
+	{Method: "POST", Path: "/api/v1/auth/change-password", Summary: "Change the password of the caller", Tags: []string{"auth"}, Request: request.ChangePasswordRequest{}},
+	{Method: "POST", Path: "/api/v1/auth/accept-invitation", Summary: "Set the password of an invited user", Tags: []string{"auth"}, Request: request.AcceptInvitationRequest{}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/enroll", Summary: "Start a TOTP enrollment for the caller", Tags: []string{"auth"}, Response: response.TwoFactorEnrollmentResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/confirm", Summary: "Confirm the TOTP enrollment and get recovery codes", Tags: []string{"auth"}, Request: request.ConfirmTwoFactorRequest{}, Response: response.RecoveryCodesResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/disable", Summary: "Turn off two-factor authentication for the caller", Tags: []string{"auth"}, Request: request.DisableTwoFactorRequest{}},
+
+	{Method: "GET", Path: "/api/v1/invitations", Summary: "List invitations", Tags: []string{"invitations"}, Response: []response.InvitationResponse{}},
+	{Method: "POST", Path: "/api/v1/invitations/:id/resend", Summary: "Resend an invitation with a new link", Tags: []string{"invitations"}, Response: response.InvitationResponse{}},
+	{Method: "DELETE", Path: "/api/v1/invitations/:id", Summary: "Revoke an invitation", Tags: []string{"invitations"}},
+
+	{Method: "POST", Path: "/api/v1/api-keys", Summary: "Create an API key", Tags: []string{"api-keys"}, Request: request.CreateAPIKeyRequest{}, Response: response.CreateAPIKeyResponse{}},
+	{Method: "GET", Path: "/api/v1/api-keys", Summary: "List API keys", Tags: []string{"api-keys"}, Response: []response.APIKeyResponse{}},
+	{Method: "DELETE", Path: "/api/v1/api-keys/:id", Summary: "Revoke an API key", Tags: []string{"api-keys"}},
+	{Method: "GET", Path: "/internal/api-keys/verify", Summary: "Verify an API key for the gateway", Tags: []string{"internal"}, Response: response.VerifyAPIKeyResponse{}},
+	{Method: "POST", Path: "/internal/2fa/verify", Summary: "Verify the second login step for the gateway", Tags: []string{"internal"}, Request: request.VerifyTwoFactorRequest{}, Response: response.LoginResponse{}},
+	{Method: "POST", Path: "/internal/oidc/login", Summary: "Sign in a user confirmed by the identity provider for the gateway", Tags: []string{"internal"}, Request: request.SSOLoginRequest{}, Response: response.LoginResponse{}},
+	{Method: "POST", Path: "/internal/sessions", Summary: "Create the session of a token the gateway issues", Tags: []string{"internal"}, Request: request.CreateSessionRequest{}, Response: response.SessionResponse{}},
+	{Method: "GET", Path: "/internal/sessions/verify", Summary: "Verify a session for the gateway", Tags: []string{"internal"}, Response: response.VerifySessionResponse{}},
+
+	{Method: "GET", Path: "/api/v1/audit", Summary: "Search the audit trail of all services", Tags: []string{"audit"}, Query: request.GetAuditLogsRequest{}, Response: response.GetAuditLogsResponse{}},
+
+	{Method: "POST", Path: "/api/v1/upload/photo", Summary: "Upload a user photo", Tags: []string{"upload"}, Upload: true, Response: response.UploadPhotoResponse{}},
+}
@@ -1,37 +1,90 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/middleware"
+	"warehouse-go/user-service/pkg/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, container *Container) {
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "user-service",
+		})
+	})
+	app.Get("/openapi.json", openapi.Handler(app, openAPIInfo, openAPIOperations))
+
 	api := app.Group("/api/v1")
+	track := container.AuditTracker
 
-	roles := api.Group("/roles")
-	roles.Post("/", container.RoleController.CreateRole)
+	roles := api.Group("/roles", middleware.RequirePermission(model.PermissionRoleManage))
+	roles.Post("/", track.Track("role", ""), container.RoleController.CreateRole)
 	roles.Get("/", container.RoleController.GetAllRoles)
 	roles.Get("/:id", container.RoleController.GetAllRoleByID)
-	roles.Put("/:id", container.RoleController.UpdateRole)
-	roles.Delete("/:id", container.RoleController.DeleteRole)
+	roles.Put("/:id", track.Track("role", "id"), container.RoleController.UpdateRole)
+	roles.Delete("/:id", track.Track("role", "id"), container.RoleController.DeleteRole)
+
+	api.Get("/permissions", middleware.RequirePermission(model.PermissionRoleManage), container.RoleController.GetAllPermissions)
 
 	users := api.Group("/users")
-	users.Post("/", container.UserController.CreateUser)
+	// /me is the caller's own account, registered before /:id.
+	users.Get("/me", container.UserController.GetMe)
+	users.Put("/me", track.TrackSelf("update-profile", "user"), container.UserController.UpdateMe)
+	users.Get("/me/sessions", container.SessionController.GetMySessions)
+	users.Delete("/me/sessions/:id", track.TrackAction("revoke", "session", "id"), container.SessionController.RevokeMySession)
+	users.Post("/", track.TrackCreate("user", "user_id"), container.UserController.CreateUser)
+	users.Post("/import", track.TrackAction("import", "user", ""), container.UserCSVController.ImportUsers)
+	users.Get("/export", container.UserCSVController.ExportUsers)
 	users.Get("/", container.UserController.GetAllUsers)
 	users.Get("/:id", container.UserController.GetUserByID)
 	users.Get("/email/:email", container.UserController.GetUserByID)
-	users.Put("/:id", container.UserController.UpdateUser)
-	users.Delete("/:id", container.UserController.DeleteUser)
+	users.Put("/:id", track.Track("user", "id"), container.UserController.UpdateUser)
+	users.Delete("/:id", track.TrackAction("deactivate", "user", "id"), container.UserController.DeleteUser)
+	users.Post("/:id/restore", track.TrackAction("restore", "user", "id"), container.UserController.RestoreUser)
+	users.Post("/:id/unlock", middleware.RequirePermission(model.PermissionUserUnlock), track.TrackAction("unlock", "user", "id"), container.AuthController.UnlockUser)
+	users.Post("/:id/2fa/reset", middleware.RequirePermission(model.PermissionUserReset2FA), track.TrackAction("reset-2fa", "user", "id"), container.TwoFactorController.Reset)
 
-	assignRole := api.Group("/assign-role")
-	assignRole.Post("/", container.UserController.AssignUserToRole)
+	assignRole := api.Group("/assign-role", middleware.RequirePermission(model.PermissionRoleManage))
+	assignRole.Post("/", track.Track("user-role", ""), container.UserController.AssignUserToRole)
 	assignRole.Get("/", container.UserController.GetAllUserRoles)
 	assignRole.Get("/:userRoleID", container.UserController.GetUserRoleByID)
-	assignRole.Put("/:userRoleID", container.UserController.EditAssignUserToRole)
+	assignRole.Put("/:userRoleID", track.Track("user-role", "userRoleID"), container.UserController.EditAssignUserToRole)
 
 	users.Get("/role/:roleName", container.UserController.GetUserByRoleName)
 
 	auth := api.Group("/auth")
 	auth.Post("/login", container.AuthController.Login)
+	auth.Post("/forgot-password", container.AuthController.ForgotPassword)
+	auth.Post("/reset-password", track.TrackAction("reset-password", "user", ""), container.AuthController.ResetPassword)
+	auth.Post("/change-password", track.TrackSelf("change-password", "user"), container.AuthController.ChangePassword)
+	auth.Post("/accept-invitation", track.TrackAction("accept", "invitation", ""), container.InvitationController.AcceptInvitation)
+	auth.Post("/2fa/enroll", track.TrackSelf("enroll-2fa", "user"), container.TwoFactorController.Enroll)
+	auth.Post("/2fa/confirm", track.TrackSelf("confirm-2fa", "user"), container.TwoFactorController.Confirm)
+	auth.Post("/2fa/disable", track.TrackSelf("disable-2fa", "user"), container.TwoFactorController.Disable)
+
+	invitations := api.Group("/invitations", middleware.RequirePermission(model.PermissionInvitationManage))
+	invitations.Get("/", container.InvitationController.GetAllInvitations)
+	invitations.Post("/:id/resend", track.TrackAction("resend", "invitation", "id"), container.InvitationController.ResendInvitation)
+	invitations.Delete("/:id", track.TrackAction("revoke", "invitation", "id"), container.InvitationController.RevokeInvitation)
 
 	upload := api.Group("/upload")
 	upload.Post("/photo", container.UploadController.UploadPhoto)
 
+	apiKeys := api.Group("/api-keys", middleware.RequirePermission(model.PermissionAPIKeyManage))
+	apiKeys.Post("/", track.Track("api-key", ""), container.APIKeyController.CreateAPIKey)
+	apiKeys.Get("/", container.APIKeyController.GetAllAPIKeys)
+	apiKeys.Delete("/:id", track.TrackAction("revoke", "api-key", "id"), container.APIKeyController.RevokeAPIKey)
+
+	api.Get("/audit", middleware.RequirePermission(model.PermissionAuditRead), container.AuditController.GetAuditLogs)
+
+	// Only the api gateway calls this, no gateway route points here.
+	app.Get("/internal/api-keys/verify", container.APIKeyController.VerifyAPIKey)
+	app.Post("/internal/2fa/verify", container.AuthController.VerifyTwoFactor)
+	app.Post("/internal/oidc/login", container.SSOController.Login)
+	app.Post("/internal/sessions", container.SessionController.CreateSession)
+	app.Get("/internal/sessions/verify", container.SessionController.VerifySession)
+
 }
\ No newline at end of file
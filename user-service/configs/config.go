@@ -1,10 +1,16 @@
 package configs
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type App struct {
 	AppPort string `json:"app_port"`
 	AppEnv  string `json:"app_env"`
+
+	UrlMerchantService string `json:"url_merchant_service"`
 }
 
 type SqlDB struct {
@@ -15,6 +21,9 @@ type SqlDB struct {
 	DBname   string `json:"db_name"`
 	DBMaxOpenConns	int    `json:"db_max_open_conns"`
 	DBMaxIdleConns	int    `json:"db_max_idle_conns"`
+	// SkipMigrations leaves migrating to the migrate command instead of
+	// applying pending migrations on start.
+	SkipMigrations bool `json:"skip_migrations"`
 }
 
 type Redis struct {
@@ -35,12 +44,29 @@ type Supabase struct {
 	Bucket string `json:"bucket"`
 }
 
+type Auth struct {
+	MaxFailedAttempts int           `json:"max_failed_attempts"`
+	LockoutDuration   time.Duration `json:"lockout_duration"`
+	// PasswordResetURL is the frontend page that takes the reset token, the
+	// emailed link is PasswordResetURL?token=<token>.
+	PasswordResetURL string        `json:"password_reset_url"`
+	PasswordResetTTL time.Duration `json:"password_reset_ttl"`
+	// InvitationURL is the frontend page where an invited user sets the
+	// password, the emailed link is InvitationURL?token=<token>.
+	InvitationURL string        `json:"invitation_url"`
+	InvitationTTL time.Duration `json:"invitation_ttl"`
+	// TwoFactorIssuer is the name authenticator apps show next to the
+	// account.
+	TwoFactorIssuer string `json:"two_factor_issuer"`
+}
+
 type Config struct {
 	App      App      `json:"app"`
 	SqlDB    SqlDB    `json:"sql_db"`
 	Redis    Redis    `json:"redis"`
 	RabitMQ  RabbitMQ  `json:"rabitmq"`
 	Supabase Supabase `json:"supabase"` 
+	Auth     Auth     `json:"auth"`
 }
 
 func NewConfig() *Config {
@@ -48,6 +74,8 @@ func NewConfig() *Config {
 		App: App{
 			AppPort: viper.GetString("APP_PORT"),
 			AppEnv:  viper.GetString("APP_ENV"),
+
+			UrlMerchantService: viper.GetString("URL_MERCHANT_SERVICE"),
 	},
 		SqlDB: SqlDB {
 			Host:     viper.GetString("DATABASE_HOST"),
@@ -57,6 +85,7 @@ func NewConfig() *Config {
 			DBname:   viper.GetString("DATABASE_NAME"),
 			DBMaxIdleConns: viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			DBMaxOpenConns: viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
+			SkipMigrations: viper.GetBool("DATABASE_SKIP_MIGRATIONS"),
 
 	},
 		RabitMQ: RabbitMQ{
@@ -73,6 +102,15 @@ func NewConfig() *Config {
 			Url: viper.GetString("SUPABASE_URL"),
 			Key: viper.GetString("SUPABASE_KEY"),
 			Bucket: viper.GetString("SUPABASE_BUCKET"),
+	},
+		Auth: Auth{
+			MaxFailedAttempts: viper.GetInt("LOGIN_MAX_FAILED_ATTEMPTS"),
+			LockoutDuration: viper.GetDuration("LOGIN_LOCKOUT_DURATION"),
+			PasswordResetURL: viper.GetString("PASSWORD_RESET_URL"),
+			PasswordResetTTL: viper.GetDuration("PASSWORD_RESET_TTL"),
+			InvitationURL: viper.GetString("INVITATION_URL"),
+			InvitationTTL: viper.GetDuration("INVITATION_TTL"),
+			TwoFactorIssuer: viper.GetString("TWO_FACTOR_ISSUER"),
 	},
   }
 }
\ No newline at end of file
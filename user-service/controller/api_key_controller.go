@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type APIKeyControllerInterface interface {
+	CreateAPIKey(c *fiber.Ctx) error
+	GetAllAPIKeys(c *fiber.Ctx) error
+	RevokeAPIKey(c *fiber.Ctx) error
+	VerifyAPIKey(c *fiber.Ctx) error
+}
+
+type apiKeyController struct {
+	apiKeyUsecase usecase.APIKeyUsecaseInterface
+}
+
+// CreateAPIKey implements APIKeyControllerInterface.
+func (a *apiKeyController) CreateAPIKey(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.CreateAPIKeyRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[APIKeyController] CreateAPIKey - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[APIKeyController] CreateAPIKey - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	reqModel := model.APIKey{
+		Name:       req.Name,
+		Scopes:     joinList(req.Scopes),
+		AllowedIPs: joinList(req.AllowedIPs),
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  conv.StringToUint(c.Get("X-User-ID")),
+	}
+
+	key, apiKey, err := a.apiKeyUsecase.CreateAPIKey(ctx, reqModel)
+	if err != nil {
+		log.Errorf("[APIKeyController] CreateAPIKey - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidAPIKeyRequest) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to create api key",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "API key created successfully. Store the key now, it cannot be shown again",
+		"data": response.CreateAPIKeyResponse{
+			Key:    key,
+			APIKey: toAPIKeyResponse(*apiKey),
+		},
+	})
+}
+
+// GetAllAPIKeys implements APIKeyControllerInterface.
+func (a *apiKeyController) GetAllAPIKeys(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	apiKeys, err := a.apiKeyUsecase.GetAllAPIKeys(ctx)
+	if err != nil {
+		log.Errorf("[APIKeyController] GetAllAPIKeys - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get api keys",
+		})
+	}
+
+	apiKeyResponses := []response.APIKeyResponse{}
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, toAPIKeyResponse(apiKey))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API keys retrieved successfully",
+		"data":    apiKeyResponses,
+	})
+}
+
+// RevokeAPIKey implements APIKeyControllerInterface.
+func (a *apiKeyController) RevokeAPIKey(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := a.apiKeyUsecase.RevokeAPIKey(ctx, id); err != nil {
+		log.Errorf("[APIKeyController] RevokeAPIKey - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "API key not found or already revoked",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to revoke api key",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
+
+// VerifyAPIKey implements APIKeyControllerInterface. It is called by the api
+// gateway with the key in the X-API-Key header and is not routed to clients.
+func (a *apiKeyController) VerifyAPIKey(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	apiKey, err := a.apiKeyUsecase.VerifyAPIKey(ctx, c.Get("X-API-Key"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidAPIKey) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid or expired api key",
+			})
+		}
+		log.Errorf("[APIKeyController] VerifyAPIKey - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to verify api key",
+		})
+	}
+
+	var roles []string
+	for _, role := range apiKey.Creator.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key is valid",
+		"data": response.VerifyAPIKeyResponse{
+			ID:          apiKey.ID,
+			Name:        apiKey.Name,
+			Scopes:      splitList(apiKey.Scopes),
+			AllowedIPs:  splitList(apiKey.AllowedIPs),
+			ExpiresAt:   apiKey.ExpiresAt,
+			UserID:      apiKey.CreatedBy,
+			Email:       apiKey.Creator.Email,
+			Roles:       roles,
+			Permissions: apiKey.Creator.PermissionNames(),
+		},
+	})
+}
+
+func toAPIKeyResponse(apiKey model.APIKey) response.APIKeyResponse {
+	return response.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     splitList(apiKey.Scopes),
+		AllowedIPs: splitList(apiKey.AllowedIPs),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// joinList stores a list as a comma separated column.
+func joinList(values []string) string {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func NewAPIKeyController(apiKeyUsecase usecase.APIKeyUsecaseInterface) APIKeyControllerInterface {
+	return &apiKeyController{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
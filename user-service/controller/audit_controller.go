@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"encoding/json"
+	"time"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/pagination"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/repository"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type AuditControllerInterface interface {
+	GetAuditLogs(c *fiber.Ctx) error
+}
+
+type auditController struct {
+	auditUsecase usecase.AuditUsecaseInterface
+}
+
+// GetAuditLogs implements AuditControllerInterface. Newest changes come
+// first.
+func (a *auditController) GetAuditLogs(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetAuditLogsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[AuditController] GetAuditLogs - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AuditController] GetAuditLogs - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	filter := repository.AuditLogFilter{
+		Service:   req.Service,
+		Entity:    req.Entity,
+		EntityID:  req.EntityID,
+		Action:    req.Action,
+		ActorID:   req.ActorID,
+		RequestID: req.RequestID,
+		Search:    req.Search,
+	}
+	from, errFrom := parseTimeQuery(req.From)
+	to, errTo := parseTimeQuery(req.To)
+	if errFrom != nil || errTo != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "from and to must be RFC 3339 timestamps",
+		})
+	}
+	filter.From, filter.To = from, to
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	auditLogs, total, err := a.auditUsecase.GetAuditLogs(ctx, req.Page, req.Limit, filter)
+	if err != nil {
+		log.Errorf("[AuditController] GetAuditLogs - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get audit logs",
+		})
+	}
+
+	auditLogResponses := []response.AuditLogResponse{}
+	for _, auditLog := range auditLogs {
+		auditLogResponses = append(auditLogResponses, toAuditLogResponse(auditLog))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Audit logs retrieved successfully",
+		"data": response.GetAuditLogsResponse{
+			AuditLogs:  auditLogResponses,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+func toAuditLogResponse(auditLog model.AuditLog) response.AuditLogResponse {
+	return response.AuditLogResponse{
+		ID:        auditLog.ID,
+		Service:   auditLog.Service,
+		Action:    auditLog.Action,
+		Entity:    auditLog.Entity,
+		EntityID:  auditLog.EntityID,
+		ActorID:   auditLog.ActorID,
+		Before:    rawJSON(auditLog.Before),
+		After:     rawJSON(auditLog.After),
+		Changes:   rawJSON(auditLog.Changes),
+		RequestID: auditLog.RequestID,
+		IP:        auditLog.IP,
+		CreatedAt: auditLog.CreatedAt,
+	}
+}
+
+// parseTimeQuery returns nil for an empty value.
+func parseTimeQuery(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// rawJSON embeds a stored JSON column as is, or null when it is empty.
+func rawJSON(data string) json.RawMessage {
+	if data == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(data)
+}
+
+func NewAuditController(auditUsecase usecase.AuditUsecaseInterface) AuditControllerInterface {
+	return &auditController{
+		auditUsecase: auditUsecase,
+	}
+}
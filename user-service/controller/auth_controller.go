@@ -1,22 +1,33 @@
 package controller
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
 	"warehouse-go/user-service/controller/request"
 	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
 	"warehouse-go/user-service/pkg/conv"
 	"warehouse-go/user-service/pkg/validator"
 	"warehouse-go/user-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type AuthControllerInterface interface {
 	Login(c *fiber.Ctx) error
+	UnlockUser(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+	VerifyTwoFactor(c *fiber.Ctx) error
 }
 
 type AuthController struct {
-	AuthService usecase.UserUsecaseInterface
+	AuthService usecase.AuthUsecaseInterface
 }
 
 // Login implements AuthControllerInterface.
@@ -36,50 +47,263 @@ func (a *AuthController) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := a.AuthService.GetUserByEmail(ctx, loginRequest.Email)
+	user, err := a.AuthService.Login(ctx, loginRequest.Email, loginRequest.Password)
 	if err != nil {
-		log.Errorf("[AuthController] Login - 3: %v", err.Error())
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
+		log.Errorf("[AuthController] Login - 3: %v", err)
+
+		var lockedErr *usecase.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+				"message":      "Account is temporarily locked because of too many failed login attempts",
+				"locked_until": lockedErr.Until,
+			})
+		}
+
+		if errors.Is(err, usecase.ErrAccountNotActivated) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Account is not activated yet. Use the invitation link sent by email",
+			})
+		}
+
+		if errors.Is(err, usecase.ErrAccountDeactivated) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Account has been deactivated",
+			})
+		}
+
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid email or password",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to login",
 		})
 	}
 
-	if user == nil {
-		log.Errorf("[AuthController] Login - 4: user not found")
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": "User not found",
+	loginResp := newLoginResponse(user)
+	loginResp.TwoFactorRequired = user.TwoFactorEnabled
+
+	message := "Login successful"
+	if loginResp.TwoFactorRequired {
+		message = "Two-factor code required"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    loginResp,
+	})
+
+
+}
+
+// VerifyTwoFactor implements AuthControllerInterface. It is the second login
+// step, called by the api gateway with the user of the login challenge.
+func (a *AuthController) VerifyTwoFactor(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.VerifyTwoFactorRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[AuthController] VerifyTwoFactor - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AuthController] VerifyTwoFactor - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
 		})
 	}
 
-	isSame := conv.CheckPasswordHash(loginRequest.Password, user.Password)
-	if !isSame {
-		log.Errorf("[AuthController] Login - 5: %v", err.Error())
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Invalid email or password",
+	user, err := a.AuthService.VerifySecondFactor(ctx, req.UserID, req.Code)
+	if err != nil {
+		log.Errorf("[AuthController] VerifyTwoFactor - 3: %v", err)
+
+		var lockedErr *usecase.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+				"message":      "Account is temporarily locked because of too many failed login attempts",
+				"locked_until": lockedErr.Until,
+			})
+		}
+
+		if errors.Is(err, usecase.ErrAccountDeactivated) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Account has been deactivated",
+			})
+		}
+
+		if errors.Is(err, usecase.ErrInvalidTwoFactorCode) || errors.Is(err, usecase.ErrTwoFactorNotEnabled) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid two-factor code",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to verify two-factor code",
 		})
-	}	 
+	}
 
-	var roles []string 
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Login successful",
+		"data":    newLoginResponse(user),
+	})
+}
+
+func newLoginResponse(user *model.User) response.LoginResponse {
+	var roles []string
 	for _, r := range user.Roles {
 		roles = append(roles, r.Name)
 	}
 
-	loginResp := response.LoginResponse{
-		UserID:   uint(user.ID),
-		Email:    user.Email,
-		Role: roles,
+	return response.LoginResponse{
+		UserID:                 uint(user.ID),
+		Email:                  user.Email,
+		Role:                   roles,
+		Permissions:            user.PermissionNames(),
+		MustChangePassword:     user.MustChangePassword,
+		TwoFactorSetupRequired: !user.TwoFactorEnabled && user.RequiresTwoFactor(),
 	}
+}
 
+// UnlockUser implements AuthControllerInterface.
+func (a *AuthController) UnlockUser(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID := conv.StringToUint(c.Params("id"))
+
+	if err := a.AuthService.UnlockUser(ctx, userID); err != nil {
+		log.Errorf("[AuthController] UnlockUser - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to unlock user",
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Login successful",
-		"data":    loginResp,
+		"message": "User unlocked successfully",
 	})
+}
 
+// ForgotPassword implements AuthControllerInterface. The answer is the same
+// whether or not the email belongs to an account.
+func (a *AuthController) ForgotPassword(c *fiber.Ctx) error {
+	ctx := c.Context()
 
+	req := request.ForgotPasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[AuthController] ForgotPassword - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AuthController] ForgotPassword - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := a.AuthService.ForgotPassword(ctx, req.Email); err != nil {
+		log.Errorf("[AuthController] ForgotPassword - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to request password reset",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword implements AuthControllerInterface.
+func (a *AuthController) ResetPassword(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.ResetPasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[AuthController] ResetPassword - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AuthController] ResetPassword - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := a.AuthService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		log.Errorf("[AuthController] ResetPassword - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidResetToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid or expired password reset token",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to reset password",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password reset successfully",
+	})
+}
+
+// ChangePassword implements AuthControllerInterface. The caller is the user
+// the gateway put in X-User-ID; their other sessions are signed out.
+func (a *AuthController) ChangePassword(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.ChangePasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[AuthController] ChangePassword - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AuthController] ChangePassword - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+	sessionID := conv.StringToUint(c.Get(SessionIDHeader))
+	if err := a.AuthService.ChangePassword(ctx, userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		log.Errorf("[AuthController] ChangePassword - 3: %v", err)
+		if errors.Is(err, usecase.ErrWrongPassword) || errors.Is(err, usecase.ErrPasswordUnchanged) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to change password",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password changed successfully. Please log in again",
+	})
 }
 
-func NewAuthController(authService usecase.UserUsecaseInterface) AuthControllerInterface {
+func NewAuthController(authService usecase.AuthUsecaseInterface) AuthControllerInterface {
 	return &AuthController{
 		AuthService: authService,
 	}
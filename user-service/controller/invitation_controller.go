@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"time"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type InvitationControllerInterface interface {
+	GetAllInvitations(c *fiber.Ctx) error
+	ResendInvitation(c *fiber.Ctx) error
+	RevokeInvitation(c *fiber.Ctx) error
+	AcceptInvitation(c *fiber.Ctx) error
+}
+
+type invitationController struct {
+	invitationUsecase usecase.InvitationUsecaseInterface
+}
+
+// GetAllInvitations implements InvitationControllerInterface. ?status=
+// filters on pending, accepted, revoked or expired.
+func (i *invitationController) GetAllInvitations(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	status := c.Query("status")
+	switch status {
+	case "", model.InvitationPending, model.InvitationAccepted, model.InvitationRevoked, model.InvitationExpired:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "status must be pending, accepted, revoked or expired",
+		})
+	}
+
+	invitations, err := i.invitationUsecase.GetAllInvitations(ctx, status)
+	if err != nil {
+		log.Errorf("[InvitationController] GetAllInvitations - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get invitations",
+		})
+	}
+
+	now := time.Now()
+	invitationResponses := []response.InvitationResponse{}
+	for _, invitation := range invitations {
+		invitationResponses = append(invitationResponses, toInvitationResponse(invitation, now))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitations retrieved successfully",
+		"data":    invitationResponses,
+	})
+}
+
+// ResendInvitation implements InvitationControllerInterface.
+func (i *invitationController) ResendInvitation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	invitation, err := i.invitationUsecase.ResendInvitation(ctx, id)
+	if err != nil {
+		log.Errorf("[InvitationController] ResendInvitation - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Invitation not found, already accepted or revoked",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to resend invitation",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation resent successfully",
+		"data":    toInvitationResponse(*invitation, time.Now()),
+	})
+}
+
+// RevokeInvitation implements InvitationControllerInterface.
+func (i *invitationController) RevokeInvitation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := i.invitationUsecase.RevokeInvitation(ctx, id); err != nil {
+		log.Errorf("[InvitationController] RevokeInvitation - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Invitation not found, already accepted or revoked",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to revoke invitation",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation implements InvitationControllerInterface. It is public,
+// the token from the emailed link is the credential.
+func (i *invitationController) AcceptInvitation(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.AcceptInvitationRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[InvitationController] AcceptInvitation - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[InvitationController] AcceptInvitation - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := i.invitationUsecase.AcceptInvitation(ctx, req.Token, req.Password); err != nil {
+		log.Errorf("[InvitationController] AcceptInvitation - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidInvitation) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid, expired or already used invitation",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to accept invitation",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Account activated successfully. You can now log in",
+	})
+}
+
+func toInvitationResponse(invitation model.Invitation, now time.Time) response.InvitationResponse {
+	return response.InvitationResponse{
+		ID:         invitation.ID,
+		UserID:     invitation.UserID,
+		Name:       invitation.User.Name,
+		Email:      invitation.User.Email,
+		Status:     invitation.Status(now),
+		ExpiresAt:  invitation.ExpiresAt,
+		SentCount:  invitation.SentCount,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedBy:  invitation.CreatedBy,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+func NewInvitationController(invitationUsecase usecase.InvitationUsecaseInterface) InvitationControllerInterface {
+	return &invitationController{
+		invitationUsecase: invitationUsecase,
+	}
+}
@@ -0,0 +1,17 @@
+package request
+
+// GetAuditLogsRequest filters the audit trail. From and To are RFC 3339
+// timestamps; To is exclusive.
+type GetAuditLogsRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Service   string `query:"service"`
+	Entity    string `query:"entity"`
+	EntityID  string `query:"entity_id"`
+	Action    string `query:"action"`
+	ActorID   uint   `query:"actor_id"`
+	RequestID string `query:"request_id"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+	Search    string `query:"search"`
+}
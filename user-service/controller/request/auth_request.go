@@ -4,3 +4,52 @@ type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 }
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// VerifyTwoFactorRequest is sent by the api gateway for the second login
+// step. Code is a TOTP code or a recovery code.
+type VerifyTwoFactorRequest struct {
+	UserID uint   `json:"user_id" validate:"required"`
+	Code   string `json:"code" validate:"required"`
+}
+
+// SSOLoginRequest is sent by the api gateway after it verified the ID token
+// of an OpenID Connect login. RoleNames are the user's groups mapped to roles.
+type SSOLoginRequest struct {
+	Issuer        string   `json:"issuer" validate:"required"`
+	Subject       string   `json:"subject" validate:"required"`
+	Email         string   `json:"email" validate:"omitempty,email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+	RoleNames     []string `json:"role_names"`
+	AutoProvision bool     `json:"auto_provision"`
+	// MFA means the identity provider asserted a second factor, which
+	// stands in for the user's own.
+	MFA bool `json:"mfa"`
+}
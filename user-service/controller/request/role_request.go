@@ -2,4 +2,8 @@ package request
 
 type CreateRoleRequest struct {
 	Name        string `json:"name" validate:"required"`
+	RequireTwoFactor bool `json:"require_two_factor"`
+	// Permissions is the complete set of the role. Left out on update, the
+	// current permissions are kept.
+	Permissions []string `json:"permissions" validate:"omitempty,dive,required"`
 }
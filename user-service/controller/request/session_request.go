@@ -0,0 +1,11 @@
+package request
+
+import "time"
+
+// CreateSessionRequest is sent by the api gateway when it issues a token.
+type CreateSessionRequest struct {
+	UserID    uint      `json:"user_id" validate:"required"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
@@ -8,7 +8,6 @@ type AssignUserToRoleRequest struct {
 type CreateUserRequest struct {
 	Name	 string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
 	Phone	string `json:"phone" validate:"required"`
 	Photo   string `json:"photo" validate:"required"`
 }
@@ -17,6 +16,8 @@ type GetAllUsersRequest struct {
 	Page      int    `json:"page" validate:"omitempty,min=1"`
 	Limit     int    `json:"limit" validate:"omitempty,min=1,max=100"`
 	Search    string `json:"search"`
+	// Status is active (the default), inactive or all.
+	Status    string `json:"status" query:"status" validate:"omitempty,oneof=active inactive all"`
 	SortBy    string `json:"sort_by"`
 	SortOrder string `json:"sort_order"`
 }
@@ -28,4 +29,23 @@ type UpdateUserRequest struct {
 	Password string `json:"password" validate:"omitempty,min=8"`
 	Phone	string `json:"phone" validate:"required"`
 	Photo   string `json:"photo" validate:"required"`
-}
\ No newline at end of file
+}
+
+// UpdateProfileRequest is what users may change about themselves. Photo is
+// a URL returned by /upload/photo.
+type UpdateProfileRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Phone string `json:"phone" validate:"required"`
+	Photo string `json:"photo"`
+}
+
+// ImportUsersRequest comes with the CSV in the multipart field "file".
+type ImportUsersRequest struct {
+	DryRun bool `query:"dry_run"`
+}
+
+// ExportUsersRequest filters the export like the user list does.
+type ExportUsersRequest struct {
+	Search string `query:"search"`
+	Status string `query:"status" validate:"omitempty,oneof=active inactive all"`
+}
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+	"warehouse-go/user-service/pkg/pagination"
+)
+
+type AuditLogResponse struct {
+	ID        uint            `json:"id"`
+	Service   string          `json:"service"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	ActorID   uint            `json:"actor_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Changes   json.RawMessage `json:"changes"`
+	RequestID string          `json:"request_id"`
+	IP        string          `json:"ip"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type GetAuditLogsResponse struct {
+	AuditLogs  []AuditLogResponse            `json:"audit_logs"`
+	Pagination pagination.PaginationResponse `json:"pagination"`
+}
@@ -4,4 +4,24 @@ type LoginResponse struct {
 	UserID   uint `json:"user_id"`
 	Email	string `json:"email"`
 	Role 	[]string `json:"role_names"`
+	Permissions []string `json:"permissions"`
+	MustChangePassword bool `json:"must_change_password"`
+	// TwoFactorRequired means the password was right but the login is only
+	// complete after /internal/2fa/verify.
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// TwoFactorSetupRequired means a role of the user requires 2FA and the
+	// user has not enrolled yet.
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
+}
+
+// TwoFactorEnrollmentResponse is rendered as a QR code from
+// provisioning_uri; secret is for typing it in by hand.
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse is the only time the recovery codes are shown.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
\ No newline at end of file
@@ -4,6 +4,13 @@ type RoleResponse struct {
 	ID   uint   `json:"id"`
 	Name string `json:"name"`
 	CountUsers int `json:"count_users"`
+	RequireTwoFactor bool `json:"require_two_factor"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 
@@ -1,14 +1,18 @@
 package response
 
-import "warehouse-go/user-service/pkg/pagination"
+import (
+	"time"
+	"warehouse-go/user-service/pkg/pagination"
+)
 
 type UserResponse struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Photo    string `json:"photo"`
-	RoleName string `json:"role_name"`
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	Photo         string     `json:"photo"`
+	RoleName      string     `json:"role_name"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
 }
 
 type GetAllUsersResponse struct {
@@ -28,4 +32,40 @@ type UserRoleResponse struct {
 type GetAllUserRolesResponse struct {
 	UserRoles  []UserRoleResponse        `json:"user_roles"`
 	Pagination pagination.PaginationResponse `json:"pagination"`
-}
\ No newline at end of file
+}
+// ProfileResponse is the caller's own account, from /users/me.
+type ProfileResponse struct {
+	ID               uint     `json:"id"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email"`
+	Phone            string   `json:"phone"`
+	Photo            string   `json:"photo"`
+	Roles            []string `json:"roles"`
+	Permissions      []string `json:"permissions"`
+	TwoFactorEnabled bool     `json:"two_factor_enabled"`
+}
+
+// ImportUsersResponse reports an import. Rows are numbered by their line in
+// the file; UserID and InvitationID are only set once users are created.
+type ImportUsersResponse struct {
+	DryRun    bool                   `json:"dry_run"`
+	TotalRows int                    `json:"total_rows"`
+	Imported  int                    `json:"imported"`
+	Users     []ImportedUserResponse `json:"users"`
+	Errors    []ImportRowError       `json:"errors"`
+}
+
+type ImportedUserResponse struct {
+	Row          int      `json:"row"`
+	UserID       uint     `json:"user_id,omitempty"`
+	InvitationID uint     `json:"invitation_id,omitempty"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	Roles        []string `json:"roles"`
+}
+
+type ImportRowError struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email"`
+	Errors []string `json:"errors"`
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/user-service/controller/request"
 	"warehouse-go/user-service/controller/response"
 	"warehouse-go/user-service/model"
@@ -18,6 +19,7 @@ type RoleControllerInterface interface {
 	DeleteRole(c *fiber.Ctx) error
 	GetAllRoleByID(c *fiber.Ctx) error
 	GetAllRoles(c *fiber.Ctx) error
+	GetAllPermissions(c *fiber.Ctx) error
 }
 
 type roleController struct {
@@ -43,10 +45,17 @@ func (r *roleController) CreateRole(c *fiber.Ctx) error {
 	}
 	reqModel := model.Role{
 		Name: req.Name,
+		RequireTwoFactor: req.RequireTwoFactor,
+		Permissions: permissionsFromNames(req.Permissions),
 	}
 
 	if err := r.roleUsecase.CreateRole(ctx, reqModel); err != nil {
 		log.Errorf("[RoleController] CreateRole - 3: %v", err)
+		if errors.Is(err, usecase.ErrUnknownPermission) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": err.Error(),
 		})
@@ -106,7 +115,7 @@ func (r *roleController) GetAllRoleByID(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message" : "Role fetched successfully",
-		"data"   : role,
+		"data"   : newRoleResponse(*role),
 	})
 }
 
@@ -124,11 +133,7 @@ func (r *roleController) GetAllRoles(c *fiber.Ctx) error {
 
 	resp := []response.RoleResponse{}
 	for _, role := range roles {
-		resp = append(resp, response.RoleResponse{
-			ID: uint(role.ID),
-			Name: role.Name,
-			CountUsers: int(len(role.Users)),
-		})
+		resp = append(resp, newRoleResponse(role))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -159,10 +164,17 @@ func (r *roleController) UpdateRole(c *fiber.Ctx) error {
 	 reqModel := model.Role{
 		ID: uint(conv.StringToUint(c.Params("id"))),
 		Name: req.Name,
+		RequireTwoFactor: req.RequireTwoFactor,
+		Permissions: permissionsFromNames(req.Permissions),
 	}
 	
 	if err := r.roleUsecase.UpdateRole(ctx, reqModel); err != nil {
 		log.Errorf("[RoleController] UpdateRole - 3: %v", err)
+		if errors.Is(err, usecase.ErrUnknownPermission) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": err.Error(),
 		})
@@ -173,6 +185,56 @@ func (r *roleController) UpdateRole(c *fiber.Ctx) error {
 	})
 }
 
+// GetAllPermissions implements RoleControllerInterface. It lists the
+// permissions roles can be given.
+func (r *roleController) GetAllPermissions(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	permissions, err := r.roleUsecase.GetAllPermissions(ctx)
+	if err != nil {
+		log.Errorf("[RoleController] GetAllPermissions - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	resp := []response.PermissionResponse{}
+	for _, permission := range permissions {
+		resp = append(resp, response.PermissionResponse{
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permissions fetched successfully",
+		"data":    resp,
+	})
+}
+
+func newRoleResponse(role model.Role) response.RoleResponse {
+	return response.RoleResponse{
+		ID: uint(role.ID),
+		Name: role.Name,
+		CountUsers: int(len(role.Users)),
+		RequireTwoFactor: role.RequireTwoFactor,
+		Permissions: role.PermissionNames(),
+	}
+}
+
+// permissionsFromNames keeps nil apart from an empty list, see
+// CreateRoleRequest.Permissions.
+func permissionsFromNames(names []string) []model.Permission {
+	if names == nil {
+		return nil
+	}
+	permissions := make([]model.Permission, 0, len(names))
+	for _, name := range names {
+		permissions = append(permissions, model.Permission{Name: name})
+	}
+	return permissions
+}
+
 func NewRoleController(roleUsecase usecase.RoleUsecaseInterface) RoleControllerInterface {
 	return &roleController{roleUsecase: roleUsecase}
 }
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"errors"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// SessionIDHeader carries the session of the caller's token. The gateway
+// sets it next to X-User-ID.
+const SessionIDHeader = "X-Session-ID"
+
+type SessionControllerInterface interface {
+	GetMySessions(c *fiber.Ctx) error
+	RevokeMySession(c *fiber.Ctx) error
+	CreateSession(c *fiber.Ctx) error
+	VerifySession(c *fiber.Ctx) error
+}
+
+type sessionController struct {
+	sessionUsecase usecase.SessionUsecaseInterface
+}
+
+// GetMySessions implements SessionControllerInterface. It lists the active
+// sessions of the caller, the one making the request marked as current.
+func (s *sessionController) GetMySessions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+
+	sessions, err := s.sessionUsecase.GetSessions(ctx, userID)
+	if err != nil {
+		log.Errorf("[SessionController] GetMySessions - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get sessions",
+		})
+	}
+
+	current := conv.StringToUint(c.Get(SessionIDHeader))
+	sessionResponses := []response.SessionResponse{}
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, toSessionResponse(session, current))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Sessions retrieved successfully",
+		"data":    sessionResponses,
+	})
+}
+
+// RevokeMySession implements SessionControllerInterface. Tokens of the
+// session stop working once the gateway's cached verification runs out.
+func (s *sessionController) RevokeMySession(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := s.sessionUsecase.RevokeSession(ctx, id, userID); err != nil {
+		log.Errorf("[SessionController] RevokeMySession - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Session not found or already signed out",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to sign out session",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session signed out successfully",
+	})
+}
+
+// CreateSession implements SessionControllerInterface. Only the api gateway
+// calls it, when it issues a token.
+func (s *sessionController) CreateSession(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.CreateSessionRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[SessionController] CreateSession - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[SessionController] CreateSession - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	session, err := s.sessionUsecase.CreateSession(ctx, model.Session{
+		UserID:    req.UserID,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		log.Errorf("[SessionController] CreateSession - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to create session",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Session created successfully",
+		"data":    toSessionResponse(*session, session.ID),
+	})
+}
+
+// VerifySession implements SessionControllerInterface. Only the api gateway
+// calls it, for the session in X-Session-ID of the user in X-User-ID.
+func (s *sessionController) VerifySession(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Get(SessionIDHeader))
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+
+	session, err := s.sessionUsecase.VerifySession(ctx, id, userID, c.Get("X-Real-IP"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSession) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Session expired or signed out",
+			})
+		}
+		log.Errorf("[SessionController] VerifySession - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to verify session",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session is active",
+		"data": response.VerifySessionResponse{
+			ID:        session.ID,
+			UserID:    session.UserID,
+			ExpiresAt: session.ExpiresAt,
+		},
+	})
+}
+
+func toSessionResponse(session model.Session, current uint) response.SessionResponse {
+	return response.SessionResponse{
+		ID:         session.ID,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		Current:    session.ID == current,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+func NewSessionController(sessionUsecase usecase.SessionUsecaseInterface) SessionControllerInterface {
+	return &sessionController{
+		sessionUsecase: sessionUsecase,
+	}
+}
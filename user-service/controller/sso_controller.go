@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"errors"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type SSOControllerInterface interface {
+	Login(c *fiber.Ctx) error
+}
+
+type ssoController struct {
+	ssoUsecase usecase.SSOUsecaseInterface
+}
+
+// Login implements SSOControllerInterface. It answers like the password
+// login, for the gateway to issue its token.
+func (s *ssoController) Login(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.SSOLoginRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[SSOController] Login - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[SSOController] Login - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	user, err := s.ssoUsecase.Login(ctx, usecase.SSOLogin{
+		Issuer:        req.Issuer,
+		Subject:       req.Subject,
+		Email:         req.Email,
+		EmailVerified: req.EmailVerified,
+		Name:          req.Name,
+		RoleNames:     req.RoleNames,
+		AutoProvision: req.AutoProvision,
+	})
+	if err != nil {
+		log.Errorf("[SSOController] Login - 3: %v", err)
+		switch {
+		case errors.Is(err, usecase.ErrAccountDeactivated):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Account has been deactivated",
+			})
+		case errors.Is(err, usecase.ErrSSOUserNotFound):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "No account is registered for this user. Ask an administrator to invite you",
+			})
+		case errors.Is(err, usecase.ErrSSOEmailRequired), errors.Is(err, usecase.ErrSSOEmailNotVerified):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to login",
+		})
+	}
+
+	// Without a second factor at the identity provider the user's own 2FA
+	// applies, as for a password login.
+	loginResp := newLoginResponse(user)
+	if req.MFA {
+		loginResp.TwoFactorSetupRequired = false
+	} else {
+		loginResp.TwoFactorRequired = user.TwoFactorEnabled
+	}
+
+	message := "Login successful"
+	if loginResp.TwoFactorRequired {
+		message = "Two-factor code required"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    loginResp,
+	})
+}
+
+func NewSSOController(ssoUsecase usecase.SSOUsecaseInterface) SSOControllerInterface {
+	return &ssoController{
+		ssoUsecase: ssoUsecase,
+	}
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"errors"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type TwoFactorControllerInterface interface {
+	Enroll(c *fiber.Ctx) error
+	Confirm(c *fiber.Ctx) error
+	Disable(c *fiber.Ctx) error
+	Reset(c *fiber.Ctx) error
+}
+
+type twoFactorController struct {
+	twoFactorUsecase usecase.TwoFactorUsecaseInterface
+}
+
+// Enroll implements TwoFactorControllerInterface. The caller is the user the
+// gateway put in X-User-ID; enrolling again before confirming starts over
+// with a new secret.
+func (t *twoFactorController) Enroll(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+	enrollment, err := t.twoFactorUsecase.Enroll(ctx, userID)
+	if err != nil {
+		log.Errorf("[TwoFactorController] Enroll - 1: %v", err)
+		if errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to start two-factor enrollment",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Scan the QR code and confirm with a code from the app",
+		"data": response.TwoFactorEnrollmentResponse{
+			Secret:          enrollment.Secret,
+			ProvisioningURI: enrollment.ProvisioningURI,
+		},
+	})
+}
+
+// Confirm implements TwoFactorControllerInterface.
+func (t *twoFactorController) Confirm(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.ConfirmTwoFactorRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[TwoFactorController] Confirm - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TwoFactorController] Confirm - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+	codes, err := t.twoFactorUsecase.Confirm(ctx, userID, req.Code)
+	if err != nil {
+		log.Errorf("[TwoFactorController] Confirm - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidTwoFactorCode) || errors.Is(err, usecase.ErrTwoFactorNotEnrolled) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to confirm two-factor enrollment",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication enabled. Store the recovery codes, they are not shown again. Please log in again",
+		"data": response.RecoveryCodesResponse{
+			RecoveryCodes: codes,
+		},
+	})
+}
+
+// Disable implements TwoFactorControllerInterface. The password is asked
+// again, a stolen session alone cannot turn 2FA off.
+func (t *twoFactorController) Disable(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.DisableTwoFactorRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[TwoFactorController] Disable - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TwoFactorController] Disable - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	userID := conv.StringToUint(c.Get("X-User-ID"))
+	if err := t.twoFactorUsecase.Disable(ctx, userID, req.Password); err != nil {
+		log.Errorf("[TwoFactorController] Disable - 3: %v", err)
+		if errors.Is(err, usecase.ErrWrongPassword) || errors.Is(err, usecase.ErrTwoFactorNotEnabled) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, usecase.ErrTwoFactorRequired) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to disable two-factor authentication",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// Reset implements TwoFactorControllerInterface. Managers use it for users
+// who lost their device and their recovery codes.
+func (t *twoFactorController) Reset(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID := conv.StringToUint(c.Params("id"))
+
+	if err := t.twoFactorUsecase.Reset(ctx, userID); err != nil {
+		log.Errorf("[TwoFactorController] Reset - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to reset two-factor authentication",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+func NewTwoFactorController(twoFactorUsecase usecase.TwoFactorUsecaseInterface) TwoFactorControllerInterface {
+	return &twoFactorController{twoFactorUsecase: twoFactorUsecase}
+}
@@ -1,9 +1,12 @@
 package controller
 
 import (
-	
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 
 	"warehouse-go/user-service/controller/request"
 	"warehouse-go/user-service/controller/response"
@@ -18,8 +21,11 @@ type UserControllerInterface interface {
 	CreateUser(c *fiber.Ctx) error
 	GetAllUsers(c *fiber.Ctx) error
 	GetUserByID(c *fiber.Ctx) error
+	GetMe(c *fiber.Ctx) error
+	UpdateMe(c *fiber.Ctx) error
 	UpdateUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
+	RestoreUser(c *fiber.Ctx) error
 
 	GetUserByRoleName(c *fiber.Ctx) error
 
@@ -30,7 +36,8 @@ type UserControllerInterface interface {
 }
 
 type userController struct {
-	userUsecase usecase.UserUsecaseInterface
+	userUsecase       usecase.UserUsecaseInterface
+	invitationUsecase usecase.InvitationUsecaseInterface
 }
 
 // AssignUserToRole implements UserControllerInterface.
@@ -82,14 +89,14 @@ func (u *userController) CreateUser(c *fiber.Ctx) error {
 	}
 
 	userModel := model.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password,
-		Phone:    req.Phone,
-		Photo:    req.Photo,
+		Name:  req.Name,
+		Email: req.Email,
+		Phone: req.Phone,
+		Photo: req.Photo,
 	}
 
-	if err := u.userUsecase.CreateUser(ctx, userModel); err != nil {
+	invitation, err := u.invitationUsecase.InviteUser(ctx, userModel, conv.StringToUint(c.Get("X-User-ID")))
+	if err != nil {
 		log.Errorf("[UserController] CreateUser - 3: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": err.Error(),
@@ -97,26 +104,80 @@ func (u *userController) CreateUser(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "User created successfully",
+		"message": "User created successfully. An invitation to set the password has been sent",
+		"data":    toInvitationResponse(*invitation, time.Now()),
 	})
 }
 
-// DeleteUser implements UserControllerInterface.
+// DeleteUser implements UserControllerInterface. The user is deactivated
+// rather than deleted: login is refused and every session signed out.
 func (u *userController) DeleteUser(c *fiber.Ctx) error {
 	ctx := c.Context()
 	id := c.Params("id")
 
 	userID := conv.StringToUint(id)
 
-	if err := u.userUsecase.DeleteUser(ctx, userID); err != nil {
+	if userID == conv.StringToUint(c.Get("X-User-ID")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "You cannot deactivate your own account",
+		})
+	}
+
+	if err := u.userUsecase.DeactivateUser(ctx, userID); err != nil {
 		log.Errorf("[UserController] DeleteUser - 1: %v", err)
+
+		var keeperErr *usecase.KeeperAssignedError
+		if errors.As(err, &keeperErr) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message":      "User is still the keeper of merchants. Assign them to another keeper first",
+				"merchant_ids": keeperErr.MerchantIDs,
+			})
+		}
+		if errors.Is(err, usecase.ErrUserAlreadyDeactivated) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "User is already deactivated",
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
+			"message": "Failed to deactivate user",
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User deleted successfully",
+		"message": "User deactivated successfully",
+	})
+}
+
+// RestoreUser implements UserControllerInterface. Sessions signed out on
+// deactivation stay signed out, the user logs in again.
+func (u *userController) RestoreUser(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID := conv.StringToUint(c.Params("id"))
+
+	if err := u.userUsecase.RestoreUser(ctx, userID); err != nil {
+		log.Errorf("[UserController] RestoreUser - 1: %v", err)
+		if errors.Is(err, usecase.ErrUserNotDeactivated) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "User is not deactivated",
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore user",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User restored successfully",
 	})
 }
 
@@ -247,7 +308,7 @@ func (u *userController) GetAllUsers(c *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	users, total, err := u.userUsecase.GetAllUsers(ctx, req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	users, total, err := u.userUsecase.GetAllUsers(ctx, req.Page, req.Limit, req.Search, req.Status, req.SortBy, req.SortOrder)
 	if err != nil {
 		log.Errorf("[UserController] GetAllUserRoles - 3: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -263,12 +324,13 @@ func (u *userController) GetAllUsers(c *fiber.Ctx) error {
 		}
 
 		resp = append(resp, response.UserResponse{
-			ID:       user.ID,
-			Name:     user.Name,
-			Email:    user.Email,
-			Phone:    user.Phone,
-			Photo:    user.Photo,
-			RoleName: roleName,
+			ID:            user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			Phone:         user.Phone,
+			Photo:         user.Photo,
+			RoleName:      roleName,
+			DeactivatedAt: user.DeactivatedAt,
 		})
 	}
 
@@ -306,12 +368,13 @@ func (u *userController) GetUserByID(c *fiber.Ctx) error {
 	}
 
 	response := response.UserResponse{
-		ID:       user.ID,
-		Name:     user.Name,
-		Email:    user.Email,
-		Phone:    user.Phone,
-		Photo:    user.Photo,
-		RoleName: roleName,
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Photo:         user.Photo,
+		RoleName:      roleName,
+		DeactivatedAt: user.DeactivatedAt,
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -319,6 +382,85 @@ func (u *userController) GetUserByID(c *fiber.Ctx) error {
 	})
 }
 
+// GetMe implements UserControllerInterface. The caller is the user the
+// gateway put in X-User-ID.
+func (u *userController) GetMe(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, err := u.userUsecase.GetUserByID(ctx, conv.StringToUint(c.Get("X-User-ID")))
+	if err != nil {
+		log.Errorf("[UserController] GetMe - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get profile",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Profile retrieved successfully",
+		"data":    toProfileResponse(*user),
+	})
+}
+
+// UpdateMe implements UserControllerInterface.
+func (u *userController) UpdateMe(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req := request.UpdateProfileRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[UserController] UpdateMe - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[UserController] UpdateMe - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	user, err := u.userUsecase.UpdateProfile(ctx, conv.StringToUint(c.Get("X-User-ID")), req.Name, req.Phone, req.Photo)
+	if err != nil {
+		log.Errorf("[UserController] UpdateMe - 3: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Unknown user",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update profile",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Profile updated successfully",
+		"data":    toProfileResponse(*user),
+	})
+}
+
+func toProfileResponse(user model.User) response.ProfileResponse {
+	roles := []string{}
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	return response.ProfileResponse{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Phone:            user.Phone,
+		Photo:            user.Photo,
+		Roles:            roles,
+		Permissions:      user.PermissionNames(),
+		TwoFactorEnabled: user.TwoFactorEnabled,
+	}
+}
+
 // GetUserByRoleName implements UserControllerInterface.
 func (u *userController) GetUserByRoleName(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -340,12 +482,13 @@ func (u *userController) GetUserByRoleName(c *fiber.Ctx) error {
 		}
 
 		resp = append(resp, response.UserResponse{
-			ID:       user.ID,
-			Name:     user.Name,
-			Email:    user.Email,
-			Phone:    user.Phone,
-			Photo:    user.Photo,
-			RoleName: roleName,
+			ID:            user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			Phone:         user.Phone,
+			Photo:         user.Photo,
+			RoleName:      roleName,
+			DeactivatedAt: user.DeactivatedAt,
 		})
 	}
 
@@ -429,7 +572,7 @@ func (u *userController) UpdateUser(c *fiber.Ctx) error {
 	})
 }
 
-func NewUserController(userUsecase usecase.UserUsecaseInterface) UserControllerInterface {
-	return &userController{userUsecase: userUsecase}
+func NewUserController(userUsecase usecase.UserUsecaseInterface, invitationUsecase usecase.InvitationUsecaseInterface) UserControllerInterface {
+	return &userController{userUsecase: userUsecase, invitationUsecase: invitationUsecase}
 }
 
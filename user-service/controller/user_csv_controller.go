@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-go/shared/audit"
+	"warehouse-go/user-service/controller/request"
+	"warehouse-go/user-service/controller/response"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/middleware"
+	"warehouse-go/user-service/pkg/validator"
+	"warehouse-go/user-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type UserCSVControllerInterface interface {
+	ImportUsers(c *fiber.Ctx) error
+	ExportUsers(c *fiber.Ctx) error
+}
+
+type userCSVController struct {
+	userCSVUsecase usecase.UserCSVUsecaseInterface
+}
+
+// ImportUsers implements UserCSVControllerInterface. Every row is checked
+// first; one bad row and no user is created, the answer lists the errors of
+// every row instead.
+func (u *userCSVController) ImportUsers(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.ImportUsersRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[UserCSVController] ImportUsers - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Errorf("[UserCSVController] ImportUsers - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Failed to get file",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Errorf("[UserCSVController] ImportUsers - 3: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Failed to read file",
+		})
+	}
+	defer file.Close()
+
+	createdBy := conv.StringToUint(c.Get("X-User-ID"))
+	canAssignRoles := middleware.HasPermission(c, model.PermissionRoleManage)
+	report, err := u.userCSVUsecase.ImportUsers(ctx, file, createdBy, req.DryRun, canAssignRoles)
+	if err != nil {
+		log.Errorf("[UserCSVController] ImportUsers - 4: %v", err)
+		if errors.Is(err, usecase.ErrInvalidCSV) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, usecase.ErrRoleAssignmentForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Assigning roles requires the role:manage permission",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to import users",
+		})
+	}
+
+	importResponse := toImportUsersResponse(*report)
+	if len(report.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "Some rows are invalid, no user was imported",
+			"data":    importResponse,
+		})
+	}
+
+	if report.DryRun {
+		audit.Skip(c)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Every row is valid, no user was imported (dry run)",
+			"data":    importResponse,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": fmt.Sprintf("%d users imported. Invitations to set the password have been sent", importResponse.Imported),
+		"data":    importResponse,
+	})
+}
+
+// ExportUsers implements UserCSVControllerInterface. The file can be edited
+// and sent back to ImportUsers.
+func (u *userCSVController) ExportUsers(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.ExportUsersRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[UserCSVController] ExportUsers - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[UserCSVController] ExportUsers - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := u.userCSVUsecase.ExportUsers(ctx, &buf, req.Search, req.Status); err != nil {
+		log.Errorf("[UserCSVController] ExportUsers - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to export users",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("users-%s.csv", time.Now().Format("20060102")))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+func toImportUsersResponse(report usecase.ImportReport) response.ImportUsersResponse {
+	importResponse := response.ImportUsersResponse{
+		DryRun:    report.DryRun,
+		TotalRows: report.TotalRows,
+		Users:     []response.ImportedUserResponse{},
+		Errors:    []response.ImportRowError{},
+	}
+
+	for _, user := range report.Users {
+		importResponse.Users = append(importResponse.Users, response.ImportedUserResponse{
+			Row:          user.Row,
+			UserID:       user.UserID,
+			InvitationID: user.InvitationID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Roles:        user.Roles,
+		})
+		if user.UserID != 0 {
+			importResponse.Imported++
+		}
+	}
+	for _, rowError := range report.Errors {
+		importResponse.Errors = append(importResponse.Errors, response.ImportRowError{
+			Row:    rowError.Row,
+			Email:  rowError.Email,
+			Errors: rowError.Errors,
+		})
+	}
+
+	return importResponse
+}
+
+func NewUserCSVController(userCSVUsecase usecase.UserCSVUsecaseInterface) UserCSVControllerInterface {
+	return &userCSVController{
+		userCSVUsecase: userCSVUsecase,
+	}
+}
@@ -0,0 +1,35 @@
+-- The schema AutoMigrate created before any of the later changes, which
+-- follow in their own migrations. Every statement is conditional, so
+-- databases that already have it adopt this migration unchanged.
+
+CREATE TABLE IF NOT EXISTS "users" (
+	"id" bigserial,
+	"name" text,
+	"email" text,
+	"password" text,
+	"photo" text,
+	"phone" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+
+CREATE TABLE IF NOT EXISTS "roles" (
+	"id" bigserial,
+	"name" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "user_roles" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"role_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_user_roles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+	CONSTRAINT "fk_user_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "two_factors";
+DROP TABLE IF EXISTS "invitations";
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "role_permissions";
+DROP TABLE IF EXISTS "permissions";
+ALTER TABLE "roles" DROP COLUMN IF EXISTS "require_two_factor";
+DROP INDEX IF EXISTS "idx_users_deactivated_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "deactivated_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "two_factor_enabled";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "must_change_password";
@@ -0,0 +1,159 @@
+-- Account security and access control: permissions, login lockout, API
+-- keys, password resets, invitations, two-factor authentication, audit
+-- logs, sessions and single sign-on. AutoMigrate created these on databases
+-- that ran the service in between, so every statement only adds what is
+-- missing.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "must_change_password" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "two_factor_enabled" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deactivated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_users_deactivated_at" ON "users" ("deactivated_at");
+
+ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "require_two_factor" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+	"id" bigserial,
+	"name" text NOT NULL,
+	"description" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_name" ON "permissions" ("name");
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+	"role_id" bigint,
+	"permission_id" bigint,
+	PRIMARY KEY ("role_id", "permission_id"),
+	CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id"),
+	CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"failed_attempts" bigint NOT NULL DEFAULT 0,
+	"last_failed_at" timestamptz,
+	"locked_until" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_attempts_user_id" ON "login_attempts" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"prefix" varchar(16) NOT NULL,
+	"key_hash" char(64) NOT NULL,
+	"scopes" text NOT NULL,
+	"allowed_ips" text,
+	"expires_at" timestamptz,
+	"last_used_at" timestamptz,
+	"revoked_at" timestamptz,
+	"created_by" bigint NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_api_keys_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_created_by" ON "api_keys" ("created_by");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"token_hash" text NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"used_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "invitations" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"token_hash" text NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"sent_count" bigint NOT NULL DEFAULT 1,
+	"accepted_at" timestamptz,
+	"revoked_at" timestamptz,
+	"created_by" bigint,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_invitations_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invitations_token_hash" ON "invitations" ("token_hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invitations_user_id" ON "invitations" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "two_factors" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"secret" text NOT NULL,
+	"confirmed_at" timestamptz,
+	"last_used_step" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_two_factors_user_id" ON "two_factors" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"code_hash" text NOT NULL,
+	"used_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+	"id" bigserial,
+	"service" varchar(50) NOT NULL,
+	"action" varchar(50) NOT NULL,
+	"entity" varchar(50) NOT NULL,
+	"entity_id" varchar(64),
+	"actor_id" bigint NOT NULL,
+	"before" text,
+	"after" text,
+	"changes" text,
+	"request_id" varchar(64),
+	"ip" varchar(64),
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity" ON "audit_logs" ("entity", "entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_service" ON "audit_logs" ("service");
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"user_agent" text,
+	"ip" varchar(64),
+	"last_seen_at" timestamptz,
+	"expires_at" timestamptz,
+	"revoked_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"issuer" varchar(255) NOT NULL,
+	"subject" varchar(255) NOT NULL,
+	"email" text,
+	"last_login_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_issuer_subject" ON "user_identities" ("issuer", "subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
@@ -0,0 +1,4 @@
+DELETE FROM "role_permissions";
+DELETE FROM "user_roles" WHERE "role_id" IN (SELECT "id" FROM "roles" WHERE "name" IN ('Manager', 'Keeper'));
+DELETE FROM "roles" WHERE "name" IN ('Manager', 'Keeper');
+DELETE FROM "permissions";
@@ -0,0 +1,48 @@
+-- The permission catalog and the built-in roles, formerly SeedPermission and
+-- SeedRole. A role that has permissions already keeps them, so edits made
+-- through /roles survive on existing databases. Permissions added to the
+-- catalog later need a migration of their own.
+
+INSERT INTO "permissions" ("name", "description", "created_at", "updated_at") VALUES
+	('user:read', 'List and view users', now(), now()),
+	('user:write', 'Create, update and delete users', now(), now()),
+	('user:unlock', 'Unlock accounts locked after failed logins', now(), now()),
+	('user:reset-2fa', 'Remove the two-factor enrollment of a user', now(), now()),
+	('role:manage', 'Manage roles, their permissions and role assignments', now(), now()),
+	('invitation:manage', 'List, resend and revoke invitations', now(), now()),
+	('api-key:manage', 'Create and revoke API keys', now(), now()),
+	('product:read', 'View products and categories', now(), now()),
+	('product:write', 'Create, update and delete products and categories', now(), now()),
+	('merchant:read', 'View merchants and their products', now(), now()),
+	('merchant:write', 'Create, update and delete merchants and their products', now(), now()),
+	('merchant:all', 'Reach every merchant instead of only the assigned ones, create merchants and assign keepers', now(), now()),
+	('warehouse:read', 'View warehouses and their stock', now(), now()),
+	('warehouse:write', 'Create, update and delete warehouses and their stock', now(), now()),
+	('warehouse:all', 'Reach every warehouse instead of only the assigned ones, create warehouses and assign staff', now(), now()),
+	('transaction:read', 'View transactions', now(), now()),
+	('transaction:create', 'Create transactions', now(), now()),
+	('dashboard:all', 'View revenue and live events of every merchant', now(), now()),
+	('dashboard:merchant', 'View revenue and live events of the merchant the user keeps', now(), now()),
+	('audit:read', 'Search the audit trail of changes made in every service', now(), now())
+ON CONFLICT ("name") DO UPDATE SET "description" = EXCLUDED."description", "updated_at" = now();
+
+INSERT INTO "roles" ("name", "created_at", "updated_at")
+SELECT "name", now(), now() FROM (VALUES ('Manager'), ('Keeper')) AS builtin ("name")
+WHERE NOT EXISTS (SELECT 1 FROM "roles" WHERE "roles"."name" = builtin."name");
+
+-- Managers get every permission, keepers what they could do before
+-- permissions existed, without the Manager-only parts.
+INSERT INTO "role_permissions" ("role_id", "permission_id")
+SELECT "roles"."id", "permissions"."id"
+FROM "roles"
+JOIN "permissions" ON "roles"."name" = 'Manager' OR "permissions"."name" IN (
+	'user:read', 'user:write',
+	'product:read', 'product:write',
+	'merchant:read', 'merchant:write',
+	'warehouse:read', 'warehouse:write',
+	'transaction:read', 'transaction:create',
+	'dashboard:merchant'
+)
+WHERE "roles"."name" IN ('Manager', 'Keeper')
+	AND NOT EXISTS (SELECT 1 FROM "role_permissions" WHERE "role_permissions"."role_id" = "roles"."id")
+ON CONFLICT DO NOTHING;
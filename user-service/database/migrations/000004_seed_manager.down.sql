@@ -0,0 +1,4 @@
+-- Fails while other rows, like API keys the manager created, still refer to
+-- the account.
+DELETE FROM "user_roles" WHERE "user_id" IN (SELECT "id" FROM "users" WHERE "email" = 'manager@mail.com');
+DELETE FROM "users" WHERE "email" = 'manager@mail.com';
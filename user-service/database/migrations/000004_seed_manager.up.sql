@@ -0,0 +1,15 @@
+-- The first account, formerly SeedManager: manager@mail.com with the
+-- password manager123. Change it after the first login.
+
+INSERT INTO "users" ("name", "email", "password", "created_at", "updated_at")
+SELECT 'manager', 'manager@mail.com', '$2a$14$7m5hAuyvny9m8EuSZEjx6eoYJGKL.0TeFQ6r.L7r5uYxD3jSfI.X.', now(), now()
+WHERE NOT EXISTS (SELECT 1 FROM "users" WHERE "email" = 'manager@mail.com');
+
+INSERT INTO "user_roles" ("user_id", "role_id", "created_at", "updated_at")
+SELECT "users"."id", "roles"."id", now(), now()
+FROM "users", "roles"
+WHERE "users"."email" = 'manager@mail.com' AND "roles"."name" = 'Manager'
+	AND NOT EXISTS (
+		SELECT 1 FROM "user_roles"
+		WHERE "user_roles"."user_id" = "users"."id" AND "user_roles"."role_id" = "roles"."id"
+	);
@@ -3,7 +3,6 @@ package database
 import (
 	"fmt"
 	"warehouse-go/user-service/configs"
-	"warehouse-go/user-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/driver/postgres"
@@ -29,16 +28,12 @@ func ConnectPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.User{}, &model.Role{}, &model.UserRole{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] Connection Postgres - 2: %v", err)
 		return nil, err
 	}
 
-	SeedRole(db)
-	SeedManager(db)
-	
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// APIKey is a credential for machine clients. Only the SHA-256 hash of the
+// key is stored, the key itself is shown once when it is created. Requests
+// made with the key act as the user who created it, limited to its scopes.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash    string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Scopes     string     `json:"scopes" gorm:"type:text;not null"`
+	AllowedIPs string     `json:"allowed_ips" gorm:"type:text"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  uint       `json:"created_by" gorm:"not null;index"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Creator User `json:"-" gorm:"foreignKey:CreatedBy"`
+}
+
+func (k APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || k.ExpiresAt.After(now)
+}
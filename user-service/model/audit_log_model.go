@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// AuditLog is one change made through a mutating route of any service. The
+// states are JSON with secrets removed; Changes holds the fields that differ
+// between Before and After.
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Service   string    `json:"service" gorm:"type:varchar(50);not null;index"`
+	Action    string    `json:"action" gorm:"type:varchar(50);not null"`
+	Entity    string    `json:"entity" gorm:"type:varchar(50);not null;index:idx_audit_logs_entity"`
+	EntityID  string    `json:"entity_id" gorm:"type:varchar(64);index:idx_audit_logs_entity"`
+	ActorID   uint      `json:"actor_id" gorm:"not null;index"`
+	Before    string    `json:"before" gorm:"type:text"`
+	After     string    `json:"after" gorm:"type:text"`
+	Changes   string    `json:"changes" gorm:"type:text"`
+	RequestID string    `json:"request_id" gorm:"type:varchar(64);index"`
+	IP        string    `json:"ip" gorm:"type:varchar(64)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
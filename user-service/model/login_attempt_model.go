@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// LoginAttempt tracks failed logins of one user for brute-force protection.
+type LoginAttempt struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	FailedAttempts int        `json:"failed_attempts" gorm:"not null;default:0"`
+	LastFailedAt   *time.Time `json:"last_failed_at"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (l LoginAttempt) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
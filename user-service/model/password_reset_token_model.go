@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// PasswordResetToken is a single-use token mailed to a user who forgot the
+// password. Only the sha256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (p PasswordResetToken) IsUsable(now time.Time) bool {
+	return p.UsedAt == nil && p.ExpiresAt.After(now)
+}
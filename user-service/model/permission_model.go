@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Permissions are named <resource>:<action>. Roles are sets of them and the
+// gateway puts the caller's permissions into the JWT, so services check a
+// permission instead of a role name.
+const (
+	PermissionUserRead          = "user:read"
+	PermissionUserWrite         = "user:write"
+	PermissionUserUnlock        = "user:unlock"
+	PermissionUserReset2FA      = "user:reset-2fa"
+	PermissionRoleManage        = "role:manage"
+	PermissionInvitationManage  = "invitation:manage"
+	PermissionAPIKeyManage      = "api-key:manage"
+	PermissionProductRead       = "product:read"
+	PermissionProductWrite      = "product:write"
+	PermissionMerchantRead      = "merchant:read"
+	PermissionMerchantWrite     = "merchant:write"
+	PermissionMerchantAll       = "merchant:all"
+	PermissionWarehouseRead     = "warehouse:read"
+	PermissionWarehouseWrite    = "warehouse:write"
+	PermissionWarehouseAll      = "warehouse:all"
+	PermissionTransactionRead   = "transaction:read"
+	PermissionTransactionCreate = "transaction:create"
+	PermissionDashboardAll      = "dashboard:all"
+	PermissionDashboardMerchant = "dashboard:merchant"
+	PermissionAuditRead         = "audit:read"
+)
+
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PermissionCatalog is every permission the services check. The migrations
+// seed it, so a permission added here needs a migration as well; roles can
+// only be given permissions from this list.
+var PermissionCatalog = []Permission{
+	{Name: PermissionUserRead, Description: "List and view users"},
+	{Name: PermissionUserWrite, Description: "Create, update and delete users"},
+	{Name: PermissionUserUnlock, Description: "Unlock accounts locked after failed logins"},
+	{Name: PermissionUserReset2FA, Description: "Remove the two-factor enrollment of a user"},
+	{Name: PermissionRoleManage, Description: "Manage roles, their permissions and role assignments"},
+	{Name: PermissionInvitationManage, Description: "List, resend and revoke invitations"},
+	{Name: PermissionAPIKeyManage, Description: "Create and revoke API keys"},
+	{Name: PermissionProductRead, Description: "View products and categories"},
+	{Name: PermissionProductWrite, Description: "Create, update and delete products and categories"},
+	{Name: PermissionMerchantRead, Description: "View merchants and their products"},
+	{Name: PermissionMerchantWrite, Description: "Create, update and delete merchants and their products"},
+	{Name: PermissionMerchantAll, Description: "Reach every merchant instead of only the assigned ones, create merchants and assign keepers"},
+	{Name: PermissionWarehouseRead, Description: "View warehouses and their stock"},
+	{Name: PermissionWarehouseWrite, Description: "Create, update and delete warehouses and their stock"},
+	{Name: PermissionWarehouseAll, Description: "Reach every warehouse instead of only the assigned ones, create warehouses and assign staff"},
+	{Name: PermissionTransactionRead, Description: "View transactions"},
+	{Name: PermissionTransactionCreate, Description: "Create transactions"},
+	{Name: PermissionDashboardAll, Description: "View revenue and live events of every merchant"},
+	{Name: PermissionDashboardMerchant, Description: "View revenue and live events of the merchant the user keeps"},
+	{Name: PermissionAuditRead, Description: "Search the audit trail of changes made in every service"},
+}
@@ -5,7 +5,18 @@ import "time"
 type Role struct {
 	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name      string    `json:"name"`
+	// RequireTwoFactor makes TOTP mandatory for every user with the role.
+	RequireTwoFactor bool `json:"require_two_factor" gorm:"not null;default:false"`
 	Users     []User    `json:"users" gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:RoleID;References:ID;joinReferences:UserID"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+func (r Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
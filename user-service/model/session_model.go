@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Session is one login, i.e. one device. The gateway puts its id into the
+// JWT and refuses the token once the session is revoked, so signing out a
+// session ends it before the token expires.
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent" gorm:"type:text"`
+	IP         string     `json:"ip" gorm:"type:varchar(64)"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to an account at an OpenID Connect provider.
+// The subject is the provider's stable id of the account; the email is only
+// what it was at the last login.
+type UserIdentity struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Issuer      string    `json:"issuer" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject     string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
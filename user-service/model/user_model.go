@@ -1,6 +1,16 @@
 package model
 
-import "time"
+import (
+	"sort"
+	"time"
+)
+
+// User statuses accepted by the user list filter.
+const (
+	UserStatusActive   = "active"
+	UserStatusInactive = "inactive"
+	UserStatusAll      = "all"
+)
 
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -9,7 +19,48 @@ type User struct {
 	Password  string    `json:"password"`
 	Photo     string    `json:"photo"`
 	Phone     string    `json:"phone"`
-	Roles     []Role    `json:"roles" gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:UserID;References:ID;joinReferences:RoleID"`
+	// MustChangePassword is set for accounts created with an initial password.
+	// The gateway only lets such users change their password until they do.
+	MustChangePassword bool `json:"must_change_password" gorm:"not null;default:false"`
+	// TwoFactorEnabled is set once the user confirmed a TOTP enrollment.
+	TwoFactorEnabled bool `json:"two_factor_enabled" gorm:"not null;default:false"`
+	// DeactivatedAt is set instead of deleting the row, merchants,
+	// transactions and audit logs keep pointing at the user.
+	DeactivatedAt *time.Time `json:"deactivated_at" gorm:"index"`
+	Roles    []Role    `json:"roles" gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:UserID;References:ID;joinReferences:RoleID"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// RequiresTwoFactor reports whether one of the user's roles makes TOTP
+// mandatory. Roles must be loaded.
+func (u User) RequiresTwoFactor() bool {
+	for _, role := range u.Roles {
+		if role.RequireTwoFactor {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionNames is the union of the permissions of the user's roles,
+// sorted. Roles and their permissions must be loaded.
+func (u User) PermissionNames() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsActive reports whether the user has not been deactivated.
+func (u User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
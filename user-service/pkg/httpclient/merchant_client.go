@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"warehouse-go/user-service/configs"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// internalRequestHeader tells merchant-service the call comes from this service
+// rather than from a user, so it is not refused for lacking one.
+const internalRequestHeader = "X-Internal-Request"
+
+type MerchantClientInterface interface {
+	GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error)
+}
+
+type MerchantClient struct {
+	urlMerchantService string
+	httpClient         *http.Client
+}
+
+// GetMerchantIDsByKeeperID implements MerchantClientInterface.
+func (m *MerchantClient) GetMerchantIDsByKeeperID(ctx context.Context, keeperID uint) ([]uint, error) {
+	url := fmt.Sprintf("%s/api/v1/merchants/keeper/%d", m.urlMerchantService, keeperID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 1: %v", err)
+		return nil, err
+	}
+	req.Header.Set(internalRequestHeader, "true")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 2: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 3: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 4: %s", string(body))
+		return nil, errors.New("failed to get merchants by keeper id")
+	}
+
+	var response struct {
+		Data struct {
+			MerchantIDs []uint `json:"merchant_ids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		log.Errorf("[MerchantClient] GetMerchantIDsByKeeperID - 5: %v", err)
+		return nil, err
+	}
+
+	return response.Data.MerchantIDs, nil
+}
+
+func NewMerchantClient(cfg configs.Config) MerchantClientInterface {
+	return &MerchantClient{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		urlMerchantService: cfg.App.UrlMerchantService,
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionsHeader carries the caller's permissions, comma separated. The
+// api gateway sets it from the JWT and strips any value sent by clients, so
+// it can be trusted here.
+const PermissionsHeader = "X-User-Permissions"
+
+// RequirePermission only lets a request through when the caller holds one
+// of the given permissions.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, permission := range permissions {
+			if HasPermission(c, permission) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Insufficient permissions",
+		})
+	}
+}
+
+// HasPermission reports whether the caller holds permission, for handlers
+// whose answer depends on it.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	for _, have := range strings.Split(c.Get(PermissionsHeader), ",") {
+		if strings.TrimSpace(have) == permission {
+			return true
+		}
+	}
+	return false
+}
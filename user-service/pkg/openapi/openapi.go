@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Operation adds request and response types to one registered route. Routes
+// without an Operation are still listed in the document, just without a
+// schema.
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	Tags    []string
+	// Query is a struct whose `query` tagged fields become query parameters.
+	Query interface{}
+	// Request is the JSON request body.
+	Request interface{}
+	// Response is the value returned in the "data" field of the response.
+	Response interface{}
+	// Upload marks a multipart/form-data endpoint taking one file, in the
+	// field named UploadField or "image" when that is empty.
+	Upload      bool
+	UploadField string
+}
+
+// Document is an OpenAPI 3 document.
+type Document map[string]interface{}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Generate builds an OpenAPI document from the routes registered on app.
+func Generate(app *fiber.App, info Info, operations []Operation) Document {
+	registry := newSchemaRegistry()
+
+	byKey := make(map[string]Operation, len(operations))
+	for _, op := range operations {
+		byKey[strings.ToUpper(op.Method)+" "+normalizePath(op.Path)] = op
+	}
+
+	paths := make(map[string]interface{})
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == http.MethodHead || route.Method == fiber.MethodConnect || route.Method == fiber.MethodTrace {
+			continue
+		}
+		path := normalizePath(route.Path)
+		if path == "/openapi.json" || strings.Contains(path, "*") {
+			continue
+		}
+
+		op, ok := byKey[route.Method+" "+path]
+		if !ok {
+			op = Operation{Method: route.Method, Path: path}
+		}
+
+		openAPIPath := pathParam.ReplaceAllString(path, "{$1}")
+		item, _ := paths[openAPIPath].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[openAPIPath] = item
+		}
+		item[strings.ToLower(route.Method)] = buildOperation(registry, path, op)
+	}
+
+	return Document{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": registry.schemas,
+		},
+	}
+}
+
+// Handler serves the generated document. It is generated on the first
+// request, once all routes are registered.
+func Handler(app *fiber.App, info Info, operations []Operation) fiber.Handler {
+	var (
+		once     sync.Once
+		document Document
+	)
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			document = Generate(app, info, operations)
+		})
+		return c.JSON(document)
+	}
+}
+
+func buildOperation(registry *schemaRegistry, path string, op Operation) map[string]interface{} {
+	operation := map[string]interface{}{
+		"operationId": operationID(op.Method, path),
+		"tags":        op.Tags,
+	}
+	if op.Summary != "" {
+		operation["summary"] = op.Summary
+	}
+	if len(op.Tags) == 0 {
+		operation["tags"] = []string{defaultTag(path)}
+	}
+
+	var parameters []interface{}
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if op.Query != nil {
+		parameters = append(parameters, registry.queryParameters(op.Query)...)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	switch {
+	case op.Upload:
+		field := op.UploadField
+		if field == "" {
+			field = "image"
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"multipart/form-data": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							field: map[string]interface{}{"type": "string", "format": "binary"},
+						},
+					},
+				},
+			},
+		}
+	case op.Request != nil:
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": registry.schemaFor(op.Request),
+				},
+			},
+		}
+	}
+
+	envelope := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"message": map[string]interface{}{"type": "string"},
+		},
+	}
+	if op.Response != nil {
+		envelope["properties"].(map[string]interface{})["data"] = registry.schemaFor(op.Response)
+	}
+
+	operation["responses"] = map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "Successful response",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": envelope},
+			},
+		},
+		"default": map[string]interface{}{
+			"description": "Error response",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"message": map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return operation
+}
+
+func normalizePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		if segment == "" || segment == "api" || segment == "v1" {
+			continue
+		}
+		parts = append(parts, segment)
+	}
+	return strings.Join(parts, "_")
+}
+
+func defaultTag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	return segments[0]
+}
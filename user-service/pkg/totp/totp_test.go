@@ -0,0 +1,149 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of RFC 6238 appendix B, "12345678901234567890",
+// in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The SHA1 test vectors of RFC 6238 appendix B. The RFC lists 8 digit codes,
+// 6 digit codes are their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	for _, vector := range rfcVectors {
+		code, err := Code(rfcSecret, Step(time.Unix(vector.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("Code at %d = %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestValidateAcceptsRFC6238Codes(t *testing.T) {
+	for _, vector := range rfcVectors {
+		at := time.Unix(vector.unix, 0)
+		step, ok := Validate(rfcSecret, vector.code, at)
+		if !ok {
+			t.Errorf("Validate(%s) at %d refused", vector.code, vector.unix)
+			continue
+		}
+		if step != Step(at) {
+			t.Errorf("Validate(%s) at %d = step %d, want %d", vector.code, vector.unix, step, Step(at))
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	for _, tc := range []struct {
+		offset int64
+		ok     bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	} {
+		code, err := Code(rfcSecret, current+tc.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := Validate(rfcSecret, code, now)
+		if ok != tc.ok {
+			t.Errorf("code of step %+d: ok = %v, want %v", tc.offset, ok, tc.ok)
+			continue
+		}
+		if ok && step != current+tc.offset {
+			t.Errorf("code of step %+d: step = %d, want %d", tc.offset, step, current+tc.offset)
+		}
+	}
+}
+
+// A code stays valid for the periods around its own, so replays are refused
+// by the caller storing the returned step. That only works if every later
+// use of the code reports the same step.
+func TestValidateReplayReportsSameStep(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, Step(issued))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used, ok := Validate(rfcSecret, code, issued)
+	if !ok {
+		t.Fatal("code refused in its own period")
+	}
+
+	for _, later := range []time.Duration{time.Second, 15 * time.Second, Period} {
+		step, ok := Validate(rfcSecret, code, issued.Add(later))
+		if !ok {
+			t.Errorf("code refused %v later", later)
+			continue
+		}
+		if step != used {
+			t.Errorf("code %v later = step %d, want %d", later, step, used)
+		}
+	}
+
+	if _, ok := Validate(rfcSecret, code, issued.Add(3*Period)); ok {
+		t.Error("code accepted three periods later")
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	if _, ok := Validate(rfcSecret, " 287 082 ", now); !ok {
+		t.Error("code with spaces refused")
+	}
+	if _, ok := Validate(rfcSecret, "28708", now); ok {
+		t.Error("short code accepted")
+	}
+	if _, ok := Validate(rfcSecret, "94287082", now); ok {
+		t.Error("8 digit code accepted")
+	}
+	if _, ok := Validate(rfcSecret, "287083", now); ok {
+		t.Error("wrong code accepted")
+	}
+	if _, ok := Validate("not base32!", "287082", now); ok {
+		t.Error("code accepted for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("generated secret %q does not decode: %v", secret, err)
+	}
+
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret == other {
+		t.Error("two generated secrets are equal")
+	}
+}
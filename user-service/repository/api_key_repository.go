@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error
+	GetAllAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, at time.Time) error
+	RevokeUserAPIKeys(ctx context.Context, userID uint, at time.Time) error
+	TouchAPIKey(ctx context.Context, id uint, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateAPIKey implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] CreateAPIKey - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := a.db.WithContext(ctx).Create(apiKey).Error; err != nil {
+		log.Errorf("[APIKeyRepository] CreateAPIKey - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAllAPIKeys implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) GetAllAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] GetAllAPIKeys - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	var apiKeys []model.APIKey
+	if err := a.db.WithContext(ctx).Order("created_at desc").Find(&apiKeys).Error; err != nil {
+		log.Errorf("[APIKeyRepository] GetAllAPIKeys - 2: %v", err)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAPIKeyByHash implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] GetAPIKeyByHash - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	apiKey := model.APIKey{}
+	err := a.db.WithContext(ctx).
+		Preload("Creator").
+		Preload("Creator.Roles.Permissions").
+		Where("key_hash = ?", keyHash).
+		First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeAPIKey implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) RevokeAPIKey(ctx context.Context, id uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] RevokeAPIKey - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := a.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		log.Errorf("[APIKeyRepository] RevokeAPIKey - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeUserAPIKeys implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) RevokeUserAPIKeys(ctx context.Context, userID uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] RevokeUserAPIKeys - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := a.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("created_by = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error; err != nil {
+		log.Errorf("[APIKeyRepository] RevokeUserAPIKeys - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// TouchAPIKey implements APIKeyRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyRepository) TouchAPIKey(ctx context.Context, id uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[APIKeyRepository] TouchAPIKey - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// UpdateColumn leaves updated_at alone, last use is not a change of the key.
+	return a.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepositoryInterface {
+	return &apiKeyRepository{db: db}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows GetAuditLogs. Empty fields do not filter; Search
+// matches the entity id, the request id and the recorded states.
+type AuditLogFilter struct {
+	Service   string
+	Entity    string
+	EntityID  string
+	Action    string
+	ActorID   uint
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	Search    string
+}
+
+type AuditRepositoryInterface interface {
+	CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, page, limit int, filter AuditLogFilter) ([]model.AuditLog, int64, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateAuditLog implements AuditRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *auditRepository) CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AuditRepository] CreateAuditLog - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := a.db.WithContext(ctx).Create(auditLog).Error; err != nil {
+		log.Errorf("[AuditRepository] CreateAuditLog - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAuditLogs implements AuditRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (a *auditRepository) GetAuditLogs(ctx context.Context, page, limit int, filter AuditLogFilter) ([]model.AuditLog, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AuditRepository] GetAuditLogs - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+	}
+
+	query := a.db.WithContext(ctx).Model(&model.AuditLog{})
+	if filter.Service != "" {
+		query = query.Where("service = ?", filter.Service)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Where("entity_id ILIKE ? OR request_id ILIKE ? OR before ILIKE ? OR after ILIKE ?", search, search, search, search)
+	}
+
+	var totalRecords int64
+	if err := query.Count(&totalRecords).Error; err != nil {
+		log.Errorf("[AuditRepository] GetAuditLogs - 2: %v", err)
+		return nil, 0, err
+	}
+
+	var auditLogs []model.AuditLog
+	if err := query.Order("created_at desc, id desc").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&auditLogs).Error; err != nil {
+		log.Errorf("[AuditRepository] GetAuditLogs - 3: %v", err)
+		return nil, 0, err
+	}
+
+	return auditLogs, totalRecords, nil
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepositoryInterface {
+	return &auditRepository{db: db}
+}
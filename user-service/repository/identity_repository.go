@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type IdentityRepositoryInterface interface {
+	GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+	TouchIdentity(ctx context.Context, id uint, email string, at time.Time) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetIdentity implements IdentityRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *identityRepository) GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[IdentityRepository] GetIdentity - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	identity := model.UserIdentity{}
+	if err := i.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateIdentity implements IdentityRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *identityRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[IdentityRepository] CreateIdentity - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := i.db.WithContext(ctx).Create(identity).Error; err != nil {
+		log.Errorf("[IdentityRepository] CreateIdentity - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateUserWithIdentity implements IdentityRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *identityRepository) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[IdentityRepository] CreateUserWithIdentity - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// A user without identity could never sign in, it has no password.
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles.*").Create(user).Error; err != nil {
+			log.Errorf("[IdentityRepository] CreateUserWithIdentity - 2: %v", err)
+			return err
+		}
+
+		identity.UserID = user.ID
+		if err := tx.Create(identity).Error; err != nil {
+			log.Errorf("[IdentityRepository] CreateUserWithIdentity - 3: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ────────────────────────────────────────────────────────────────
+// TouchIdentity implements IdentityRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *identityRepository) TouchIdentity(ctx context.Context, id uint, email string, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[IdentityRepository] TouchIdentity - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := i.db.WithContext(ctx).Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"email":         email,
+			"last_login_at": at,
+		}).Error; err != nil {
+		log.Errorf("[IdentityRepository] TouchIdentity - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepositoryInterface {
+	return &identityRepository{db: db}
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type InvitationRepositoryInterface interface {
+	CreateInvitedUser(ctx context.Context, user *model.User, invitation *model.Invitation) error
+	CreateInvitedUsers(ctx context.Context, users []*model.User, invitations []*model.Invitation) error
+	GetAllInvitations(ctx context.Context) ([]model.Invitation, error)
+	GetInvitationByID(ctx context.Context, id uint) (*model.Invitation, error)
+	GetInvitationByHash(ctx context.Context, tokenHash string) (*model.Invitation, error)
+	RotateInvitationToken(ctx context.Context, id uint, tokenHash string, expiresAt time.Time) error
+	RevokeInvitation(ctx context.Context, id uint, at time.Time) error
+	AcceptInvitation(ctx context.Context, id uint, at time.Time) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// openInvitation matches invitations that were neither accepted nor revoked.
+const openInvitation = "accepted_at IS NULL AND revoked_at IS NULL"
+
+// ────────────────────────────────────────────────────────────────
+// CreateInvitedUser implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) CreateInvitedUser(ctx context.Context, user *model.User, invitation *model.Invitation) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] CreateInvitedUser - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// A user without an invitation could never log in, so both rows are
+	// written or neither.
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createInvitedUser(tx, user, invitation)
+	})
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateInvitedUsers implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) CreateInvitedUsers(ctx context.Context, users []*model.User, invitations []*model.Invitation) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] CreateInvitedUsers - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// An import is all or nothing, so a file can be fixed and sent again.
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for idx, user := range users {
+			if err := createInvitedUser(tx, user, invitations[idx]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createInvitedUser writes the user, the links to its roles and the
+// invitation. Roles must exist already, they are only referenced.
+func createInvitedUser(tx *gorm.DB, user *model.User, invitation *model.Invitation) error {
+	if err := tx.Omit("Roles.*").Create(user).Error; err != nil {
+		log.Errorf("[InvitationRepository] createInvitedUser - 1: %v", err)
+		return err
+	}
+
+	invitation.UserID = user.ID
+	if err := tx.Omit("User").Create(invitation).Error; err != nil {
+		log.Errorf("[InvitationRepository] createInvitedUser - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAllInvitations implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) GetAllInvitations(ctx context.Context) ([]model.Invitation, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] GetAllInvitations - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	var invitations []model.Invitation
+	if err := i.db.WithContext(ctx).Preload("User").Order("created_at desc").Find(&invitations).Error; err != nil {
+		log.Errorf("[InvitationRepository] GetAllInvitations - 2: %v", err)
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetInvitationByID implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) GetInvitationByID(ctx context.Context, id uint) (*model.Invitation, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] GetInvitationByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	invitation := model.Invitation{}
+	if err := i.db.WithContext(ctx).Preload("User").Where("id = ?", id).First(&invitation).Error; err != nil {
+		log.Errorf("[InvitationRepository] GetInvitationByID - 2: %v", err)
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetInvitationByHash implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) GetInvitationByHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] GetInvitationByHash - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	invitation := model.Invitation{}
+	if err := i.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RotateInvitationToken implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) RotateInvitationToken(ctx context.Context, id uint, tokenHash string, expiresAt time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] RotateInvitationToken - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := i.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND "+openInvitation, id).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"expires_at": expiresAt,
+			"sent_count": gorm.Expr("sent_count + 1"),
+		})
+	if result.Error != nil {
+		log.Errorf("[InvitationRepository] RotateInvitationToken - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeInvitation implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) RevokeInvitation(ctx context.Context, id uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] RevokeInvitation - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := i.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND "+openInvitation, id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		log.Errorf("[InvitationRepository] RevokeInvitation - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// AcceptInvitation implements InvitationRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationRepository) AcceptInvitation(ctx context.Context, id uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[InvitationRepository] AcceptInvitation - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// Conditional on the invitation still being open and unexpired, so a
+	// token can be used once even by concurrent requests.
+	result := i.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND expires_at > ? AND "+openInvitation, id, at).
+		Update("accepted_at", at)
+	if result.Error != nil {
+		log.Errorf("[InvitationRepository] AcceptInvitation - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepositoryInterface {
+	return &invitationRepository{db: db}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepositoryInterface interface {
+	GetByUserID(ctx context.Context, userID uint) (*model.LoginAttempt, error)
+	RecordFailure(ctx context.Context, userID uint) (*model.LoginAttempt, error)
+	Lock(ctx context.Context, userID uint, until time.Time) error
+	Reset(ctx context.Context, userID uint) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetByUserID implements LoginAttemptRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (l *loginAttemptRepository) GetByUserID(ctx context.Context, userID uint) (*model.LoginAttempt, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[LoginAttemptRepository] GetByUserID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	attempt := model.LoginAttempt{}
+	err := l.db.WithContext(ctx).Where("user_id = ?", userID).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.LoginAttempt{UserID: userID}, nil
+	}
+	if err != nil {
+		log.Errorf("[LoginAttemptRepository] GetByUserID - 2: %v", err)
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RecordFailure implements LoginAttemptRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (l *loginAttemptRepository) RecordFailure(ctx context.Context, userID uint) (*model.LoginAttempt, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[LoginAttemptRepository] RecordFailure - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	now := time.Now()
+	attempt := model.LoginAttempt{
+		UserID:         userID,
+		FailedAttempts: 1,
+		LastFailedAt:   &now,
+	}
+
+	// Upsert with an in-database increment so concurrent failures are all counted.
+	err := l.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failed_attempts": gorm.Expr("login_attempts.failed_attempts + 1"),
+			"last_failed_at":  now,
+			"updated_at":      now,
+		}),
+	}, clause.Returning{}).Create(&attempt).Error
+	if err != nil {
+		log.Errorf("[LoginAttemptRepository] RecordFailure - 2: %v", err)
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Lock implements LoginAttemptRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (l *loginAttemptRepository) Lock(ctx context.Context, userID uint, until time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[LoginAttemptRepository] Lock - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return l.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    until,
+		}).Error
+}
+
+// ────────────────────────────────────────────────────────────────
+// Reset implements LoginAttemptRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (l *loginAttemptRepository) Reset(ctx context.Context, userID uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[LoginAttemptRepository] Reset - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return l.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepositoryInterface {
+	return &loginAttemptRepository{db: db}
+}
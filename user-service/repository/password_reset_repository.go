@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	UsePasswordResetToken(ctx context.Context, id uint, at time.Time) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreatePasswordResetToken implements PasswordResetRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (p *passwordResetRepository) CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[PasswordResetRepository] CreatePasswordResetToken - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// Only the newest link works, older unused ones are burnt with it.
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", token.CreatedAt).Error; err != nil {
+			log.Errorf("[PasswordResetRepository] CreatePasswordResetToken - 2: %v", err)
+			return err
+		}
+
+		if err := tx.Create(token).Error; err != nil {
+			log.Errorf("[PasswordResetRepository] CreatePasswordResetToken - 3: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetPasswordResetTokenByHash implements PasswordResetRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (p *passwordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[PasswordResetRepository] GetPasswordResetTokenByHash - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	token := model.PasswordResetToken{}
+	if err := p.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// UsePasswordResetToken implements PasswordResetRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (p *passwordResetRepository) UsePasswordResetToken(ctx context.Context, id uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[PasswordResetRepository] UsePasswordResetToken - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// The used_at condition makes two concurrent resets with the same token
+	// race for one row; only one of them wins.
+	result := p.db.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		log.Errorf("[PasswordResetRepository] UsePasswordResetToken - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepositoryInterface {
+	return &passwordResetRepository{db: db}
+}
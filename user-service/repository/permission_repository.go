@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type PermissionRepositoryInterface interface {
+	GetAllPermissions(ctx context.Context) ([]model.Permission, error)
+	GetPermissionsByNames(ctx context.Context, names []string) ([]model.Permission, error)
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAllPermissions implements PermissionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (p *permissionRepository) GetAllPermissions(ctx context.Context) ([]model.Permission, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[PermissionRepository] GetAllPermissions - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	permissions := []model.Permission{}
+	if err := p.db.WithContext(ctx).Order("name").Find(&permissions).Error; err != nil {
+		log.Errorf("[PermissionRepository] GetAllPermissions - 2: %v", err)
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetPermissionsByNames implements PermissionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (p *permissionRepository) GetPermissionsByNames(ctx context.Context, names []string) ([]model.Permission, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[PermissionRepository] GetPermissionsByNames - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	permissions := []model.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+
+	if err := p.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		log.Errorf("[PermissionRepository] GetPermissionsByNames - 2: %v", err)
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepositoryInterface {
+	return &permissionRepository{db: db}
+}
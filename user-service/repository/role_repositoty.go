@@ -69,7 +69,7 @@ func (r *roleRepository) GetAllRoles(ctx context.Context) ([]model.Role, error)
 		return nil, ctx.Err()
 	default:
 		modelRoles := []model.Role{}
-		err := r.db.WithContext(ctx).Preload("Users").Find(&modelRoles).Error 
+		err := r.db.WithContext(ctx).Preload("Users").Preload("Permissions").Find(&modelRoles).Error 
 		if err != nil {
 			log.Errorf("[RoleRepository] GetAllRoles - 2: %v", err)
 			return nil, err
@@ -89,7 +89,7 @@ func (r *roleRepository) GetAllRoleByID(ctx context.Context, id uint) (*model.Ro
 		return nil, ctx.Err()
 	default:
 		modelRole := model.Role{}
-		if err := r.db.WithContext(ctx).Preload("Users").Where("id = ?", id).First(&modelRole).Error; err != nil {
+		if err := r.db.WithContext(ctx).Preload("Users").Preload("Permissions").Where("id = ?", id).First(&modelRole).Error; err != nil {
 			log.Errorf("[RoleRepository] GetAllRoleByID - 2: %v", err)
 			return nil, err
 		}
@@ -115,7 +115,26 @@ func (r *roleRepository) UpdateRole(ctx context.Context, role model.Role) error
 		}
 
 		modelRole.Name = role.Name
-		return r.db.WithContext(ctx).Save(&modelRole).Error
+		modelRole.RequireTwoFactor = role.RequireTwoFactor
+
+		// The permissions are replaced as a whole, a role is exactly the set
+		// it was last given.
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&modelRole).Error; err != nil {
+				log.Errorf("[RoleRepository] UpdateRole - 3: %v", err)
+				return err
+			}
+
+			if role.Permissions == nil {
+				return nil
+			}
+			if err := tx.Model(&modelRole).Association("Permissions").Replace(role.Permissions); err != nil {
+				log.Errorf("[RoleRepository] UpdateRole - 4: %v", err)
+				return err
+			}
+
+			return nil
+		})
 	}
 }
 
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSessionByID(ctx context.Context, id uint) (*model.Session, error)
+	GetActiveSessionsByUserID(ctx context.Context, userID uint, now time.Time) ([]model.Session, error)
+	TouchSession(ctx context.Context, id uint, ip string, at time.Time) error
+	RevokeSession(ctx context.Context, id, userID uint, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error
+	RevokeOtherSessions(ctx context.Context, userID, keepID uint, at time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateSession implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] CreateSession - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		log.Errorf("[SessionRepository] CreateSession - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetSessionByID implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) GetSessionByID(ctx context.Context, id uint) (*model.Session, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] GetSessionByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	session := model.Session{}
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		log.Errorf("[SessionRepository] GetSessionByID - 2: %v", err)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetActiveSessionsByUserID implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) GetActiveSessionsByUserID(ctx context.Context, userID uint, now time.Time) ([]model.Session, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] GetActiveSessionsByUserID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	var sessions []model.Session
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").
+		Find(&sessions).Error; err != nil {
+		log.Errorf("[SessionRepository] GetActiveSessionsByUserID - 2: %v", err)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// TouchSession implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) TouchSession(ctx context.Context, id uint, ip string, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] TouchSession - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_seen_at": at, "ip": ip}).Error
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeSession implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) RevokeSession(ctx context.Context, id, userID uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] RevokeSession - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// Scoped to the user, so nobody can sign out someone else's session.
+	result := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		log.Errorf("[SessionRepository] RevokeSession - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeUserSessions implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] RevokeUserSessions - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error; err != nil {
+		log.Errorf("[SessionRepository] RevokeUserSessions - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeOtherSessions implements SessionRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionRepository) RevokeOtherSessions(ctx context.Context, userID, keepID uint, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[SessionRepository] RevokeOtherSessions - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if err := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", at).Error; err != nil {
+		log.Errorf("[SessionRepository] RevokeOtherSessions - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepositoryInterface {
+	return &sessionRepository{db: db}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warehouse-go/user-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepositoryInterface interface {
+	GetTwoFactorByUserID(ctx context.Context, userID uint) (*model.TwoFactor, error)
+	SavePendingTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error
+	ConfirmTwoFactor(ctx context.Context, userID uint, step int64, recoveryCodeHashes []string, at time.Time) error
+	UseTwoFactorStep(ctx context.Context, userID uint, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) error
+	DeleteTwoFactor(ctx context.Context, userID uint) error
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetTwoFactorByUserID implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) GetTwoFactorByUserID(ctx context.Context, userID uint) (*model.TwoFactor, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] GetTwoFactorByUserID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	twoFactor := model.TwoFactor{}
+	if err := t.db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+
+	return &twoFactor, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// SavePendingTwoFactor implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) SavePendingTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] SavePendingTwoFactor - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// Starting over replaces the secret of an unfinished enrollment. The
+	// confirmed_at condition keeps a confirmed one untouched.
+	result := t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":         twoFactor.Secret,
+			"last_used_step": 0,
+			"updated_at":     time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "two_factors.confirmed_at IS NULL"}}},
+	}).Create(twoFactor)
+	if result.Error != nil {
+		log.Errorf("[TwoFactorRepository] SavePendingTwoFactor - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrDuplicatedKey
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ConfirmTwoFactor implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) ConfirmTwoFactor(ctx context.Context, userID uint, step int64, recoveryCodeHashes []string, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] ConfirmTwoFactor - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TwoFactor{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"confirmed_at":   at,
+				"last_used_step": step,
+			})
+		if result.Error != nil {
+			log.Errorf("[TwoFactorRepository] ConfirmTwoFactor - 2: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("two_factor_enabled", true).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] ConfirmTwoFactor - 3: %v", err)
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] ConfirmTwoFactor - 4: %v", err)
+			return err
+		}
+
+		codes := make([]model.RecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, model.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: at})
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] ConfirmTwoFactor - 5: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ────────────────────────────────────────────────────────────────
+// UseTwoFactorStep implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) UseTwoFactorStep(ctx context.Context, userID uint, step int64) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] UseTwoFactorStep - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	// Only a step newer than the last accepted one moves the counter, so a
+	// code seen over someone's shoulder is worthless once it was used.
+	result := t.db.WithContext(ctx).Model(&model.TwoFactor{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		log.Errorf("[TwoFactorRepository] UseTwoFactorStep - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// UseRecoveryCode implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] UseRecoveryCode - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := t.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		log.Errorf("[TwoFactorRepository] UseRecoveryCode - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// DeleteTwoFactor implements TwoFactorRepositoryInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TwoFactorRepository] DeleteTwoFactor - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] DeleteTwoFactor - 2: %v", err)
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] DeleteTwoFactor - 3: %v", err)
+			return err
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("two_factor_enabled", false).Error; err != nil {
+			log.Errorf("[TwoFactorRepository] DeleteTwoFactor - 4: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepositoryInterface {
+	return &twoFactorRepository{db: db}
+}
@@ -2,6 +2,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 	"warehouse-go/user-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
@@ -10,15 +11,19 @@ import (
 
 type UserRepositoryInterface interface {
 	CreateUser(ctx context.Context, user model.User) (*model.User, error)
-	GetAllUsers(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.User, int64, error)
+	GetAllUsers(ctx context.Context, page, limit int, search, status, sortBy, sortOrder string) ([]model.User, int64, error)
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetExistingEmails(ctx context.Context, emails []string) ([]string, error)
 	UpdateUser(ctx context.Context, user model.User) error
-	DeleteUser(ctx context.Context, id uint) error
+	UpdatePassword(ctx context.Context, id uint, passwordHash string, mustChangePassword bool) error
+	DeactivateUser(ctx context.Context, id uint, at time.Time) error
+	RestoreUser(ctx context.Context, id uint) error
 
 	GetUserByRoleName(ctx context.Context, roleName string) ([]model.User, error)
 
 	AssignUserToRole(ctx context.Context, userID uint, roleID uint) error
+	ReplaceUserRoles(ctx context.Context, userID uint, roleIDs []uint) error
 	EditAssignUserToRole(ctx context.Context, assignRoleID uint, userID uint, roleID uint) error
 	GetUserByRoleID(ctx context.Context, assignRoleID uint) (*model.UserRole, error)
 	GetAllUserRoles(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.UserRole, int64, error)
@@ -75,25 +80,56 @@ func (u *userRepository) CreateUser(ctx context.Context, user model.User) (*mode
 }
 
 // ────────────────────────────────────────────────────────────────
-// DeleteUser Implements UserRepository Inteface
+// DeactivateUser Implements UserRepository Interface
 // ────────────────────────────────────────────────────────────────
-func (u *userRepository) DeleteUser(ctx context.Context, id uint) error {
+func (u *userRepository) DeactivateUser(ctx context.Context, id uint, at time.Time) error {
 	select {
 	case <-ctx.Done():
-		log.Errorf("[UserRepository] DeleteUser - 1 %v", ctx.Err())
+		log.Errorf("[UserRepository] DeactivateUser - 1: %v", ctx.Err())
 		return ctx.Err()
 	default:
 	}
 
-	modelUser := model.User{}
+	// The row stays, merchants, transactions and audit logs still refer to
+	// it. Only an active user is updated, so the first deactivation time is
+	// kept.
+	result := u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND deactivated_at IS NULL", id).
+		Update("deactivated_at", at)
+	if result.Error != nil {
+		log.Errorf("[UserRepository] DeactivateUser - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
 
-	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone").
-		Preload("Roles").Where("id = ?", id).First(&modelUser).Error; err != nil {
-			log.Errorf("[UserRepository] DeleteUser - 2 %v", err)
-			return err
-		}
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RestoreUser Implements UserRepository Interface
+// ────────────────────────────────────────────────────────────────
+func (u *userRepository) RestoreUser(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[UserRepository] RestoreUser - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND deactivated_at IS NOT NULL", id).
+		Update("deactivated_at", nil)
+	if result.Error != nil {
+		log.Errorf("[UserRepository] RestoreUser - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
 
-		return u.db.WithContext(ctx).Delete(&modelUser).Error
+	return nil
 }
 
 // ────────────────────────────────────────────────────────────────
@@ -179,7 +215,7 @@ func (u *userRepository) GetAllUserRoles(ctx context.Context, page, limit int, s
 // ────────────────────────────────────────────────────────────────
 // GetAllUsers Implements UserRepository Interface 
 // ────────────────────────────────────────────────────────────────
-func (u *userRepository) GetAllUsers(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.User, int64, error) {
+func (u *userRepository) GetAllUsers(ctx context.Context, page, limit int, search, status, sortBy, sortOrder string) ([]model.User, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[UserRepository] GetAllUsers - 1: %v", ctx.Err())
@@ -208,6 +244,15 @@ func (u *userRepository) GetAllUsers(ctx context.Context, page, limit int, searc
 	// ✅ Mulai query dengan model agar GORM tahu tabel yang dipakai
 	query := u.db.Model(&model.User{})
 
+	// Deactivated users are left out unless asked for.
+	switch status {
+	case model.UserStatusAll:
+	case model.UserStatusInactive:
+		query = query.Where("deactivated_at IS NOT NULL")
+	default:
+		query = query.Where("deactivated_at IS NULL")
+	}
+
 	// ✅ Perbaiki syntax pencarian
 	if search != "" {
 		query = query.Where("name ILIKE ? OR email ILIKE ?", "%"+search+"%", "%"+search+"%")
@@ -222,7 +267,7 @@ func (u *userRepository) GetAllUsers(ctx context.Context, page, limit int, searc
 
 	// ✅ Ambil data dengan pagination dan preload relasi Roles
 	var modelUsers []model.User
-	if err := query.Select("id", "name", "email", "password", "photo", "phone", "created_at").
+	if err := query.Select("id", "name", "email", "password", "photo", "phone", "created_at", "deactivated_at").
 		Preload("Roles").
 		Order(sortBy + " " + sortOrder).
 		Offset(offset).
@@ -248,8 +293,8 @@ func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 	}
 
 	modelUsers := model.User{}
-	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone", "created_at").
-		Preload("Roles").
+	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone", "created_at", "must_change_password", "two_factor_enabled", "deactivated_at").
+		Preload("Roles.Permissions").
 		Where("email = ?", email).
 		First(&modelUsers).Error; err != nil {
 			log.Errorf("[UserRepository] GetUserByEmail - 2: %v", err)
@@ -259,6 +304,33 @@ func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 		return &modelUsers, nil
 }
 
+// ────────────────────────────────────────────────────────────────
+// GetExistingEmails Implements UserRepository Interface
+// ────────────────────────────────────────────────────────────────
+func (u *userRepository) GetExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[UserRepository] GetExistingEmails - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	existing := []string{}
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	// Compared lowercased, deactivated users still hold their address.
+	if err := u.db.WithContext(ctx).Model(&model.User{}).
+		Where("LOWER(email) IN ?", emails).
+		Pluck("LOWER(email)", &existing).Error; err != nil {
+		log.Errorf("[UserRepository] GetExistingEmails - 2: %v", err)
+		return nil, err
+	}
+
+	return existing, nil
+}
+
 // ────────────────────────────────────────────────────────────────
 // GetUserbyID Implements UserRepository Interface
 // ────────────────────────────────────────────────────────────────
@@ -271,9 +343,9 @@ func (u *userRepository) GetUserByID(ctx context.Context, id uint) (*model.User,
 	}
 	
 	modelUsers := model.User{}
-	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone", "created_at").
+	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone", "created_at", "must_change_password", "two_factor_enabled", "deactivated_at").
 	Where("id = ?", id).
-	Preload("Roles").
+	Preload("Roles.Permissions").
 	First(&modelUsers).Error; err != nil {
 		log.Errorf("[UserRepository] GetUserByID - 2: %v", err)
 		return nil, err
@@ -302,9 +374,9 @@ func (u *userRepository) GetUserByRoleName(ctx context.Context, roleName string)
 		Joins("JOIN roles ON user_roles.role_id = roles.id").
 		Where("roles.name = ?", roleName)
 
-	//Query utama dengan preload roles
+	//Query utama dengan preload roles, deactivated users are left out
 	if err := u.db.WithContext(ctx).
-		Where("id IN (?)", subQuery).
+		Where("id IN (?) AND deactivated_at IS NULL", subQuery).
 		Preload("Roles").
 		Find(&users).Error; err != nil {
 			log.Errorf("[UserRepository] GetUserByRoleName - 2: %v", err)
@@ -351,7 +423,7 @@ func (u *userRepository) UpdateUser(ctx context.Context, user model.User) error
 
 	modelUser := model.User{}
 
-	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone").
+	if err := u.db.WithContext(ctx).Select("id", "name", "email", "password", "photo", "phone", "must_change_password", "two_factor_enabled", "deactivated_at").
 		Preload("Roles").Where("id = ?", user.ID).First(&modelUser).Error; err != nil {
 			log.Errorf("[UserRepository] UpdateUser - 2: %v", err)
 			return err
@@ -359,7 +431,7 @@ func (u *userRepository) UpdateUser(ctx context.Context, user model.User) error
 
 		modelUser.Name = user.Name
 		modelUser.Email = user.Email
-		if user.Password == "" {
+		if user.Password != "" {
 			modelUser.Password = user.Password
 		}
 		modelUser.Photo = user.Photo
@@ -368,6 +440,62 @@ func (u *userRepository) UpdateUser(ctx context.Context, user model.User) error
 		return u.db.WithContext(ctx).Save(&modelUser).Error	 
 }
 
+// ────────────────────────────────────────────────────────────────
+// UpdatePassword implements UserRepository Interface
+// ────────────────────────────────────────────────────────────────
+func (u *userRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string, mustChangePassword bool) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[UserRepository] UpdatePassword - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	result := u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password":             passwordHash,
+			"must_change_password": mustChangePassword,
+		})
+	if result.Error != nil {
+		log.Errorf("[UserRepository] UpdatePassword - 2: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ReplaceUserRoles implements UserRepository Interface
+// ────────────────────────────────────────────────────────────────
+func (u *userRepository) ReplaceUserRoles(ctx context.Context, userID uint, roleIDs []uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[UserRepository] ReplaceUserRoles - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+			log.Errorf("[UserRepository] ReplaceUserRoles - 2: %v", err)
+			return err
+		}
+
+		for _, roleID := range roleIDs {
+			if err := tx.Create(&model.UserRole{UserID: userID, RoleID: roleID}).Error; err != nil {
+				log.Errorf("[UserRepository] ReplaceUserRoles - 3: %v", err)
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 func NewUserRepository(db *gorm.DB) UserRepositoryInterface {
 	return &userRepository{db: db}
 }
\ No newline at end of file
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"warehouse-go/shared/audit"
+	"warehouse-go/user-service/configs"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+const auditQueue = "audit_log"
+
+type AuditConsumer struct {
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	recorder audit.Recorder
+}
+
+func NewAuditConsumer(config configs.Config, recorder audit.Recorder) (*AuditConsumer, error) {
+	conn, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/",
+		config.RabitMQ.Username,
+		config.RabitMQ.Password,
+		config.RabitMQ.Host,
+		config.RabitMQ.Port,
+	))
+	if err != nil {
+		log.Errorf("[AuditConsumer] NewAuditConsumer - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[AuditConsumer] NewAuditConsumer - 2: %v", err)
+		return nil, err
+	}
+
+	if err := ch.ExchangeDeclare(audit.Exchange, "topic", true, false, false, false, nil); err != nil {
+		log.Errorf("[AuditConsumer] NewAuditConsumer - 3: %v", err)
+		return nil, err
+	}
+
+	q, err := ch.QueueDeclare(auditQueue, true, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[AuditConsumer] NewAuditConsumer - 4: %v", err)
+		return nil, err
+	}
+
+	if err := ch.QueueBind(q.Name, "#", audit.Exchange, false, nil); err != nil {
+		log.Errorf("[AuditConsumer] NewAuditConsumer - 5: %v", err)
+		return nil, err
+	}
+
+	return &AuditConsumer{
+		conn:     conn,
+		ch:       ch,
+		recorder: recorder,
+	}, nil
+}
+
+// ConsumeAuditEvents stores events until ctx is done. A message is only
+// acknowledged once it is stored, so events survive a database outage.
+func (a *AuditConsumer) ConsumeAuditEvents(ctx context.Context) error {
+	msgs, err := a.ch.Consume(auditQueue, "", false, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[AuditConsumer] ConsumeAuditEvents - 1: %v", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping audit consumer...")
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("audit queue closed")
+			}
+			a.handleAuditEvent(ctx, msg)
+		}
+	}
+}
+
+func (a *AuditConsumer) handleAuditEvent(ctx context.Context, msg amqp.Delivery) {
+	var event audit.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[AuditConsumer] handleAuditEvent - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if err := a.recorder.RecordAuditEvent(ctx, event); err != nil {
+		log.Errorf("[AuditConsumer] handleAuditEvent - 2: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+func (a *AuditConsumer) Close() error {
+	if a.ch != nil {
+		a.ch.Close()
+	}
+	if a.conn != nil {
+		return a.conn.Close()
+	}
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/streadway/amqp"
 )
+// EmailPayload never carries a password. New users get an invitation link
+// and choose their password themselves.
 type EmailPayload struct {
 	Email    	string `json:"email"`
-	Password 	string `json:"password"`
 	Type 	  	string `json:"type"`
 	UserID   	uint   `json:"user_id"`
 	Name    	string `json:"name"`
+	LockedUntil string `json:"locked_until,omitempty"`
+	ResetLink   string `json:"reset_link,omitempty"`
+	InvitationLink string `json:"invitation_link,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
 }
 
 type RabbitMQServiceInterface interface {
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix = "whk_"
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key.
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+
+	// A scope is "<route group>:<read|write>", e.g. products:read. The group
+	// is the first path segment after /api/v1, or * for every group.
+	scopePattern = regexp.MustCompile(`^(\*|[a-z0-9-]+):(read|write)$`)
+)
+
+type APIKeyUsecaseInterface interface {
+	CreateAPIKey(ctx context.Context, apiKey model.APIKey) (string, *model.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+	VerifyAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo repository.APIKeyRepositoryInterface
+	userRepo   repository.UserRepositoryInterface
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateAPIKey implements APIKeyUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyUsecase) CreateAPIKey(ctx context.Context, apiKey model.APIKey) (string, *model.APIKey, error) {
+	if err := validateScopes(apiKey.Scopes); err != nil {
+		return "", nil, err
+	}
+	if err := validateAllowedIPs(apiKey.AllowedIPs); err != nil {
+		return "", nil, err
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return "", nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+	}
+
+	if _, err := a.userRepo.GetUserByID(ctx, apiKey.CreatedBy); err != nil {
+		log.Errorf("[APIKeyUsecase] CreateAPIKey - 1: %v", err)
+		return "", nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		log.Errorf("[APIKeyUsecase] CreateAPIKey - 2: %v", err)
+		return "", nil, err
+	}
+
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+	apiKey.Prefix = key[:len(apiKeyPrefix)+8]
+	apiKey.KeyHash = hashToken(key)
+
+	if err := a.apiKeyRepo.CreateAPIKey(ctx, &apiKey); err != nil {
+		log.Errorf("[APIKeyUsecase] CreateAPIKey - 3: %v", err)
+		return "", nil, err
+	}
+
+	return key, &apiKey, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAllAPIKeys implements APIKeyUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyUsecase) GetAllAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	return a.apiKeyRepo.GetAllAPIKeys(ctx)
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeAPIKey implements APIKeyUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyUsecase) RevokeAPIKey(ctx context.Context, id uint) error {
+	return a.apiKeyRepo.RevokeAPIKey(ctx, id, time.Now())
+}
+
+// ────────────────────────────────────────────────────────────────
+// VerifyAPIKey implements APIKeyUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *apiKeyUsecase) VerifyAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := a.apiKeyRepo.GetAPIKeyByHash(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		log.Errorf("[APIKeyUsecase] VerifyAPIKey - 1: %v", err)
+		return nil, err
+	}
+
+	// A key acts as its creator, so it stops working with them. Keys are
+	// revoked on deactivation too; this covers the ones created before.
+	now := time.Now()
+	if !apiKey.IsActive(now) || !apiKey.Creator.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := a.apiKeyRepo.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+			log.Errorf("[APIKeyUsecase] VerifyAPIKey - 2: %v", err)
+		}
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey, nil
+}
+
+// hashToken is how API keys and password reset tokens are stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateScopes(scopes string) error {
+	if scopes == "" {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range strings.Split(scopes, ",") {
+		if !scopePattern.MatchString(scope) {
+			return fmt.Errorf("%w: invalid scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+	return nil
+}
+
+func validateAllowedIPs(allowedIPs string) error {
+	if allowedIPs == "" {
+		return nil
+	}
+	for _, entry := range strings.Split(allowedIPs, ",") {
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("%w: invalid IP or CIDR %q", ErrInvalidAPIKeyRequest, entry)
+		}
+	}
+	return nil
+}
+
+func NewAPIKeyUsecase(apiKeyRepo repository.APIKeyRepositoryInterface, userRepo repository.UserRepositoryInterface) APIKeyUsecaseInterface {
+	return &apiKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"warehouse-go/shared/audit"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// AuditUsecaseInterface stores the events of every service: user-service
+// records its own directly, the others through the audit_events exchange.
+type AuditUsecaseInterface interface {
+	audit.Recorder
+	GetAuditLogs(ctx context.Context, page, limit int, filter repository.AuditLogFilter) ([]model.AuditLog, int64, error)
+}
+
+type auditUsecase struct {
+	auditRepo repository.AuditRepositoryInterface
+}
+
+// ────────────────────────────────────────────────────────────────
+// RecordAuditEvent implements AuditUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *auditUsecase) RecordAuditEvent(ctx context.Context, event audit.Event) error {
+	auditLog := model.AuditLog{
+		Service:   event.Service,
+		Action:    event.Action,
+		Entity:    event.Entity,
+		EntityID:  event.EntityID,
+		ActorID:   event.ActorID,
+		Before:    string(event.Before),
+		After:     string(event.After),
+		RequestID: event.RequestID,
+		IP:        event.IP,
+		CreatedAt: event.Timestamp,
+	}
+
+	if len(event.Changes) > 0 {
+		changes, err := json.Marshal(event.Changes)
+		if err != nil {
+			log.Errorf("[AuditUsecase] RecordAuditEvent - 1: %v", err)
+			return err
+		}
+		auditLog.Changes = string(changes)
+	}
+
+	return a.auditRepo.CreateAuditLog(ctx, &auditLog)
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAuditLogs implements AuditUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *auditUsecase) GetAuditLogs(ctx context.Context, page, limit int, filter repository.AuditLogFilter) ([]model.AuditLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	return a.auditRepo.GetAuditLogs(ctx, page, limit, filter)
+}
+
+func NewAuditUsecase(auditRepo repository.AuditRepositoryInterface) AuditUsecaseInterface {
+	return &auditUsecase{auditRepo: auditRepo}
+}
@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"warehouse-go/user-service/configs"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/totp"
+	"warehouse-go/user-service/repository"
+	"warehouse-go/user-service/service"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrAccountNotActivated = errors.New("account is not activated")
+	ErrAccountDeactivated  = errors.New("account is deactivated")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrWrongPassword       = errors.New("current password is incorrect")
+	ErrPasswordUnchanged   = errors.New("new password must differ from the current password")
+)
+
+// AccountLockedError is returned by Login while an account is locked after
+// too many failed attempts.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account is locked until %s", e.Until.Format(time.RFC3339))
+}
+
+type AuthUsecaseInterface interface {
+	Login(ctx context.Context, email, password string) (*model.User, error)
+	UnlockUser(ctx context.Context, userID uint) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// ChangePassword signs out every session of the user but sessionID, the
+	// one the change was made from.
+	ChangePassword(ctx context.Context, userID, sessionID uint, currentPassword, newPassword string) error
+	VerifySecondFactor(ctx context.Context, userID uint, code string) (*model.User, error)
+}
+
+type authUsecase struct {
+	userRepo          repository.UserRepositoryInterface
+	loginAttemptRepo  repository.LoginAttemptRepositoryInterface
+	passwordResetRepo repository.PasswordResetRepositoryInterface
+	twoFactorRepo     repository.TwoFactorRepositoryInterface
+	sessionRepo       repository.SessionRepositoryInterface
+	rabbitMQService   service.RabbitMQServiceInterface
+	maxAttempts       int
+	lockoutDuration   time.Duration
+	passwordResetURL  string
+	passwordResetTTL  time.Duration
+}
+
+// ────────────────────────────────────────────────────────────────
+// Login implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) Login(ctx context.Context, email, password string) (*model.User, error) {
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		log.Errorf("[AuthUsecase] Login - 1: %v", err)
+		return nil, ErrInvalidCredentials
+	}
+
+	attempt, err := a.loginAttemptRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		log.Errorf("[AuthUsecase] Login - 2: %v", err)
+		return nil, err
+	}
+
+	if user.Password == "" {
+		return nil, ErrAccountNotActivated
+	}
+
+	// A locked account is refused before the password is checked, so the
+	// lock cannot be used to guess passwords either.
+	now := time.Now()
+	if attempt.IsLocked(now) {
+		return nil, &AccountLockedError{Until: *attempt.LockedUntil}
+	}
+
+	if !conv.CheckPasswordHash(password, user.Password) {
+		if err := a.recordFailure(ctx, user); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	// Only told after a right password, so it does not reveal which
+	// addresses belong to deactivated accounts.
+	if !user.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+
+	// With 2FA the password is only half of the login. The failures stay
+	// counted until VerifySecondFactor succeeds, so guessing codes after a
+	// right password still ends in a lock.
+	if user.TwoFactorEnabled {
+		return user, nil
+	}
+
+	if attempt.FailedAttempts > 0 || attempt.LockedUntil != nil {
+		if err := a.loginAttemptRepo.Reset(ctx, user.ID); err != nil {
+			log.Errorf("[AuthUsecase] Login - 3: %v", err)
+		}
+	}
+
+	return user, nil
+}
+
+func (a *authUsecase) recordFailure(ctx context.Context, user *model.User) error {
+	attempt, err := a.loginAttemptRepo.RecordFailure(ctx, user.ID)
+	if err != nil {
+		log.Errorf("[AuthUsecase] recordFailure - 1: %v", err)
+		return err
+	}
+
+	if attempt.FailedAttempts < a.maxAttempts {
+		return nil
+	}
+
+	until := time.Now().Add(a.lockoutDuration)
+	if err := a.loginAttemptRepo.Lock(ctx, user.ID, until); err != nil {
+		log.Errorf("[AuthUsecase] recordFailure - 2: %v", err)
+		return err
+	}
+
+	emailPayload := service.EmailPayload{
+		Email:       user.Email,
+		Type:        "account_locked",
+		UserID:      user.ID,
+		Name:        user.Name,
+		LockedUntil: until.Format(time.RFC3339),
+	}
+
+	go func() {
+		if err := a.rabbitMQService.PublishEmail(context.Background(), emailPayload); err != nil {
+			log.Errorf("[AuthUsecase] recordFailure - 3: %v", err)
+		}
+	}()
+
+	return &AccountLockedError{Until: until}
+}
+
+// ────────────────────────────────────────────────────────────────
+// UnlockUser implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) UnlockUser(ctx context.Context, userID uint) error {
+	if _, err := a.userRepo.GetUserByID(ctx, userID); err != nil {
+		log.Errorf("[AuthUsecase] UnlockUser - 1: %v", err)
+		return err
+	}
+
+	if err := a.loginAttemptRepo.Reset(ctx, userID); err != nil {
+		log.Errorf("[AuthUsecase] UnlockUser - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ForgotPassword implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) ForgotPassword(ctx context.Context, email string) error {
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Unknown addresses get the same answer, so the endpoint cannot be
+		// used to find out who has an account.
+		return nil
+	}
+	if err != nil {
+		log.Errorf("[AuthUsecase] ForgotPassword - 1: %v", err)
+		return err
+	}
+
+	if user.Password == "" || !user.IsActive() {
+		// The account is still waiting for its invitation to be accepted,
+		// or it has been deactivated.
+		return nil
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		log.Errorf("[AuthUsecase] ForgotPassword - 2: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	resetToken := model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(a.passwordResetTTL),
+		CreatedAt: now,
+	}
+	if err := a.passwordResetRepo.CreatePasswordResetToken(ctx, &resetToken); err != nil {
+		log.Errorf("[AuthUsecase] ForgotPassword - 3: %v", err)
+		return err
+	}
+
+	emailPayload := service.EmailPayload{
+		Email:     user.Email,
+		Type:      "password_reset",
+		UserID:    user.ID,
+		Name:      user.Name,
+		ResetLink: a.passwordResetURL + "?token=" + url.QueryEscape(token),
+		ExpiresAt: resetToken.ExpiresAt.Format(time.RFC3339),
+	}
+
+	go func() {
+		if err := a.rabbitMQService.PublishEmail(context.Background(), emailPayload); err != nil {
+			log.Errorf("[AuthUsecase] ForgotPassword - 4: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ResetPassword implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	resetToken, err := a.passwordResetRepo.GetPasswordResetTokenByHash(ctx, hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		log.Errorf("[AuthUsecase] ResetPassword - 1: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	if !resetToken.IsUsable(now) {
+		return ErrInvalidResetToken
+	}
+
+	// Burn the token before the password changes, a second request with the
+	// same token loses here.
+	if err := a.passwordResetRepo.UsePasswordResetToken(ctx, resetToken.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		log.Errorf("[AuthUsecase] ResetPassword - 2: %v", err)
+		return err
+	}
+
+	if err := a.setPassword(ctx, resetToken.UserID, newPassword); err != nil {
+		log.Errorf("[AuthUsecase] ResetPassword - 3: %v", err)
+		return err
+	}
+
+	// Whoever got hold of the old password may still hold a token.
+	if err := a.sessionRepo.RevokeUserSessions(ctx, resetToken.UserID, now); err != nil {
+		log.Errorf("[AuthUsecase] ResetPassword - 4: %v", err)
+		return err
+	}
+
+	// Proving access to the mailbox is enough to lift a lockout.
+	if err := a.loginAttemptRepo.Reset(ctx, resetToken.UserID); err != nil {
+		log.Errorf("[AuthUsecase] ResetPassword - 5: %v", err)
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ChangePassword implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) ChangePassword(ctx context.Context, userID, sessionID uint, currentPassword, newPassword string) error {
+	user, err := a.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Errorf("[AuthUsecase] ChangePassword - 1: %v", err)
+		return err
+	}
+
+	if !conv.CheckPasswordHash(currentPassword, user.Password) {
+		return ErrWrongPassword
+	}
+	if currentPassword == newPassword {
+		return ErrPasswordUnchanged
+	}
+
+	if err := a.setPassword(ctx, user.ID, newPassword); err != nil {
+		log.Errorf("[AuthUsecase] ChangePassword - 2: %v", err)
+		return err
+	}
+
+	if err := a.sessionRepo.RevokeOtherSessions(ctx, user.ID, sessionID, time.Now()); err != nil {
+		log.Errorf("[AuthUsecase] ChangePassword - 3: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// VerifySecondFactor implements AuthUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (a *authUsecase) VerifySecondFactor(ctx context.Context, userID uint, code string) (*model.User, error) {
+	user, err := a.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Errorf("[AuthUsecase] VerifySecondFactor - 1: %v", err)
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+
+	attempt, err := a.loginAttemptRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		log.Errorf("[AuthUsecase] VerifySecondFactor - 2: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if attempt.IsLocked(now) {
+		return nil, &AccountLockedError{Until: *attempt.LockedUntil}
+	}
+
+	twoFactor, err := a.twoFactorRepo.GetTwoFactorByUserID(ctx, user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !twoFactor.IsConfirmed()) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		log.Errorf("[AuthUsecase] VerifySecondFactor - 3: %v", err)
+		return nil, err
+	}
+
+	ok, err := a.useSecondFactor(ctx, twoFactor, code, now)
+	if err != nil {
+		log.Errorf("[AuthUsecase] VerifySecondFactor - 4: %v", err)
+		return nil, err
+	}
+	if !ok {
+		if err := a.recordFailure(ctx, user); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if attempt.FailedAttempts > 0 || attempt.LockedUntil != nil {
+		if err := a.loginAttemptRepo.Reset(ctx, user.ID); err != nil {
+			log.Errorf("[AuthUsecase] VerifySecondFactor - 5: %v", err)
+		}
+	}
+
+	return user, nil
+}
+
+// useSecondFactor accepts a TOTP code or, failing that, an unused recovery
+// code. Either is spent by the conditional update that accepts it.
+func (a *authUsecase) useSecondFactor(ctx context.Context, twoFactor *model.TwoFactor, code string, now time.Time) (bool, error) {
+	if step, ok := totp.Validate(twoFactor.Secret, code, now); ok {
+		err := a.twoFactorRepo.UseTwoFactorStep(ctx, twoFactor.UserID, step)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Already used, a replayed code counts as a wrong one.
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	recoveryCode := normalizeRecoveryCode(code)
+	if recoveryCode == "" {
+		return false, nil
+	}
+	err := a.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.UserID, hashToken(recoveryCode), now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// setPassword stores a password the user chose, which also clears
+// must_change_password.
+func (a *authUsecase) setPassword(ctx context.Context, userID uint, password string) error {
+	passwordHash, err := conv.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return a.userRepo.UpdatePassword(ctx, userID, passwordHash, false)
+}
+
+func NewAuthUsecase(userRepo repository.UserRepositoryInterface, loginAttemptRepo repository.LoginAttemptRepositoryInterface, passwordResetRepo repository.PasswordResetRepositoryInterface, twoFactorRepo repository.TwoFactorRepositoryInterface, sessionRepo repository.SessionRepositoryInterface, rabbitMQService service.RabbitMQServiceInterface, cfg configs.Auth) AuthUsecaseInterface {
+	maxAttempts := cfg.MaxFailedAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	lockoutDuration := cfg.LockoutDuration
+	if lockoutDuration <= 0 {
+		lockoutDuration = 15 * time.Minute
+	}
+
+	passwordResetTTL := cfg.PasswordResetTTL
+	if passwordResetTTL <= 0 {
+		passwordResetTTL = 30 * time.Minute
+	}
+
+	return &authUsecase{
+		userRepo:          userRepo,
+		loginAttemptRepo:  loginAttemptRepo,
+		passwordResetRepo: passwordResetRepo,
+		twoFactorRepo:     twoFactorRepo,
+		sessionRepo:       sessionRepo,
+		rabbitMQService:   rabbitMQService,
+		maxAttempts:       maxAttempts,
+		lockoutDuration:   lockoutDuration,
+		passwordResetURL:  cfg.PasswordResetURL,
+		passwordResetTTL:  passwordResetTTL,
+	}
+}
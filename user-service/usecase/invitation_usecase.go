@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+	"warehouse-go/user-service/configs"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/repository"
+	"warehouse-go/user-service/service"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidInvitation = errors.New("invalid, expired or already used invitation")
+
+type InvitationUsecaseInterface interface {
+	InviteUser(ctx context.Context, user model.User, createdBy uint) (*model.Invitation, error)
+	InviteUsers(ctx context.Context, users []model.User, createdBy uint) ([]model.Invitation, error)
+	GetAllInvitations(ctx context.Context, status string) ([]model.Invitation, error)
+	ResendInvitation(ctx context.Context, id uint) (*model.Invitation, error)
+	RevokeInvitation(ctx context.Context, id uint) error
+	AcceptInvitation(ctx context.Context, token, password string) error
+}
+
+type invitationUsecase struct {
+	invitationRepo  repository.InvitationRepositoryInterface
+	userRepo        repository.UserRepositoryInterface
+	rabbitMQService service.RabbitMQServiceInterface
+	invitationURL   string
+	invitationTTL   time.Duration
+}
+
+// ────────────────────────────────────────────────────────────────
+// InviteUser implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) InviteUser(ctx context.Context, user model.User, createdBy uint) (*model.Invitation, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		log.Errorf("[InvitationUsecase] InviteUser - 1: %v", err)
+		return nil, err
+	}
+
+	// No password until the invitation is accepted, Login refuses the
+	// account meanwhile.
+	user.Password = ""
+	invitation := model.Invitation{
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(i.invitationTTL),
+		SentCount: 1,
+		CreatedBy: createdBy,
+	}
+	if err := i.invitationRepo.CreateInvitedUser(ctx, &user, &invitation); err != nil {
+		log.Errorf("[InvitationUsecase] InviteUser - 2: %v", err)
+		return nil, err
+	}
+
+	invitation.User = user
+	i.sendInvitation(user, token, invitation.ExpiresAt)
+	return &invitation, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// InviteUsers implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) InviteUsers(ctx context.Context, users []model.User, createdBy uint) ([]model.Invitation, error) {
+	userPtrs := make([]*model.User, len(users))
+	invitationPtrs := make([]*model.Invitation, len(users))
+	tokens := make([]string, len(users))
+	expiresAt := time.Now().Add(i.invitationTTL)
+	for idx := range users {
+		token, err := newSecretToken()
+		if err != nil {
+			log.Errorf("[InvitationUsecase] InviteUsers - 1: %v", err)
+			return nil, err
+		}
+		tokens[idx] = token
+
+		users[idx].Password = ""
+		userPtrs[idx] = &users[idx]
+		invitationPtrs[idx] = &model.Invitation{
+			TokenHash: hashToken(token),
+			ExpiresAt: expiresAt,
+			SentCount: 1,
+			CreatedBy: createdBy,
+		}
+	}
+
+	if err := i.invitationRepo.CreateInvitedUsers(ctx, userPtrs, invitationPtrs); err != nil {
+		log.Errorf("[InvitationUsecase] InviteUsers - 2: %v", err)
+		return nil, err
+	}
+
+	// Emails go out only once every user is stored.
+	invitations := make([]model.Invitation, len(users))
+	for idx, invitation := range invitationPtrs {
+		invitation.User = users[idx]
+		invitations[idx] = *invitation
+		i.sendInvitation(users[idx], tokens[idx], invitation.ExpiresAt)
+	}
+	return invitations, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetAllInvitations implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) GetAllInvitations(ctx context.Context, status string) ([]model.Invitation, error) {
+	invitations, err := i.invitationRepo.GetAllInvitations(ctx)
+	if err != nil {
+		log.Errorf("[InvitationUsecase] GetAllInvitations - 1: %v", err)
+		return nil, err
+	}
+	if status == "" {
+		return invitations, nil
+	}
+
+	// Expired is derived from expires_at, so the status filter runs here
+	// rather than in SQL.
+	now := time.Now()
+	filtered := []model.Invitation{}
+	for _, invitation := range invitations {
+		if invitation.Status(now) == status {
+			filtered = append(filtered, invitation)
+		}
+	}
+	return filtered, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ResendInvitation implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) ResendInvitation(ctx context.Context, id uint) (*model.Invitation, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		log.Errorf("[InvitationUsecase] ResendInvitation - 1: %v", err)
+		return nil, err
+	}
+
+	// The new token replaces the old one, an earlier link stops working.
+	// Expired invitations can be resent, accepted or revoked ones cannot.
+	expiresAt := time.Now().Add(i.invitationTTL)
+	if err := i.invitationRepo.RotateInvitationToken(ctx, id, hashToken(token), expiresAt); err != nil {
+		log.Errorf("[InvitationUsecase] ResendInvitation - 2: %v", err)
+		return nil, err
+	}
+
+	invitation, err := i.invitationRepo.GetInvitationByID(ctx, id)
+	if err != nil {
+		log.Errorf("[InvitationUsecase] ResendInvitation - 3: %v", err)
+		return nil, err
+	}
+
+	i.sendInvitation(invitation.User, token, invitation.ExpiresAt)
+	return invitation, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeInvitation implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) RevokeInvitation(ctx context.Context, id uint) error {
+	return i.invitationRepo.RevokeInvitation(ctx, id, time.Now())
+}
+
+// ────────────────────────────────────────────────────────────────
+// AcceptInvitation implements InvitationUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (i *invitationUsecase) AcceptInvitation(ctx context.Context, token, password string) error {
+	invitation, err := i.invitationRepo.GetInvitationByHash(ctx, hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidInvitation
+	}
+	if err != nil {
+		log.Errorf("[InvitationUsecase] AcceptInvitation - 1: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	if invitation.Status(now) != model.InvitationPending {
+		return ErrInvalidInvitation
+	}
+
+	if err := i.invitationRepo.AcceptInvitation(ctx, invitation.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		log.Errorf("[InvitationUsecase] AcceptInvitation - 2: %v", err)
+		return err
+	}
+
+	passwordHash, err := conv.HashPassword(password)
+	if err != nil {
+		log.Errorf("[InvitationUsecase] AcceptInvitation - 3: %v", err)
+		return err
+	}
+	if err := i.userRepo.UpdatePassword(ctx, invitation.UserID, passwordHash, false); err != nil {
+		log.Errorf("[InvitationUsecase] AcceptInvitation - 4: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (i *invitationUsecase) sendInvitation(user model.User, token string, expiresAt time.Time) {
+	emailPayload := service.EmailPayload{
+		Email:          user.Email,
+		Type:           "invitation",
+		UserID:         user.ID,
+		Name:           user.Name,
+		InvitationLink: i.invitationURL + "?token=" + url.QueryEscape(token),
+		ExpiresAt:      expiresAt.Format(time.RFC3339),
+	}
+
+	go func() {
+		if err := i.rabbitMQService.PublishEmail(context.Background(), emailPayload); err != nil {
+			log.Errorf("[InvitationUsecase] sendInvitation - 1: %v", err)
+		}
+	}()
+}
+
+// newSecretToken returns a random token for links sent by email.
+func newSecretToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func NewInvitationUsecase(invitationRepo repository.InvitationRepositoryInterface, userRepo repository.UserRepositoryInterface, rabbitMQService service.RabbitMQServiceInterface, cfg configs.Auth) InvitationUsecaseInterface {
+	invitationTTL := cfg.InvitationTTL
+	if invitationTTL <= 0 {
+		invitationTTL = 72 * time.Hour
+	}
+
+	return &invitationUsecase{
+		invitationRepo:  invitationRepo,
+		userRepo:        userRepo,
+		rabbitMQService: rabbitMQService,
+		invitationURL:   cfg.InvitationURL,
+		invitationTTL:   invitationTTL,
+	}
+}
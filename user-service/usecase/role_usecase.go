@@ -2,21 +2,29 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"warehouse-go/user-service/model"
 	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
+var ErrUnknownPermission = errors.New("unknown permission")
+
 type RoleUsecaseInterface interface {
 	CreateRole(ctx context.Context, role model.Role) error
 	UpdateRole(ctx context.Context, role model.Role) error
 	DeleteRole(ctx context.Context, id uint) error
 	GetAllRoleByID(ctx context.Context, id uint) (*model.Role, error)
 	GetAllRoles(ctx context.Context) ([]model.Role, error)
+	GetAllPermissions(ctx context.Context) ([]model.Permission, error)
 }
 
 type roleUsecase struct {
-	roleRepo repository.RoleRepositoryInterface
+	roleRepo       repository.RoleRepositoryInterface
+	permissionRepo repository.PermissionRepositoryInterface
 }
 
 
@@ -24,6 +32,9 @@ type roleUsecase struct {
 // CreateRole implements Roleusecase Interface
 // ────────────────────────────────────────────────────────────────
 func (r *roleUsecase) CreateRole(ctx context.Context, role model.Role) error {
+	if err := r.resolvePermissions(ctx, &role); err != nil {
+		return err
+	}
 	return r.roleRepo.CreateRole(ctx, role)
 }
 
@@ -51,10 +62,49 @@ func (r *roleUsecase) GetAllRoleByID(ctx context.Context, id uint) (*model.Role,
 // UpdateRole implements Roleusecase Interface
 // ────────────────────────────────────────────────────────────────
 func (r *roleUsecase) UpdateRole(ctx context.Context, role model.Role) error {
+	if err := r.resolvePermissions(ctx, &role); err != nil {
+		return err
+	}
 	return r.roleRepo.UpdateRole(ctx, role)
 }
 
+// ────────────────────────────────────────────────────────────────
+// GetAllPermissions implements Roleusecase Interface
+// ────────────────────────────────────────────────────────────────
+func (r *roleUsecase) GetAllPermissions(ctx context.Context) ([]model.Permission, error) {
+	return r.permissionRepo.GetAllPermissions(ctx)
+}
+
+// resolvePermissions replaces the permissions of role, which only carry a
+// name, with the stored ones. A nil list stays nil so an update keeps the
+// current set.
+func (r *roleUsecase) resolvePermissions(ctx context.Context, role *model.Role) error {
+	if role.Permissions == nil {
+		return nil
+	}
+
+	names := role.PermissionNames()
+	permissions, err := r.permissionRepo.GetPermissionsByNames(ctx, names)
+	if err != nil {
+		log.Errorf("[RoleUsecase] resolvePermissions - 1: %v", err)
+		return err
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+	}
+
+	role.Permissions = permissions
+	return nil
+}
+
 
-func NewRoleUsecase(roleRepo repository.RoleRepositoryInterface) RoleUsecaseInterface {
-	return &roleUsecase{roleRepo: roleRepo}
+func NewRoleUsecase(roleRepo repository.RoleRepositoryInterface, permissionRepo repository.PermissionRepositoryInterface) RoleUsecaseInterface {
+	return &roleUsecase{roleRepo: roleRepo, permissionRepo: permissionRepo}
 }
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written for a busy
+// session. The gateway caches verifications as well, so last seen is only
+// ever that precise.
+const sessionTouchInterval = time.Minute
+
+var ErrInvalidSession = errors.New("invalid session")
+
+type SessionUsecaseInterface interface {
+	CreateSession(ctx context.Context, session model.Session) (*model.Session, error)
+	VerifySession(ctx context.Context, id, userID uint, ip string) (*model.Session, error)
+	GetSessions(ctx context.Context, userID uint) ([]model.Session, error)
+	RevokeSession(ctx context.Context, id, userID uint) error
+}
+
+type sessionUsecase struct {
+	sessionRepo repository.SessionRepositoryInterface
+}
+
+// ────────────────────────────────────────────────────────────────
+// CreateSession implements SessionUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionUsecase) CreateSession(ctx context.Context, session model.Session) (*model.Session, error) {
+	session.LastSeenAt = time.Now()
+	if err := s.sessionRepo.CreateSession(ctx, &session); err != nil {
+		log.Errorf("[SessionUsecase] CreateSession - 1: %v", err)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// VerifySession implements SessionUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionUsecase) VerifySession(ctx context.Context, id, userID uint, ip string) (*model.Session, error) {
+	session, err := s.sessionRepo.GetSessionByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidSession
+	}
+	if err != nil {
+		log.Errorf("[SessionUsecase] VerifySession - 1: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.UserID != userID || !session.IsActive(now) {
+		return nil, ErrInvalidSession
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval || (ip != "" && ip != session.IP) {
+		if ip == "" {
+			ip = session.IP
+		}
+		if err := s.sessionRepo.TouchSession(ctx, session.ID, ip, now); err != nil {
+			log.Errorf("[SessionUsecase] VerifySession - 2: %v", err)
+		}
+		session.LastSeenAt = now
+		session.IP = ip
+	}
+
+	return session, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// GetSessions implements SessionUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionUsecase) GetSessions(ctx context.Context, userID uint) ([]model.Session, error) {
+	return s.sessionRepo.GetActiveSessionsByUserID(ctx, userID, time.Now())
+}
+
+// ────────────────────────────────────────────────────────────────
+// RevokeSession implements SessionUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (s *sessionUsecase) RevokeSession(ctx context.Context, id, userID uint) error {
+	return s.sessionRepo.RevokeSession(ctx, id, userID, time.Now())
+}
+
+func NewSessionUsecase(sessionRepo repository.SessionRepositoryInterface) SessionUsecaseInterface {
+	return &sessionUsecase{
+		sessionRepo: sessionRepo,
+	}
+}
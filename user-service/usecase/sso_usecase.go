@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSSOEmailRequired = errors.New("the identity provider did not share an email address")
+	// ErrSSOEmailNotVerified refuses to link an existing user to a provider
+	// account whose address the provider has not verified; anyone could
+	// otherwise take over a user by claiming their address.
+	ErrSSOEmailNotVerified = errors.New("the identity provider has not verified the email address")
+	// ErrSSOUserNotFound is returned for an unknown user while auto
+	// provisioning is off.
+	ErrSSOUserNotFound = errors.New("no user is registered for this single sign-on account")
+)
+
+// SSOLogin is a login confirmed by an OpenID Connect provider, sent by the
+// api gateway. RoleNames are the user's provider groups mapped to roles.
+type SSOLogin struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	RoleNames     []string
+	AutoProvision bool
+}
+
+type SSOUsecaseInterface interface {
+	Login(ctx context.Context, login SSOLogin) (*model.User, error)
+}
+
+type ssoUsecase struct {
+	identityRepo repository.IdentityRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	roleRepo     repository.RoleRepositoryInterface
+}
+
+// ────────────────────────────────────────────────────────────────
+// Login implements SSOUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (s *ssoUsecase) Login(ctx context.Context, login SSOLogin) (*model.User, error) {
+	login.Email = strings.TrimSpace(login.Email)
+	now := time.Now()
+
+	user, identity, err := s.findUser(ctx, login, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+
+	if err := s.syncRoles(ctx, user, login.RoleNames); err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.TouchIdentity(ctx, identity.ID, login.Email, now); err != nil {
+		log.Errorf("[SSOUsecase] Login - 1: %v", err)
+	}
+
+	// Loaded again for the roles and permissions of the token.
+	user, err = s.userRepo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Errorf("[SSOUsecase] Login - 2: %v", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// findUser returns the user linked to the provider account. The first login
+// links a user with the same, verified, address or creates one.
+func (s *ssoUsecase) findUser(ctx context.Context, login SSOLogin, now time.Time) (*model.User, *model.UserIdentity, error) {
+	identity, err := s.identityRepo.GetIdentity(ctx, login.Issuer, login.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			log.Errorf("[SSOUsecase] findUser - 1: %v", err)
+			return nil, nil, err
+		}
+		return user, identity, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("[SSOUsecase] findUser - 2: %v", err)
+		return nil, nil, err
+	}
+
+	if login.Email == "" {
+		return nil, nil, ErrSSOEmailRequired
+	}
+
+	identity = &model.UserIdentity{
+		Issuer:      login.Issuer,
+		Subject:     login.Subject,
+		Email:       login.Email,
+		LastLoginAt: now,
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, login.Email)
+	if err == nil {
+		if !login.EmailVerified {
+			return nil, nil, ErrSSOEmailNotVerified
+		}
+
+		identity.UserID = user.ID
+		if err := s.identityRepo.CreateIdentity(ctx, identity); err != nil {
+			log.Errorf("[SSOUsecase] findUser - 3: %v", err)
+			return nil, nil, err
+		}
+		return user, identity, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("[SSOUsecase] findUser - 4: %v", err)
+		return nil, nil, err
+	}
+
+	if !login.AutoProvision {
+		return nil, nil, ErrSSOUserNotFound
+	}
+
+	// Without a password the account can only be used through the provider.
+	user = &model.User{
+		Name:  login.Name,
+		Email: login.Email,
+	}
+	if user.Name == "" {
+		user.Name = login.Email
+	}
+	if err := s.identityRepo.CreateUserWithIdentity(ctx, user, identity); err != nil {
+		log.Errorf("[SSOUsecase] findUser - 5: %v", err)
+		return nil, nil, err
+	}
+
+	return user, identity, nil
+}
+
+// syncRoles gives the user exactly the roles mapped from the provider's
+// groups. Roles are left alone when no group maps to an existing role, so
+// SSO can be turned on before the group mapping is complete.
+func (s *ssoUsecase) syncRoles(ctx context.Context, user *model.User, roleNames []string) error {
+	if len(roleNames) == 0 {
+		return nil
+	}
+
+	roles, err := s.roleRepo.GetAllRoles(ctx)
+	if err != nil {
+		log.Errorf("[SSOUsecase] syncRoles - 1: %v", err)
+		return err
+	}
+	rolesByName := make(map[string]model.Role, len(roles))
+	for _, role := range roles {
+		rolesByName[strings.ToLower(role.Name)] = role
+	}
+
+	roleIDs := []uint{}
+	seen := make(map[uint]bool)
+	for _, name := range roleNames {
+		role, ok := rolesByName[strings.ToLower(name)]
+		if !ok {
+			log.Warnf("[SSOUsecase] syncRoles - role %q of the group mapping does not exist", name)
+			continue
+		}
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	currentIDs := make([]uint, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		currentIDs = append(currentIDs, role.ID)
+	}
+	if sameIDs(currentIDs, roleIDs) {
+		return nil
+	}
+
+	if err := s.userRepo.ReplaceUserRoles(ctx, user.ID, roleIDs); err != nil {
+		log.Errorf("[SSOUsecase] syncRoles - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func sameIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]uint(nil), a...)
+	b = append([]uint(nil), b...)
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func NewSSOUsecase(identityRepo repository.IdentityRepositoryInterface, userRepo repository.UserRepositoryInterface, roleRepo repository.RoleRepositoryInterface) SSOUsecaseInterface {
+	return &ssoUsecase{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+	}
+}
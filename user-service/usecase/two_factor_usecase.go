@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"warehouse-go/user-service/configs"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/totp"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment has not been started")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required by one of your roles")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// TwoFactorEnrollment is what an authenticator app needs to add the account.
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+type TwoFactorUsecaseInterface interface {
+	Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, password string) error
+	Reset(ctx context.Context, userID uint) error
+}
+
+type twoFactorUsecase struct {
+	twoFactorRepo repository.TwoFactorRepositoryInterface
+	userRepo      repository.UserRepositoryInterface
+	issuer        string
+}
+
+// ────────────────────────────────────────────────────────────────
+// Enroll implements TwoFactorUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorUsecase) Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error) {
+	user, err := t.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Errorf("[TwoFactorUsecase] Enroll - 1: %v", err)
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Errorf("[TwoFactorUsecase] Enroll - 2: %v", err)
+		return nil, err
+	}
+
+	twoFactor := model.TwoFactor{
+		UserID: user.ID,
+		Secret: secret,
+	}
+	if err := t.twoFactorRepo.SavePendingTwoFactor(ctx, &twoFactor); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		log.Errorf("[TwoFactorUsecase] Enroll - 3: %v", err)
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(t.issuer, user.Email, secret),
+	}, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Confirm implements TwoFactorUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorUsecase) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	twoFactor, err := t.twoFactorRepo.GetTwoFactorByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		log.Errorf("[TwoFactorUsecase] Confirm - 1: %v", err)
+		return nil, err
+	}
+	if twoFactor.IsConfirmed() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	// The first code proves the app holds the secret before login starts
+	// asking for it.
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		log.Errorf("[TwoFactorUsecase] Confirm - 2: %v", err)
+		return nil, err
+	}
+
+	if err := t.twoFactorRepo.ConfirmTwoFactor(ctx, userID, step, hashes, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		log.Errorf("[TwoFactorUsecase] Confirm - 3: %v", err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Disable implements TwoFactorUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorUsecase) Disable(ctx context.Context, userID uint, password string) error {
+	user, err := t.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Errorf("[TwoFactorUsecase] Disable - 1: %v", err)
+		return err
+	}
+
+	if !conv.CheckPasswordHash(password, user.Password) {
+		return ErrWrongPassword
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if user.RequiresTwoFactor() {
+		return ErrTwoFactorRequired
+	}
+
+	if err := t.twoFactorRepo.DeleteTwoFactor(ctx, userID); err != nil {
+		log.Errorf("[TwoFactorUsecase] Disable - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// Reset implements TwoFactorUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (t *twoFactorUsecase) Reset(ctx context.Context, userID uint) error {
+	if _, err := t.userRepo.GetUserByID(ctx, userID); err != nil {
+		log.Errorf("[TwoFactorUsecase] Reset - 1: %v", err)
+		return err
+	}
+
+	// For lost devices: the user logs in with the password alone and, if a
+	// role requires it, has to enroll again before doing anything else.
+	if err := t.twoFactorRepo.DeleteTwoFactor(ctx, userID); err != nil {
+		log.Errorf("[TwoFactorUsecase] Reset - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// newRecoveryCodes returns the codes shown to the user once and the hashes
+// that are stored.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret := make([]byte, 5)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(secret)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts the code with or without the dash and in
+// any case, the way it gets typed in.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func NewTwoFactorUsecase(twoFactorRepo repository.TwoFactorRepositoryInterface, userRepo repository.UserRepositoryInterface, cfg configs.Auth) TwoFactorUsecaseInterface {
+	issuer := cfg.TwoFactorIssuer
+	if issuer == "" {
+		issuer = "Warehouse"
+	}
+
+	return &twoFactorUsecase{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+	}
+}
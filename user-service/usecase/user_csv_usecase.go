@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// maxImportRows keeps an import within one request and one transaction.
+const maxImportRows = 1000
+
+var (
+	ErrInvalidCSV = errors.New("invalid csv")
+	// ErrRoleAssignmentForbidden is returned for a file that assigns roles,
+	// sent by a caller who may not manage roles.
+	ErrRoleAssignmentForbidden = errors.New("assigning roles requires the role:manage permission")
+)
+
+// An import matches columns by header name and ignores the ones it does not
+// know, so an export can be edited and imported again. roles holds role
+// names separated by ";".
+var (
+	importRequiredColumns = []string{"name", "email", "phone"}
+	exportColumns         = []string{"id", "name", "email", "phone", "roles", "status", "photo", "created_at", "deactivated_at"}
+)
+
+// exportStatusInvited is the status of users who have not accepted their
+// invitation yet.
+const exportStatusInvited = "invited"
+
+type ImportRowError struct {
+	Row    int
+	Email  string
+	Errors []string
+}
+
+type ImportedUser struct {
+	Row          int
+	UserID       uint
+	InvitationID uint
+	Name         string
+	Email        string
+	Roles        []string
+}
+
+// ImportReport lists the users of an import. Nothing is created while Errors
+// is not empty, or for a dry run.
+type ImportReport struct {
+	DryRun    bool
+	TotalRows int
+	Users     []ImportedUser
+	Errors    []ImportRowError
+}
+
+type UserCSVUsecaseInterface interface {
+	ImportUsers(ctx context.Context, r io.Reader, createdBy uint, dryRun, canAssignRoles bool) (*ImportReport, error)
+	ExportUsers(ctx context.Context, w io.Writer, search, status string) error
+}
+
+type userCSVUsecase struct {
+	userRepo          repository.UserRepositoryInterface
+	roleRepo          repository.RoleRepositoryInterface
+	invitationUsecase InvitationUsecaseInterface
+}
+
+type importRow struct {
+	row   int
+	user  model.User
+	roles []string
+}
+
+// ────────────────────────────────────────────────────────────────
+// ImportUsers implements UserCSVUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (u *userCSVUsecase) ImportUsers(ctx context.Context, r io.Reader, createdBy uint, dryRun, canAssignRoles bool) (*ImportReport, error) {
+	rows, err := readImportRows(r)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.roleRepo.GetAllRoles(ctx)
+	if err != nil {
+		log.Errorf("[UserCSVUsecase] ImportUsers - 1: %v", err)
+		return nil, err
+	}
+	rolesByName := make(map[string]model.Role, len(roles))
+	for _, role := range roles {
+		rolesByName[strings.ToLower(role.Name)] = role
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, strings.ToLower(row.user.Email))
+	}
+	existing, err := u.userRepo.GetExistingEmails(ctx, emails)
+	if err != nil {
+		log.Errorf("[UserCSVUsecase] ImportUsers - 2: %v", err)
+		return nil, err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		taken[email] = true
+	}
+
+	report := &ImportReport{DryRun: dryRun, TotalRows: len(rows)}
+	seen := make(map[string]int, len(rows))
+	users := make([]model.User, 0, len(rows))
+	for _, row := range rows {
+		if len(row.roles) > 0 && !canAssignRoles {
+			return nil, ErrRoleAssignmentForbidden
+		}
+
+		var rowErrors []string
+		if row.user.Name == "" {
+			rowErrors = append(rowErrors, "name is required")
+		}
+		if row.user.Phone == "" {
+			rowErrors = append(rowErrors, "phone is required")
+		}
+
+		email := strings.ToLower(row.user.Email)
+		switch {
+		case row.user.Email == "":
+			rowErrors = append(rowErrors, "email is required")
+		case !isEmailAddress(row.user.Email):
+			rowErrors = append(rowErrors, "email is not a valid email address")
+		case taken[email]:
+			rowErrors = append(rowErrors, "email is already used by another user")
+		case seen[email] != 0:
+			rowErrors = append(rowErrors, fmt.Sprintf("email is already used in row %d", seen[email]))
+		}
+		if email != "" && seen[email] == 0 {
+			seen[email] = row.row
+		}
+
+		for _, name := range row.roles {
+			role, ok := rolesByName[strings.ToLower(name)]
+			if !ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("role %q does not exist", name))
+				continue
+			}
+			row.user.Roles = append(row.user.Roles, model.Role{ID: role.ID, Name: role.Name})
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, ImportRowError{Row: row.row, Email: row.user.Email, Errors: rowErrors})
+			continue
+		}
+
+		users = append(users, row.user)
+		report.Users = append(report.Users, ImportedUser{
+			Row:   row.row,
+			Name:  row.user.Name,
+			Email: row.user.Email,
+			Roles: roleNames(row.user.Roles),
+		})
+	}
+
+	if len(report.Errors) > 0 || dryRun {
+		return report, nil
+	}
+
+	invitations, err := u.invitationUsecase.InviteUsers(ctx, users, createdBy)
+	if err != nil {
+		log.Errorf("[UserCSVUsecase] ImportUsers - 3: %v", err)
+		return nil, err
+	}
+	for idx, invitation := range invitations {
+		report.Users[idx].UserID = invitation.UserID
+		report.Users[idx].InvitationID = invitation.ID
+	}
+
+	return report, nil
+}
+
+// ────────────────────────────────────────────────────────────────
+// ExportUsers implements UserCSVUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (u *userCSVUsecase) ExportUsers(ctx context.Context, w io.Writer, search, status string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		log.Errorf("[UserCSVUsecase] ExportUsers - 1: %v", err)
+		return err
+	}
+
+	// Oldest first, so users created while exporting end up on the last
+	// page instead of shifting the others.
+	const pageSize = 100
+	for page := 1; ; page++ {
+		users, total, err := u.userRepo.GetAllUsers(ctx, page, pageSize, search, status, "id", "asc")
+		if err != nil {
+			log.Errorf("[UserCSVUsecase] ExportUsers - 2: %v", err)
+			return err
+		}
+
+		for _, user := range users {
+			if err := writer.Write(exportRecord(user)); err != nil {
+				log.Errorf("[UserCSVUsecase] ExportUsers - 3: %v", err)
+				return err
+			}
+		}
+
+		if len(users) < pageSize || int64(page*pageSize) >= total {
+			break
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readImportRows reads the header and the rows below it. Blank lines are
+// skipped; rows are numbered by their line in the file.
+func readImportRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidCSV)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		// Spreadsheets like to start the file with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: column %q is missing", ErrInvalidCSV, name)
+		}
+	}
+
+	cell := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return unescapeCSVCell(strings.TrimSpace(record[idx]))
+	}
+
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d users can be imported at once", ErrInvalidCSV, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow{
+			row: line,
+			user: model.User{
+				Name:  cell(record, "name"),
+				Email: cell(record, "email"),
+				Phone: cell(record, "phone"),
+				Photo: cell(record, "photo"),
+			},
+			roles: splitRoles(cell(record, "roles")),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no users", ErrInvalidCSV)
+	}
+	return rows, nil
+}
+
+func exportRecord(user model.User) []string {
+	status := model.UserStatusActive
+	switch {
+	case !user.IsActive():
+		status = model.UserStatusInactive
+	case user.Password == "":
+		status = exportStatusInvited
+	}
+
+	deactivatedAt := ""
+	if user.DeactivatedAt != nil {
+		deactivatedAt = user.DeactivatedAt.Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		escapeCSVCell(user.Name),
+		escapeCSVCell(user.Email),
+		escapeCSVCell(user.Phone),
+		escapeCSVCell(strings.Join(roleNames(user.Roles), ";")),
+		status,
+		escapeCSVCell(user.Photo),
+		user.CreatedAt.Format(time.RFC3339),
+		deactivatedAt,
+	}
+}
+
+// escapeCSVCell keeps spreadsheets from running a cell as a formula. Phone
+// numbers like +62... are left alone.
+func escapeCSVCell(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if len(value) == 1 || value[1] < '0' || value[1] > '9' {
+			return "'" + value
+		}
+	}
+	return value
+}
+
+// unescapeCSVCell undoes escapeCSVCell for a file that went through an
+// export.
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && escapeCSVCell(value[1:]) == value {
+		return value[1:]
+	}
+	return value
+}
+
+func splitRoles(value string) []string {
+	roles := []string{}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ";") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		roles = append(roles, name)
+	}
+	return roles
+}
+
+func roleNames(roles []model.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func isEmailAddress(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func NewUserCSVUsecase(userRepo repository.UserRepositoryInterface, roleRepo repository.RoleRepositoryInterface, invitationUsecase InvitationUsecaseInterface) UserCSVUsecaseInterface {
+	return &userCSVUsecase{
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		invitationUsecase: invitationUsecase,
+	}
+}
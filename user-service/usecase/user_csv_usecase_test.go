@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"warehouse-go/user-service/model"
+	"warehouse-go/user-service/repository"
+)
+
+func TestReadImportRows(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		csv    string
+		rows   []int
+		emails []string
+	}{
+		{
+			name:   "rows numbered by line",
+			csv:    "name,email,phone\nAna,ana@mail.com,0811\nBudi,budi@mail.com,0812\n",
+			rows:   []int{2, 3},
+			emails: []string{"ana@mail.com", "budi@mail.com"},
+		},
+		{
+			name:   "byte order mark before the header",
+			csv:    "\ufeffname,email,phone\nAna,ana@mail.com,0811\n",
+			rows:   []int{2},
+			emails: []string{"ana@mail.com"},
+		},
+		{
+			name:   "blank lines skipped, numbering kept",
+			csv:    "name,email,phone\n\nAna,ana@mail.com,0811\n,,\n\nBudi,budi@mail.com,0812\n",
+			rows:   []int{3, 6},
+			emails: []string{"ana@mail.com", "budi@mail.com"},
+		},
+		{
+			name:   "columns by header name, in any order and case",
+			csv:    "Phone, EMAIL ,id,Name\n0811,ana@mail.com,9,Ana\n",
+			rows:   []int{2},
+			emails: []string{"ana@mail.com"},
+		},
+		{
+			name:   "quoted cell over two lines",
+			csv:    "name,email,phone\n\"Ana\nMaria\",ana@mail.com,0811\nBudi,budi@mail.com,0812\n",
+			rows:   []int{2, 4},
+			emails: []string{"ana@mail.com", "budi@mail.com"},
+		},
+	} {
+		rows, err := readImportRows(strings.NewReader(tc.csv))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if len(rows) != len(tc.rows) {
+			t.Errorf("%s: %d rows, want %d", tc.name, len(rows), len(tc.rows))
+			continue
+		}
+		for i, row := range rows {
+			if row.row != tc.rows[i] || row.user.Email != tc.emails[i] {
+				t.Errorf("%s: row %d is line %d %s, want line %d %s", tc.name, i, row.row, row.user.Email, tc.rows[i], tc.emails[i])
+			}
+		}
+	}
+}
+
+func TestReadImportRowsRejects(t *testing.T) {
+	full := "name,email,phone\n" + strings.Repeat("Ana,ana@mail.com,0811\n", maxImportRows)
+
+	for _, tc := range []struct {
+		name string
+		csv  string
+		err  string
+	}{
+		{"empty file", "", "the file is empty"},
+		{"header only", "name,email,phone\n", "the file has no users"},
+		{"header and blank lines", "name,email,phone\n\n,,\n", "the file has no users"},
+		{"missing column", "name,email\nAna,ana@mail.com\n", `column "phone" is missing`},
+		{"too many rows", full + "Budi,budi@mail.com,0812\n", "at most 1000 users"},
+		{"unterminated quote", "name,email,phone\n\"Ana,ana@mail.com,0811\n", "invalid csv"},
+	} {
+		_, err := readImportRows(strings.NewReader(tc.csv))
+		if !errors.Is(err, ErrInvalidCSV) || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error %v, want ErrInvalidCSV with %q", tc.name, err, tc.err)
+		}
+	}
+
+	if rows, err := readImportRows(strings.NewReader(full + "\n,,\n")); err != nil || len(rows) != maxImportRows {
+		t.Errorf("exactly %d rows and trailing blanks: %d rows, %v", maxImportRows, len(rows), err)
+	}
+}
+
+func TestEscapeCSVCell(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		escaped string
+	}{
+		{"", ""},
+		{"Ana", "Ana"},
+		{"=HYPERLINK(\"x\")", "'=HYPERLINK(\"x\")"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"+62811", "+62811"},
+		{"-5", "-5"},
+		{"+", "'+"},
+		{"-cmd", "'-cmd"},
+		{"\tx", "'\tx"},
+		{"'quoted", "'quoted"},
+		{"''=x", "''=x"},
+	} {
+		escaped := escapeCSVCell(tc.value)
+		if escaped != tc.escaped {
+			t.Errorf("escapeCSVCell(%q) = %q, want %q", tc.value, escaped, tc.escaped)
+		}
+		if back := unescapeCSVCell(escaped); back != tc.value {
+			t.Errorf("unescapeCSVCell(%q) = %q, want %q", escaped, back, tc.value)
+		}
+	}
+}
+
+type fakeRoleRepo struct {
+	repository.RoleRepositoryInterface
+	roles []model.Role
+}
+
+func (f *fakeRoleRepo) GetAllRoles(ctx context.Context) ([]model.Role, error) {
+	return f.roles, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+}
+
+func (f *fakeUserRepo) GetExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	return nil, nil
+}
+
+func TestImportUsersRoleColumns(t *testing.T) {
+	usecase := NewUserCSVUsecase(&fakeUserRepo{}, &fakeRoleRepo{roles: []model.Role{{ID: 2, Name: "Keeper"}}}, nil)
+
+	for _, tc := range []struct {
+		name           string
+		csv            string
+		canAssignRoles bool
+		err            error
+		roles          []string
+	}{
+		{"roles without role:manage", "name,email,phone,roles\nAna,ana@mail.com,0811,Keeper\n", false, ErrRoleAssignmentForbidden, nil},
+		{"roles in a later row", "name,email,phone,roles\nAna,ana@mail.com,0811,\nBudi,budi@mail.com,0812,keeper\n", false, ErrRoleAssignmentForbidden, nil},
+		{"empty roles column", "name,email,phone,roles\nAna,ana@mail.com,0811, ; \n", false, nil, []string{}},
+		{"roles with role:manage", "name,email,phone,roles\nAna,ana@mail.com,0811,keeper\n", true, nil, []string{"Keeper"}},
+	} {
+		report, err := usecase.ImportUsers(context.Background(), strings.NewReader(tc.csv), 1, true, tc.canAssignRoles)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: error %v, want %v", tc.name, err, tc.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(report.Errors) != 0 || len(report.Users) != 1 {
+			t.Fatalf("%s: report %+v, want one user", tc.name, report)
+		}
+		if got := strings.Join(report.Users[0].Roles, ","); got != strings.Join(tc.roles, ",") {
+			t.Errorf("%s: roles %q, want %q", tc.name, got, tc.roles)
+		}
+	}
+}
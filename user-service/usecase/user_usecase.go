@@ -2,21 +2,40 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 	"warehouse-go/user-service/model"
-	"warehouse-go/user-service/pkg/conv"
+	"warehouse-go/user-service/pkg/httpclient"
 	"warehouse-go/user-service/repository"
-	"warehouse-go/user-service/service"
 
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrUserAlreadyDeactivated = errors.New("user is already deactivated")
+	ErrUserNotDeactivated     = errors.New("user is not deactivated")
+)
+
+// KeeperAssignedError is returned by DeactivateUser for a keeper still
+// assigned to merchants, who have to be handed to someone else first.
+type KeeperAssignedError struct {
+	MerchantIDs []uint
+}
+
+func (e *KeeperAssignedError) Error() string {
+	return fmt.Sprintf("user is still the keeper of %d merchant(s)", len(e.MerchantIDs))
+}
+
 type UserUsecaseInterface interface {
-	CreateUser(ctx context.Context, user model.User) error
-	GetAllUsers(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.User, int64, error)
+	GetAllUsers(ctx context.Context, page, limit int, search, status, sortBy, sortOrder string) ([]model.User, int64, error)
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateUser(ctx context.Context, user model.User) error
-	DeleteUser(ctx context.Context, id uint) error
+	UpdateProfile(ctx context.Context, id uint, name, phone, photo string) (*model.User, error)
+	DeactivateUser(ctx context.Context, id uint) error
+	RestoreUser(ctx context.Context, id uint) error
 
 	GetUserByRoleName(ctx context.Context, roleName string) ([]model.User, error)
 
@@ -27,8 +46,10 @@ type UserUsecaseInterface interface {
 }
 
 type userUsecase struct {
-	userRepo        repository.UserRepositoryInterface
-	rabbitMQService service.RabbitMQServiceInterface
+	userRepo       repository.UserRepositoryInterface
+	sessionRepo    repository.SessionRepositoryInterface
+	apiKeyRepo     repository.APIKeyRepositoryInterface
+	merchantClient httpclient.MerchantClientInterface
 }
 
 // GetUserRoleByID implements UserUsecaseInterface.
@@ -44,52 +65,69 @@ func (u *userUsecase) AssignUserToRole(ctx context.Context, userID uint, roleID
 }
 
 // ────────────────────────────────────────────────────────────────
-// CreateUser implements UserUsecaseInteface
+// DeactivateUser Implements UserUsecaseInterface
 // ────────────────────────────────────────────────────────────────
-func (u *userUsecase) CreateUser(ctx context.Context, user model.User) error {
-	password, err := conv.HashPassword(user.Password)
+func (u *userUsecase) DeactivateUser(ctx context.Context, id uint) error {
+	user, err := u.userRepo.GetUserByID(ctx, id)
 	if err != nil {
-		log.Errorf("[UserUsecase] CreateUser - 1: %v", err)
+		log.Errorf("[UserUsecase] DeactivateUser - 1: %v", err)
 		return err
 	}
+	if !user.IsActive() {
+		return ErrUserAlreadyDeactivated
+	}
 
-	uncryptedPassword := user.Password
-	user.Password = password
-	result, err := u.userRepo.CreateUser(ctx, user)
+	// Merchants must not be left without a keeper. When merchant-service
+	// cannot answer the user stays active.
+	merchantIDs, err := u.merchantClient.GetMerchantIDsByKeeperID(ctx, id)
 	if err != nil {
-		log.Errorf("[UserUsecase] CreateUser - 2: %v", err)
+		log.Errorf("[UserUsecase] DeactivateUser - 2: %v", err)
 		return err
 	}
-
-	emailPayload := service.EmailPayload{
-		Email:    result.Email,
-		Password: uncryptedPassword,
-		Type:     "welcome_email",
-		UserID:   uint(result.ID),
-		Name:     result.Name,
+	if len(merchantIDs) > 0 {
+		return &KeeperAssignedError{MerchantIDs: merchantIDs}
 	}
 
-	go func() {
-		if err := u.rabbitMQService.PublishEmail(ctx, emailPayload); err != nil {
-			log.Errorf("[UserUsecase] CreateUser - 3: %v", err)
+	now := time.Now()
+	if err := u.userRepo.DeactivateUser(ctx, id, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserAlreadyDeactivated
 		}
-	}()
+		log.Errorf("[UserUsecase] DeactivateUser - 3: %v", err)
+		return err
+	}
+
+	// Signing out every session makes the gateway refuse the user's tokens
+	// once its cached session checks run out.
+	if err := u.sessionRepo.RevokeUserSessions(ctx, id, now); err != nil {
+		log.Errorf("[UserUsecase] DeactivateUser - 4: %v", err)
+		return err
+	}
+
+	// Keys act as the user who created them. Restoring the user does not
+	// bring them back, new ones have to be issued.
+	if err := u.apiKeyRepo.RevokeUserAPIKeys(ctx, id, now); err != nil {
+		log.Errorf("[UserUsecase] DeactivateUser - 5: %v", err)
+		return err
+	}
 
 	return nil
 }
 
 // ────────────────────────────────────────────────────────────────
-// DeleteUser Implements UserRepository Inteface
+// RestoreUser Implements UserUsecaseInterface
 // ────────────────────────────────────────────────────────────────
-func (u *userUsecase) DeleteUser(ctx context.Context, id uint) error {
-	_, err := u.userRepo.GetUserByID(ctx, id)
-	if err != nil {
-		log.Errorf("[UserUsecase] DeleteUser - 1: %v", err)
+func (u *userUsecase) RestoreUser(ctx context.Context, id uint) error {
+	if _, err := u.userRepo.GetUserByID(ctx, id); err != nil {
+		log.Errorf("[UserUsecase] RestoreUser - 1: %v", err)
 		return err
 	}
 
-	if err := u.userRepo.DeleteUser(ctx, id); err != nil {
-		log.Errorf("[UserUsecase] DeleteUser - 2: %v", err)
+	if err := u.userRepo.RestoreUser(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotDeactivated
+		}
+		log.Errorf("[UserUsecase] RestoreUser - 2: %v", err)
 		return err
 	}
 
@@ -113,7 +151,7 @@ func (u *userUsecase) GetAllUserRoles(ctx context.Context, page, limit int, sear
 // ────────────────────────────────────────────────────────────────
 // GetAllUsers Implements UserRepository Interface
 // ────────────────────────────────────────────────────────────────
-func (u *userUsecase) GetAllUsers(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.User, int64, error) {
+func (u *userUsecase) GetAllUsers(ctx context.Context, page, limit int, search, status, sortBy, sortOrder string) ([]model.User, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -122,7 +160,7 @@ func (u *userUsecase) GetAllUsers(ctx context.Context, page, limit int, search,
 	}
 
 	//Get Users fromr repository
-	users, totalRecords, err := u.userRepo.GetAllUsers(ctx, page, limit, search, sortBy, sortOrder)
+	users, totalRecords, err := u.userRepo.GetAllUsers(ctx, page, limit, search, status, sortBy, sortOrder)
 	if err != nil {
 		log.Errorf("[UserUsecase] GetAllUsers - 1: %v", err)
 		return nil, 0, err
@@ -163,6 +201,34 @@ func (u *userUsecase) UpdateUser(ctx context.Context, user model.User) error {
 	return nil
 }
 
-func NewUserUsecase(userRepo repository.UserRepositoryInterface, rabbitMQService service.RabbitMQServiceInterface) UserUsecaseInterface {
-	return &userUsecase{userRepo: userRepo, rabbitMQService: rabbitMQService}
+// ────────────────────────────────────────────────────────────────
+// UpdateProfile Implements UserUsecaseInterface
+// ────────────────────────────────────────────────────────────────
+func (u *userUsecase) UpdateProfile(ctx context.Context, id uint, name, phone, photo string) (*model.User, error) {
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		log.Errorf("[UserUsecase] UpdateProfile - 1: %v", err)
+		return nil, err
+	}
+
+	// Users edit only these about themselves, the email and roles stay as a
+	// Manager set them.
+	user.Name = name
+	user.Phone = phone
+	user.Photo = photo
+	if err := u.userRepo.UpdateUser(ctx, model.User{ID: user.ID, Name: user.Name, Email: user.Email, Phone: user.Phone, Photo: user.Photo}); err != nil {
+		log.Errorf("[UserUsecase] UpdateProfile - 2: %v", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func NewUserUsecase(userRepo repository.UserRepositoryInterface, sessionRepo repository.SessionRepositoryInterface, apiKeyRepo repository.APIKeyRepositoryInterface, merchantClient httpclient.MerchantClientInterface) UserUsecaseInterface {
+	return &userUsecase{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		apiKeyRepo:     apiKeyRepo,
+		merchantClient: merchantClient,
+	}
 }
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"context"
 	"log"
 	"time"
+	"warehouse-go/shared/audit"
 	"warehouse-go/warehouse-service/configs"
 	"warehouse-go/warehouse-service/controller"
 	"warehouse-go/warehouse-service/database"
@@ -19,6 +21,7 @@ type Container struct {
 	WarehouseProductController controller.WarehouseProductControllerInterface
 	UploadController controller.UploadControllerInterface
 	RabbitMQConsumer *rabbitmq.RabbitMQConsumer
+	AuditTracker *audit.Tracker
 }
 
 func BuildContainer() *Container {
@@ -27,6 +30,11 @@ func BuildContainer() *Container {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if !config.SqlDB.SkipMigrations {
+		if err := database.Migrate(context.Background(), db.DB); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 	productClient := httpclient.NewProductClient(*config)
 	redisClient := redis.NewRedisClient(*config)
 	cacheProductClient := httpclient.NewCachedProductClient(productClient, redisClient, 1 *time.Hour)
@@ -36,10 +44,10 @@ func BuildContainer() *Container {
 	warehouseController := controller.NewWarehouseController(warehouseUsecase)
 
 	warehouseProductRepo := repository.NewWarehouseProductRepository(db.DB)
-	warehouseProductUsecase := usecase.NewWarehouseProductUsecase(warehouseProductRepo, cacheProductClient)
+	warehouseProductUsecase := usecase.NewWarehouseProductUsecase(warehouseProductRepo, warehouseRepo, cacheProductClient)
 	warehouseProductController := controller.NewWarehouseProductController(warehouseProductUsecase)
 
-	rabbitMQConsumer, err := rabbitmq.NewRabbitMQConsumer(config.RabbitMQ.URL(), warehouseProductRepo)
+	rabbitMQConsumer, err := rabbitmq.NewRabbitMQConsumer(config.RabbitMQ.URL(), warehouseProductRepo, config.App.LowStockThreshold)
 	if err != nil {
 		log.Fatalf("Failed to create rabbitmq consumer: %v", err)
 	}
@@ -49,12 +57,24 @@ func BuildContainer() *Container {
 	fileUploadHelper := storage.NewUploadFileHelper(supabaseStorage, *config)
 	uploadController := controller.NewFileUploadController(fileUploadHelper)
 
-	 
+	auditPublisher, err := audit.NewPublisher(config.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to create rabbitmq audit publisher: %v", err)
+	}
+
+	auditTracker := audit.NewTracker("warehouse-service", auditPublisher)
+	auditTracker.Register("warehouse", func(ctx context.Context, id uint) (interface{}, error) {
+		return warehouseRepo.GetWarehouseByID(ctx, id)
+	})
+	auditTracker.Register("warehouse-product", func(ctx context.Context, id uint) (interface{}, error) {
+		return warehouseProductRepo.GetDetailWarehouseProductByID(ctx, id)
+	})
 
 	return &Container{
 		WarehouseController: warehouseController,
 		WarehouseProductController: warehouseProductController,
 		UploadController: uploadController,
 		RabbitMQConsumer: rabbitMQConsumer,
+		AuditTracker: auditTracker,
 	}
 }
\ No newline at end of file
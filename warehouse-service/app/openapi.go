@@ -0,0 +1,36 @@
+package app
+
+import (
+	"warehouse-go/warehouse-service/controller/request"
+	"warehouse-go/warehouse-service/controller/response"
+	"warehouse-go/warehouse-service/pkg/openapi"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "Warehouse Service",
+	Version:     "1.0.0",
+	Description: "Warehouses and their product stock",
+}
+
+// openAPIOperations documents the request and response types of the routes
+// registered in SetupRoutes.
+var openAPIOperations = []openapi.Operation{
+	{Method: "POST", Path: "/api/v1/warehouses", Summary: "Create a warehouse", Tags: []string{"warehouses"}, Request: request.CreateWarehouseRequest{}},
+	{Method: "GET", Path: "/api/v1/warehouses", Summary: "List warehouses", Tags: []string{"warehouses"}, Query: request.GetAllWarehouseRequest{}, Response: response.GetAllWarehouseResponse{}},
+	{Method: "GET", Path: "/api/v1/warehouses/:id", Summary: "Get a warehouse with its products", Tags: []string{"warehouses"}, Response: response.DetailWarehouseResponse{}},
+	{Method: "PUT", Path: "/api/v1/warehouses/:id", Summary: "Update a warehouse", Tags: []string{"warehouses"}, Request: request.CreateWarehouseRequest{}},
+	{Method: "DELETE", Path: "/api/v1/warehouses/:id", Summary: "Delete a warehouse", Tags: []string{"warehouses"}},
+	{Method: "PUT", Path: "/api/v1/warehouses/:id/staff", Summary: "Replace the staff of a warehouse", Tags: []string{"warehouses"}, Request: request.ReplaceWarehouseStaffRequest{}},
+
+	{Method: "POST", Path: "/api/v1/warehouse-products/:warehouse_id", Summary: "Add stock to a warehouse", Tags: []string{"warehouse-products"}, Request: request.CreateWarehouseProductRequest{}},
+	{Method: "GET", Path: "/api/v1/warehouse-products/:warehouse_id", Summary: "List the products of a warehouse", Tags: []string{"warehouse-products"}, Response: response.DetailWarehouseResponse{}},
+	{Method: "GET", Path: "/api/v1/warehouse-products/:warehouse_id/detail/:product_id", Summary: "Get a product in a warehouse", Tags: []string{"warehouse-products"}, Query: request.GetWarehouseProductStockRequest{}, Response: response.GetDetailWarehouseProductByIDResponse{}},
+	{Method: "PUT", Path: "/api/v1/warehouse-products/detail/:warehouse_product_id", Summary: "Update a warehouse product", Tags: []string{"warehouse-products"}, Request: request.CreateWarehouseProductRequest{}},
+	{Method: "DELETE", Path: "/api/v1/warehouse-products/detail/:warehouse_product_id", Summary: "Delete a warehouse product", Tags: []string{"warehouse-products"}},
+	{Method: "DELETE", Path: "/api/v1/warehouse-products/detail/products/:product_id", Summary: "Delete a product from every warehouse", Tags: []string{"warehouse-products"}},
+	{Method: "GET", Path: "/api/v1/warehouse-products/detail/products/:product_id/total-stock", Summary: "Warehouses holding a product", Tags: []string{"warehouse-products"}, Response: []response.WarehouseResponse{}},
+	{Method: "GET", Path: "/api/v1/warehouse-products/detail/products/:product_id", Summary: "Total stock of a product across warehouses", Tags: []string{"warehouse-products"}, Response: response.ProductTotalStockResponse{}},
+	{Method: "GET", Path: "/api/v1/warehouse-products/detail/products/:product_id/warehouses", Summary: "Get a warehouse product", Tags: []string{"warehouse-products"}, Response: response.GetDetailWarehouseProductByIDResponse{}},
+
+	{Method: "POST", Path: "/api/v1/upload-warehouse", Summary: "Upload a warehouse photo", Tags: []string{"upload"}, Upload: true, Response: response.UploadResponse{}},
+}
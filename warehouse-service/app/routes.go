@@ -1,26 +1,41 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"warehouse-go/warehouse-service/pkg/middleware"
+	"warehouse-go/warehouse-service/pkg/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, c *Container) {
-	api := app.Group("/api/v1")
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{
+			"status":  "OK",
+			"service": "warehouse-service",
+		})
+	})
+	app.Get("/openapi.json", openapi.Handler(app, openAPIInfo, openAPIOperations))
+
+	api := app.Group("/api/v1", middleware.RequireIdentity())
+	track := c.AuditTracker
 
 	warehouses := api.Group("/warehouses")
-	warehouses.Post("/", c.WarehouseController.CreateWarehouse)
+	warehouses.Post("/", middleware.RequireUnscoped(), track.Track("warehouse", ""), c.WarehouseController.CreateWarehouse)
 	warehouses.Get("/", c.WarehouseController.GetAllWarehouses)
 	warehouses.Get("/:id", c.WarehouseController.GetWarehouseByID)
-	warehouses.Put("/:id", c.WarehouseController.UpdateWarehouse)
-	warehouses.Delete("/:id", c.WarehouseController.DeleteWarehouse)
+	warehouses.Put("/:id", track.Track("warehouse", "id"), c.WarehouseController.UpdateWarehouse)
+	warehouses.Delete("/:id", track.Track("warehouse", "id"), c.WarehouseController.DeleteWarehouse)
+	warehouses.Put("/:id/staff", middleware.RequireUnscoped(), track.TrackAction("assign-staff", "warehouse", "id"), c.WarehouseController.ReplaceWarehouseStaff)
 
 	warehouseProducts := api.Group("/warehouse-products")
-	warehouseProducts.Post("/:warehouse_id", c.WarehouseProductController.CreateWarehouseProduct)
+	warehouseProducts.Post("/:warehouse_id", track.TrackAction("add-product", "warehouse", "warehouse_id"), c.WarehouseProductController.CreateWarehouseProduct)
 	warehouseProducts.Get("/:warehouse_id", c.WarehouseProductController.GetDetailWarehouse)
 	warehouseProducts.Get("/:warehouse_id/detail/:product_id", c.WarehouseProductController.GetWarehouseProductByWarehouseIDAndProductID)
-	warehouseProducts.Put("/detail/:warehouse_product_id", c.WarehouseProductController.UpdateWarehouseProduct)
-	warehouseProducts.Delete("/detail/:warehouse_product_id", c.WarehouseProductController.DeleteWarehouseProduct)
-	warehouseProducts.Delete("/detail/products/:product_id", c.WarehouseProductController.DeleteAllWarehouseProductByProductID)
+	warehouseProducts.Put("/detail/:warehouse_product_id", track.Track("warehouse-product", "warehouse_product_id"), c.WarehouseProductController.UpdateWarehouseProduct)
+	warehouseProducts.Delete("/detail/:warehouse_product_id", track.Track("warehouse-product", "warehouse_product_id"), c.WarehouseProductController.DeleteWarehouseProduct)
+	warehouseProducts.Delete("/detail/products/:product_id", middleware.RequireUnscoped(), track.Track("product-warehouse-products", "product_id"), c.WarehouseProductController.DeleteAllWarehouseProductByProductID)
 	warehouseProducts.Get("/detail/products/:product_id/total-stock", c.WarehouseProductController.GetWarehouseProductByProductID)
-	warehouseProducts.Get("/detail/products/:product_id", c.WarehouseProductController.GetProductTotalStock)
+	warehouseProducts.Get("/detail/products/:product_id", middleware.RequireUnscoped(), c.WarehouseProductController.GetProductTotalStock)
 	warehouseProducts.Get("/detail/products/:product_id/warehouses", c.WarehouseProductController.GetDetailWarehouseProductByID)
 
 	api.Post("/upload-warehouse", c.UploadController.UploadPhoto)
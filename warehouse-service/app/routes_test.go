@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"warehouse-go/shared/audit"
+	"warehouse-go/warehouse-service/controller"
+	"warehouse-go/warehouse-service/model"
+	"warehouse-go/warehouse-service/repository"
+	"warehouse-go/warehouse-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeWarehouseRepo knows warehouses 1 and 2 and assigns user 5 to
+// warehouse 1 only.
+type fakeWarehouseRepo struct {
+	repository.WarehouseRepositoryInterface
+	listedFor uint
+}
+
+func (f *fakeWarehouseRepo) IsWarehouseStaff(ctx context.Context, warehouseID, userID uint) (bool, error) {
+	return warehouseID == 1 && userID == 5, nil
+}
+
+func (f *fakeWarehouseRepo) GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error) {
+	return &model.Warehouse{ID: id, Name: "Warehouse"}, nil
+}
+
+func (f *fakeWarehouseRepo) GetAllWarehouse(ctx context.Context, page, limit int, search, sortBy, sortOrder string, staffID uint, ids []uint) ([]model.Warehouse, int64, error) {
+	f.listedFor = staffID
+	return nil, 0, nil
+}
+
+type discardRecorder struct{}
+
+func (discardRecorder) RecordAuditEvent(ctx context.Context, event audit.Event) error {
+	return nil
+}
+
+// newTestApp serves the routes of the service over fakeWarehouseRepo. The
+// warehouse product routes have no usecase, requests reaching them panic.
+func newTestApp(repo *fakeWarehouseRepo) *fiber.App {
+	app := fiber.New()
+	SetupRoutes(app, &Container{
+		WarehouseController:        controller.NewWarehouseController(usecase.NewWarehouseUsecase(repo)),
+		WarehouseProductController: controller.NewWarehouseProductController(nil),
+		UploadController:           controller.NewFileUploadController(nil),
+		AuditTracker:               audit.NewTracker("warehouse-service", discardRecorder{}),
+	})
+	return app
+}
+
+// caller is the identity the api gateway forwards.
+type caller struct {
+	userID      string
+	permissions string
+	internal    bool
+}
+
+var (
+	keeper  = caller{userID: "5", permissions: "warehouse:read,warehouse:write"}
+	manager = caller{userID: "1", permissions: "warehouse:read,warehouse:write,warehouse:all"}
+	service = caller{internal: true}
+	nobody  = caller{}
+)
+
+func send(t *testing.T, app *fiber.App, who caller, method, target, body string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if who.userID != "" {
+		req.Header.Set("X-User-ID", who.userID)
+		req.Header.Set("X-User-Permissions", who.permissions)
+	}
+	if who.internal {
+		req.Header.Set("X-Internal-Request", "true")
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWarehouseScoping(t *testing.T) {
+	app := newTestApp(&fakeWarehouseRepo{})
+
+	for _, tc := range []struct {
+		name   string
+		who    caller
+		target string
+		status int
+	}{
+		{"staff on their warehouse", keeper, "/api/v1/warehouses/1", fiber.StatusOK},
+		{"staff on another warehouse", keeper, "/api/v1/warehouses/2", fiber.StatusForbidden},
+		{"warehouse:all on any warehouse", manager, "/api/v1/warehouses/2", fiber.StatusOK},
+		{"another service", service, "/api/v1/warehouses/2", fiber.StatusOK},
+		{"no identity", nobody, "/api/v1/warehouses/1", fiber.StatusUnauthorized},
+		{"X-User-ID that is no id", caller{userID: "abc"}, "/api/v1/warehouses/1", fiber.StatusUnauthorized},
+	} {
+		if status := send(t, app, tc.who, http.MethodGet, tc.target, ""); status != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, status, tc.status)
+		}
+	}
+}
+
+func TestWarehouseListScoping(t *testing.T) {
+	repo := &fakeWarehouseRepo{}
+	app := newTestApp(repo)
+
+	for _, tc := range []struct {
+		name      string
+		who       caller
+		listedFor uint
+	}{
+		{"staff", keeper, 5},
+		{"warehouse:all", manager, 0},
+		{"another service", service, 0},
+	} {
+		repo.listedFor = 99
+		if status := send(t, app, tc.who, http.MethodGet, "/api/v1/warehouses", ""); status != fiber.StatusOK {
+			t.Fatalf("%s: status %d", tc.name, status)
+		}
+		if repo.listedFor != tc.listedFor {
+			t.Errorf("%s: listed for staff %d, want %d", tc.name, repo.listedFor, tc.listedFor)
+		}
+	}
+}
+
+// Routes about every warehouse refuse staff before the handler runs.
+func TestUnscopedRoutes(t *testing.T) {
+	app := newTestApp(&fakeWarehouseRepo{})
+
+	for _, tc := range []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPut, "/api/v1/warehouses/1/staff", `{"user_ids":[5]}`},
+		{http.MethodPost, "/api/v1/warehouses", `{"name":"New"}`},
+		{http.MethodGet, "/api/v1/warehouse-products/detail/products/3", ""},
+		{http.MethodDelete, "/api/v1/warehouse-products/detail/products/3", ""},
+	} {
+		if status := send(t, app, keeper, tc.method, tc.target, tc.body); status != fiber.StatusForbidden {
+			t.Errorf("staff %s %s: status %d, want 403", tc.method, tc.target, status)
+		}
+		if status := send(t, app, nobody, tc.method, tc.target, tc.body); status != fiber.StatusUnauthorized {
+			t.Errorf("no identity %s %s: status %d, want 401", tc.method, tc.target, status)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"warehouse-go/warehouse-service/configs"
+	"warehouse-go/warehouse-service/database"
+	"warehouse-go/shared/migrate"
+)
+
+func init() {
+	rootCmd.AddCommand(migrate.Command(func() (*migrate.Migrator, error) {
+		db, err := database.ConnectPostgres(*configs.NewConfig())
+		if err != nil {
+			return nil, err
+		}
+		return database.NewMigrator(db.DB)
+	}))
+}
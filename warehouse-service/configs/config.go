@@ -11,6 +11,8 @@ type App struct {
 	AppEnv  string `json:"app_env"`
 
 	UrlProductService string `json:"url_product_service"`
+
+	LowStockThreshold int `json:"low_stock_threshold"`
 }
 
 type SqlDB struct {
@@ -21,6 +23,9 @@ type SqlDB struct {
 	DBname   string `json:"db_name"`
 	DBMaxOpenConns	int    `json:"db_max_open_conns"`
 	DBMaxIdleConns	int    `json:"db_max_idle_conns"`
+	// SkipMigrations leaves migrating to the migrate command instead of
+	// applying pending migrations on start.
+	SkipMigrations bool `json:"skip_migrations"`
 }
 
 type Redis struct {
@@ -59,6 +64,7 @@ func NewConfig() *Config {
 			AppPort: viper.GetString("APP_PORT"),
 			AppEnv:  viper.GetString("APP_ENV"),
 			UrlProductService: viper.GetString("URL_PRODUCT_SERVICE"),
+			LowStockThreshold: viper.GetInt("LOW_STOCK_THRESHOLD"),
 	},
 		SqlDB: SqlDB {
 			Host:     viper.GetString("DATABASE_HOST"),
@@ -68,6 +74,7 @@ func NewConfig() *Config {
 			DBname:   viper.GetString("DATABASE_NAME"),
 			DBMaxIdleConns: viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			DBMaxOpenConns: viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
+			SkipMigrations: viper.GetBool("DATABASE_SKIP_MIGRATIONS"),
 
 	},
 		RabbitMQ: RabbitMQ{
@@ -2,5 +2,13 @@ package request
 
 type CreateWarehouseProductRequest struct {
 	ProductID       uint 	`json:"product_id" validare:"required"`
+	VariantID		uint	`json:"variant_id"`
 	Stock			int 	`json:"stock" validate:"required"`
+	// Unit is what Stock is counted in, the product's base unit when empty.
+	// It is stored converted to base units.
+	Unit			string	`json:"unit"`
+}
+
+type GetWarehouseProductStockRequest struct {
+	VariantID uint `query:"variant_id"`
 }
@@ -7,10 +7,23 @@ type CreateWarehouseRequest struct {
 	Photo 	string 		`json:"photo" validate:"required"`
 }
 
+// MaxIDs is how many warehouses one ids filter may list.
+const MaxIDs = 100
+
+// GetAllWarehouseRequest lists warehouses. ids, a comma separated list,
+// keeps the listed warehouses and loads them all on one page unless limit
+// says otherwise.
 type GetAllWarehouseRequest struct {
 	Page 		int 	`query:"page" validate:"omitempty,min=1"`
 	Limit		int		`query:"limit" validate:"omitempty,min=1"`
 	Search		string 	`query:"search" validate:"omitempty"`
 	SortBy		string	`query:"sort_by" validate:"omitempty,oneof=id name address phone created_at"`
 	SortOrder 	string 	`query:"sort_order" validate:"omitempty,oneof=asc desc"`
-}
\ No newline at end of file
+	IDs 		string 	`query:"ids"`
+}
+
+// ReplaceWarehouseStaffRequest sets every user assigned to a warehouse. An
+// empty list removes them all.
+type ReplaceWarehouseStaffRequest struct {
+	UserIDs []uint `json:"user_ids" validate:"dive,required"`
+}
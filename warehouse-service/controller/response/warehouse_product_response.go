@@ -4,6 +4,7 @@ type WarehouseProductResponse struct {
 	ID 					 uint 				`json:"id"`
 	WarehouseID			 uint 				`json:"warehouse_id"`
 	ProductID			 uint 				`json:"product_id"`
+	VariantID			 uint 				`json:"variant_id"`
 	ProductName 		 string				`json:"product_name"`
 	ProductAbout		 string				`json:"product_about"`
 	ProductPhoto		 string				`json:"product_photo"`
@@ -11,6 +12,7 @@ type WarehouseProductResponse struct {
 	ProductCategory 	 string				`json:"product_category"`
 	ProductCategoryPhoto string				`json:"product_category_photo"`	
 	Stock 				 int 				`json:"stock"`
+	BaseUnit			 string				`json:"base_unit,omitempty"`
 	Warehouse 			 WarehouseResponse 	`json:"warehouse"`	
 }
 
@@ -18,7 +20,9 @@ type GetDetailWarehouseProductByIDResponse struct {
 	ID 				uint 				`json:"id"`
 	WarehouseID		uint 				`json:"warehouse_id"`
 	ProductID		uint 				`json:"product_id"`
+	VariantID		uint 				`json:"variant_id"`
 	Stock 			int 				`json:"stock"`
+	BaseUnit		string				`json:"base_unit,omitempty"`
 	WarehouseName 	string     		 	`json:"warehouse_name"`
 	WarehousePhoto	string				`json:"warehouse_photo"`
 	WarehousePhone	string				`json:"warehouse_phone"`
@@ -9,6 +9,7 @@ type WarehouseResponse struct {
 	Photo        string `json:"photo"`
 	Phone        string `json:"phone"`
 	CountProduct int    `json:"count_product"`
+	StaffIDs     []uint `json:"staff_ids"`
 }
 
 type GetAllWarehouseResponse struct {
@@ -23,5 +24,6 @@ type DetailWarehouseResponse struct {
 	Photo	string 	`json:"photo"`
 	Phone 	string  `json:"phone"`
 	CountProduct int `json:"count_product"`
+	StaffIDs	[]uint	`json:"staff_ids"`
 	WarehouseProducts 	[]WarehouseProductResponse	`json:"products"`
 }
\ No newline at end of file
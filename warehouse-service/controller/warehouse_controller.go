@@ -1,16 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/warehouse-service/controller/request"
 	"warehouse-go/warehouse-service/controller/response"
 	"warehouse-go/warehouse-service/model"
 	"warehouse-go/warehouse-service/pkg/conv"
+	"warehouse-go/warehouse-service/pkg/middleware"
 	"warehouse-go/warehouse-service/pkg/pagination"
 	"warehouse-go/warehouse-service/pkg/validator"
 	"warehouse-go/warehouse-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type WarehouseControllerInterface interface {
@@ -19,6 +22,7 @@ type WarehouseControllerInterface interface {
 	GetWarehouseByID(ctx *fiber.Ctx) error
 	UpdateWarehouse(ctx *fiber.Ctx) error
 	DeleteWarehouse(ctx *fiber.Ctx) error
+	ReplaceWarehouseStaff(ctx *fiber.Ctx) error
 }
 
 type warehouseController struct {
@@ -67,8 +71,13 @@ func (w *warehouseController) DeleteWarehouse(ctx *fiber.Ctx) error {
 
 	warehouseID := conv.StringToUint(id)
 
-	if err := w.warehouseUsecase.DeleteWarehouse(ctx.Context(), warehouseID); err != nil {
+	if err := w.warehouseUsecase.DeleteWarehouse(ctx.Context(), warehouseID, middleware.ScopedUserID(ctx)); err != nil {
 		log.Errorf("[WarehouseController] DeleteWarehouse - 1: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "You are not assigned to this warehouse",
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to delete warehouse",
 		})
@@ -96,6 +105,21 @@ func (w *warehouseController) GetAllWarehouses(ctx *fiber.Ctx) error {
 		})
 	}
 
+	var ids []uint
+	if req.IDs != "" {
+		var err error
+		ids, err = conv.StringToUints(req.IDs)
+		if err != nil || len(ids) > request.MaxIDs {
+			log.Errorf("[WarehouseController] GetAllWarehouses - 3: invalid ids %q", req.IDs)
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "ids must be a comma separated list of at most 100 ids",
+			})
+		}
+		if req.Limit <= 0 {
+			req.Limit = len(ids)
+		}
+	}
+
 	if req.Page <= 0 {
 		req.Page = 1
 	}
@@ -104,9 +128,9 @@ func (w *warehouseController) GetAllWarehouses(ctx *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	warehouses, total, err := w.warehouseUsecase.GetAllWarehouse(ctx.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	warehouses, total, err := w.warehouseUsecase.GetAllWarehouse(ctx.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, middleware.ScopedUserID(ctx), ids)
 	if err != nil {
-		log.Errorf("[WarehouseController] GetAllWarehouses - 3: %v", err)
+		log.Errorf("[WarehouseController] GetAllWarehouses - 4: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get all warehouses",
 		})
@@ -122,6 +146,7 @@ func (w *warehouseController) GetAllWarehouses(ctx *fiber.Ctx) error {
 			Photo:        warehouse.Photo,
 			Phone:        warehouse.Phone,
 			CountProduct: len(warehouse.WarehouseProducts),
+			StaffIDs:     warehouse.StaffIDs(),
 		})
 	}
 
@@ -141,20 +166,26 @@ func (w *warehouseController) GetWarehouseByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 	warehouseID := conv.StringToUint(id)
 
-	warehouse, err := w.warehouseUsecase.GetWarehouseByID(ctx.Context(), warehouseID)
+	warehouse, err := w.warehouseUsecase.GetWarehouseByID(ctx.Context(), warehouseID, middleware.ScopedUserID(ctx))
 	if err != nil {
 		log.Errorf("[WarehouseController] GetWarehouseByID - 1: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "You are not assigned to this warehouse",
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get warehouse",
 		})
 	}
 
 	respWarehouses := response.DetailWarehouseResponse{
-		ID:      warehouse.ID,
-		Name:    warehouse.Name,
-		Address: warehouse.Address,
-		Photo:   warehouse.Photo,
-		Phone:   warehouse.Phone,
+		ID:       warehouse.ID,
+		Name:     warehouse.Name,
+		Address:  warehouse.Address,
+		Photo:    warehouse.Photo,
+		Phone:    warehouse.Phone,
+		StaffIDs: warehouse.StaffIDs(),
 	}
 
 	for _, warehouseProduct := range warehouse.WarehouseProducts {
@@ -201,8 +232,13 @@ func (w *warehouseController) UpdateWarehouse(ctx *fiber.Ctx) error {
 		Phone:   req.Phone,
 	}
 
-	if err := w.warehouseUsecase.UpdateWarehouse(ctx.Context(), &reqModel); err != nil {
+	if err := w.warehouseUsecase.UpdateWarehouse(ctx.Context(), &reqModel, middleware.ScopedUserID(ctx)); err != nil {
 		log.Errorf("[WarehouseController] UpdateWarehouse - 3: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "You are not assigned to this warehouse",
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to update warehouse",
 		})
@@ -213,6 +249,42 @@ func (w *warehouseController) UpdateWarehouse(ctx *fiber.Ctx) error {
 	})
 }
 
+// ReplaceWarehouseStaff implements WarehouseControllerInterface.
+func (w *warehouseController) ReplaceWarehouseStaff(ctx *fiber.Ctx) error {
+	warehouseID := conv.StringToUint(ctx.Params("id"))
+
+	var req request.ReplaceWarehouseStaffRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[WarehouseController] ReplaceWarehouseStaff - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[WarehouseController] ReplaceWarehouseStaff - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := w.warehouseUsecase.ReplaceWarehouseStaff(ctx.Context(), warehouseID, req.UserIDs); err != nil {
+		log.Errorf("[WarehouseController] ReplaceWarehouseStaff - 3: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse not found",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to assign staff",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Staff assigned successfully",
+	})
+}
+
 func NewWarehouseController(warehouseUsecase usecase.WarehouseUsecaseInterface) WarehouseControllerInterface {
 	return &warehouseController{warehouseUsecase: warehouseUsecase}
 }
@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"warehouse-go/warehouse-service/controller/request"
 	"warehouse-go/warehouse-service/controller/response"
 	"warehouse-go/warehouse-service/model"
 	"warehouse-go/warehouse-service/pkg/conv"
 	"warehouse-go/warehouse-service/pkg/httpclient"
+	"warehouse-go/warehouse-service/pkg/middleware"
 	"warehouse-go/warehouse-service/pkg/validator"
 	"warehouse-go/warehouse-service/usecase"
 
@@ -55,11 +57,22 @@ func (w *warehouseProductController) CreateWarehouseProduct(c *fiber.Ctx) error
 	reqModel := model.WarehouseProduct{
 		WarehouseID:  warehouseIDUint,
 		ProductID: req.ProductID,
+		VariantID: req.VariantID,
 		Stock: req.Stock,
 	}
 
-	if err := w.warehouseProductUsecase.CreateWarehouseProduct(ctx, &reqModel); err != nil {
+	if err := w.warehouseProductUsecase.CreateWarehouseProduct(ctx, &reqModel, req.Unit, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[WarehouseProductController] CreateWarehouseProduct - 3: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
+		if errors.Is(err, httpclient.ErrUnknownVariant) || errors.Is(err, httpclient.ErrUnknownUnit) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to create warehouse product",
 		})
@@ -96,8 +109,13 @@ func (w *warehouseProductController) DeleteWarehouseProduct(c *fiber.Ctx) error
 	warehouseProductID := c.Params("warehouse_product_id")
 	warehouseProductIDUint := conv.StringToUint(warehouseProductID)
 
-	if err := w.warehouseProductUsecase.DeleteWarehouseProduct(ctx, warehouseProductIDUint); err != nil {
+	if err := w.warehouseProductUsecase.DeleteWarehouseProduct(ctx, warehouseProductIDUint, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[WarehouseProductController] DeleteAllWarehouseProductByProductID - 2: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to delete warehouse product",
 		})
@@ -114,9 +132,14 @@ func (w *warehouseProductController) GetDetailWarehouse(c *fiber.Ctx) error {
 	warehouseID := c.Params("warehouse_id")
 	warehouseIDUint := conv.StringToUint(warehouseID)
 
-	warehouse, products, err := w.warehouseProductUsecase.GetDetailWarehouse(ctx, warehouseIDUint)
+	warehouse, products, err := w.warehouseProductUsecase.GetDetailWarehouse(ctx, warehouseIDUint, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[WarehouseProductController] GetDetailWarehouse - 1: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "failed to get detail warehouse",
 		})
@@ -140,6 +163,7 @@ func (w *warehouseProductController) GetDetailWarehouse(c *fiber.Ctx) error {
 			ID: wp.ID,
 			WarehouseID: wp.WarehouseID,
 			ProductID: wp.ProductID,
+			VariantID: wp.VariantID,
 			Stock: int(wp.Stock),
 		}
 
@@ -150,6 +174,7 @@ func (w *warehouseProductController) GetDetailWarehouse(c *fiber.Ctx) error {
 			warehouseProduct.ProductPrice = int(product.Price)
 			warehouseProduct.ProductCategory = product.Category.Name
 			warehouseProduct.ProductCategoryPhoto = product.Category.Photo
+			warehouseProduct.BaseUnit = product.BaseUnit
 		}
 
 		respWarehouseProducts.WarehouseProducts = append(respWarehouseProducts.WarehouseProducts, warehouseProduct)
@@ -168,9 +193,14 @@ func (w *warehouseProductController) GetDetailWarehouseProductByID(c *fiber.Ctx)
 	warehouseProductID := c.Params("warehouse_product_id")
 	warehouseProductIDUint := conv.StringToUint(warehouseProductID)
 
-	warehouseProduct, product, err := w.warehouseProductUsecase.GetDetailWarehouseProductByID(ctx, warehouseProductIDUint)
+	warehouseProduct, product, err := w.warehouseProductUsecase.GetDetailWarehouseProductByID(ctx, warehouseProductIDUint, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[WarehouseProductController] GetDetailWarehouseProductByID - 1: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to get detail warehouse product by id",
 		})
@@ -180,7 +210,9 @@ func (w *warehouseProductController) GetDetailWarehouseProductByID(c *fiber.Ctx)
 		ID: warehouseProduct.ID,
 		WarehouseID: warehouseProduct.WarehouseID,
 		ProductID: warehouseProduct.ProductID,
+		VariantID: warehouseProduct.VariantID,
 		Stock: int(warehouseProduct.Stock),
+		BaseUnit: product.BaseUnit,
 		WarehouseName: warehouseProduct.Warehouse.Name,
 		WarehousePhoto: warehouseProduct.Warehouse.Photo,
 		WarehousePhone: warehouseProduct.Warehouse.Phone,
@@ -227,7 +259,7 @@ func (w *warehouseProductController) GetWarehouseProductByProductID(c *fiber.Ctx
 	productID := c.Params("product_id")
 	productIDUint := conv.StringToUint(productID)
 
-	warehouseProducts, err := w.warehouseProductUsecase.GetWarehouseProductByProductID(ctx, productIDUint)
+	warehouseProducts, err := w.warehouseProductUsecase.GetWarehouseProductByProductID(ctx, productIDUint, middleware.ScopedUserID(c))
 	if err != nil {
 		log.Errorf("[WarehouseProductController] GetWarehouseProductByProductID - 1: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -260,9 +292,21 @@ func (w *warehouseProductController) GetWarehouseProductByWarehouseIDAndProductI
 	productID := c.Params("product_id")
 	productIDUint := conv.StringToUint(productID)
 
+	var req request.GetWarehouseProductStockRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message" : "Invalid query parameters",
+		})
+	}
+
 	var warehouseProduct *model.WarehouseProduct
-	warehouseProduct, err := w.warehouseProductUsecase.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseIDUint, productIDUint)
+	warehouseProduct, err := w.warehouseProductUsecase.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseIDUint, productIDUint, req.VariantID, middleware.ScopedUserID(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"message": "warehouse product not found",
@@ -279,6 +323,7 @@ func (w *warehouseProductController) GetWarehouseProductByWarehouseIDAndProductI
 		ID:          warehouseProduct.ID,
 		WarehouseID: warehouseProduct.WarehouseID,
 		ProductID:   warehouseProduct.ProductID,
+		VariantID:   warehouseProduct.VariantID,
 		Stock:       int(warehouseProduct.Stock),
 	}
 
@@ -316,11 +361,22 @@ func (w *warehouseProductController) UpdateWarehouseProduct(c *fiber.Ctx) error
 		ID: 			warehouseProductIDUint,
 		WarehouseID: 	warehouseIDUint,
 		ProductID: 		req.ProductID,
+		VariantID: 		req.VariantID,
 		Stock: 			req.Stock,
 	}
 
-	if err := w.warehouseProductUsecase.UpdateWarehouseProduct(ctx, &reqModel); err != nil {
+	if err := w.warehouseProductUsecase.UpdateWarehouseProduct(ctx, &reqModel, req.Unit, middleware.ScopedUserID(c)); err != nil {
 		log.Errorf("[WarehouseProductController] UpdateWarehouseProduct - 2: %v", err)
+		if errors.Is(err, usecase.ErrWarehouseNotAssigned) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message" : "You are not assigned to this warehouse",
+			})
+		}
+		if errors.Is(err, httpclient.ErrUnknownVariant) || errors.Is(err, httpclient.ErrUnknownUnit) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message" : err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message" : "Failed to update warehouse ",
 		})
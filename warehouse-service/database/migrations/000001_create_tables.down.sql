@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "warehouse_products";
+DROP TABLE IF EXISTS "warehouses";
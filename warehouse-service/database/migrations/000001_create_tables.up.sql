@@ -0,0 +1,30 @@
+-- The schema AutoMigrate created before any of the later changes, which
+-- follow in their own migrations. Every statement is conditional, so
+-- databases that already have it adopt this migration unchanged.
+
+CREATE TABLE IF NOT EXISTS "warehouses" (
+	"id" bigserial,
+	"name" text NOT NULL,
+	"address" text,
+	"photo" text,
+	"phone" text NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warehouses_deleted_at" ON "warehouses" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "warehouse_products" (
+	"id" bigserial,
+	"warehouse_id" bigint NOT NULL,
+	"product_id" bigint NOT NULL,
+	"stock" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_warehouses_warehouse_products" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warehouse_products_product_id" ON "warehouse_products" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_warehouse_products_warehouse_id" ON "warehouse_products" ("warehouse_id");
@@ -0,0 +1,14 @@
+-- A warehouse can have several staff members. AutoMigrate created the
+-- table on databases that ran the service in between, so it is only
+-- created when missing.
+
+CREATE TABLE IF NOT EXISTS "warehouse_staff" (
+	"id" bigserial,
+	"warehouse_id" bigint NOT NULL,
+	"user_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_warehouses_staff" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warehouse_staff_user_id" ON "warehouse_staff" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_warehouse_staff" ON "warehouse_staff" ("warehouse_id", "user_id");
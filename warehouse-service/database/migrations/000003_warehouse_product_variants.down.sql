@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_warehouse_products_stock";
+ALTER TABLE "warehouse_products" DROP COLUMN IF EXISTS "variant_id";
@@ -0,0 +1,5 @@
+-- Stock of a product variant is kept on its own row. 0 is the product
+-- itself, for products without variants. Stock stays in base units.
+
+ALTER TABLE "warehouse_products" ADD COLUMN IF NOT EXISTS "variant_id" bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_warehouse_products_stock" ON "warehouse_products" ("warehouse_id", "product_id", "variant_id");
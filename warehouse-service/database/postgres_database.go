@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"warehouse-go/warehouse-service/configs"
-	"warehouse-go/warehouse-service/model"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/driver/postgres"
@@ -30,7 +29,6 @@ func ConnectPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Warehouse{}, &model.WarehouseProduct{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] Connection Postgres - 2: %v", err)
@@ -56,4 +56,7 @@ require (
 	golang.org/x/text v0.29.0 // indirect
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.0
+	warehouse-go/shared v0.0.0
 )
+
+replace warehouse-go/shared => ../shared
@@ -13,4 +13,14 @@ type Warehouse struct {
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
 	WarehouseProducts []WarehouseProduct `json:"warehouse_products" gorm:"foreignKey:WarehouseID"`
+	Staff             []WarehouseStaff   `json:"staff" gorm:"foreignKey:WarehouseID"`
+}
+
+// StaffIDs returns the ids of every user assigned to the warehouse.
+func (w Warehouse) StaffIDs() []uint {
+	ids := make([]uint, 0, len(w.Staff))
+	for _, staff := range w.Staff {
+		ids = append(ids, staff.UserID)
+	}
+	return ids
 }
@@ -6,6 +6,9 @@ type WarehouseProduct struct {
 	ID          	uint       `json:"id" gorm:"primaryKey"`
 	WarehouseID 	uint       `json:"warehouse_id" gorm:"not null;index"`
 	ProductID   	uint       `json:"product_id" gorm:"not null;index"`
+	// VariantID is the product variant the stock is of, 0 for the product
+	// itself. Stock is counted in the product's base unit.
+	VariantID		uint	   `json:"variant_id" gorm:"not null;default:0"`
 	Stock    		int        `json:"stock" gorm:"not null;default:0"`
 	CreatedAt  		time.Time  `json:"created_at"`
 	UpdatedAt   	*time.Time `json:"updated_at"`
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// WarehouseStaff assigns a user to a warehouse. A user can work in several
+// warehouses and a warehouse can have several staff members.
+type WarehouseStaff struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"not null;uniqueIndex:idx_warehouse_staff"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_warehouse_staff;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName keeps the plural gorm would otherwise make up ("warehouse_staffs").
+func (WarehouseStaff) TableName() string {
+	return "warehouse_staff"
+}
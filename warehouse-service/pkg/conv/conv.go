@@ -2,6 +2,7 @@ package conv
 
 import (
 	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -22,4 +23,20 @@ func StringToUint(s string) uint {
 		return 0
 	}
 	return uint(id)
-}
\ No newline at end of file
+}
+
+// StringToUints parses a comma separated list of ids. Unlike StringToUint it
+// fails on anything that is not an id, so a typo does not quietly widen a
+// lookup.
+func StringToUints(s string) ([]uint, error) {
+	parts := strings.Split(s, ",")
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
@@ -28,9 +28,9 @@ func (cpc *CachedProductClient) GenerateCacheKey(prefix string, id uint) string
 }
 
 func (cpc *CachedProductClient) GenerateCacheKeyMultiple(prefix string, ids []uint) string {
-	key := fmt.Sprintf("product:%s:%d", prefix)
+	key := fmt.Sprintf("product:%s:", prefix)
 	for _, id := range ids {
-		key += fmt.Sprintf(":id", id)
+		key += fmt.Sprintf("%d:", id)
 	}
 
 	return key[:len(key)-1]
@@ -137,6 +137,9 @@ type ProductResponse struct {
 		Name  string `json:"name"`
 		Photo string `json:"photo"`
 	} `json:"category"`
+	BaseUnit  string                   `json:"base_unit"`
+	Variants  []ProductVariantResponse `json:"variants"`
+	Units     []ProductUnitResponse    `json:"units"`
 }
 
 type ProductServiceResponse struct {
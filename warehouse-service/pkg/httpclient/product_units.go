@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnknownVariant = errors.New("the product has no such variant")
+	ErrUnknownUnit    = errors.New("the product has no such unit")
+)
+
+type ProductVariantResponse struct {
+	ID         uint              `json:"id"`
+	SKU        string            `json:"sku"`
+	Barcode    string            `json:"barcode"`
+	Price      int64             `json:"price"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// ProductUnitResponse is a packaging of Factor base units, e.g. a carton.
+type ProductUnitResponse struct {
+	Name    string `json:"name"`
+	Factor  int    `json:"factor"`
+	Barcode string `json:"barcode"`
+}
+
+// BaseQuantity converts a quantity counted in unit to the base unit stock is
+// kept in. An empty unit is the base unit.
+func (p ProductResponse) BaseQuantity(unit string, quantity int) (int, error) {
+	unit = strings.TrimSpace(unit)
+	if unit == "" || strings.EqualFold(unit, p.BaseUnit) {
+		return quantity, nil
+	}
+	for _, productUnit := range p.Units {
+		if strings.EqualFold(productUnit.Name, unit) {
+			return quantity * productUnit.Factor, nil
+		}
+	}
+	return 0, ErrUnknownUnit
+}
+
+// CheckVariant returns ErrUnknownVariant unless variantID is 0, the product
+// itself, or one of its variants.
+func (p ProductResponse) CheckVariant(variantID uint) error {
+	if variantID == 0 {
+		return nil
+	}
+	for _, variant := range p.Variants {
+		if variant.ID == variantID {
+			return nil
+		}
+	}
+	return ErrUnknownVariant
+}
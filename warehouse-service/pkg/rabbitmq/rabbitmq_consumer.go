@@ -16,11 +16,14 @@ type RabbitMQConsumer struct {
 	conn 		*amqp.Connection
 	channel 	*amqp.Channel
 	repo 		 repository.WarehouseProductRepositoryInterface
+	lowStockThreshold int
 }
 
 type StockReductionEvent struct {
 	WarehouseID 	uint		`json:"warehouse_id"`
 	ProductID 		uint 		`json:"product_id"`
+	VariantID		uint		`json:"variant_id"`
+	// Stock is in base units.
 	Stock 			int 		`json:"stock"`
 	MerchantID		uint		`json:"merchat_id"`
 	Timestamp		time.Time 	`json:"timestamp"`
@@ -32,7 +35,7 @@ const (
 	RoutingKey 		= "stock.reduction"
 )
 
-func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRepositoryInterface) (*RabbitMQConsumer, error) {
+func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRepositoryInterface, lowStockThreshold int) (*RabbitMQConsumer, error) {
 	conn, err := amqp.Dial(rabbitMQURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -82,10 +85,19 @@ func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRep
 		return nil, fmt.Errorf("failed to bidn queue: %w", err)
 	}
 
+	if err := declareRealtimeExchange(ch); err != nil {
+		return nil, fmt.Errorf("failed to declare realtime exchange: %w", err)
+	}
+
+	if lowStockThreshold <= 0 {
+		lowStockThreshold = DefaultLowStockThreshold
+	}
+
 	return &RabbitMQConsumer{
 		conn: conn,
 		channel: ch,
 		repo: repo,
+		lowStockThreshold: lowStockThreshold,
 	}, nil
 }
 
@@ -136,7 +148,7 @@ func (rc *RabbitMQConsumer) handleMessage(ctx context.Context, msg amqp.Delivery
 
 
 func (rc *RabbitMQConsumer) processStockReduction(ctx context.Context, event StockReductionEvent) error {
-	warehouseProduct, err := rc.repo.GetWarehouseProductByWarehouseIDAndProductID(ctx, event.WarehouseID, event.ProductID)
+	warehouseProduct, err := rc.repo.GetWarehouseProductByWarehouseIDAndProductID(ctx, event.WarehouseID, event.ProductID, event.VariantID)
 	if err != nil {
 		log.Errorf("[RabbitMQConsumer] processStockReduction - 1: %v", err)
 		return err
@@ -151,6 +163,14 @@ func (rc *RabbitMQConsumer) processStockReduction(ctx context.Context, event Sto
 
 	if err := rc.repo.UpdateWarehouseProduct(ctx, warehouseProduct); err != nil {
 		log.Errorf("[RabbitMQConsumer] processStockReduction - 2: %v", err)
+		return nil
+	}
+
+	// Warehouse stock is not scoped to a merchant, so these events only reach managers.
+	for _, realtimeEvent := range stockEvents(0, event.WarehouseID, event.ProductID, newStock, rc.lowStockThreshold) {
+		if err := publishRealtimeEvent(rc.channel, realtimeEvent); err != nil {
+			log.Errorf("[RabbitMQConsumer] processStockReduction - 3: %v", err)
+		}
 	}
 
 	return nil
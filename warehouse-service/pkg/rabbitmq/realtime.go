@@ -0,0 +1,93 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// RealtimeExchange carries the events the API gateway pushes to connected
+// clients. The routing key is the event type.
+const RealtimeExchange = "realtime_events"
+
+const (
+	EventPaymentStatus = "payment.status"
+	EventStockChanged  = "stock.changed"
+	EventStockLow      = "stock.low"
+)
+
+// DefaultLowStockThreshold is used when LOW_STOCK_THRESHOLD is not set.
+const DefaultLowStockThreshold = 10
+
+type RealtimeEvent struct {
+	Type          string    `json:"type"`
+	MerchantID    uint      `json:"merchant_id,omitempty"`
+	WarehouseID   uint      `json:"warehouse_id,omitempty"`
+	ProductID     uint      `json:"product_id,omitempty"`
+	Stock         *int      `json:"stock,omitempty"`
+	OrderID       string    `json:"order_id,omitempty"`
+	PaymentStatus string    `json:"payment_status,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func declareRealtimeExchange(ch *amqp.Channel) error {
+	return ch.ExchangeDeclare(
+		RealtimeExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+}
+
+// publishRealtimeEvent is fire and forget: the events are only a hint for
+// connected clients, so they are neither persisted nor retried.
+func publishRealtimeEvent(ch *amqp.Channel, event RealtimeEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return ch.Publish(
+		RealtimeExchange,
+		event.Type,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+			Timestamp:   event.Timestamp,
+		},
+	)
+}
+
+// stockEvents returns the stock change of a product and, when the new stock
+// is at or below the threshold, a low stock alert.
+func stockEvents(merchantID, warehouseID, productID uint, stock, lowStockThreshold int) []RealtimeEvent {
+	events := []RealtimeEvent{{
+		Type:        EventStockChanged,
+		MerchantID:  merchantID,
+		WarehouseID: warehouseID,
+		ProductID:   productID,
+		Stock:       &stock,
+	}}
+
+	if stock <= lowStockThreshold {
+		events = append(events, RealtimeEvent{
+			Type:        EventStockLow,
+			MerchantID:  merchantID,
+			WarehouseID: warehouseID,
+			ProductID:   productID,
+			Stock:       &stock,
+		})
+	}
+
+	return events
+}
@@ -12,11 +12,11 @@ type WarehouseProductRepositoryInterface interface {
 	GetDetailWarehouse(ctx context.Context, warehouseID uint) (*model.Warehouse, error)
 	GetDetailWarehouseProductByID(ctx context.Context, warehouseProductID uint) (*model.WarehouseProduct, error)
 	CreateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error
-	GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID, productID uint) (*model.WarehouseProduct, error)
+	GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID, productID, variantID uint) (*model.WarehouseProduct, error)
 	UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error
 	DeleteWarehouseProduct(ctx context.Context, warehouseProductID uint) error
 	DeleteAllWarehouseProductByProductID(ctx context.Context, productID uint) error
-	GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error)
+	GetWarehouseProductByProductID(ctx context.Context, productID, staffID uint) ([]model.WarehouseProduct, error)
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
 }
 
@@ -94,7 +94,7 @@ func (w *warehouseProductRepository) GetDetailWarehouseProductByID(ctx context.C
 		return nil, ctx.Err()
 	default:
 		var warehouseProduct model.WarehouseProduct
-		if err := w.db.WithContext(ctx).Where("id = ?", warehouseProductID).Select("id", "product_id", "stock", "warehouse_id").Preload("Warehouse").
+		if err := w.db.WithContext(ctx).Where("id = ?", warehouseProductID).Select("id", "product_id", "variant_id", "stock", "warehouse_id").Preload("Warehouse").
 		First(&warehouseProduct).Error; err != nil {
 			log.Errorf("[WarehouseProductRepository] GetDetailWarehouseProductByID - 2: %v", err)
 			return nil, err
@@ -125,14 +125,19 @@ func (w *warehouseProductRepository) GetProductTotalStock(ctx context.Context, p
 }
 
 // GetWarehouseProductByProductID implements WarehouseProductRepositoryInterface.
-func (w *warehouseProductRepository) GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error) {
+func (w *warehouseProductRepository) GetWarehouseProductByProductID(ctx context.Context, productID uint, staffID uint) ([]model.WarehouseProduct, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[WarehouseProductRepository] GetWarehouseProductByProductID - 1: %v", ctx.Err())
 		return nil, ctx.Err()
 	default:
 		var warehouseProducts []model.WarehouseProduct
-		if err := w.db.WithContext(ctx).Where("product_id = ?", productID). 
+		query := w.db.WithContext(ctx).Where("product_id = ?", productID)
+		if staffID != 0 {
+			query = query.Where("warehouse_id IN (?)", w.db.Model(&model.WarehouseStaff{}).Select("warehouse_id").Where("user_id = ?", staffID))
+		}
+
+		if err := query.
 		Preload("Warehouse").
 		Find(&warehouseProducts).Error; err != nil {
 			log.Errorf("[WarehouseProductRepository] GetWarehouseProductByProductID - 2: %v", err)
@@ -143,14 +148,14 @@ func (w *warehouseProductRepository) GetWarehouseProductByProductID(ctx context.
 }
 
 // GetWarehouseProductByWarehouseIDAndProductID implements WarehouseProductRepositoryInterface.
-func (w *warehouseProductRepository) GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID uint, productID uint) (*model.WarehouseProduct, error) {
+func (w *warehouseProductRepository) GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID uint, productID uint, variantID uint) (*model.WarehouseProduct, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[WarehouseProductRepository] GetWarehouseProductByWarehouseIDAndProductID - 1: %v", ctx.Err())
 		return nil, ctx.Err()
 	default:
 		var warehouseProduct model.WarehouseProduct
-		if err := w.db.WithContext(ctx).Where("warehouse_id = ? AND product_id =?", warehouseID, productID).
+		if err := w.db.WithContext(ctx).Where("warehouse_id = ? AND product_id = ? AND variant_id = ?", warehouseID, productID, variantID).
 		First(&warehouseProduct).Error; err != nil {
 			log.Errorf("[WarehouseProductRepository] GetWarehouseProductByWarehouseIDAndProductID - 2: %v", err)
 			return nil, err
@@ -176,6 +181,7 @@ func (w *warehouseProductRepository) UpdateWarehouseProduct(ctx context.Context,
 		existingWarehouseProduct.Stock = warehouseProduct.Stock
 		existingWarehouseProduct.WarehouseID = warehouseProduct.WarehouseID
 		existingWarehouseProduct.ProductID = warehouseProduct.ProductID 
+		existingWarehouseProduct.VariantID = warehouseProduct.VariantID
 
 		return w.db.WithContext(ctx).Save(existingWarehouseProduct).Error
 	}
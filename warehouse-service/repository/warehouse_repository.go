@@ -11,10 +11,13 @@ import (
 
 type WarehouseRepositoryInterface interface {
 	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
-	GetAllWarehouse(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Warehouse, int64, error)
+	// ids does not filter when nil.
+	GetAllWarehouse(ctx context.Context, page, limit int, search, sortBy, sortOrder string, staffID uint, ids []uint) ([]model.Warehouse, int64, error)
 	GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
 	DeleteWarehouse(ctx context.Context, id uint) error
+	IsWarehouseStaff(ctx context.Context, warehouseID, userID uint) (bool, error)
+	ReplaceWarehouseStaff(ctx context.Context, warehouseID uint, userIDs []uint) error
 }
 
 type warehouseRepository struct {
@@ -49,12 +52,18 @@ func (w *warehouseRepository) DeleteWarehouse(ctx context.Context, id uint) erro
 			log.Errorf("[WarehouseProject] DeleteWarehoouse- 3: %v", errors.New("warehouse has products"))
 			return errors.New("warehouse has products")
 		}
-		return w.db.WithContext(ctx).Delete(&modelWarehouse).Error
+		return w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("warehouse_id = ?", id).Delete(&model.WarehouseStaff{}).Error; err != nil {
+				log.Errorf("[WarehouseRepository] DeleteWarehouse - 4: %v", err)
+				return err
+			}
+			return tx.Delete(&modelWarehouse).Error
+		})
 	}
 }
 
 // GetAllWarehouse implements WarehouseRepositoryInterface.
-func (w *warehouseRepository) GetAllWarehouse(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Warehouse, int64, error) {
+func (w *warehouseRepository) GetAllWarehouse(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, staffID uint, ids []uint) ([]model.Warehouse, int64, error) {
 	select {
 	case <- ctx.Done():
 		log.Errorf("[WarehouseRepository] GetAllWarehouse - 1: %v", ctx.Err())
@@ -81,6 +90,14 @@ func (w *warehouseRepository) GetAllWarehouse(ctx context.Context, page int, lim
 			query = query.Where("name ILIKE ? OR address ILIKE ? OR phone ILIKE ?", "%"+search+"%", "%"+search+"%")
 		}
 
+		if staffID != 0 {
+			query = query.Where("id IN (?)", w.db.Model(&model.WarehouseStaff{}).Select("warehouse_id").Where("user_id = ?", staffID))
+		}
+
+		if ids != nil {
+			query = query.Where("id IN ?", ids)
+		}
+
 		var total int64
 		if err := query.Count(&total).Error; err != nil {
 			log.Errorf("[WarehouseRepository] GetAllWarehouse - 2: %v", err)
@@ -92,6 +109,7 @@ func (w *warehouseRepository) GetAllWarehouse(ctx context.Context, page int, lim
 			Order(sortBy + " " + sortOrder).
 			WithContext(ctx).
 			Preload("WarehouseProducts").
+			Preload("Staff").
 			Offset(offset).
 			Limit(limit).
 			Find(&warehouses).Error; err != nil {
@@ -112,7 +130,7 @@ func (w *warehouseRepository) GetWarehouseByID(ctx context.Context, id uint) (*m
 		return nil, ctx.Err()
 	default:
 		modelWarehouse := model.Warehouse{}
-		if err := w.db.WithContext(ctx).Where("id = ?", id).Preload("WarehouseProducts").First(&modelWarehouse).Error; err != nil {
+		if err := w.db.WithContext(ctx).Where("id = ?", id).Preload("WarehouseProducts").Preload("Staff").First(&modelWarehouse).Error; err != nil {
 			log.Errorf("[WarehouseRepository] GetWarehouseByID - 2: %v", err)
 			return nil, err
 		}
@@ -143,6 +161,60 @@ func (w *warehouseRepository) UpdateWarehouse(ctx context.Context, warehouse *mo
 	}
 }
 
+// IsWarehouseStaff implements WarehouseRepositoryInterface.
+func (w *warehouseRepository) IsWarehouseStaff(ctx context.Context, warehouseID uint, userID uint) (bool, error) {
+	select {
+	case <- ctx.Done():
+		log.Errorf("[WarehouseRepository] IsWarehouseStaff - 1: %v", ctx.Err())
+		return false, ctx.Err()
+	default:
+		var count int64
+		if err := w.db.WithContext(ctx).Model(&model.WarehouseStaff{}).
+			Where("warehouse_id = ? AND user_id = ?", warehouseID, userID).
+			Count(&count).Error; err != nil {
+			log.Errorf("[WarehouseRepository] IsWarehouseStaff - 2: %v", err)
+			return false, err
+		}
+
+		return count > 0, nil
+	}
+}
+
+// ReplaceWarehouseStaff implements WarehouseRepositoryInterface.
+func (w *warehouseRepository) ReplaceWarehouseStaff(ctx context.Context, warehouseID uint, userIDs []uint) error {
+	select {
+	case <- ctx.Done():
+		log.Errorf("[WarehouseRepository] ReplaceWarehouseStaff - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("id = ?", warehouseID).First(&model.Warehouse{}).Error; err != nil {
+				log.Errorf("[WarehouseRepository] ReplaceWarehouseStaff - 2: %v", err)
+				return err
+			}
+
+			if err := tx.Where("warehouse_id = ?", warehouseID).Delete(&model.WarehouseStaff{}).Error; err != nil {
+				log.Errorf("[WarehouseRepository] ReplaceWarehouseStaff - 3: %v", err)
+				return err
+			}
+
+			if len(userIDs) == 0 {
+				return nil
+			}
+
+			staff := make([]model.WarehouseStaff, 0, len(userIDs))
+			for _, userID := range userIDs {
+				staff = append(staff, model.WarehouseStaff{WarehouseID: warehouseID, UserID: userID})
+			}
+			if err := tx.Create(&staff).Error; err != nil {
+				log.Errorf("[WarehouseRepository] ReplaceWarehouseStaff - 4: %v", err)
+				return err
+			}
+			return nil
+		})
+	}
+}
+
 func NewWarehouseRepository(db *gorm.DB) WarehouseRepositoryInterface {
 	return &warehouseRepository{db: db}
 }
@@ -10,31 +10,44 @@ import (
 	"gorm.io/gorm"
 )
 
+// The staffID arguments limit a call to the warehouses that user is assigned
+// to, 0 means no limit.
 type WarehouseProductUsecaseInterface interface {
-	GetDetailWarehouse(ctx context.Context, warehouseID uint) (*model.Warehouse, []httpclient.ProductResponse, error)
-	GetDetailWarehouseProductByID(ctx context.Context, warehouseProductID uint) (*model.WarehouseProduct, *httpclient.ProductResponse, error)
-	CreateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error
-	GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID, productID uint) (*model.WarehouseProduct, error)
-	UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error
-	DeleteWarehouseProduct(ctx context.Context, warehouseProductID uint) error
+	GetDetailWarehouse(ctx context.Context, warehouseID, staffID uint) (*model.Warehouse, []httpclient.ProductResponse, error)
+	GetDetailWarehouseProductByID(ctx context.Context, warehouseProductID, staffID uint) (*model.WarehouseProduct, *httpclient.ProductResponse, error)
+	// unit is what the stock of warehouseProduct is counted in, the base
+	// unit of the product when empty. It is stored in base units.
+	CreateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct, unit string, staffID uint) error
+	GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID, productID, variantID, staffID uint) (*model.WarehouseProduct, error)
+	UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct, unit string, staffID uint) error
+	DeleteWarehouseProduct(ctx context.Context, warehouseProductID, staffID uint) error
 	DeleteAllWarehouseProductByProductID(ctx context.Context, productID uint) error
-	GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error)
+	GetWarehouseProductByProductID(ctx context.Context, productID, staffID uint) ([]model.WarehouseProduct, error)
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
 }
 
 type warehouseProductUsecase struct {
 	warehouseProductRepo repository.WarehouseProductRepositoryInterface
+	warehouseRepo        repository.WarehouseRepositoryInterface
 	productClient        httpclient.ProductClientInterface
 }
 
 // CreateWarehouseProduct implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) CreateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error {
-	result, err := w.warehouseProductRepo.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Errorf("[WarehouseProductUsecase] CreateWarehouseProduct - 1: %v", err)
-			return err
-		}
+func (w *warehouseProductUsecase) CreateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct, unit string, staffID uint) error {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouseProduct.WarehouseID, staffID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] CreateWarehouseProduct - 1: %v", err)
+		return err
+	}
+
+	if err := w.toBaseUnits(ctx, warehouseProduct, unit); err != nil {
+		log.Errorf("[WarehouseProductUsecase] CreateWarehouseProduct - 2: %v", err)
+		return err
+	}
+
+	result, err := w.warehouseProductRepo.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID, warehouseProduct.VariantID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Errorf("[WarehouseProductUsecase] CreateWarehouseProduct - 3: %v", err)
+		return err
 	}
 
 	if result != nil {
@@ -51,15 +64,31 @@ func (w *warehouseProductUsecase) DeleteAllWarehouseProductByProductID(ctx conte
 }
 
 // DeleteWarehouseProduct implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) DeleteWarehouseProduct(ctx context.Context, warehouseProductID uint) error {
+func (w *warehouseProductUsecase) DeleteWarehouseProduct(ctx context.Context, warehouseProductID uint, staffID uint) error {
+	if staffID != 0 {
+		existing, err := w.warehouseProductRepo.GetDetailWarehouseProductByID(ctx, warehouseProductID)
+		if err != nil {
+			log.Errorf("[WarehouseProductUsecase] DeleteWarehouseProduct - 1: %v", err)
+			return err
+		}
+		if err := checkWarehouseStaff(ctx, w.warehouseRepo, existing.WarehouseID, staffID); err != nil {
+			log.Errorf("[WarehouseProductUsecase] DeleteWarehouseProduct - 2: %v", err)
+			return err
+		}
+	}
 	return w.warehouseProductRepo.DeleteWarehouseProduct(ctx, warehouseProductID)
 }
 
 // GetDetailWarehouse implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) GetDetailWarehouse(ctx context.Context, warehouseID uint) (*model.Warehouse, []httpclient.ProductResponse, error) {
+func (w *warehouseProductUsecase) GetDetailWarehouse(ctx context.Context, warehouseID uint, staffID uint) (*model.Warehouse, []httpclient.ProductResponse, error) {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouseID, staffID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouse - 1: %v", err)
+		return nil, nil, err
+	}
+
 	warehouse, err := w.warehouseProductRepo.GetDetailWarehouse(ctx, warehouseID)
 	if err != nil {
-		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouse- 1: %v", err)
+		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouse - 2: %v", err)
 		return nil, nil, err
 	}
 
@@ -69,7 +98,7 @@ func (w *warehouseProductUsecase) GetDetailWarehouse(ctx context.Context, wareho
 		for _, wp := range warehouse.WarehouseProducts {
 			product, err := w.productClient.GetProductByID(ctx, wp.ProductID)
 			if err != nil {
-				log.Errorf("[WarehouseProductUsecase] GetDetailWarehouse - 2: %v", err)
+				log.Errorf("[WarehouseProductUsecase] GetDetailWarehouse - 3: %v", err)
 				return nil, nil, err
 			}
 
@@ -81,16 +110,21 @@ func (w *warehouseProductUsecase) GetDetailWarehouse(ctx context.Context, wareho
 }
 
 // GetDetailWarehouseProductByID implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) GetDetailWarehouseProductByID(ctx context.Context, warehouseProductID uint) (*model.WarehouseProduct, *httpclient.ProductResponse, error) {
+func (w *warehouseProductUsecase) GetDetailWarehouseProductByID(ctx context.Context, warehouseProductID uint, staffID uint) (*model.WarehouseProduct, *httpclient.ProductResponse, error) {
 	warehouseProduct, err := w.warehouseProductRepo.GetDetailWarehouseProductByID(ctx, warehouseProductID)
 	if err != nil {
 		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouseProductByID - 1: %v", err)
 		return nil, nil, err
 	}
 
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouseProduct.WarehouseID, staffID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouseProductByID - 2: %v", err)
+		return nil, nil, err
+	}
+
 	product, err := w.productClient.GetProductByID(ctx, warehouseProduct.ProductID)
 	if err != nil {
-		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouseProductByID - 2 %v", err)
+		log.Errorf("[WarehouseProductUsecase] GetDetailWarehouseProductByID - 3 %v", err)
 		return nil, nil, err
 	}
 
@@ -103,23 +137,75 @@ func (w *warehouseProductUsecase) GetProductTotalStock(ctx context.Context, prod
 }
 
 // GetWarehouseProductByProductID implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error) {
-	return w.warehouseProductRepo.GetWarehouseProductByProductID(ctx, productID)
+func (w *warehouseProductUsecase) GetWarehouseProductByProductID(ctx context.Context, productID uint, staffID uint) ([]model.WarehouseProduct, error) {
+	return w.warehouseProductRepo.GetWarehouseProductByProductID(ctx, productID, staffID)
 }
 
 // GetWarehouseProductByWarehouseIDAndProductID implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID uint, productID uint) (*model.WarehouseProduct, error) {
-	return w.warehouseProductRepo.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseID, productID)
+func (w *warehouseProductUsecase) GetWarehouseProductByWarehouseIDAndProductID(ctx context.Context, warehouseID uint, productID uint, variantID uint, staffID uint) (*model.WarehouseProduct, error) {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouseID, staffID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] GetWarehouseProductByWarehouseIDAndProductID - 1: %v", err)
+		return nil, err
+	}
+	return w.warehouseProductRepo.GetWarehouseProductByWarehouseIDAndProductID(ctx, warehouseID, productID, variantID)
 }
 
 // UpdateWarehouseProduct implements WarehouseProductUsecaseInterface.
-func (w *warehouseProductUsecase) UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error {
+func (w *warehouseProductUsecase) UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct, unit string, staffID uint) error {
+	if staffID != 0 {
+		existing, err := w.warehouseProductRepo.GetDetailWarehouseProductByID(ctx, warehouseProduct.ID)
+		if err != nil {
+			log.Errorf("[WarehouseProductUsecase] UpdateWarehouseProduct - 1: %v", err)
+			return err
+		}
+		if err := checkWarehouseStaff(ctx, w.warehouseRepo, existing.WarehouseID, staffID); err != nil {
+			log.Errorf("[WarehouseProductUsecase] UpdateWarehouseProduct - 2: %v", err)
+			return err
+		}
+		// Moving stock into a warehouse needs that warehouse too.
+		if warehouseProduct.WarehouseID != 0 && warehouseProduct.WarehouseID != existing.WarehouseID {
+			if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouseProduct.WarehouseID, staffID); err != nil {
+				log.Errorf("[WarehouseProductUsecase] UpdateWarehouseProduct - 3: %v", err)
+				return err
+			}
+		}
+	}
+
+	if err := w.toBaseUnits(ctx, warehouseProduct, unit); err != nil {
+		log.Errorf("[WarehouseProductUsecase] UpdateWarehouseProduct - 4: %v", err)
+		return err
+	}
+
 	return w.warehouseProductRepo.UpdateWarehouseProduct(ctx, warehouseProduct)
 }
 
-func NewWarehouseProductUsecase(warehouseProductRepo repository.WarehouseProductRepositoryInterface, productClient httpclient.ProductClientInterface) WarehouseProductUsecaseInterface {
+// toBaseUnits checks the variant belongs to the product and converts the
+// stock, counted in unit, to the base unit of the product.
+func (w *warehouseProductUsecase) toBaseUnits(ctx context.Context, warehouseProduct *model.WarehouseProduct, unit string) error {
+	if warehouseProduct.VariantID == 0 && unit == "" {
+		return nil
+	}
+
+	product, err := w.productClient.GetProductByID(ctx, warehouseProduct.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := product.CheckVariant(warehouseProduct.VariantID); err != nil {
+		return err
+	}
+
+	stock, err := product.BaseQuantity(unit, warehouseProduct.Stock)
+	if err != nil {
+		return err
+	}
+	warehouseProduct.Stock = stock
+	return nil
+}
+
+func NewWarehouseProductUsecase(warehouseProductRepo repository.WarehouseProductRepositoryInterface, warehouseRepo repository.WarehouseRepositoryInterface, productClient httpclient.ProductClientInterface) WarehouseProductUsecaseInterface {
 	return &warehouseProductUsecase{
 		warehouseProductRepo: warehouseProductRepo,
+		warehouseRepo:        warehouseRepo,
 		productClient:        productClient,
 	}
 }
\ No newline at end of file
@@ -2,16 +2,26 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"warehouse-go/warehouse-service/model"
 	"warehouse-go/warehouse-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
+// ErrWarehouseNotAssigned is returned when a caller limited to their
+// assigned warehouses touches another one.
+var ErrWarehouseNotAssigned = errors.New("warehouse is not assigned to the user")
+
+// The staffID arguments limit a call to the warehouses that user is assigned
+// to, 0 means no limit.
 type WarehouseUsecaseInterface interface {
 	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
-	GetAllWarehouse(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Warehouse, int64, error)
-	GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error)
-	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
-	DeleteWarehouse(ctx context.Context, id uint) error
+	GetAllWarehouse(ctx context.Context, page, limit int, search, sortBy, sortOrder string, staffID uint, ids []uint) ([]model.Warehouse, int64, error)
+	GetWarehouseByID(ctx context.Context, id, staffID uint) (*model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse, staffID uint) error
+	DeleteWarehouse(ctx context.Context, id, staffID uint) error
+	ReplaceWarehouseStaff(ctx context.Context, warehouseID uint, userIDs []uint) error
 }
 
 type warehouseUsecase struct {
@@ -24,25 +34,74 @@ func (w *warehouseUsecase) CreateWarehouse(ctx context.Context, warehouse *model
 }
 
 // DeleteWarehouse implements WarehouseUsecaseInterface.
-func (w *warehouseUsecase) DeleteWarehouse(ctx context.Context, id uint) error {
+func (w *warehouseUsecase) DeleteWarehouse(ctx context.Context, id uint, staffID uint) error {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, id, staffID); err != nil {
+		log.Errorf("[WarehouseUsecase] DeleteWarehouse - 1: %v", err)
+		return err
+	}
 	return w.warehouseRepo.DeleteWarehouse(ctx, id)
 }
 
 // GetAllWarehouse implements WarehouseUsecaseInterface.
-func (w *warehouseUsecase) GetAllWarehouse(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Warehouse, int64, error) {
-	return w.warehouseRepo.GetAllWarehouse(ctx, page, limit, search, sortBy, sortOrder)
+func (w *warehouseUsecase) GetAllWarehouse(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, staffID uint, ids []uint) ([]model.Warehouse, int64, error) {
+	return w.warehouseRepo.GetAllWarehouse(ctx, page, limit, search, sortBy, sortOrder, staffID, ids)
 }
 
 // GetWarehouseByID implements WarehouseUsecaseInterface.
-func (w *warehouseUsecase) GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error) {
+func (w *warehouseUsecase) GetWarehouseByID(ctx context.Context, id uint, staffID uint) (*model.Warehouse, error) {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, id, staffID); err != nil {
+		log.Errorf("[WarehouseUsecase] GetWarehouseByID - 1: %v", err)
+		return nil, err
+	}
 	return w.warehouseRepo.GetWarehouseByID(ctx, id)
 }
 
 // UpdateWarehouse implements WarehouseUsecaseInterface.
-func (w *warehouseUsecase) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+func (w *warehouseUsecase) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse, staffID uint) error {
+	if err := checkWarehouseStaff(ctx, w.warehouseRepo, warehouse.ID, staffID); err != nil {
+		log.Errorf("[WarehouseUsecase] UpdateWarehouse - 1: %v", err)
+		return err
+	}
 	return w.warehouseRepo.UpdateWarehouse(ctx, warehouse)
 }
 
+// ReplaceWarehouseStaff implements WarehouseUsecaseInterface. Duplicates
+// are dropped.
+func (w *warehouseUsecase) ReplaceWarehouseStaff(ctx context.Context, warehouseID uint, userIDs []uint) error {
+	seen := make(map[uint]bool, len(userIDs))
+	staff := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		staff = append(staff, userID)
+	}
+
+	if err := w.warehouseRepo.ReplaceWarehouseStaff(ctx, warehouseID, staff); err != nil {
+		log.Errorf("[WarehouseUsecase] ReplaceWarehouseStaff - 1: %v", err)
+		return err
+	}
+	return nil
+}
+
+// checkWarehouseStaff returns ErrWarehouseNotAssigned unless staffID is 0 or
+// is assigned to the warehouse.
+func checkWarehouseStaff(ctx context.Context, warehouseRepo repository.WarehouseRepositoryInterface, warehouseID, staffID uint) error {
+	if staffID == 0 {
+		return nil
+	}
+
+	assigned, err := warehouseRepo.IsWarehouseStaff(ctx, warehouseID, staffID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrWarehouseNotAssigned
+	}
+	return nil
+}
+
 func NewWarehouseUsecase(warehouseRepo repository.WarehouseRepositoryInterface) WarehouseUsecaseInterface {
 	return &warehouseUsecase{warehouseRepo: warehouseRepo}
 }